@@ -8,6 +8,8 @@ CREATE TABLE events (type VARCHAR(64) NOT NULL,
                      transaction_id xid8 NOT NULL,
                      position BIGSERIAL NOT NULL PRIMARY KEY,
                      occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                     metadata JSONB, -- optional non-queryable metadata (e.g. causation)
+                     command_transaction_id xid8, -- set by ExecuteCommand: the transaction of the command that produced the event
                      CONSTRAINT chk_event_type_length CHECK (LENGTH(type) <= 64));
 
 -- Create the commands table for command tracking
@@ -19,6 +21,73 @@ CREATE TABLE commands (
     occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Commands that failed in a CommandExecutor with CommandExecutorConfig.DeadLetter enabled
+CREATE TABLE dcb_failed_commands (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(64) NOT NULL,
+    data JSONB NOT NULL,
+    metadata JSONB,
+    error TEXT NOT NULL,
+    panicked BOOLEAN NOT NULL DEFAULT FALSE,
+    attempts INTEGER NOT NULL DEFAULT 1,
+    failed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    resolved_transaction_id xid8
+);
+
+-- Commands scheduled with CommandExecutor.ScheduleCommand and executed by RunDue
+CREATE TABLE dcb_scheduled_commands (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(64) NOT NULL,
+    data JSONB NOT NULL,
+    metadata JSONB,
+    due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    scheduled_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    done_at TIMESTAMP WITH TIME ZONE,
+    done_transaction_id xid8,
+    failed_command_id BIGINT
+);
+
+CREATE INDEX idx_dcb_scheduled_commands_due ON dcb_scheduled_commands (due_at, id) WHERE done_at IS NULL;
+
+-- Progress of named EventStore.Replay runs, so a restarted replay resumes after its checkpoint
+CREATE TABLE dcb_replay_checkpoints (
+    name TEXT PRIMARY KEY,
+    transaction_id xid8 NOT NULL,
+    position BIGINT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Rows and checkpoints of the read models kept by EventStore.MaterializeView
+CREATE TABLE dcb_view_rows (
+    view_name TEXT NOT NULL,
+    key TEXT NOT NULL,
+    state JSONB NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (view_name, key)
+);
+
+CREATE TABLE dcb_view_checkpoints (
+    view_name TEXT PRIMARY KEY,
+    transaction_id xid8 NOT NULL DEFAULT '0',
+    position BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Per-aggregate versions of EventStoreConfig.AggregateVersionTagKeys, bumped by every append
+CREATE TABLE dcb_aggregate_versions (
+    tag TEXT PRIMARY KEY,
+    version BIGINT NOT NULL
+);
+
+-- Tags blocked with BlockTag, shared by stores with EventStoreConfig.SharedTagBlocks
+CREATE TABLE dcb_blocked_tags (
+    tag TEXT PRIMARY KEY,
+    blocked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
 -- Indexes for commands table
 -- CREATE INDEX idx_commands_type ON commands (type);
 -- CREATE INDEX idx_commands_target_table ON commands (target_events_table);
@@ -27,23 +96,74 @@ CREATE TABLE commands (
 CREATE INDEX idx_events_transaction_position_btree ON events (transaction_id, position);
 CREATE INDEX idx_events_tags ON events USING GIN (tags);
 CREATE INDEX idx_events_type ON events (type);
+CREATE INDEX idx_events_metadata ON events USING GIN (metadata jsonb_path_ops);
+
+-- Tags with lower-cased values (keys unchanged) for case-insensitive tag matching (QueryBuilder.WithTagCI)
+-- IMMUTABLE so it can back the optional index created by dcb.CreateCaseInsensitiveTagIndex:
+-- CREATE INDEX CONCURRENTLY idx_events_tags_lower ON events USING GIN (lower_tag_values(tags));
+CREATE OR REPLACE FUNCTION lower_tag_values(p_tags TEXT[]) RETURNS TEXT[] AS $$
+    SELECT COALESCE(array_agg(split_part(t, ':', 1) || ':' || lower(substr(t, strpos(t, ':') + 1))), '{}')
+    FROM unnest(p_tags) AS t
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+
+-- Numeric value of the first tag with p_key whose value is a decimal number, NULL if none
+-- (QueryBuilder.WithTagNumeric); the pattern guards the cast. IMMUTABLE so it can back the optional
+-- indexes created by dcb.CreateNumericTagIndex:
+-- CREATE INDEX CONCURRENTLY idx_events_numeric_priority ON events (numeric_tag_value(tags, 'priority'));
+CREATE OR REPLACE FUNCTION numeric_tag_value(p_tags TEXT[], p_key TEXT) RETURNS NUMERIC AS $$
+    SELECT substr(t, length(p_key) + 2)::numeric
+    FROM unnest(p_tags) WITH ORDINALITY AS u(t, ord)
+    WHERE starts_with(t, p_key || ':') AND substr(t, length(p_key) + 2) ~ '^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+)?$'
+    ORDER BY ord
+    LIMIT 1
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+
+-- The "key:value" tags of p_keys in p_keys order, or NULL unless each key has exactly one tag
+-- IMMUTABLE so it can back the unique indexes created by dcb.CreateUniqueTagIndexes for
+-- EventStoreConfig.UniqueTags, e.g. one TicketsBooked per concert and customer:
+-- CREATE UNIQUE INDEX CONCURRENTLY idx_events_unique_booking ON events
+--     (unique_tag_values(tags, ARRAY['concert_id', 'customer_id']::TEXT[])) WHERE type = 'TicketsBooked';
+CREATE OR REPLACE FUNCTION unique_tag_values(p_tags TEXT[], p_keys TEXT[]) RETURNS TEXT[] AS $$
+    SELECT CASE WHEN count(*) = cardinality(p_keys) THEN array_agg(t ORDER BY k.ord) END
+    FROM unnest(p_keys) WITH ORDINALITY AS k(key, ord)
+    JOIN unnest(p_tags) AS t ON split_part(t, ':', 1) = k.key
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
 
 -- Function to batch insert events using UNNEST for better performance
 -- Always uses 'events' table for maximum performance
+-- Returns the position and transaction id of every inserted event (INSERT ... RETURNING), so
+-- callers learn the assigned positions without querying the events table again
 CREATE OR REPLACE FUNCTION append_events_batch(
     p_types TEXT[],
     p_tags TEXT[], -- array of Postgres array literals as strings
-    p_data JSONB[]
-) RETURNS VOID AS $$
+    p_data JSONB[],
+    p_metadata JSONB[] DEFAULT NULL, -- optional per-event metadata (NULL entries allowed)
+    p_positions BIGINT[] DEFAULT NULL, -- optional positions from a PositionAllocator (NULL = sequence)
+    p_occurred_at TIMESTAMPTZ DEFAULT NULL, -- optional timestamp from EventStoreConfig.Clock (NULL = transaction timestamp)
+    p_from_command BOOLEAN DEFAULT FALSE -- TRUE when ExecuteCommand appends the events of a command
+) RETURNS TABLE (appended_position BIGINT, appended_transaction_id xid8) AS $$
 BEGIN
     -- Insert directly into events table (no dynamic table name needed)
-    INSERT INTO events (type, tags, data, transaction_id)
-    SELECT 
-        t.type,
-        t.tag_string::TEXT[], -- Cast the array literal string to TEXT[]
-        t.data,
-        pg_current_xact_id()
-    FROM UNNEST($1, $2, $3) AS t(type, tag_string, data);
+    -- UNNEST pads NULL/shorter metadata and position arrays with NULLs
+    -- WITH ORDINALITY keeps sequence-assigned positions in input order: nextval is evaluated
+    -- after the sort, so positions strictly increase in array order (a documented append guarantee)
+    RETURN QUERY
+    WITH inserted AS (
+        INSERT INTO events (type, tags, data, transaction_id, metadata, position, occurred_at, command_transaction_id)
+        SELECT
+            t.type,
+            t.tag_string::TEXT[], -- Cast the array literal string to TEXT[]
+            t.data,
+            pg_current_xact_id(),
+            t.metadata,
+            COALESCE(t.position, nextval(pg_get_serial_sequence('events', 'position'))),
+            COALESCE(p_occurred_at, CURRENT_TIMESTAMP),
+            CASE WHEN p_from_command THEN pg_current_xact_id() END
+        FROM UNNEST(p_types, p_tags, p_data, p_metadata, p_positions) WITH ORDINALITY AS t(type, tag_string, data, metadata, position, ord)
+        ORDER BY t.ord
+        RETURNING events.position, events.transaction_id
+    )
+    SELECT inserted.position, inserted.transaction_id FROM inserted;
 END;
 $$ LANGUAGE plpgsql;
 
@@ -56,11 +176,16 @@ CREATE OR REPLACE FUNCTION append_events_if(
     p_event_types TEXT[] DEFAULT NULL,
     p_condition_tags TEXT[] DEFAULT NULL,
     p_after_cursor_tx_id xid8 DEFAULT NULL,
-    p_after_cursor_position BIGINT DEFAULT NULL
+    p_after_cursor_position BIGINT DEFAULT NULL,
+    p_metadata JSONB[] DEFAULT NULL,
+    p_positions BIGINT[] DEFAULT NULL,
+    p_occurred_at TIMESTAMPTZ DEFAULT NULL,
+    p_from_command BOOLEAN DEFAULT FALSE
 ) RETURNS JSONB AS $$
 DECLARE
     condition_count INTEGER;
     result JSONB;
+    appended_positions BIGINT[];
 BEGIN
     -- Initialize result
     result := '{"success": true, "message": "condition check passed"}'::JSONB;
@@ -98,13 +223,17 @@ BEGIN
     END IF;
     
     -- If conditions pass, insert events using UNNEST for all cases
-    PERFORM append_events_batch(p_types, p_tags, p_data);
+    SELECT array_agg(b.appended_position ORDER BY b.appended_position)
+    INTO appended_positions
+    FROM append_events_batch(p_types, p_tags, p_data, p_metadata, p_positions, p_occurred_at, p_from_command) AS b;
     
-    -- Return success status
+    -- Return success status with the assigned positions (the transaction id is the caller's own)
     RETURN jsonb_build_object(
         'success', true,
         'message', 'events appended successfully',
-        'events_count', array_length(p_types, 1)
+        'events_count', array_length(p_types, 1),
+        'positions', to_jsonb(appended_positions),
+        'transaction_id', pg_current_xact_id()::TEXT
     );
 END;
 $$ LANGUAGE plpgsql;
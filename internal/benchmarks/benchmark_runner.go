@@ -192,6 +192,7 @@ func SetupProjectionBenchmarkContext(b *testing.B, datasetSize string) *Benchmar
 		DefaultAppendIsolation: dcb.IsolationLevelReadCommitted,
 		QueryTimeout:           15000,
 		AppendTimeout:          15000,
+		AllowTruncate:          true, // Benchmarks reset the events table between runs
 	}
 
 	repeatableReadConfig := dcb.EventStoreConfig{
@@ -238,6 +239,7 @@ func SetupBenchmarkContext(b *testing.B, datasetSize string, pastEventCount int)
 		DefaultAppendIsolation: dcb.IsolationLevelReadCommitted,
 		QueryTimeout:           15000,
 		AppendTimeout:          15000,
+		AllowTruncate:          true, // Benchmarks reset the events table between runs
 	}
 
 	repeatableReadConfig := dcb.EventStoreConfig{
@@ -310,13 +312,7 @@ func BenchmarkAppendIfConcurrent(b *testing.B, benchCtx *BenchmarkContext, concu
 	defer cancel()
 
 	// Truncate events table BEFORE timing starts (but after dataset is loaded)
-	pool, err := getOrCreateGlobalPool()
-	if err != nil {
-		b.Fatalf("Failed to get global pool: %v", err)
-	}
-
-	_, err = pool.Exec(ctx, "TRUNCATE TABLE events RESTART IDENTITY CASCADE")
-	if err != nil {
+	if err := benchCtx.Store.Truncate(ctx); err != nil {
 		b.Fatalf("Failed to truncate events table: %v", err)
 	}
 
@@ -479,13 +475,7 @@ func BenchmarkAppendIfConcurrentRealistic(b *testing.B, benchCtx *BenchmarkConte
 	defer cancel()
 
 	// Truncate events table BEFORE timing starts (but after dataset is loaded)
-	pool, err := getOrCreateGlobalPool()
-	if err != nil {
-		b.Fatalf("Failed to get global pool: %v", err)
-	}
-
-	_, err = pool.Exec(ctx, "TRUNCATE TABLE events RESTART IDENTITY CASCADE")
-	if err != nil {
+	if err := benchCtx.Store.Truncate(ctx); err != nil {
 		b.Fatalf("Failed to truncate events table: %v", err)
 	}
 
@@ -749,13 +739,7 @@ func BenchmarkAppendConcurrent(b *testing.B, benchCtx *BenchmarkContext, concurr
 	defer cancel()
 
 	// Truncate events table BEFORE timing starts (but after dataset is loaded)
-	pool, err := getOrCreateGlobalPool()
-	if err != nil {
-		b.Fatalf("Failed to get global pool: %v", err)
-	}
-
-	_, err = pool.Exec(ctx, "TRUNCATE TABLE events RESTART IDENTITY CASCADE")
-	if err != nil {
+	if err := benchCtx.Store.Truncate(ctx); err != nil {
 		b.Fatalf("Failed to truncate events table: %v", err)
 	}
 
@@ -860,13 +844,7 @@ func BenchmarkAppendConcurrentRealistic(b *testing.B, benchCtx *BenchmarkContext
 	defer cancel()
 
 	// Truncate events table BEFORE timing starts (but after dataset is loaded)
-	pool, err := getOrCreateGlobalPool()
-	if err != nil {
-		b.Fatalf("Failed to get global pool: %v", err)
-	}
-
-	_, err = pool.Exec(ctx, "TRUNCATE TABLE events RESTART IDENTITY CASCADE")
-	if err != nil {
+	if err := benchCtx.Store.Truncate(ctx); err != nil {
 		b.Fatalf("Failed to truncate events table: %v", err)
 	}
 
@@ -1034,13 +1012,7 @@ func BenchmarkProjectConcurrent(b *testing.B, benchCtx *BenchmarkContext, gorout
 	defer cancel()
 
 	// Truncate events table and create test data BEFORE timing starts
-	pool, err := getOrCreateGlobalPool()
-	if err != nil {
-		b.Fatalf("Failed to get global pool: %v", err)
-	}
-
-	_, err = pool.Exec(ctx, "TRUNCATE TABLE events RESTART IDENTITY CASCADE")
-	if err != nil {
+	if err := benchCtx.Store.Truncate(ctx); err != nil {
 		b.Fatalf("Failed to truncate events table: %v", err)
 	}
 
@@ -1143,13 +1115,7 @@ func BenchmarkProjectConcurrentRealistic(b *testing.B, benchCtx *BenchmarkContex
 	defer cancel()
 
 	// Truncate events table and create realistic test data BEFORE timing starts
-	pool, err := getOrCreateGlobalPool()
-	if err != nil {
-		b.Fatalf("Failed to get global pool: %v", err)
-	}
-
-	_, err = pool.Exec(ctx, "TRUNCATE TABLE events RESTART IDENTITY CASCADE")
-	if err != nil {
+	if err := benchCtx.Store.Truncate(ctx); err != nil {
 		b.Fatalf("Failed to truncate events table: %v", err)
 	}
 
@@ -1303,13 +1269,7 @@ func BenchmarkProjectStreamConcurrent(b *testing.B, benchCtx *BenchmarkContext,
 	ctx := context.Background()
 
 	// Truncate events table and create test data BEFORE timing starts
-	pool, err := getOrCreateGlobalPool()
-	if err != nil {
-		b.Fatalf("Failed to get global pool: %v", err)
-	}
-
-	_, err = pool.Exec(ctx, "TRUNCATE TABLE events RESTART IDENTITY CASCADE")
-	if err != nil {
+	if err := benchCtx.Store.Truncate(ctx); err != nil {
 		b.Fatalf("Failed to truncate events table: %v", err)
 	}
 
@@ -1443,13 +1403,7 @@ func BenchmarkProjectStreamConcurrentRealistic(b *testing.B, benchCtx *Benchmark
 	ctx := context.Background()
 
 	// Truncate events table and create realistic test data BEFORE timing starts
-	pool, err := getOrCreateGlobalPool()
-	if err != nil {
-		b.Fatalf("Failed to get global pool: %v", err)
-	}
-
-	_, err = pool.Exec(ctx, "TRUNCATE TABLE events RESTART IDENTITY CASCADE")
-	if err != nil {
+	if err := benchCtx.Store.Truncate(ctx); err != nil {
 		b.Fatalf("Failed to truncate events table: %v", err)
 	}
 
@@ -1880,6 +1834,73 @@ func BenchmarkProjectionLimitsRealistic(b *testing.B, benchCtx *BenchmarkContext
 	}
 }
 
+// BenchmarkProjectManyWorkers benchmarks ProjectMany loading 20 independent projector sets
+// with the given number of projection workers, to compare throughput against worker count
+func BenchmarkProjectManyWorkers(b *testing.B, benchCtx *BenchmarkContext, workers int) {
+	ctx, cancel := context.WithTimeoutCause(context.Background(), 2*time.Minute,
+		fmt.Errorf("ProjectMany benchmark timeout after 2 minutes"))
+	defer cancel()
+
+	pool, err := getOrCreateGlobalPool()
+	if err != nil {
+		b.Fatalf("Failed to get global pool: %v", err)
+	}
+
+	// Same configuration as the projection benchmark store, with the worker count under test
+	config := benchCtx.Store.GetConfig()
+	config.ProjectionWorkers = workers
+	store, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+	if err != nil {
+		b.Fatalf("Failed to create event store: %v", err)
+	}
+
+	// Truncate events table and create test data BEFORE timing starts
+	if err := store.Truncate(ctx); err != nil {
+		b.Fatalf("Failed to truncate events table: %v", err)
+	}
+
+	// 100 events spread over 20 groups, one projector set per group
+	const groups = 20
+	testEvents := make([]dcb.InputEvent, 100)
+	for i := 0; i < 100; i++ {
+		eventID := fmt.Sprintf("test_event_%d", i)
+		testEvents[i] = dcb.NewInputEvent("TestEvent",
+			dcb.NewTags("test", "project_many", "group_id", fmt.Sprintf("group_%d", i%groups)),
+			[]byte(fmt.Sprintf(`{"value": "test", "unique_id": "%s"}`, eventID)))
+	}
+	if err := store.Append(ctx, testEvents); err != nil {
+		b.Fatalf("Failed to append test events: %v", err)
+	}
+
+	projectorSets := make([][]dcb.StateProjector, groups)
+	for i := range projectorSets {
+		projectorSets[i] = []dcb.StateProjector{{
+			ID:           "test_project_many",
+			Query:        dcb.NewQueryBuilder().WithType("TestEvent").WithTag("group_id", fmt.Sprintf("group_%d", i)).Build(),
+			InitialState: 0,
+			TransitionFn: func(state any, event dcb.Event) any {
+				return state.(int) + 1
+			},
+		}}
+	}
+
+	// WARM-UP PHASE: Run the exact same logic we'll benchmark without timing
+	warmupBenchmark(func() {
+		_, _ = store.ProjectMany(ctx, projectorSets)
+	})
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := store.ProjectMany(ctx, projectorSets); err != nil {
+			b.Fatalf("ProjectMany failed: %v", err)
+		}
+	}
+
+	b.ReportMetric(float64(groups*b.N)/b.Elapsed().Seconds(), "sets/s")
+}
+
 // RunAllBenchmarks runs all benchmarks with the specified dataset size
 func RunAllBenchmarks(b *testing.B, datasetSize string) {
 	// Use 100 past events for realistic AppendIf testing (business rule validation context)
@@ -1941,6 +1962,21 @@ func RunAllBenchmarks(b *testing.B, datasetSize string) {
 		BenchmarkAppendIfConcurrent(b, benchCtx, 100, 10, true)
 	})
 
+	// Tail latency under a sustained 100 requests/s for 3s, comparable across isolation levels
+	for _, load := range []struct {
+		name string
+		load LatencyLoad
+	}{
+		{"Append_Latency_ReadCommitted_100RPS", LatencyLoad{Rate: 100, Duration: 3 * time.Second, Isolation: dcb.IsolationLevelReadCommitted}},
+		{"AppendIf_Latency_ReadCommitted_100RPS", LatencyLoad{Rate: 100, Duration: 3 * time.Second, Isolation: dcb.IsolationLevelReadCommitted, Conditional: true}},
+		{"AppendIf_Latency_RepeatableRead_100RPS", LatencyLoad{Rate: 100, Duration: 3 * time.Second, Isolation: dcb.IsolationLevelRepeatableRead, Conditional: true}},
+		{"AppendIf_Latency_Serializable_100RPS", LatencyLoad{Rate: 100, Duration: 3 * time.Second, Isolation: dcb.IsolationLevelSerializable, Conditional: true}},
+	} {
+		b.Run(load.name, func(b *testing.B) {
+			BenchmarkAppendLatency(b, benchCtx, load.load)
+		})
+	}
+
 	// Projection benchmarks
 	// Use separate setup for projection benchmarks to avoid scanning large datasets
 	projectionCtx := SetupProjectionBenchmarkContext(b, datasetSize)
@@ -1975,6 +2011,19 @@ func RunAllBenchmarks(b *testing.B, datasetSize string) {
 		BenchmarkProjectionLimits(b, projectionCtx, 10)
 	})
 
+	// ProjectMany throughput by projection worker count
+	b.Run("ProjectMany_1Worker", func(b *testing.B) {
+		BenchmarkProjectManyWorkers(b, projectionCtx, 1)
+	})
+
+	b.Run("ProjectMany_4Workers", func(b *testing.B) {
+		BenchmarkProjectManyWorkers(b, projectionCtx, 4)
+	})
+
+	b.Run("ProjectMany_16Workers", func(b *testing.B) {
+		BenchmarkProjectManyWorkers(b, projectionCtx, 16)
+	})
+
 }
 
 // BenchmarkQueryConcurrentRealistic benchmarks realistic Query operations with business events
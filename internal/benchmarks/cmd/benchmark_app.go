@@ -180,7 +180,12 @@ func benchmarkSingleAppend(ctx context.Context, store dcb.EventStore) {
 func benchmarkBatchAppend(ctx context.Context, store dcb.EventStore) {
 	fmt.Println("Batch Append:")
 
+	// Up to the largest batch the store accepts; appends return their positions via RETURNING,
+	// so the conditional path (which reports them back as JSON) is timed as well
 	batchSizes := []int{10, 100, 1000}
+	if maxSize := store.GetConfig().MaxAppendBatchSize; maxSize > batchSizes[len(batchSizes)-1] {
+		batchSizes = append(batchSizes, maxSize)
+	}
 
 	for _, size := range batchSizes {
 		events := make([]dcb.InputEvent, size)
@@ -198,6 +203,18 @@ func benchmarkBatchAppend(ctx context.Context, store dcb.EventStore) {
 		}
 
 		fmt.Printf("  Batch %d: %v (%.2f events/sec)\n", size, duration, float64(size)/duration.Seconds())
+
+		condition := dcb.NewAppendCondition(dcb.NewQuery(dcb.NewTags("test", "batch-none"), "TestEvent"))
+		start = time.Now()
+		err = store.AppendIf(ctx, events, condition)
+		duration = time.Since(start)
+
+		if err != nil {
+			fmt.Printf("  Batch %d (AppendIf): Error: %v\n", size, err)
+			continue
+		}
+
+		fmt.Printf("  Batch %d (AppendIf): %v (%.2f events/sec)\n", size, duration, float64(size)/duration.Seconds())
 	}
 }
 
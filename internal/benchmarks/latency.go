@@ -0,0 +1,162 @@
+package benchmarks
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+)
+
+// LatencyLoad describes a fixed-rate load for RunLatencyLoad
+type LatencyLoad struct {
+	Rate        int                // Requests started per second
+	Duration    time.Duration      // How long requests are started for
+	Isolation   dcb.IsolationLevel // Append isolation level of the store under load
+	Conditional bool               // AppendIf with a FailIfExists condition instead of Append
+	EventsPerOp int                // Events appended per request (0 = 1)
+}
+
+// LatencyReport summarizes a RunLatencyLoad run. Latencies are measured from when a request was
+// due, not when it started, so a store falling behind the rate shows up in the tail instead of
+// silently lowering the load (coordinated omission)
+type LatencyReport struct {
+	Operation   string
+	Isolation   dcb.IsolationLevel
+	Requests    int
+	Errors      int // Failed requests, including serialization failures; not in the latencies
+	AchievedRPS float64
+	P50         time.Duration
+	P95         time.Duration
+	P99         time.Duration
+	Max         time.Duration
+}
+
+// String formats the report as one line, so runs at different isolation levels or dataset sizes
+// can be compared side by side
+func (r LatencyReport) String() string {
+	return fmt.Sprintf("%-8s %-16s requests=%d errors=%d rps=%.1f p50=%s p95=%s p99=%s max=%s",
+		r.Operation, r.Isolation, r.Requests, r.Errors, r.AchievedRPS,
+		r.P50.Round(time.Microsecond), r.P95.Round(time.Microsecond), r.P99.Round(time.Microsecond), r.Max.Round(time.Microsecond))
+}
+
+// RunLatencyLoad starts load.Rate requests per second against store for load.Duration, each in its
+// own goroutine, waits for them to finish and reports their latency percentiles. Every request
+// appends events with a unique op_id tag, so conditional requests never conflict and the
+// latencies measure the cost of the append and its isolation level
+func RunLatencyLoad(ctx context.Context, store dcb.EventStore, load LatencyLoad) (LatencyReport, error) {
+	if load.Rate <= 0 || load.Duration <= 0 {
+		return LatencyReport{}, fmt.Errorf("latency load needs a positive rate and duration, got %d/s for %s", load.Rate, load.Duration)
+	}
+	eventsPerOp := max(load.EventsPerOp, 1)
+	report := LatencyReport{Operation: "Append", Isolation: load.Isolation}
+	if load.Conditional {
+		report.Operation = "AppendIf"
+	}
+
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		wg        sync.WaitGroup
+	)
+	interval := time.Second / time.Duration(load.Rate)
+	requests := int(load.Duration / interval)
+	start := time.Now()
+	for i := range requests {
+		due := start.Add(time.Duration(i) * interval)
+		select {
+		case <-time.After(time.Until(due)):
+		case <-ctx.Done():
+			wg.Wait()
+			return LatencyReport{}, ctx.Err()
+		}
+
+		wg.Go(func() {
+			opID := fmt.Sprintf("latency_%d_%d", start.UnixNano(), i)
+			events := make([]dcb.InputEvent, eventsPerOp)
+			for j := range events {
+				events[j] = dcb.NewInputEvent("LatencyEvent",
+					dcb.NewTags("test", "latency", "op_id", opID),
+					[]byte(fmt.Sprintf(`{"op_id": "%s", "index": %d}`, opID, j)))
+			}
+
+			var err error
+			if load.Conditional {
+				err = store.AppendIf(ctx, events, dcb.FailIfExists("op_id", opID))
+			} else {
+				err = store.Append(ctx, events)
+			}
+			latency := time.Since(due)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				report.Errors++
+				return
+			}
+			latencies = append(latencies, latency)
+		})
+	}
+	wg.Wait()
+
+	report.Requests = requests
+	report.AchievedRPS = float64(len(latencies)) / time.Since(start).Seconds()
+	slices.Sort(latencies)
+	report.P50 = percentile(latencies, 50)
+	report.P95 = percentile(latencies, 95)
+	report.P99 = percentile(latencies, 99)
+	if len(latencies) > 0 {
+		report.Max = latencies[len(latencies)-1]
+	}
+	return report, nil
+}
+
+// percentile returns the p-th percentile of sorted latencies (nearest rank), 0 when empty
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100 // ceil(p/100 * n)
+	return sorted[max(rank, 1)-1]
+}
+
+// BenchmarkAppendLatency runs RunLatencyLoad once against a store like benchCtx.Store with the
+// load's isolation level, so it reuses whatever dataset benchCtx was set up with, and reports the
+// percentiles as p50-ms, p95-ms, p99-ms and max-ms metrics
+func BenchmarkAppendLatency(b *testing.B, benchCtx *BenchmarkContext, load LatencyLoad) {
+	ctx, cancel := context.WithTimeoutCause(context.Background(), load.Duration+time.Minute,
+		fmt.Errorf("latency benchmark timeout after %s", load.Duration+time.Minute))
+	defer cancel()
+
+	pool, err := getOrCreateGlobalPool()
+	if err != nil {
+		b.Fatalf("Failed to get global pool: %v", err)
+	}
+
+	config := benchCtx.Store.GetConfig()
+	config.DefaultAppendIsolation = load.Isolation
+	store, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+	if err != nil {
+		b.Fatalf("Failed to create event store: %v", err)
+	}
+
+	// The load runs for a fixed duration, not b.N iterations: one run per benchmark
+	b.ResetTimer()
+	report, err := RunLatencyLoad(ctx, store, load)
+	if err != nil {
+		b.Fatalf("Latency load failed: %v", err)
+	}
+	b.StopTimer()
+
+	b.Log(report)
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+	b.ReportMetric(ms(report.P50), "p50-ms")
+	b.ReportMetric(ms(report.P95), "p95-ms")
+	b.ReportMetric(ms(report.P99), "p99-ms")
+	b.ReportMetric(ms(report.Max), "max-ms")
+	b.ReportMetric(report.AchievedRPS, "req/s")
+	b.ReportMetric(float64(report.Errors), "errors")
+}
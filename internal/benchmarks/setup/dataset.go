@@ -440,9 +440,7 @@ func GenerateRandomQueries(dataset *Dataset, count int) []dcb.Query {
 }
 
 // TruncateDatabase truncates the events table and resets the position sequence
-// Note: This is a utility function for benchmarks, not part of the core DCB API
+// The store must be created with EventStoreConfig.AllowTruncate enabled
 func TruncateDatabase(ctx context.Context, store dcb.EventStore) error {
-	// For benchmarks, we should use direct SQL instead of going through the API
-	// This function is deprecated - use direct SQL: "TRUNCATE TABLE events RESTART IDENTITY CASCADE"
-	return fmt.Errorf("TruncateDatabase is deprecated - use direct SQL instead")
+	return store.Truncate(ctx)
 }
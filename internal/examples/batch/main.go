@@ -113,29 +113,10 @@ func main() {
 
 func handleCreateUser(ctx context.Context, store dcb.EventStore, cmd CreateUserCommand) error {
 	// Command-specific projectors
+	// Existence projectors stop reading as soon as both answers are known
 	projectors := []dcb.StateProjector{
-		{
-			ID: "userExists",
-			Query: dcb.NewQuery(
-				dcb.NewTags("user_id", cmd.UserID),
-				"UserCreated",
-			),
-			InitialState: false,
-			TransitionFn: func(state any, event dcb.Event) any {
-				return true // If we see a UserCreated event, user exists
-			},
-		},
-		{
-			ID: "emailExists",
-			Query: dcb.NewQuery(
-				dcb.NewTags("email", cmd.Email),
-				"UserCreated",
-			),
-			InitialState: false,
-			TransitionFn: func(state any, event dcb.Event) any {
-				return true // If we see a UserCreated event with this email, email exists
-			},
-		},
+		dcb.NewExistsProjector("userExists", dcb.NewQuery(dcb.NewTags("user_id", cmd.UserID), "UserCreated")),
+		dcb.NewExistsProjector("emailExists", dcb.NewQuery(dcb.NewTags("email", cmd.Email), "UserCreated")),
 	}
 
 	states, _, err := store.Project(ctx, projectors, nil)
@@ -164,8 +145,16 @@ func handleCreateUser(ctx context.Context, store dcb.EventStore, cmd CreateUserC
 			Build(),
 	}
 
-	// Append events atomically for this command
-	err = store.Append(ctx, events)
+	// The projection gives precise errors, but a concurrent create could take the ID or email
+	// before we append: the create condition re-checks both under a lock
+	unique := dcb.FailIfAnyExists(
+		dcb.TagMatch{Type: "UserCreated", Key: "user_id", Value: cmd.UserID},
+		dcb.TagMatch{Type: "UserCreated", Key: "email", Value: cmd.Email},
+	)
+	_, err = store.AppendIfNotExists(ctx, events, unique, dcb.OnConflictError)
+	if dcb.IsConcurrencyError(err) {
+		return fmt.Errorf("user %s or email %s already exists", cmd.UserID, cmd.Email)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
@@ -245,69 +234,48 @@ func handleCreateOrder(ctx context.Context, store dcb.EventStore, cmd CreateOrde
 }
 
 func handleBatchCreateUsers(ctx context.Context, store dcb.EventStore, commands []CreateUserCommand) error {
-	// Batch-specific projectors to check all users and emails at once
-	projectors := []dcb.StateProjector{}
-
-	// Add projectors for each user and email
-	for _, cmd := range commands {
-		projectors = append(projectors, dcb.StateProjector{
-			ID: fmt.Sprintf("userExists_%s", cmd.UserID),
-			Query: dcb.NewQuery(
-				dcb.NewTags("user_id", cmd.UserID),
-				"UserCreated",
-			),
-			InitialState: false,
-			TransitionFn: func(state any, event dcb.Event) any {
-				return true
-			},
-		})
-
-		projectors = append(projectors, dcb.StateProjector{
-			ID: fmt.Sprintf("emailExists_%s", cmd.Email),
-			Query: dcb.NewQuery(
-				dcb.NewTags("email", cmd.Email),
-				"UserCreated",
-			),
-			InitialState: false,
-			TransitionFn: func(state any, event dcb.Event) any {
-				return true
-			},
-		})
+	// Check all users and emails at once (one query per tag key instead of one projector per value)
+	userIDs := make([]string, len(commands))
+	emails := make([]string, len(commands))
+	for i, cmd := range commands {
+		userIDs[i] = cmd.UserID
+		emails[i] = cmd.Email
 	}
 
-	states, _, err := store.Project(ctx, projectors, nil)
+	existingUsers, err := store.ExistsAny(ctx, "UserCreated", "user_id", userIDs)
 	if err != nil {
 		return fmt.Errorf("failed to check batch user existence: %w", err)
 	}
+	existingEmails, err := store.ExistsAny(ctx, "UserCreated", "email", emails)
+	if err != nil {
+		return fmt.Errorf("failed to check batch email existence: %w", err)
+	}
 
 	// Batch-specific business rules
 	for _, cmd := range commands {
-		if states[fmt.Sprintf("userExists_%s", cmd.UserID)].(bool) {
+		if existingUsers[cmd.UserID] {
 			return fmt.Errorf("user %s already exists", cmd.UserID)
 		}
-		if states[fmt.Sprintf("emailExists_%s", cmd.Email)].(bool) {
+		if existingEmails[cmd.Email] {
 			return fmt.Errorf("email %s already exists", cmd.Email)
 		}
 	}
 
-	// Create events for all commands in the batch using BatchBuilder
-	batch := dcb.NewBatch()
+	// Create events for all commands in the batch; tags come from the data's json fields
+	var batch dcb.EventBatch
 	for _, cmd := range commands {
-		batch.AddEventFromBuilder(
-			dcb.NewEvent("UserCreated").
-				WithTag("user_id", cmd.UserID).
-				WithTag("email", cmd.Email).
-				WithData(UserCreatedData{
-					UserID:   cmd.UserID,
-					Username: cmd.Username,
-					Email:    cmd.Email,
-				}),
-		)
-	}
-	events := batch.Build()
+		batch.AddFromStruct("UserCreated", UserCreatedData{
+			UserID:   cmd.UserID,
+			Username: cmd.Username,
+			Email:    cmd.Email,
+		}, "user_id", "email")
+	}
+	if err := batch.Validate(); err != nil {
+		return fmt.Errorf("invalid user batch: %w", err)
+	}
 
 	// Append events atomically for this batch
-	err = store.Append(ctx, events)
+	err = store.Append(ctx, batch)
 	if err != nil {
 		return fmt.Errorf("failed to batch create users: %w", err)
 	}
@@ -362,8 +330,8 @@ func handleBatchCreateOrders(ctx context.Context, store dcb.EventStore, commands
 		}
 	}
 
-	// Create events for all commands in the batch using BatchBuilder
-	batch := dcb.NewBatch()
+	// Create events for all commands in the batch
+	var batch dcb.EventBatch
 	for _, cmd := range commands {
 		// Calculate total for this order
 		total := 0.0
@@ -371,22 +339,20 @@ func handleBatchCreateOrders(ctx context.Context, store dcb.EventStore, commands
 			total += float64(item.Quantity) * item.Price
 		}
 
-		batch.AddEventFromBuilder(
-			dcb.NewEvent("OrderCreated").
-				WithTag("order_id", cmd.OrderID).
-				WithTag("user_id", cmd.UserID).
-				WithData(OrderCreatedData{
-					OrderID: cmd.OrderID,
-					UserID:  cmd.UserID,
-					Items:   cmd.Items,
-					Total:   total,
-				}),
-		)
+		batch.Add(dcb.NewEvent("OrderCreated").
+			WithTag("order_id", cmd.OrderID).
+			WithTag("user_id", cmd.UserID).
+			WithData(OrderCreatedData{
+				OrderID: cmd.OrderID,
+				UserID:  cmd.UserID,
+				Items:   cmd.Items,
+				Total:   total,
+			}).
+			Build())
 	}
-	events := batch.Build()
 
 	// Append events atomically for this batch
-	err = store.Append(ctx, events)
+	err = store.Append(ctx, batch)
 	if err != nil {
 		return fmt.Errorf("failed to batch create orders: %w", err)
 	}
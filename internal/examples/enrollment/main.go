@@ -278,8 +278,16 @@ func handleRegisterStudent(ctx context.Context, store dcb.EventStore, cmd Regist
 			Build(),
 	}
 
-	// Append events atomically for this command
-	err = store.Append(ctx, events)
+	// The projection gives precise errors, but a concurrent registration could take the ID or
+	// email before we append: the create condition re-checks both under a lock
+	unique := dcb.FailIfAnyExists(
+		dcb.TagMatch{Type: EventTypeStudentRegistered, Key: "student_id", Value: cmd.StudentID},
+		dcb.TagMatch{Type: EventTypeStudentRegistered, Key: "email", Value: cmd.Email},
+	)
+	_, err = store.AppendIfNotExists(ctx, events, unique, dcb.OnConflictError)
+	if dcb.IsConcurrencyError(err) {
+		return fmt.Errorf("student %s or email %s already exists", cmd.StudentID, cmd.Email)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to register student: %w", err)
 	}
@@ -36,35 +36,14 @@ func main() {
 		log.Fatalf("Failed to create event store: %v", err)
 	}
 
-	// Create test events using EventBuilder and BatchBuilder
-	batch := dcb.NewBatch().
-		AddEventFromBuilder(
-			dcb.NewEvent("UserCreated").
-				WithTag("user_id", "user-1").
-				WithData(map[string]string{"name": "Alice"}),
-		).
-		AddEventFromBuilder(
-			dcb.NewEvent("UserCreated").
-				WithTag("user_id", "user-2").
-				WithData(map[string]string{"name": "Bob"}),
-		).
-		AddEventFromBuilder(
-			dcb.NewEvent("UserCreated").
-				WithTag("user_id", "user-3").
-				WithData(map[string]string{"name": "Charlie"}),
-		).
-		AddEventFromBuilder(
-			dcb.NewEvent("UserUpdated").
-				WithTag("user_id", "user-1").
-				WithData(map[string]string{"name": "Alice Smith"}),
-		).
-		AddEventFromBuilder(
-			dcb.NewEvent("UserUpdated").
-				WithTag("user_id", "user-2").
-				WithData(map[string]string{"name": "Bob Johnson"}),
-		)
-
-	events := batch.Build()
+	// Create test events using EventBuilder and EventBatch
+	events := dcb.NewEventBatch(
+		dcb.NewEvent("UserCreated").WithTag("user_id", "user-1").WithData(map[string]string{"name": "Alice"}).Build(),
+		dcb.NewEvent("UserCreated").WithTag("user_id", "user-2").WithData(map[string]string{"name": "Bob"}).Build(),
+		dcb.NewEvent("UserCreated").WithTag("user_id", "user-3").WithData(map[string]string{"name": "Charlie"}).Build(),
+		dcb.NewEvent("UserUpdated").WithTag("user_id", "user-1").WithData(map[string]string{"name": "Alice Smith"}).Build(),
+		dcb.NewEvent("UserUpdated").WithTag("user_id", "user-2").WithData(map[string]string{"name": "Bob Johnson"}).Build(),
+	)
 
 	// Append events
 	err = store.Append(ctx, events)
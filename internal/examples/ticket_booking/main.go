@@ -79,9 +79,42 @@ func runSingleApproach(ctx context.Context, store dcb.EventStore, numUsers, numS
 	fmt.Printf("Average Time per Booking: %v\n", results.avgTime)
 	fmt.Printf("Success Rate: %.1f%%\n", results.successRate)
 	fmt.Printf("Throughput: %.0f ops/s\n", results.throughput)
+
+	verifyBookings(ctx, store, results, numSeats, ticketsPerUser)
+}
+
+// verifyBookings drains the run by projecting the final concert state and fails loudly if
+// concurrency control let an overbooking through or the booked seats don't match the successes
+func verifyBookings(ctx context.Context, store dcb.EventStore, results TestResults, numSeats, ticketsPerUser int) {
+	fmt.Printf("\n=== Verification ===\n")
+	err := dcb.AssertNoOverbook(ctx, store, concertStateProjector(results.concertID), func(state any) (int, int) {
+		concert := state.(ConcertState)
+		return concert.BookedSeats, concert.TotalSeats
+	})
+	if err != nil {
+		log.Fatalf("VERIFICATION FAILED: %v", err)
+	}
+
+	states, _, err := store.Project(ctx, []dcb.StateProjector{concertStateProjector(results.concertID)}, nil)
+	if err != nil {
+		log.Fatalf("VERIFICATION FAILED: could not project concert state: %v", err)
+	}
+	concert := states["concertState"].(ConcertState)
+	if concert.BookedSeats != results.successCount*ticketsPerUser {
+		log.Fatalf("VERIFICATION FAILED: %d seats booked but %d bookings of %d tickets succeeded",
+			concert.BookedSeats, results.successCount, ticketsPerUser)
+	}
+
+	fmt.Printf("No overbooking: %d of %d seats booked by %d successful bookings\n",
+		concert.BookedSeats, numSeats, results.successCount)
+	if maxBookings := numSeats / ticketsPerUser; results.successCount < maxBookings {
+		// Conflicting bookings fail instead of retrying, so a run may leave seats unsold
+		fmt.Printf("%d bookings could still fit; the rest failed on concurrency conflicts\n", maxBookings-results.successCount)
+	}
 }
 
 type TestResults struct {
+	concertID    string
 	totalTime    time.Duration
 	avgTime      time.Duration
 	successRate  float64
@@ -167,6 +200,7 @@ func runConcurrencyTest(ctx context.Context, store dcb.EventStore, numUsers, num
 	fmt.Printf("Throughput: %.0f ops/s\n", throughput)
 
 	return TestResults{
+		concertID:    concertID,
 		totalTime:    totalTime,
 		avgTime:      avgTime,
 		successRate:  successRate,
@@ -248,36 +282,16 @@ func handleBookTicketsWithDCBControl(ctx context.Context, store dcb.EventStore,
 	// Use DCB concurrency control to prevent concurrent modifications to concert capacity
 	// This ensures only one booking can check/update seat availability at a time
 
+	// Snapshot the condition before projecting: any booking or cancellation for the concert
+	// appended after this point (including ones the projection below already sees) fails the append
+	appendCondition, err := store.ConditionFromQuery(ctx, concertQuery(cmd.ConcertID))
+	if err != nil {
+		return fmt.Errorf("failed to snapshot booking condition: %w", err)
+	}
+
 	// Command-specific projectors to check current state
 	projectors := []dcb.StateProjector{
-		{
-			ID: "concertState",
-			Query: dcb.NewQuery(
-				dcb.NewTags("concert_id", cmd.ConcertID),
-				"ConcertDefined",
-			),
-			InitialState: ConcertState{},
-			TransitionFn: func(state any, event dcb.Event) any {
-				concert := state.(ConcertState)
-				if event.Type == "ConcertDefined" {
-					var data map[string]any
-					json.Unmarshal(event.Data, &data)
-					concert.Artist = data["Artist"].(string)
-					concert.Venue = data["Venue"].(string)
-					concert.TotalSeats = int(data["TotalSeats"].(float64))
-					concert.PricePerTicket = data["PricePerTicket"].(float64)
-				} else if event.Type == "TicketsBooked" {
-					var data map[string]any
-					json.Unmarshal(event.Data, &data)
-					concert.BookedSeats += int(data["quantity"].(float64))
-				} else if event.Type == "BookingCancelled" {
-					var data map[string]any
-					json.Unmarshal(event.Data, &data)
-					concert.BookedSeats -= int(data["quantity"].(float64))
-				}
-				return concert
-			},
-		},
+		concertStateProjector(cmd.ConcertID),
 		{
 			ID: "customerBookings",
 			Query: dcb.NewQuery(
@@ -286,9 +300,8 @@ func handleBookTicketsWithDCBControl(ctx context.Context, store dcb.EventStore,
 			),
 			InitialState: 0,
 			TransitionFn: func(state any, event dcb.Event) any {
-				var data map[string]any
-				json.Unmarshal(event.Data, &data)
-				return state.(int) + int(data["quantity"].(float64))
+				quantity, _ := event.DataInt("quantity")
+				return state.(int) + int(quantity)
 			},
 		},
 	}
@@ -328,13 +341,8 @@ func handleBookTicketsWithDCBControl(ctx context.Context, store dcb.EventStore,
 			Build(),
 	}
 
-	// Create AppendCondition to ensure concert state hasn't changed since our projection
-	// This prevents race conditions where multiple bookings could overbook the concert
-	item := dcb.NewQueryItem([]string{"ConcertDefined"}, []dcb.Tag{dcb.NewTag("concert_id", cmd.ConcertID)})
-	query := dcb.NewQueryFromItems(item)
-	appendCondition := dcb.NewAppendCondition(query)
-
-	// Append events atomically with DCB concurrency control
+	// Append events atomically with DCB concurrency control: the condition fails if the concert's
+	// bookings changed since the snapshot, which prevents concurrent bookings from overbooking it
 	err = store.AppendIf(ctx, events, appendCondition)
 	if err != nil {
 		return fmt.Errorf("failed to book tickets: %w", err)
@@ -345,37 +353,41 @@ func handleBookTicketsWithDCBControl(ctx context.Context, store dcb.EventStore,
 	return nil
 }
 
-func showConcertState(ctx context.Context, store dcb.EventStore, concertID string) {
-	projectors := []dcb.StateProjector{
-		{
-			ID: "concertState",
-			Query: dcb.NewQuery(
-				dcb.NewTags("concert_id", concertID),
-				"ConcertDefined",
-			),
-			InitialState: ConcertState{},
-			TransitionFn: func(state any, event dcb.Event) any {
-				concert := state.(ConcertState)
-				if event.Type == "ConcertDefined" {
-					var data map[string]any
-					json.Unmarshal(event.Data, &data)
-					concert.Artist = data["Artist"].(string)
-					concert.Venue = data["Venue"].(string)
-					concert.TotalSeats = int(data["TotalSeats"].(float64))
-					concert.PricePerTicket = data["PricePerTicket"].(float64)
-				} else if event.Type == "TicketsBooked" {
-					var data map[string]any
-					json.Unmarshal(event.Data, &data)
-					concert.BookedSeats += int(data["quantity"].(float64))
-				} else if event.Type == "BookingCancelled" {
-					var data map[string]any
-					json.Unmarshal(event.Data, &data)
-					concert.BookedSeats -= int(data["quantity"].(float64))
+// concertQuery matches the events that define a concert and change its booked seats
+func concertQuery(concertID string) dcb.Query {
+	return dcb.NewQuery(dcb.NewTags("concert_id", concertID), "ConcertDefined", "TicketsBooked", "BookingCancelled")
+}
+
+// concertStateProjector projects a concert's seats and bookings
+func concertStateProjector(concertID string) dcb.StateProjector {
+	return dcb.StateProjector{
+		ID:           "concertState",
+		Query:        concertQuery(concertID),
+		InitialState: ConcertState{},
+		TransitionFn: func(state any, event dcb.Event) any {
+			concert := state.(ConcertState)
+			if event.Type == "ConcertDefined" {
+				var data CreateConcertCommand
+				if err := dcb.DecodeData(event, &data); err == nil {
+					concert.Artist = data.Artist
+					concert.Venue = data.Venue
+					concert.TotalSeats = data.TotalSeats
+					concert.PricePerTicket = data.PricePerTicket
 				}
-				return concert
-			},
+			} else if event.Type == "TicketsBooked" {
+				quantity, _ := event.DataInt("quantity")
+				concert.BookedSeats += int(quantity)
+			} else if event.Type == "BookingCancelled" {
+				quantity, _ := event.DataInt("quantity")
+				concert.BookedSeats -= int(quantity)
+			}
+			return concert
 		},
 	}
+}
+
+func showConcertState(ctx context.Context, store dcb.EventStore, concertID string) {
+	projectors := []dcb.StateProjector{concertStateProjector(concertID)}
 
 	states, _, err := store.Project(ctx, projectors, nil)
 	if err != nil {
@@ -30,6 +30,35 @@ type AccountState struct {
 	UpdatedAt time.Time
 }
 
+// Apply returns the account state after event
+func (s AccountState) Apply(event dcb.Event) AccountState {
+	switch event.Type {
+	case "AccountOpened":
+		var accountOpened AccountOpened
+		if err := json.Unmarshal(event.Data, &accountOpened); err != nil {
+			return s
+		}
+		s.AccountID = accountOpened.AccountID
+		s.Owner = accountOpened.Owner
+		s.Balance = accountOpened.InitialBalance
+		s.CreatedAt = accountOpened.OpenedAt
+		s.UpdatedAt = accountOpened.OpenedAt
+	case "MoneyTransferred":
+		var transfer MoneyTransferred
+		if err := json.Unmarshal(event.Data, &transfer); err != nil {
+			return s
+		}
+		if transfer.FromAccountID == s.AccountID {
+			s.Balance = transfer.FromBalance
+			s.UpdatedAt = transfer.TransferredAt
+		} else if transfer.ToAccountID == s.AccountID {
+			s.Balance = transfer.ToBalance
+			s.UpdatedAt = transfer.TransferredAt
+		}
+	}
+	return s
+}
+
 // AccountOpened represents when an account is opened
 type AccountOpened struct {
 	AccountID      string    `json:"account_id"`
@@ -95,7 +124,7 @@ func HandleOpenAccount(ctx context.Context, store dcb.EventStore, cmd OpenAccoun
 	}
 
 	event := dcb.NewEvent("AccountOpened").
-		WithTag("account_id", cmd.AccountID).
+		WithTagsFromStruct(accountOpened, "account_id").
 		WithData(accountOpened).
 		Build()
 	return []dcb.InputEvent{event}, nil
@@ -103,77 +132,13 @@ func HandleOpenAccount(ctx context.Context, store dcb.EventStore, cmd OpenAccoun
 
 // HandleTransferMoney handles money transfers between accounts
 func HandleTransferMoney(ctx context.Context, store dcb.EventStore, cmd TransferMoneyCommand) ([]dcb.InputEvent, *dcb.AppendCondition, error) {
-	// Project source account state using simplified query
-	fromAccountProjector := dcb.StateProjector{
-		ID:           "fromAccount",
-		Query:        dcb.NewQueryBuilder().WithTypes("AccountOpened", "MoneyTransferred").WithTag("account_id", cmd.FromAccountID).Build(),
-		InitialState: AccountState{},
-		TransitionFn: func(state any, event dcb.Event) any {
-			accountState := state.(AccountState)
-
-			if event.Type == "AccountOpened" {
-				var accountOpened AccountOpened
-				if err := json.Unmarshal(event.Data, &accountOpened); err != nil {
-					return accountState
-				}
-				accountState.AccountID = accountOpened.AccountID
-				accountState.Owner = accountOpened.Owner
-				accountState.Balance = accountOpened.InitialBalance
-				accountState.CreatedAt = accountOpened.OpenedAt
-				accountState.UpdatedAt = accountOpened.OpenedAt
-			} else if event.Type == "MoneyTransferred" {
-				var transfer MoneyTransferred
-				if err := json.Unmarshal(event.Data, &transfer); err != nil {
-					return accountState
-				}
-				if transfer.FromAccountID == cmd.FromAccountID {
-					accountState.Balance = transfer.FromBalance
-					accountState.UpdatedAt = transfer.TransferredAt
-				} else if transfer.ToAccountID == cmd.FromAccountID {
-					accountState.Balance = transfer.ToBalance
-					accountState.UpdatedAt = transfer.TransferredAt
-				}
-			}
-			return accountState
-		},
-	}
-
-	// Project destination account state using simplified query
-	toAccountProjector := dcb.StateProjector{
-		ID:           "toAccount",
-		Query:        dcb.NewQueryBuilder().WithTypes("AccountOpened", "MoneyTransferred").WithTag("account_id", cmd.ToAccountID).Build(),
-		InitialState: AccountState{},
-		TransitionFn: func(state any, event dcb.Event) any {
-			accountState := state.(AccountState)
-
-			if event.Type == "AccountOpened" {
-				var accountOpened AccountOpened
-				if err := json.Unmarshal(event.Data, &accountOpened); err != nil {
-					return accountState
-				}
-				accountState.AccountID = accountOpened.AccountID
-				accountState.Owner = accountOpened.Owner
-				accountState.Balance = accountOpened.InitialBalance
-				accountState.CreatedAt = accountOpened.OpenedAt
-				accountState.UpdatedAt = accountOpened.OpenedAt
-			} else if event.Type == "MoneyTransferred" {
-				var transfer MoneyTransferred
-				if err := json.Unmarshal(event.Data, &transfer); err != nil {
-					return accountState
-				}
-				if transfer.FromAccountID == cmd.ToAccountID {
-					accountState.Balance = transfer.FromBalance
-					accountState.UpdatedAt = transfer.TransferredAt
-				} else if transfer.ToAccountID == cmd.ToAccountID {
-					accountState.Balance = transfer.ToBalance
-					accountState.UpdatedAt = transfer.TransferredAt
-				}
-			}
-			return accountState
-		},
-	}
-
-	// Project both accounts
+	// Project both accounts; AccountState.Apply folds each one's events
+	accountQuery := func(accountID string) dcb.Query {
+		return dcb.NewQueryBuilder().WithTypes("AccountOpened", "MoneyTransferred").WithTag("account_id", accountID).Build()
+	}
+	fromAccountProjector := dcb.ProjectorFor("fromAccount", accountQuery(cmd.FromAccountID), AccountState{AccountID: cmd.FromAccountID})
+	toAccountProjector := dcb.ProjectorFor("toAccount", accountQuery(cmd.ToAccountID), AccountState{AccountID: cmd.ToAccountID})
+
 	projectedStates, appendCondition, err := store.Project(ctx, []dcb.StateProjector{fromAccountProjector, toAccountProjector}, nil)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to project account states: %w", err)
@@ -250,12 +215,12 @@ func executeOpenAccountCommand(ctx context.Context, commandExecutor dcb.CommandE
 		"source":     "web_api",
 	})
 
-	_, err = commandExecutor.ExecuteCommand(ctx, command, handler, nil)
+	result, err := commandExecutor.ExecuteCommand(ctx, command, handler, nil)
 	if err != nil {
 		return fmt.Errorf("open account failed: %w", err)
 	}
 
-	fmt.Printf("✓ Opened account %s for %s with balance %d\n", cmd.AccountID, cmd.Owner, cmd.InitialBalance)
+	fmt.Printf("✓ Opened account %v for %s with balance %d (position %v)\n", result.Output, cmd.Owner, cmd.InitialBalance, result.Positions)
 	return nil
 }
 
@@ -271,7 +236,7 @@ func executeTransferCommand(ctx context.Context, commandExecutor dcb.CommandExec
 		"source":     "web_api",
 	})
 
-	_, err = commandExecutor.ExecuteCommand(ctx, command, handler, nil)
+	err = dcb.RunCommand(ctx, commandExecutor, command, handler, nil)
 	if err != nil {
 		return fmt.Errorf("transfer failed: %w", err)
 	}
@@ -360,9 +325,17 @@ func main() {
 	defer pool.Close()
 
 	// Create command handler
-	handler := dcb.CommandHandlerFunc(func(ctx context.Context, store dcb.EventStore, command dcb.Command) ([]dcb.InputEvent, error) {
+	// Open account commands return the account ID as the command output
+	handler := dcb.CommandHandlerFunc(func(ctx context.Context, store dcb.EventStore, command dcb.Command) ([]dcb.InputEvent, any, error) {
 		events, _, err := HandleCommand(ctx, store, command)
-		return events, err
+		if err != nil || command.GetType() != CommandTypeOpenAccount {
+			return events, nil, err
+		}
+		var cmd OpenAccountCommand
+		if err := json.Unmarshal(command.GetData(), &cmd); err != nil {
+			return nil, nil, err
+		}
+		return events, cmd.AccountID, nil
 	})
 
 	// Execute commands with early returns for failures
@@ -0,0 +1,160 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Per-Aggregate Streams (expected-version concurrency)
+// =============================================================================
+
+// AppendToAggregate appends events to the stream identified by tagKey:tagValue using classic
+// expected-version optimistic concurrency, built on top of the DCB primitives.
+// The version of a stream is the number of events carrying the tag, or its stored aggregate version
+// when tagKey is listed in EventStoreConfig.AggregateVersionTagKeys. The append succeeds
+// only if the current version equals expectedVersion and no event with the tag is appended
+// concurrently; otherwise a ConcurrencyError with ExpectedVersion and ActualVersion is returned.
+// Every event must carry the aggregate tag so that it counts towards the next version.
+func (es *eventStore) AppendToAggregate(ctx context.Context, tagKey, tagValue string, expectedVersion int, events []InputEvent) error {
+	if tagKey == "" || tagValue == "" {
+		return &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "appendToAggregate",
+				Err: fmt.Errorf("aggregate tag key and value cannot be empty"),
+			},
+			Field: "aggregateTag",
+			Value: tagKey + ":" + tagValue,
+		}
+	}
+	if expectedVersion < 0 {
+		return &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "appendToAggregate",
+				Err: fmt.Errorf("expected version cannot be negative: %d", expectedVersion),
+			},
+			Field: "expectedVersion",
+			Value: fmt.Sprintf("%d", expectedVersion),
+		}
+	}
+
+	// Every event must belong to the aggregate stream
+	for i, event := range events {
+		if !hasTag(event.GetTags(), tagKey, tagValue) {
+			return &ValidationError{
+				EventStoreError: EventStoreError{
+					Op:  "appendToAggregate",
+					Err: fmt.Errorf("event %d does not carry aggregate tag %s:%s", i, tagKey, tagValue),
+				},
+				Field: fmt.Sprintf("event[%d].tags", i),
+				Value: tagKey + ":" + tagValue,
+			}
+		}
+	}
+
+	version, latest, err := es.aggregateVersion(ctx, tagKey, tagValue)
+	if err != nil {
+		return err
+	}
+	if version != expectedVersion {
+		return newAggregateVersionError(tagKey, tagValue, expectedVersion, version)
+	}
+
+	// Fail if any event of the aggregate was appended after the one we counted last
+	condition := NewAppendCondition(NewQuery(NewTags(tagKey, tagValue)))
+	condition.setAfterCursor(latest)
+
+	err = es.AppendIf(ctx, events, condition)
+	if err != nil && IsConcurrencyError(err) {
+		// Report the version that beat us when it can be determined
+		actual, _, versionErr := es.aggregateVersion(ctx, tagKey, tagValue)
+		if versionErr != nil {
+			actual = -1
+		}
+		return newAggregateVersionError(tagKey, tagValue, expectedVersion, actual)
+	}
+	return err
+}
+
+// aggregateVersion returns the number of events carrying tagKey:tagValue and the cursor of the latest one
+func (es *eventStore) aggregateVersion(ctx context.Context, tagKey, tagValue string) (int, *Cursor, error) {
+	tags := []string{tagKey + ":" + tagValue}
+
+	var version int
+	var latest *Cursor
+	err := es.executeReadInTx(ctx, func(tx pgx.Tx) error {
+		// A versioned tag key (EventStoreConfig.AggregateVersionTagKeys) has its version stored
+		stored, ok, err := es.storedAggregateVersion(ctx, tx, tagKey, tagValue)
+		if err != nil {
+			return &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "appendToAggregate",
+					Err: fmt.Errorf("failed to read aggregate version: %w", err),
+				},
+				Resource: "database",
+			}
+		}
+		if ok {
+			version = int(stored)
+		} else if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM `+es.eventsSource()+` WHERE tags @> $1::text[]`, tags).Scan(&version); err != nil {
+			return &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "appendToAggregate",
+					Err: fmt.Errorf("failed to count aggregate events: %w", err),
+				},
+				Resource: "database",
+			}
+		}
+		if version == 0 {
+			return nil
+		}
+
+		latest = &Cursor{}
+		err = tx.QueryRow(ctx, `
+			SELECT transaction_id, position FROM `+es.eventsSource()+`
+			WHERE tags @> $1::text[]
+			ORDER BY transaction_id DESC, position DESC
+			LIMIT 1
+		`, tags).Scan(&latest.TransactionID, &latest.Position)
+		if err != nil {
+			return &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "appendToAggregate",
+					Err: fmt.Errorf("failed to read latest aggregate event: %w", err),
+				},
+				Resource: "database",
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return version, latest, nil
+}
+
+// newAggregateVersionError builds the ConcurrencyError returned on a version mismatch
+// actual is -1 when the current version could not be determined
+func newAggregateVersionError(tagKey, tagValue string, expected, actual int) *ConcurrencyError {
+	return &ConcurrencyError{
+		EventStoreError: EventStoreError{
+			Op:  "appendToAggregate",
+			Err: fmt.Errorf("aggregate %s:%s version mismatch: expected %d, actual %d", tagKey, tagValue, expected, actual),
+		},
+		ExpectedVersion: expected,
+		ActualVersion:   actual,
+	}
+}
+
+// hasTag reports whether tags contains key:value
+func hasTag(tags []Tag, key, value string) bool {
+	for _, t := range tags {
+		if t.GetKey() == key && t.GetValue() == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package dcb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Per-Aggregate Versions
+// =============================================================================
+
+// MetadataAggregateVersions is the metadata key under which appended events carry their aggregate
+// versions (EventStoreConfig.AggregateVersionTagKeys), as an object from "key:value" tag to version.
+// Like causation it is stored in the events.metadata column, not as a tag
+const MetadataAggregateVersions = "aggregate_versions"
+
+// AggregateVersion returns the version the event has in the aggregate identified by
+// tagKey:tagValue: 1 for the aggregate's first event, then one more per event. It is only recorded
+// for tag keys listed in EventStoreConfig.AggregateVersionTagKeys when the event was appended
+func (e Event) AggregateVersion(tagKey, tagValue string) (int64, bool) {
+	if len(e.Metadata) == 0 {
+		return 0, false
+	}
+
+	var metadata struct {
+		AggregateVersions map[string]int64 `json:"aggregate_versions"`
+	}
+	if err := json.Unmarshal(e.Metadata, &metadata); err != nil {
+		return 0, false
+	}
+	version, ok := metadata.AggregateVersions[tagKey+":"+tagValue]
+	return version, ok
+}
+
+// validateAggregateVersionTagKeys checks EventStoreConfig.AggregateVersionTagKeys
+func validateAggregateVersionTagKeys(op string, keys []string) error {
+	for i, key := range keys {
+		if key == "" || strings.Contains(key, ":") {
+			return &ValidationError{
+				EventStoreError: EventStoreError{Op: op, Err: fmt.Errorf("aggregate version tag key %q must be non-empty and without ':'", key)},
+				Field:           "aggregateVersionTagKeys",
+				Value:           key,
+			}
+		}
+		if slices.Contains(keys[:i], key) {
+			return &ValidationError{
+				EventStoreError: EventStoreError{Op: op, Err: fmt.Errorf("aggregate version tag key %q is repeated", key)},
+				Field:           "aggregateVersionTagKeys",
+				Value:           key,
+			}
+		}
+	}
+	return nil
+}
+
+// aggregateTags returns, for every event, its "key:value" tags whose key is versioned
+func aggregateTags(events []InputEvent, keys []string) [][]string {
+	tags := make([][]string, len(events))
+	for i, event := range events {
+		for _, tag := range event.GetTags() {
+			aggregate := tag.GetKey() + ":" + tag.GetValue()
+			if slices.Contains(keys, tag.GetKey()) && !slices.Contains(tags[i], aggregate) {
+				tags[i] = append(tags[i], aggregate)
+			}
+		}
+	}
+	return tags
+}
+
+// assignAggregateVersions bumps the dcb_aggregate_versions row of every aggregate the events
+// belong to by the number of its events, and stamps each event's versions into its metadata entry.
+// stored tells that the events were already inserted by this transaction, so they must not be
+// counted twice when an aggregate's row is created.
+// The rows stay locked until the append transaction ends, which serializes concurrent appends to
+// the same aggregate (and fails one of them under REPEATABLE READ and SERIALIZABLE); a rolled back
+// append leaves the versions untouched
+func (es *eventStore) assignAggregateVersions(ctx context.Context, tx pgx.Tx, events []InputEvent, metadata [][]byte, stored bool) error {
+	if len(es.config.AggregateVersionTagKeys) == 0 {
+		return nil
+	}
+	tags := aggregateTags(events, es.config.AggregateVersionTagKeys)
+
+	counts := make(map[string]int64)
+	for _, eventTags := range tags {
+		for _, tag := range eventTags {
+			counts[tag]++
+		}
+	}
+	// Lock the rows in a fixed order so that appends to overlapping aggregates can't deadlock
+	next := make(map[string]int64, len(counts))
+	for _, tag := range slices.Sorted(maps.Keys(counts)) {
+		var counted int64
+		if stored {
+			counted = counts[tag]
+		}
+		version, err := es.bumpAggregateVersion(ctx, tx, tag, counts[tag], counted)
+		if err != nil {
+			if isLockTimeout(err) {
+				return &ResourceError{
+					EventStoreError: EventStoreError{Op: "appendInTx", Err: fmt.Errorf("lock wait timed out: %w", err)},
+					Resource:        "lock",
+				}
+			}
+			return &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "appendInTx",
+					Err: fmt.Errorf("failed to update version of aggregate %s: %w", tag, err),
+				},
+				Resource: "database",
+			}
+		}
+		next[tag] = version - counts[tag] + 1
+	}
+
+	for i, eventTags := range tags {
+		if len(eventTags) == 0 {
+			continue
+		}
+		versions := make(map[string]int64, len(eventTags))
+		for _, tag := range eventTags {
+			versions[tag] = next[tag]
+			next[tag]++
+		}
+		metadata[i] = withMetadataField(metadata[i], MetadataAggregateVersions, versions)
+	}
+	return nil
+}
+
+// bumpAggregateVersion adds n to the version of the aggregate tag and returns the new version.
+// The first time an aggregate is versioned its row starts from the number of events already
+// carrying the tag, so enabling versioning on an existing store continues their count; counted of
+// the n events are already among them
+func (es *eventStore) bumpAggregateVersion(ctx context.Context, tx pgx.Tx, tag string, n, counted int64) (int64, error) {
+	var version int64
+	err := tx.QueryRow(ctx, `UPDATE dcb_aggregate_versions SET version = version + $2 WHERE tag = $1 RETURNING version`, tag, n).Scan(&version)
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return version, err
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO dcb_aggregate_versions (tag, version)
+		SELECT $1::text, COUNT(*) + $2::bigint - $3::bigint FROM `+es.eventsSource()+` WHERE tags @> ARRAY[$1::text]
+		ON CONFLICT (tag) DO UPDATE SET version = dcb_aggregate_versions.version + $2::bigint
+		RETURNING version
+	`, tag, n, counted).Scan(&version)
+	return version, err
+}
+
+// versionStoredEvents assigns aggregate versions to the events AppendSkipDuplicates stored (those
+// with a position; skipped duplicates have 0) and writes them into the stored rows' metadata, so
+// skipped duplicates never count towards an aggregate's version
+func (es *eventStore) versionStoredEvents(ctx context.Context, tx pgx.Tx, events []InputEvent, metadata [][]byte, positions []int64) error {
+	if len(es.config.AggregateVersionTagKeys) == 0 {
+		return nil
+	}
+	var (
+		versioned         []InputEvent
+		versionedMetadata [][]byte
+		versionedPosition []int64
+	)
+	for i, tags := range aggregateTags(events, es.config.AggregateVersionTagKeys) {
+		if positions[i] != 0 && len(tags) > 0 {
+			versioned = append(versioned, events[i])
+			versionedMetadata = append(versionedMetadata, metadata[i])
+			versionedPosition = append(versionedPosition, positions[i])
+		}
+	}
+	if len(versioned) == 0 {
+		return nil
+	}
+	if err := es.assignAggregateVersions(ctx, tx, versioned, versionedMetadata, true); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, `
+		UPDATE events SET metadata = u.metadata
+		FROM UNNEST($1::BIGINT[], $2::JSONB[]) AS u(position, metadata)
+		WHERE events.position = u.position
+	`, versionedPosition, versionedMetadata)
+	if err != nil {
+		return &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "appendSkipDuplicates",
+				Err: fmt.Errorf("failed to record aggregate versions: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	return nil
+}
+
+// storedAggregateVersion returns the version recorded in dcb_aggregate_versions for tagKey:tagValue,
+// false when the tag key isn't versioned or the aggregate has no row yet
+func (es *eventStore) storedAggregateVersion(ctx context.Context, tx pgx.Tx, tagKey, tagValue string) (int64, bool, error) {
+	if !slices.Contains(es.config.AggregateVersionTagKeys, tagKey) {
+		return 0, false, nil
+	}
+	var version int64
+	err := tx.QueryRow(ctx, `SELECT version FROM dcb_aggregate_versions WHERE tag = $1`, tagKey+":"+tagValue).Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	return version, err == nil, err
+}
@@ -0,0 +1,46 @@
+package dcb
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEventAggregateVersion(t *testing.T) {
+	event := Event{Metadata: withMetadataField([]byte(`{"source":"api"}`), MetadataAggregateVersions, map[string]int64{"account_id:a1": 3})}
+	if version, ok := event.AggregateVersion("account_id", "a1"); !ok || version != 3 {
+		t.Errorf("expected version 3, got %d, %v", version, ok)
+	}
+	if _, ok := event.AggregateVersion("account_id", "a2"); ok {
+		t.Error("expected no version for another aggregate")
+	}
+
+	for _, metadata := range []string{"", `{"source":"api"}`, `not json`} {
+		if _, ok := (Event{Metadata: []byte(metadata)}).AggregateVersion("account_id", "a1"); ok {
+			t.Errorf("expected no version for metadata %q", metadata)
+		}
+	}
+}
+
+func TestAggregateTags(t *testing.T) {
+	events := []InputEvent{
+		NewInputEvent("Deposited", NewTags("account_id", "a1", "currency", "EUR"), []byte(`{}`)),
+		NewInputEvent("Transferred", NewTags("account_id", "a1", "account_id", "a2", "account_id", "a1"), []byte(`{}`)),
+		NewInputEvent("Noted", NewTags("note", "x"), []byte(`{}`)),
+	}
+	got := aggregateTags(events, []string{"account_id"})
+	want := [][]string{{"account_id:a1"}, {"account_id:a1", "account_id:a2"}, nil}
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestValidateAggregateVersionTagKeys(t *testing.T) {
+	if err := validateAggregateVersionTagKeys("test", []string{"account_id", "order_id"}); err != nil {
+		t.Errorf("expected valid keys, got %v", err)
+	}
+	for _, keys := range [][]string{{""}, {"account:id"}, {"account_id", "account_id"}} {
+		if err := validateAggregateVersionTagKeys("test", keys); !IsValidationError(err) {
+			t.Errorf("expected a validation error for %q, got %v", keys, err)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package dcb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// =============================================================================
+// Server-Side Aggregation
+// =============================================================================
+
+// AggregationFunc is an aggregate function computed by PostgreSQL over event data
+type AggregationFunc string
+
+const (
+	AggregateSum   AggregationFunc = "sum"
+	AggregateMin   AggregationFunc = "min"
+	AggregateMax   AggregationFunc = "max"
+	AggregateAvg   AggregationFunc = "avg"
+	AggregateCount AggregationFunc = "count"
+)
+
+// Aggregation describes an aggregate over a numeric field of the event data
+// DataPath is a dot-separated path into the JSON data, e.g. "amount" or "payment.amount"
+// (array elements can be addressed by index, e.g. "items.0.price").
+// For AggregateCount an empty DataPath counts matching events; otherwise every function
+// only considers events that have a non-null value at DataPath
+type Aggregation struct {
+	Func     AggregationFunc `json:"func"`
+	DataPath string          `json:"data_path"`
+}
+
+// numericValueErrorCode is the PostgreSQL error code raised when a value can't be cast to numeric
+const numericValueErrorCode = "22P02"
+
+// Aggregate computes agg over the events matching query in a single SQL statement
+// Values are cast to numeric, so sums and averages are exact in PostgreSQL and only the
+// result is converted to float64 (about 15 significant digits). Events without a value at
+// DataPath (missing field or JSON null) are ignored, as in SQL aggregates. Sum and Count
+// return 0 when no value matches; Min, Max and Avg also return 0, so use Count with the same
+// DataPath to tell "no values" apart from a real 0. A value that isn't a number (including
+// JSON strings that don't parse as one) fails with a ValidationError
+func (es *eventStore) Aggregate(ctx context.Context, query Query, agg Aggregation) (float64, error) {
+	aggregateSQL, err := aggregateExpression(agg)
+	if err != nil {
+		return 0, err
+	}
+	if err := query.Validate(); err != nil {
+		return 0, err
+	}
+
+	innerSQL, args, err := es.buildReadQuerySQL(query, nil, nil)
+	if err != nil {
+		return 0, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "aggregate",
+				Err: fmt.Errorf("failed to build SQL query: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	// Row order doesn't matter for aggregates
+	innerSQL = strings.TrimSuffix(innerSQL, readOrderBy)
+
+	if agg.DataPath != "" {
+		args = append(args, strings.Split(agg.DataPath, "."))
+		aggregateSQL = strings.ReplaceAll(aggregateSQL, "$path", fmt.Sprintf("$%d::text[]", len(args)))
+	}
+	sqlQuery := "SELECT COALESCE(" + aggregateSQL + ", 0)::float8 FROM (" + innerSQL + ") AS e"
+
+	var result float64
+	err = es.executeReadInTx(ctx, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, sqlQuery, args...).Scan(&result); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == numericValueErrorCode {
+				return &ValidationError{
+					EventStoreError: EventStoreError{
+						Op:  "aggregate",
+						Err: fmt.Errorf("value at data path %q is not numeric: %w", agg.DataPath, err),
+					},
+					Field: "dataPath",
+					Value: agg.DataPath,
+				}
+			}
+			return &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "aggregate",
+					Err: fmt.Errorf("failed to execute aggregate: %w", err),
+				},
+				Resource: "database",
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result, nil
+}
+
+// aggregateExpression returns the SQL aggregate for agg, with $path standing for the data path parameter
+func aggregateExpression(agg Aggregation) (string, error) {
+	if agg.DataPath == "" && agg.Func == AggregateCount {
+		return "COUNT(*)", nil
+	}
+
+	if agg.DataPath == "" || strings.Contains("."+agg.DataPath+".", "..") {
+		return "", &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "aggregate",
+				Err: fmt.Errorf("data path %q must be a dot-separated path of non-empty field names", agg.DataPath),
+			},
+			Field: "dataPath",
+			Value: agg.DataPath,
+		}
+	}
+
+	value := "(e.data #>> $path)::numeric"
+	switch agg.Func {
+	case AggregateSum:
+		return "SUM(" + value + ")", nil
+	case AggregateMin:
+		return "MIN(" + value + ")", nil
+	case AggregateMax:
+		return "MAX(" + value + ")", nil
+	case AggregateAvg:
+		return "AVG(" + value + ")", nil
+	case AggregateCount:
+		// Count values present at the path without requiring them to be numeric
+		return "COUNT(e.data #>> $path)", nil
+	default:
+		return "", &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "aggregate",
+				Err: fmt.Errorf("unsupported aggregation function %q", agg.Func),
+			},
+			Field: "func",
+			Value: string(agg.Func),
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package dcb
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAggregateExpression(t *testing.T) {
+	tests := []struct {
+		name    string
+		agg     Aggregation
+		want    string
+		wantErr bool
+	}{
+		{"sum", Aggregation{Func: AggregateSum, DataPath: "amount"}, "SUM((e.data #>> $path)::numeric)", false},
+		{"avg nested", Aggregation{Func: AggregateAvg, DataPath: "payment.amount"}, "AVG((e.data #>> $path)::numeric)", false},
+		{"count events", Aggregation{Func: AggregateCount}, "COUNT(*)", false},
+		{"count values", Aggregation{Func: AggregateCount, DataPath: "amount"}, "COUNT(e.data #>> $path)", false},
+		{"sum without path", Aggregation{Func: AggregateSum}, "", true},
+		{"empty path segment", Aggregation{Func: AggregateMax, DataPath: "payment..amount"}, "", true},
+		{"leading dot", Aggregation{Func: AggregateMin, DataPath: ".amount"}, "", true},
+		{"unknown function", Aggregation{Func: "median", DataPath: "amount"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := aggregateExpression(tt.agg)
+			if tt.wantErr {
+				if !errors.Is(err, ErrValidation) {
+					t.Fatalf("expected validation error, got %q, %v", got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("got %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
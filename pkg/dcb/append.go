@@ -1,9 +1,11 @@
 package dcb
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/jackc/pgx/v5"
@@ -24,6 +26,13 @@ type AppendCondition interface {
 	getFailIfEventsMatch() *Query
 	// getAfterCursor returns the internal after cursor (used by event store)
 	getAfterCursor() *Cursor
+	// Query returns the FailIfEventsMatch query, or nil if the condition has none
+	Query() Query
+	// AfterPosition returns the position of the after cursor, if the condition has one
+	AfterPosition() (int64, bool)
+	// IsEmpty reports whether the condition constrains nothing (no query or a query without items);
+	// appending with an empty condition is an unconditional append
+	IsEmpty() bool
 }
 
 // InputEvent represents an event to be appended to the store
@@ -34,12 +43,18 @@ type InputEvent interface {
 	GetType() string
 	GetTags() []Tag
 	GetData() []byte
+	// GetMetadata returns the optional non-queryable metadata (JSON) stored alongside the event
+	GetMetadata() []byte
 }
 
 // appendCondition is the internal implementation
 type appendCondition struct {
 	FailIfEventsMatch *query  `json:"fail_if_events_match"`
 	AfterCursor       *Cursor `json:"after_cursor"`
+
+	// anyItem marks conditions built by FailIfAnyExists, whose items must each be checked on
+	// their own (AppendIfNotExists) rather than as one combined match (AppendIf)
+	anyItem bool
 }
 
 // isAppendCondition implements AppendCondition
@@ -64,37 +79,69 @@ func (ac *appendCondition) getAfterCursor() *Cursor {
 	return ac.AfterCursor
 }
 
+// Query returns the FailIfEventsMatch query, or nil if the condition has none
+// This lets callers (e.g. HTTP handlers) expose the real condition returned by Project
+func (ac *appendCondition) Query() Query {
+	if ac.FailIfEventsMatch == nil {
+		return nil
+	}
+	return ac.FailIfEventsMatch
+}
+
+// AfterPosition returns the position of the after cursor, if the condition has one
+// Conditions returned by Project only have one when events matched the projection
+func (ac *appendCondition) AfterPosition() (int64, bool) {
+	if ac.AfterCursor == nil {
+		return 0, false
+	}
+	return ac.AfterCursor.Position, true
+}
+
+// IsEmpty reports whether the condition has no FailIfEventsMatch query or one without items
+// An after cursor alone doesn't constrain anything: it only narrows which matching events count
+func (ac *appendCondition) IsEmpty() bool {
+	return ac.FailIfEventsMatch == nil || len(ac.FailIfEventsMatch.Items) == 0
+}
+
+// effectiveCondition returns nil for a nil or empty condition (see AppendCondition.IsEmpty),
+// so every append path treats both as an unconditional append
+func effectiveCondition(condition AppendCondition) AppendCondition {
+	if condition == nil || condition.IsEmpty() {
+		return nil
+	}
+	return condition
+}
+
 // inputEvent is the internal implementation
 type inputEvent struct {
 	eventType string
 	tags      []Tag
 	data      []byte
+	metadata  []byte
 }
 
-func (e *inputEvent) isInputEvent()   {}
-func (e *inputEvent) GetType() string { return e.eventType }
-func (e *inputEvent) GetTags() []Tag  { return e.tags }
-func (e *inputEvent) GetData() []byte { return e.data }
+func (e *inputEvent) isInputEvent()       {}
+func (e *inputEvent) GetType() string     { return e.eventType }
+func (e *inputEvent) GetTags() []Tag      { return e.tags }
+func (e *inputEvent) GetData() []byte     { return e.data }
+func (e *inputEvent) GetMetadata() []byte { return e.metadata }
 
 // Append appends events to the store with optional condition
 // Append appends events to the store without any consistency/concurrency checks
 // Use this only when there are no business rules or consistency requirements
+// An empty slice is a ValidationError unless EventStoreConfig.AllowEmptyAppend makes it a no-op
 func (es *eventStore) Append(ctx context.Context, events []InputEvent) error {
-	// Validate events
-	if len(events) == 0 {
-		return &ValidationError{
-			EventStoreError: EventStoreError{
-				Op:  "append",
-				Err: fmt.Errorf("events slice cannot be empty"),
-			},
-			Field: "events",
-			Value: "empty",
-		}
+	// Validate events before touching the database
+	if es.skipEmptyAppend(events) {
+		return nil
+	}
+	if err := es.validateAppendEvents(events, "append"); err != nil {
+		return err
 	}
 
 	// Start transaction using caller's context (caller controls timeout)
-	tx, err := es.pool.BeginTx(ctx, pgx.TxOptions{
-		IsoLevel: toPgxIsoLevel(es.config.DefaultAppendIsolation),
+	tx, err := es.beginTx(ctx, pgx.TxOptions{
+		IsoLevel: appendIsolation(ctx, es.config),
 	})
 	if err != nil {
 		return &ResourceError{
@@ -107,8 +154,13 @@ func (es *eventStore) Append(ctx context.Context, events []InputEvent) error {
 	}
 	defer tx.Rollback(ctx)
 
+	// Bound waits on aggregate version rows by the configured lock timeout and the caller's deadline
+	if err := es.applyVersionLockTimeout(ctx, tx, "append"); err != nil {
+		return err
+	}
+
 	// Use unconditional append (no consistency checks)
-	err = es.appendInTx(ctx, tx, events, nil, nil)
+	_, err = es.appendInTx(ctx, tx, events, nil, nil)
 	if err != nil {
 		return err
 	}
@@ -130,39 +182,52 @@ func (es *eventStore) Append(ctx context.Context, events []InputEvent) error {
 // AppendIf appends events to the store with explicit DCB concurrency control
 // This method makes it clear when consistency/concurrency checks are required
 // Note: DCB uses its own concurrency control mechanism via AppendCondition
+// A nil condition or an empty one (AppendCondition.IsEmpty) degrades to an unconditional Append
 func (es *eventStore) AppendIf(ctx context.Context, events []InputEvent, condition AppendCondition) error {
+	_, err := es.appendIf(ctx, "appendIf", events, condition)
+	return err
+}
+
+// appendIf is AppendIf reporting what was appended (nothing for a skipped empty append); op names
+// the calling operation in errors
+func (es *eventStore) appendIf(ctx context.Context, op string, events []InputEvent, condition AppendCondition) (appendedEvents, error) {
+	condition = effectiveCondition(condition)
+
 	// Validate and prepare condition FIRST (fail early)
 	conditionJSON, err := json.Marshal(condition)
 	if err != nil {
-		return &ResourceError{
+		return appendedEvents{}, &ResourceError{
 			EventStoreError: EventStoreError{
-				Op:  "appendIf",
+				Op:  op,
 				Err: fmt.Errorf("failed to marshal condition: %w", err),
 			},
 			Resource: "json",
 		}
 	}
 
-	// Validate events
-	if len(events) == 0 {
-		return &ValidationError{
-			EventStoreError: EventStoreError{
-				Op:  "appendIf",
-				Err: fmt.Errorf("events slice cannot be empty"),
-			},
-			Field: "events",
-			Value: "empty",
+	// Validate events before touching the database
+	if es.skipEmptyAppend(events) {
+		return appendedEvents{}, nil
+	}
+	if err := es.validateAppendEvents(events, op); err != nil {
+		return appendedEvents{}, err
+	}
+
+	// Validate the condition query before opening a transaction
+	if condition != nil {
+		if err := validateCombinedCondition(condition); err != nil {
+			return appendedEvents{}, err
 		}
 	}
 
 	// Start transaction using caller's context (caller controls timeout)
-	tx, err := es.pool.BeginTx(ctx, pgx.TxOptions{
-		IsoLevel: toPgxIsoLevel(es.config.DefaultAppendIsolation),
+	tx, err := es.beginTx(ctx, pgx.TxOptions{
+		IsoLevel: appendIsolation(ctx, es.config),
 	})
 	if err != nil {
-		return &ResourceError{
+		return appendedEvents{}, &ResourceError{
 			EventStoreError: EventStoreError{
-				Op:  "appendIf",
+				Op:  op,
 				Err: fmt.Errorf("failed to begin transaction: %w", err),
 			},
 			Resource: "database",
@@ -170,24 +235,29 @@ func (es *eventStore) AppendIf(ctx context.Context, events []InputEvent, conditi
 	}
 	defer tx.Rollback(ctx)
 
+	// Bound waits on aggregate version rows by the configured lock timeout and the caller's deadline
+	if err := es.applyVersionLockTimeout(ctx, tx, op); err != nil {
+		return appendedEvents{}, err
+	}
+
 	// Use conditional append with DCB concurrency control
-	err = es.appendInTx(ctx, tx, events, condition, conditionJSON)
+	appended, err := es.appendInTx(ctx, tx, events, condition, conditionJSON)
 	if err != nil {
-		return err
+		return appendedEvents{}, err
 	}
 
 	// Commit transaction
 	if err := tx.Commit(ctx); err != nil {
-		return &ResourceError{
+		return appendedEvents{}, &ResourceError{
 			EventStoreError: EventStoreError{
-				Op:  "appendIf",
+				Op:  op,
 				Err: fmt.Errorf("failed to commit transaction: %w", err),
 			},
 			Resource: "database",
 		}
 	}
 
-	return nil
+	return appended, nil
 }
 
 // extractConditionPrimitives extracts primitive values from AppendCondition for optimized PostgreSQL function
@@ -223,6 +293,50 @@ func extractConditionPrimitives(condition AppendCondition) ([]string, []string,
 	return eventTypes, conditionTags, afterCursorTxID, afterCursorPosition
 }
 
+// conditionPredicates returns the SQL predicates (over events aliased e) and their arguments that
+// select the events violating condition, as checked by append_events_if minus its
+// committed-transactions filter (never empty, so it can be joined with AND directly)
+func conditionPredicates(condition AppendCondition) ([]string, []any) {
+	eventTypes, conditionTags, afterCursorTxID, afterCursorPosition := extractConditionPrimitives(condition)
+
+	var predicates []string
+	var args []any
+	if eventTypes != nil {
+		args = append(args, eventTypes)
+		predicates = append(predicates, fmt.Sprintf("e.type = ANY($%d::text[])", len(args)))
+	}
+	if conditionTags != nil {
+		args = append(args, conditionTags)
+		predicates = append(predicates, fmt.Sprintf("e.tags @> $%d::text[]", len(args)))
+	}
+	if afterCursorTxID != nil {
+		args = append(args, *afterCursorTxID, *afterCursorPosition)
+		predicates = append(predicates, fmt.Sprintf(
+			"(e.transaction_id > $%d::xid8 OR (e.transaction_id = $%d::xid8 AND e.position > $%d::bigint))",
+			len(args)-1, len(args)-1, len(args)))
+	}
+	if len(predicates) == 0 {
+		// A match-all condition without cursor: every event violates it
+		predicates = append(predicates, "TRUE")
+	}
+	return predicates, args
+}
+
+// conditionKey identifies a condition by its event types, tags and whether it has a cursor
+func conditionKey(eventTypes, conditionTags []string, hasCursor bool) string {
+	return fmt.Sprintf("%q|%q|%t", eventTypes, conditionTags, hasCursor)
+}
+
+// sortTagStrings sorts encoded "key:value" tags by key, then by value, in place
+// Comparing the parts rather than the strings keeps e.g. "a:x" before "a0:y"
+func sortTagStrings(tags []string) {
+	slices.SortFunc(tags, func(a, b string) int {
+		aKey, aValue, _ := strings.Cut(a, ":")
+		bKey, bValue, _ := strings.Cut(b, ":")
+		return cmp.Or(strings.Compare(aKey, bKey), strings.Compare(aValue, bValue))
+	})
+}
+
 // Add helper function to encode tags as Postgres array literal
 func encodeTagsArrayLiteral(tags []string) string {
 	if len(tags) == 0 {
@@ -254,68 +368,76 @@ func toPgxIsoLevel(level IsolationLevel) pgx.TxIsoLevel {
 	}
 }
 
+// appendedEvents is what appendInTx wrote, as reported by the INSERT's RETURNING clause
+type appendedEvents struct {
+	Positions     []int64 // Positions assigned to the events, in append order
+	TransactionID uint64  // The appending transaction
+}
+
 // appendInTx appends events within an existing transaction
 // This is the internal method that does the actual work without managing transactions
-func (es *eventStore) appendInTx(ctx context.Context, tx pgx.Tx, events []InputEvent, condition AppendCondition, conditionJSON []byte) error {
-	// Validate events
-	if len(events) == 0 {
-		return &ValidationError{
-			EventStoreError: EventStoreError{
-				Op:  "appendInTx",
-				Err: fmt.Errorf("events slice cannot be empty"),
-			},
-			Field: "events",
-			Value: "empty",
+// Callers validate events with validateAppendEvents first, before opening the transaction
+// Returns the assigned positions and transaction id, taken from the insert itself
+func (es *eventStore) appendInTx(ctx context.Context, tx pgx.Tx, events []InputEvent, condition AppendCondition, conditionJSON []byte) (appendedEvents, error) {
+	condition = effectiveCondition(condition)
+
+	// Validate that the condition can be evaluated by the append functions
+	if condition != nil {
+		if err := validateCombinedCondition(condition); err != nil {
+			return appendedEvents{}, err
 		}
 	}
 
-	// Validate batch size
-	if err := es.validateBatchSize(events, "appendInTx"); err != nil {
-		return err
+	types, tags, data, metadata, err := es.appendColumns(ctx, events)
+	if err != nil {
+		return appendedEvents{}, err
 	}
-
-	// Validate each event
-	for i, event := range events {
-		if err := validateEvent(event, i); err != nil {
-			return err
-		}
+	if err := es.assignAggregateVersions(ctx, tx, events, metadata, false); err != nil {
+		return appendedEvents{}, err
 	}
 
-	// Prepare data for batch insert
-	types := make([]string, len(events))
-	tags := make([]string, len(events)) // array literal strings for storage
-	data := make([][]byte, len(events))
-
-	for i, event := range events {
-		types[i] = event.GetType()
-		data[i] = event.GetData()
-
-		// Encode tags for storage
-		var tagStrings []string
-		for _, tag := range event.GetTags() {
-			tagStrings = append(tagStrings, tag.GetKey()+":"+tag.GetValue())
-		}
-		tags[i] = encodeTagsArrayLiteral(tagStrings)
-
-		// Debug logging removed for performance
+	// Allocate explicit positions if a PositionAllocator is configured (nil = events sequence)
+	positions, err := es.allocatePositions(ctx, tx, len(events))
+	if err != nil {
+		return appendedEvents{}, err
 	}
+	// Timestamp from EventStoreConfig.Clock (nil = the database transaction timestamp)
+	occurredAt := es.occurredAt()
+	// ExecuteCommand's appends link their events to the command (events.command_transaction_id)
+	fromCommand := ctx.Value(commandContextKey{}) != nil
 
 	// Execute append operation using appropriate PostgreSQL function
-	var result []byte
-	var err error
+	var (
+		result   []byte
+		appended appendedEvents
+	)
 	if condition != nil {
 		// Extract primitive values from condition for optimized function
 		eventTypes, conditionTags, afterCursorTxID, afterCursorPosition := extractConditionPrimitives(condition)
 
 		err = tx.QueryRow(ctx, `
-			SELECT append_events_if($1, $2, $3, $4, $5, $6, $7)
-		`, types, tags, data, eventTypes, conditionTags, afterCursorTxID, afterCursorPosition).Scan(&result)
+			SELECT append_events_if($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		`, types, tags, data, eventTypes, conditionTags, afterCursorTxID, afterCursorPosition, metadata, positions, occurredAt, fromCommand).Scan(&result)
 	} else {
-		_, err = tx.Exec(ctx, `SELECT append_events_batch($1, $2, $3)`, types, tags, data)
+		appended, err = collectAppended(tx.Query(ctx, `
+			SELECT appended_position, appended_transaction_id FROM append_events_batch($1, $2, $3, $4, $5, $6, $7)
+		`, types, tags, data, metadata, positions, occurredAt, fromCommand))
 	}
 
 	if err != nil {
-		return &ResourceError{
+		if isLockTimeout(err) {
+			return appendedEvents{}, &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "appendInTx",
+					Err: fmt.Errorf("lock wait timed out: %w", err),
+				},
+				Resource: "lock",
+			}
+		}
+		if configErr := asMissingFunctionError("appendInTx", err); configErr != nil {
+			return appendedEvents{}, configErr
+		}
+		return appendedEvents{}, &ResourceError{
 			EventStoreError: EventStoreError{
 				Op:  "appendInTx",
 				Err: fmt.Errorf("failed to append events: %w", err),
@@ -326,9 +448,14 @@ func (es *eventStore) appendInTx(ctx context.Context, tx pgx.Tx, events []InputE
 
 	// Check result for conditional append operations
 	if condition != nil && len(result) > 0 {
-		var resultMap map[string]interface{}
-		if err := json.Unmarshal(result, &resultMap); err != nil {
-			return &ResourceError{
+		var appendResult struct {
+			Success       bool    `json:"success"`
+			Message       string  `json:"message"`
+			Positions     []int64 `json:"positions"`
+			TransactionID uint64  `json:"transaction_id,string"`
+		}
+		if err := json.Unmarshal(result, &appendResult); err != nil {
+			return appendedEvents{}, &ResourceError{
 				EventStoreError: EventStoreError{
 					Op:  "appendInTx",
 					Err: fmt.Errorf("failed to parse append result: %w", err),
@@ -338,16 +465,96 @@ func (es *eventStore) appendInTx(ctx context.Context, tx pgx.Tx, events []InputE
 		}
 
 		// Check if the operation was successful
-		if success, ok := resultMap["success"].(bool); !ok || !success {
+		if !appendResult.Success {
 			// This is a concurrency violation
-			return &ConcurrencyError{
+			return appendedEvents{}, &ConcurrencyError{
 				EventStoreError: EventStoreError{
 					Op:  "appendInTx",
-					Err: fmt.Errorf("append condition violated: %v", resultMap["message"]),
+					Err: fmt.Errorf("append condition violated: %v (condition %s)", appendResult.Message, describeCondition(condition)),
 				},
 			}
 		}
+		appended = appendedEvents{Positions: appendResult.Positions, TransactionID: appendResult.TransactionID}
 	}
 
-	return nil
+	return appended, nil
+}
+
+// appendColumns encodes events into the column arrays passed to the append functions, one entry
+// per event, attaching the producing command (ExecuteCommand), the derived tags
+// (EventStoreConfig.TagDerivers) and the producer (EventStoreConfig.ProducerTag or WithProducer)
+func (es *eventStore) appendColumns(ctx context.Context, events []InputEvent) (types, tags []string, data, metadata [][]byte, err error) {
+	producer, err := es.appendProducer(ctx)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	// Prepare data for batch insert
+	types = make([]string, len(events))
+	tags = make([]string, len(events)) // array literal strings for storage
+	data = make([][]byte, len(events))
+	metadata = make([][]byte, len(events)) // nil entries are stored as NULL
+
+	// Command whose handler produced the events (ExecuteCommand), empty otherwise
+	commandType, _ := ctx.Value(commandContextKey{}).(string)
+	// Append time passed to the tag derivers, the same for every event of the batch
+	now := es.clock().Now()
+
+	for i, event := range events {
+		types[i] = event.GetType()
+		data[i] = event.GetData()
+		metadata[i] = event.GetMetadata()
+		if commandType != "" {
+			metadata[i] = withMetadataField(metadata[i], MetadataCommandType, commandType)
+		}
+
+		// Encode tags for storage
+		var tagStrings []string
+		for _, tag := range event.GetTags() {
+			tagStrings = append(tagStrings, tag.GetKey()+":"+tag.GetValue())
+		}
+		if len(es.config.TagDerivers) > 0 {
+			if tagStrings, err = es.deriveTags(event, i, now, tagStrings); err != nil {
+				return nil, nil, nil, nil, err
+			}
+		}
+
+		if producer != nil {
+			if es.config.ProducerAsTag {
+				tagStrings = producer.withProducerTag(tagStrings)
+			} else {
+				metadata[i] = producer.withProducerMetadata(metadata[i])
+			}
+		}
+		if err := es.validateStoredTagBlocks(tagStrings, i, "appendInTx"); err != nil {
+			return nil, nil, nil, nil, err
+		}
+		if !es.config.PreserveTagOrder {
+			sortTagStrings(tagStrings)
+		}
+		tags[i] = encodeTagsArrayLiteral(tagStrings)
+
+		// Debug logging removed for performance
+	}
+	return types, tags, data, metadata, nil
+}
+
+// collectAppended reads the (position, transaction id) rows returned by append_events_batch
+// Positions strictly increase in append order, for the sequence as for a PositionAllocator,
+// so sorting them restores append order whatever order RETURNING produced
+func collectAppended(rows pgx.Rows, err error) (appendedEvents, error) {
+	if err != nil {
+		return appendedEvents{}, err
+	}
+	var appended appendedEvents
+	var position int64
+	_, err = pgx.ForEachRow(rows, []any{&position, &appended.TransactionID}, func() error {
+		appended.Positions = append(appended.Positions, position)
+		return nil
+	})
+	if err != nil {
+		return appendedEvents{}, err
+	}
+	slices.Sort(appended.Positions)
+	return appended, nil
 }
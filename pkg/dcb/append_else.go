@@ -0,0 +1,55 @@
+package dcb
+
+import "context"
+
+// =============================================================================
+// Append If / Else
+// =============================================================================
+
+// AppendBranch tells which events AppendIfElse appended
+type AppendBranch string
+
+const (
+	// AppendBranchEvents means the condition held and the events were appended
+	AppendBranchEvents AppendBranch = "events"
+	// AppendBranchOnFail means the condition failed and the onFail events were appended instead
+	AppendBranchOnFail AppendBranch = "on_fail"
+)
+
+// AppendIfElseResult reports the outcome of AppendIfElse
+type AppendIfElseResult struct {
+	Branch AppendBranch
+	// Violation is the condition failure that selected the onFail branch (nil for AppendBranchEvents)
+	Violation *ConcurrencyError
+}
+
+// AppendIfElse appends events if condition holds; if it fails, it appends onFail instead,
+// e.g. a "BookingRejected" event recording why a command was turned down.
+// The onFail events are appended unconditionally in a second transaction after the first one
+// rolled back, so the two branches are exclusive but not atomic: if appending onFail fails the
+// error is returned together with a result whose Branch is AppendBranchOnFail.
+// A condition failure is not an error here; any other failure of the first append is returned as is.
+// Both event slices are validated before anything is written.
+func (es *eventStore) AppendIfElse(ctx context.Context, events []InputEvent, condition AppendCondition, onFail []InputEvent) (AppendIfElseResult, error) {
+	if !es.skipEmptyAppend(onFail) {
+		if err := es.validateAppendEvents(onFail, "appendIfElse"); err != nil {
+			return AppendIfElseResult{}, err
+		}
+	}
+
+	err := es.AppendIf(ctx, events, condition)
+	if err == nil {
+		return AppendIfElseResult{Branch: AppendBranchEvents}, nil
+	}
+
+	violation, ok := GetConcurrencyError(err)
+	if !ok {
+		return AppendIfElseResult{}, err
+	}
+
+	result := AppendIfElseResult{Branch: AppendBranchOnFail, Violation: violation}
+	if err := es.Append(ctx, onFail); err != nil {
+		return result, err
+	}
+	return result, nil
+}
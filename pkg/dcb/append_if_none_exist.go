@@ -0,0 +1,160 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Append If None Exist
+// =============================================================================
+
+// AppendIfNoneExist atomically appends a batch of creations, failing if any event already stored
+// has one of the batch's values for tagKey. It is FailIfExists for many entities at once:
+//
+//	err := store.AppendIfNoneExist(ctx, []dcb.InputEvent{openAcc1, openAcc2}, "account_id")
+//
+// Every event must have a tagKey tag; several events may share a value (e.g. two events of the
+// same new entity). If any value exists nothing is appended and the ConcurrencyError lists the
+// existing values in ConflictingValues.
+//
+// As in AppendIfNotExists, each value takes the transaction-scoped advisory lock that
+// AppendIfNotExists(ctx, events, FailIfExists(tagKey, value), ...) takes, and the check runs after
+// the locks against all committed events, so concurrent creators of the same values are
+// serialized under READ COMMITTED with each other and with AppendIfNotExists. Lock waits are
+// bounded by EventStoreConfig.LockTimeout.
+func (es *eventStore) AppendIfNoneExist(ctx context.Context, events []InputEvent, tagKey string) error {
+	if tagKey == "" {
+		return &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "appendIfNoneExist",
+				Err: fmt.Errorf("tag key cannot be empty"),
+			},
+			Field: "tagKey",
+			Value: "empty",
+		}
+	}
+	if es.skipEmptyAppend(events) {
+		return nil
+	}
+	if err := es.validateAppendEvents(events, "appendIfNoneExist"); err != nil {
+		return err
+	}
+
+	values, err := batchTagValues(events, tagKey)
+	if err != nil {
+		return err
+	}
+
+	tx, err := es.beginTx(ctx, pgx.TxOptions{
+		IsoLevel: appendIsolation(ctx, es.config),
+	})
+	if err != nil {
+		return &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "appendIfNoneExist",
+				Err: fmt.Errorf("failed to begin transaction: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	defer tx.Rollback(ctx)
+
+	if err := es.applyLockTimeout(ctx, tx, "appendIfNoneExist"); err != nil {
+		return err
+	}
+
+	tags := make([]string, len(values))
+	keys := make([]string, len(values))
+	for i, value := range values {
+		tags[i] = tagKey + ":" + value
+		// Same key as FailIfExists(tagKey, value) in AppendIfNotExists
+		keys[i] = conditionKey(nil, []string{tags[i]}, false)
+	}
+	if err := lockConditionKeys(ctx, tx, "appendIfNoneExist", keys); err != nil {
+		return err
+	}
+
+	// Like AppendIfNotExists, check all committed events after taking the locks
+	rows, err := tx.Query(ctx, `SELECT DISTINCT t FROM events e, unnest(e.tags) AS t
+		WHERE e.tags && $1::text[] AND t = ANY($1::text[]) ORDER BY t`, tags)
+	if err != nil {
+		return &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "appendIfNoneExist",
+				Err: fmt.Errorf("failed to check for existing events: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	existing, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "appendIfNoneExist",
+				Err: fmt.Errorf("failed to check for existing events: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	if len(existing) > 0 {
+		conflicting := make([]string, len(existing))
+		for i, tag := range existing {
+			conflicting[i] = strings.TrimPrefix(tag, tagKey+":")
+		}
+		return &ConcurrencyError{
+			EventStoreError: EventStoreError{
+				Op:  "appendIfNoneExist",
+				Err: fmt.Errorf("append condition violated: events already exist for %s %v", tagKey, conflicting),
+			},
+			ConflictingValues: conflicting,
+		}
+	}
+
+	if _, err := es.appendInTx(ctx, tx, events, nil, nil); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "appendIfNoneExist",
+				Err: fmt.Errorf("failed to commit transaction: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	return nil
+}
+
+// batchTagValues returns the distinct values of tagKey across events, sorted
+// Every event must carry the tag
+func batchTagValues(events []InputEvent, tagKey string) ([]string, error) {
+	values := make([]string, 0, len(events))
+	for i, event := range events {
+		found := false
+		for _, tag := range event.GetTags() {
+			if tag.GetKey() == tagKey {
+				values = append(values, tag.GetValue())
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, &ValidationError{
+				EventStoreError: EventStoreError{
+					Op:  "appendIfNoneExist",
+					Err: fmt.Errorf("event %d has no %s tag", i, tagKey),
+				},
+				Field: fmt.Sprintf("event[%d]", i),
+				Value: tagKey,
+			}
+		}
+	}
+	slices.Sort(values)
+	return slices.Compact(values), nil
+}
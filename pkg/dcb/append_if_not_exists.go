@@ -0,0 +1,304 @@
+package dcb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Append If Not Exists
+// =============================================================================
+
+// OnConflict selects what AppendIfNotExists does when the entity already exists
+type OnConflict string
+
+const (
+	// OnConflictError returns the ConcurrencyError, as AppendIf does (the default)
+	OnConflictError OnConflict = "error"
+	// OnConflictIgnore treats an existing entity as a successful idempotent create
+	OnConflictIgnore OnConflict = "ignore"
+)
+
+// CreateResult reports the outcome of AppendIfNotExists
+type CreateResult struct {
+	// Created is true when the events were appended, false when the entity already existed
+	Created bool
+	// Existing is set when Created is false: the create condition's query with its cursor after
+	// the latest matching event, ready for follow-up AppendIf calls on the existing entity
+	Existing AppendCondition
+}
+
+// AppendIfNotExists appends events unless events matching condition already exist, typically a
+// FailIfExists condition: store.AppendIfNotExists(ctx, events, dcb.FailIfExists("account_id", id), dcb.OnConflictIgnore).
+//
+// Concurrent calls with the same condition are serialized by a transaction-scoped advisory lock
+// on the condition's types and tags, and the existence check runs after the lock against all
+// committed events. Each item of a multi-item condition (FailIfAnyExists) is checked and locked
+// on its own, so creators sharing any unique value are serialized too. So under the default READ COMMITTED isolation exactly one of several
+// concurrent creators appends, and the others see its events as a conflict. The lock wait is
+// bounded by EventStoreConfig.LockTimeout (a ResourceError with Resource "lock").
+//
+// With OnConflictError (or "") a conflict returns the ConcurrencyError. With OnConflictIgnore it
+// returns a nil error, Created false and the Existing condition.
+func (es *eventStore) AppendIfNotExists(ctx context.Context, events []InputEvent, condition AppendCondition, onConflict OnConflict) (CreateResult, error) {
+	if onConflict == "" {
+		onConflict = OnConflictError
+	}
+	if onConflict != OnConflictError && onConflict != OnConflictIgnore {
+		return CreateResult{}, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "appendIfNotExists",
+				Err: fmt.Errorf("unsupported conflict strategy %q", onConflict),
+			},
+			Field: "onConflict",
+			Value: string(onConflict),
+		}
+	}
+
+	condition = effectiveCondition(condition)
+	if condition == nil {
+		return CreateResult{}, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "appendIfNotExists",
+				Err: fmt.Errorf("condition must identify the entity (e.g. FailIfExists)"),
+			},
+			Field: "condition",
+			Value: "empty",
+		}
+	}
+	if err := validateConditionQuery(condition); err != nil {
+		return CreateResult{}, err
+	}
+	if err := es.validateAppendEvents(events, "appendIfNotExists"); err != nil {
+		return CreateResult{}, err
+	}
+	conditionJSON, err := json.Marshal(condition)
+	if err != nil {
+		return CreateResult{}, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "appendIfNotExists",
+				Err: fmt.Errorf("failed to marshal condition: %w", err),
+			},
+			Resource: "json",
+		}
+	}
+
+	err = es.appendIfNotExistsInTx(ctx, events, condition, conditionJSON)
+	if err == nil {
+		return CreateResult{Created: true}, nil
+	}
+	if onConflict == OnConflictError || !IsConcurrencyError(err) {
+		return CreateResult{}, err
+	}
+
+	existing, err := es.existingCondition(ctx, condition)
+	if err != nil {
+		return CreateResult{}, err
+	}
+	return CreateResult{Existing: existing}, nil
+}
+
+// appendIfNotExistsInTx appends events in a transaction holding the condition's advisory lock
+func (es *eventStore) appendIfNotExistsInTx(ctx context.Context, events []InputEvent, condition AppendCondition, conditionJSON []byte) error {
+	tx, err := es.beginTx(ctx, pgx.TxOptions{
+		IsoLevel: appendIsolation(ctx, es.config),
+	})
+	if err != nil {
+		return &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "appendIfNotExists",
+				Err: fmt.Errorf("failed to begin transaction: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	defer tx.Rollback(ctx)
+
+	// Bound lock waits (including the advisory lock) by the configured lock timeout and the caller's deadline
+	if err := es.applyLockTimeout(ctx, tx, "appendIfNotExists"); err != nil {
+		return err
+	}
+
+	if err := lockConditionKeys(ctx, tx, "appendIfNotExists", createLockKeys(condition)); err != nil {
+		return err
+	}
+
+	// append_events_if only sees transactions older than every running one; check all committed
+	// events instead, so a creator that committed while we waited for the lock is never missed
+	predicates, args := createPredicates(condition)
+	var exists bool
+	existsSQL := "SELECT EXISTS (SELECT 1 FROM events e WHERE " + strings.Join(predicates, " AND ") + ")"
+	if err := tx.QueryRow(ctx, existsSQL, args...).Scan(&exists); err != nil {
+		return &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "appendIfNotExists",
+				Err: fmt.Errorf("failed to check for existing events: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	if exists {
+		return &ConcurrencyError{
+			EventStoreError: EventStoreError{
+				Op:  "appendIfNotExists",
+				Err: fmt.Errorf("append condition violated: matching events already exist (condition %s)", describeCondition(condition)),
+			},
+		}
+	}
+
+	if _, err := es.appendInTx(ctx, tx, events, condition, conditionJSON); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "appendIfNotExists",
+				Err: fmt.Errorf("failed to commit transaction: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	return nil
+}
+
+// lockConditionKeys takes the transaction-scoped advisory locks of the given condition keys
+// Keys are locked in sorted order so concurrent callers with overlapping keys can't deadlock.
+// The locks must be taken through the tx that appends: a transaction runs on one connection from
+// begin to commit, so the lock, the existence check and the append share that session, and the
+// locks are released by its commit or rollback. Never take them through the pool, which may route
+// each statement to a different connection; that is also why session-level pg_advisory_lock,
+// whose unlock would have to find its way back to the same connection, is not used
+func lockConditionKeys(ctx context.Context, tx pgx.Tx, op string, keys []string) error {
+	_, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended(k, 0)) FROM unnest($1::text[]) AS k ORDER BY k`, keys)
+	if err == nil {
+		return nil
+	}
+	if isLockTimeout(err) {
+		return &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  op,
+				Err: fmt.Errorf("lock wait timed out: %w", err),
+			},
+			Resource: "lock",
+		}
+	}
+	return &ResourceError{
+		EventStoreError: EventStoreError{
+			Op:  op,
+			Err: fmt.Errorf("failed to acquire create lock: %w", err),
+		},
+		Resource: "database",
+	}
+}
+
+// createLockKeys returns the advisory lock keys of a create condition: one per item, so creators
+// sharing any item (e.g. the email of a FailIfAnyExists condition) are serialized. A single-item
+// condition has the key FailIfExists and AppendIfNoneExist use for the same types and tags
+func createLockKeys(condition AppendCondition) []string {
+	items := createConditionItems(condition)
+	keys := make([]string, len(items))
+	for i, item := range items {
+		eventTypes, conditionTags, afterCursorTxID, _ := extractConditionPrimitives(item)
+		keys[i] = conditionKey(eventTypes, conditionTags, afterCursorTxID != nil)
+	}
+	return keys
+}
+
+// createPredicates is conditionPredicates for a create condition: an event matching any item of
+// a multi-item condition violates it, where append_events_if would combine the items into one match
+func createPredicates(condition AppendCondition) ([]string, []any) {
+	items := createConditionItems(condition)
+	if len(items) == 1 {
+		return conditionPredicates(condition)
+	}
+
+	var (
+		alternatives []string
+		args         []any
+	)
+	for _, item := range items {
+		eventTypes, conditionTags, _, _ := extractConditionPrimitives(item)
+		var match []string
+		if eventTypes != nil {
+			args = append(args, eventTypes)
+			match = append(match, fmt.Sprintf("e.type = ANY($%d::text[])", len(args)))
+		}
+		if conditionTags != nil {
+			args = append(args, conditionTags)
+			match = append(match, fmt.Sprintf("e.tags @> $%d::text[]", len(args)))
+		}
+		if len(match) == 0 {
+			match = []string{"TRUE"} // A match-all item
+		}
+		alternatives = append(alternatives, "("+strings.Join(match, " AND ")+")")
+	}
+	predicates := []string{"(" + strings.Join(alternatives, " OR ") + ")"}
+	if after := condition.getAfterCursor(); after != nil {
+		args = append(args, after.TransactionID, after.Position)
+		predicates = append(predicates, fmt.Sprintf(
+			"(e.transaction_id > $%d::xid8 OR (e.transaction_id = $%d::xid8 AND e.position > $%d::bigint))",
+			len(args)-1, len(args)-1, len(args)))
+	}
+	return predicates, args
+}
+
+// createConditionItems splits condition into one single-item condition per query item, each with
+// the condition's cursor; a condition without items is returned as is
+func createConditionItems(condition AppendCondition) []AppendCondition {
+	failQuery := condition.getFailIfEventsMatch()
+	if failQuery == nil || len((*failQuery).GetItems()) <= 1 {
+		return []AppendCondition{condition}
+	}
+	items := (*failQuery).GetItems()
+	conditions := make([]AppendCondition, len(items))
+	for i, item := range items {
+		conditions[i] = NewAppendCondition(NewQueryFromItems(item))
+		conditions[i].setAfterCursor(condition.getAfterCursor())
+	}
+	return conditions
+}
+
+// existingCondition returns the condition's query with its cursor after the latest matching event
+func (es *eventStore) existingCondition(ctx context.Context, condition AppendCondition) (AppendCondition, error) {
+	predicates, args := createPredicates(condition)
+	sqlQuery := "SELECT e.transaction_id, e.position FROM events e WHERE " + strings.Join(predicates, " AND ") +
+		" ORDER BY e.transaction_id DESC, e.position DESC LIMIT 1"
+
+	var cursor *Cursor
+	err := es.executeReadInTx(ctx, func(tx pgx.Tx) error {
+		cursor = nil
+		var latest Cursor
+		err := tx.QueryRow(ctx, sqlQuery, args...).Scan(&latest.TransactionID, &latest.Position)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "appendIfNotExists",
+					Err: fmt.Errorf("failed to read existing events: %w", err),
+				},
+				Resource: "database",
+			}
+		}
+		cursor = &latest
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	existing := NewAppendCondition(condition.Query())
+	if cursor == nil {
+		// The conflicting cursor range had matches that are gone (e.g. archived): keep the original cursor
+		cursor = condition.getAfterCursor()
+	}
+	existing.setAfterCursor(cursor)
+	return existing, nil
+}
@@ -0,0 +1,59 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+)
+
+// =============================================================================
+// Append Into
+// =============================================================================
+
+// AppendEntry is an event for AppendInto together with the position and transaction it is
+// appended at, so the caller can reference it (e.g. with EventBuilder.CausedBy) without a re-read
+type AppendEntry struct {
+	Event         InputEvent
+	Position      int64  // Set by AppendInto; 0 until the event is appended
+	TransactionID uint64 // Set by AppendInto; 0 until the event is appended
+}
+
+// NewAppendEntries wraps events in AppendEntry values for AppendInto, in the same order
+func NewAppendEntries(events ...InputEvent) []*AppendEntry {
+	entries := make([]*AppendEntry, len(events))
+	for i, event := range events {
+		entries[i] = &AppendEntry{Event: event}
+	}
+	return entries
+}
+
+// AppendInto appends the entries' events like AppendIf (unconditionally when condition is nil)
+// and writes the assigned position and transaction id back into each entry.
+// It mutates the entries in place, so references the caller holds see the positions; on error
+// (including a ConcurrencyError) nothing is appended and the entries are left unchanged
+func (es *eventStore) AppendInto(ctx context.Context, entries []*AppendEntry, condition AppendCondition) error {
+	events := make([]InputEvent, len(entries))
+	for i, entry := range entries {
+		if entry == nil || entry.Event == nil {
+			return &ValidationError{
+				EventStoreError: EventStoreError{
+					Op:  "appendInto",
+					Err: fmt.Errorf("entry at index %d has no event", i),
+				},
+				Field: "entries",
+				Value: fmt.Sprintf("entries[%d]", i),
+			}
+		}
+		events[i] = entry.Event
+	}
+
+	appended, err := es.appendIf(ctx, "appendInto", events, condition)
+	if err != nil {
+		return err
+	}
+	// Positions are reported in append order; a skipped empty append reports none
+	for i, position := range appended.Positions {
+		entries[i].Position = position
+		entries[i].TransactionID = appended.TransactionID
+	}
+	return nil
+}
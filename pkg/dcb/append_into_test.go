@@ -0,0 +1,25 @@
+package dcb
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAppendIntoRejectsMissingEvents(t *testing.T) {
+	// es has no pool: entries without an event must be rejected before the database
+	es := newEventStore(nil, EventStoreConfig{})
+	entries := NewAppendEntries(NewInputEvent("AccountOpened", NewTags("account_id", "a1"), []byte(`{}`)))
+	if len(entries) != 1 || entries[0].Position != 0 {
+		t.Fatalf("expected one unappended entry, got %+v", entries)
+	}
+
+	for _, invalid := range [][]*AppendEntry{
+		append(entries, nil),
+		append(entries, &AppendEntry{}),
+	} {
+		err := es.AppendInto(context.Background(), invalid, nil)
+		if validationErr, ok := GetValidationError(err); !ok || validationErr.Value != "entries[1]" {
+			t.Errorf("expected a ValidationError for entries[1], got %v", err)
+		}
+	}
+}
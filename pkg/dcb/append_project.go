@@ -0,0 +1,128 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Append and Project
+// =============================================================================
+
+// AppendAndProject appends events (conditionally when condition is not nil) and projects the given
+// projectors in the same transaction, so the returned states reflect exactly the stream right after
+// the append with no window for other writers in between.
+// Returns the projected states, the append condition for the next decision (cursor after the last
+// event read, including the ones just appended) and the positions assigned to the appended events.
+// Like Project it takes a projection slot and fails fast with TooManyProjectionsError when none is free;
+// like AppendIf a violated condition returns a ConcurrencyError and nothing is appended.
+// With EventStoreConfig.AllowEmptyAppend, empty events only project (positions is nil).
+func (es *eventStore) AppendAndProject(ctx context.Context, events []InputEvent, condition AppendCondition, projectors []StateProjector) (map[string]any, AppendCondition, []int64, error) {
+	condition = effectiveCondition(condition)
+
+	skipAppend := es.skipEmptyAppend(events)
+	if !skipAppend {
+		if err := es.validateAppendEvents(events, "appendAndProject"); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	if len(projectors) == 0 {
+		return nil, nil, nil, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "appendAndProject",
+				Err: fmt.Errorf("at least one projector is required"),
+			},
+			Field: "projectors",
+			Value: "empty",
+		}
+	}
+	if err := validateProjectors("appendAndProject", projectors); err != nil {
+		return nil, nil, nil, err
+	}
+	if condition != nil {
+		if err := validateCombinedCondition(condition); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	// Acquire projection semaphore with fail-fast behavior
+	select {
+	case <-es.projectionSemaphore:
+		defer func() { es.projectionSemaphore <- struct{}{} }()
+	default:
+		return nil, nil, nil, &TooManyProjectionsError{
+			EventStoreError: EventStoreError{
+				Op:  "appendAndProject",
+				Err: fmt.Errorf("too many concurrent projections"),
+			},
+			MaxConcurrent: es.config.MaxConcurrentProjections,
+			CurrentCount:  es.config.MaxConcurrentProjections,
+		}
+	}
+
+	query := CombineProjectorQueries(projectors)
+	sqlQuery, args, err := es.buildReadQuerySQL(query, nil, projectionLimit(projectors))
+	if err != nil {
+		return nil, nil, nil, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "appendAndProject",
+				Err: fmt.Errorf("failed to build query: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+
+	// Start transaction using caller's context (caller controls timeout)
+	tx, err := es.beginTx(ctx, pgx.TxOptions{
+		IsoLevel: appendIsolation(ctx, es.config),
+	})
+	if err != nil {
+		return nil, nil, nil, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "appendAndProject",
+				Err: fmt.Errorf("failed to begin transaction: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	defer tx.Rollback(ctx)
+
+	// Bound waits on aggregate version rows by the configured lock timeout and the caller's deadline
+	if err := es.applyVersionLockTimeout(ctx, tx, "appendAndProject"); err != nil {
+		return nil, nil, nil, err
+	}
+
+	var positions []int64
+	if !skipAppend {
+		appended, err := es.appendInTx(ctx, tx, events, condition, nil)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		positions = appended.Positions
+	}
+
+	// The transaction sees its own inserts, so the projection includes the appended events
+	states, latestCursor, _, err := es.projectRowsInTx(ctx, tx, "appendAndProject", query, sqlQuery, args, projectors)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, nil, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "appendAndProject",
+				Err: fmt.Errorf("failed to commit transaction: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+
+	appendCondition := BuildAppendConditionFromQuery(query)
+	if latestCursor != nil {
+		appendCondition.setAfterCursor(latestCursor)
+	}
+
+	return states, appendCondition, positions, nil
+}
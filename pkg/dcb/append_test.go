@@ -0,0 +1,372 @@
+package dcb
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAppendConditionAccessors(t *testing.T) {
+	t.Run("empty condition", func(t *testing.T) {
+		condition := NewAppendCondition(nil)
+		if condition.Query() != nil {
+			t.Errorf("expected nil query, got %v", condition.Query())
+		}
+		if _, ok := condition.AfterPosition(); ok {
+			t.Error("expected no after position")
+		}
+	})
+
+	t.Run("query and after position", func(t *testing.T) {
+		condition := NewAppendCondition(NewQuery(NewTags("course_id", "c1"), "StudentEnrolled"))
+		condition.setAfterCursor(&Cursor{TransactionID: 7, Position: 42})
+
+		query := condition.Query()
+		if query == nil || len(query.GetItems()) != 1 {
+			t.Fatalf("expected a query with one item, got %v", query)
+		}
+		item := query.GetItems()[0]
+		if types := item.GetEventTypes(); len(types) != 1 || types[0] != "StudentEnrolled" {
+			t.Errorf("unexpected event types %v", types)
+		}
+		if tags := item.GetTags(); len(tags) != 1 || tags[0].GetKey() != "course_id" || tags[0].GetValue() != "c1" {
+			t.Errorf("unexpected tags %v", tags)
+		}
+
+		position, ok := condition.AfterPosition()
+		if !ok || position != 42 {
+			t.Errorf("expected after position 42, got %d (ok=%v)", position, ok)
+		}
+	})
+}
+
+func TestAppendConditionIsEmpty(t *testing.T) {
+	withCursor := NewAppendCondition(NewQueryEmpty())
+	withCursor.setAfterCursor(&Cursor{TransactionID: 7, Position: 42})
+
+	tests := []struct {
+		name      string
+		condition AppendCondition
+		empty     bool
+	}{
+		{"nil query", NewAppendCondition(nil), true},
+		{"query without items", NewAppendCondition(NewQueryEmpty()), true},
+		{"empty query with cursor", withCursor, true},
+		{"query with items", NewAppendCondition(NewQuery(NewTags("course_id", "c1"))), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.condition.IsEmpty(); got != tt.empty {
+				t.Errorf("IsEmpty() = %v, want %v", got, tt.empty)
+			}
+			if got := effectiveCondition(tt.condition) == nil; got != tt.empty {
+				t.Errorf("effectiveCondition nil = %v, want %v", got, tt.empty)
+			}
+		})
+	}
+
+	if effectiveCondition(nil) != nil {
+		t.Error("effectiveCondition(nil) should be nil")
+	}
+}
+
+func TestValidateAppendEventsLimits(t *testing.T) {
+	es := &eventStore{config: EventStoreConfig{MaxAppendBatchSize: 3, MaxEventDataSize: 16}}
+	newEvents := func(n int) []InputEvent {
+		events := make([]InputEvent, n)
+		for i := range events {
+			events[i] = NewInputEvent("E", NewTags("k", "v"), []byte(`{}`))
+		}
+		return events
+	}
+
+	t.Run("batch at the limit", func(t *testing.T) {
+		if err := es.validateAppendEvents(newEvents(3), "append"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("batch one over the limit", func(t *testing.T) {
+		err := es.validateAppendEvents(newEvents(4), "append")
+		valErr, ok := GetValidationError(err)
+		if !ok || valErr.Field != "batchSize" || valErr.Value != "4" {
+			t.Fatalf("expected batchSize ValidationError, got %v", err)
+		}
+		if msg := err.Error(); !strings.Contains(msg, "4") || !strings.Contains(msg, "3") {
+			t.Errorf("expected limit and actual size in %q", msg)
+		}
+	})
+
+	t.Run("event data at the limit", func(t *testing.T) {
+		events := []InputEvent{NewInputEvent("E", NewTags("k", "v"), []byte(`{"a":"12345678"}`))}
+		if err := es.validateAppendEvents(events, "append"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("event data one byte over the limit", func(t *testing.T) {
+		events := newEvents(1)
+		events = append(events, NewInputEvent("E", NewTags("k", "v"), []byte(`{"a":"123456789"}`)))
+		valErr, ok := GetValidationError(es.validateAppendEvents(events, "append"))
+		if !ok || valErr.Field != "event[1].data" || valErr.Value != "17" {
+			t.Fatalf("expected event[1].data ValidationError, got %+v", valErr)
+		}
+	})
+
+	t.Run("rejected before touching the database", func(t *testing.T) {
+		// es has no pool: reaching the database would panic
+		if err := es.Append(context.Background(), newEvents(4)); !IsValidationError(err) {
+			t.Fatalf("Append: expected ValidationError, got %v", err)
+		}
+		if err := es.AppendIf(context.Background(), newEvents(4), nil); !IsValidationError(err) {
+			t.Fatalf("AppendIf: expected ValidationError, got %v", err)
+		}
+	})
+}
+
+func TestEmptyAppend(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("rejected by default", func(t *testing.T) {
+		es := &eventStore{config: EventStoreConfig{MaxAppendBatchSize: 10}}
+		if err := es.Append(ctx, []InputEvent{}); !IsValidationError(err) {
+			t.Errorf("Append: expected ValidationError, got %v", err)
+		}
+		if err := es.AppendIf(ctx, nil, NewAppendCondition(NewQuery(NewTags("k", "v")))); !IsValidationError(err) {
+			t.Errorf("AppendIf: expected ValidationError, got %v", err)
+		}
+	})
+
+	t.Run("no-op with AllowEmptyAppend", func(t *testing.T) {
+		// es has no pool: a no-op must not reach the database
+		es := &eventStore{config: EventStoreConfig{MaxAppendBatchSize: 10, AllowEmptyAppend: true}}
+		if err := es.Append(ctx, []InputEvent{}); err != nil {
+			t.Errorf("Append: expected no-op, got %v", err)
+		}
+		if err := es.AppendIf(ctx, nil, NewAppendCondition(NewQuery(NewTags("k", "v")))); err != nil {
+			t.Errorf("AppendIf: expected no-op, got %v", err)
+		}
+	})
+}
+
+func TestAppendIfElseValidatesOnFailFirst(t *testing.T) {
+	// es has no pool: invalid onFail events must be rejected before reaching the database
+	es := &eventStore{config: EventStoreConfig{MaxAppendBatchSize: 10}}
+	events := []InputEvent{NewInputEvent("SeatBooked", NewTags("seat_id", "A1"), []byte(`{}`))}
+
+	for name, onFail := range map[string][]InputEvent{
+		"empty":        nil,
+		"missing tags": {NewInputEvent("BookingRejected", nil, []byte(`{}`))},
+	} {
+		if _, err := es.AppendIfElse(context.Background(), events, nil, onFail); !IsValidationError(err) {
+			t.Errorf("%s onFail: expected ValidationError, got %v", name, err)
+		}
+	}
+}
+
+func TestAppendIfNotExistsValidatesFirst(t *testing.T) {
+	// es has no pool: invalid arguments must be rejected before reaching the database
+	es := &eventStore{config: EventStoreConfig{MaxAppendBatchSize: 10}}
+	events := []InputEvent{NewInputEvent("AccountOpened", NewTags("account_id", "a1"), []byte(`{}`))}
+	condition := FailIfExists("account_id", "a1")
+
+	tests := []struct {
+		name       string
+		events     []InputEvent
+		condition  AppendCondition
+		onConflict OnConflict
+		field      string
+	}{
+		{"unknown strategy", events, condition, "upsert", "onConflict"},
+		{"no condition", events, nil, OnConflictIgnore, "condition"},
+		{"empty condition", events, NewAppendCondition(NewQueryEmpty()), OnConflictError, "condition"},
+		{"no events", nil, condition, OnConflictIgnore, "events"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := es.AppendIfNotExists(context.Background(), tt.events, tt.condition, tt.onConflict)
+			validationErr, ok := GetValidationError(err)
+			if !ok {
+				t.Fatalf("expected ValidationError, got %v", err)
+			}
+			if validationErr.Field != tt.field {
+				t.Errorf("expected field %q, got %q", tt.field, validationErr.Field)
+			}
+		})
+	}
+}
+
+func TestAppendIfNoneExistValidatesFirst(t *testing.T) {
+	// es has no pool: invalid arguments must be rejected before reaching the database
+	es := &eventStore{config: EventStoreConfig{MaxAppendBatchSize: 10}}
+	opened := NewInputEvent("AccountOpened", NewTags("account_id", "a1"), []byte(`{}`))
+	untagged := NewInputEvent("AccountOpened", NewTags("owner", "alice"), []byte(`{}`))
+
+	tests := []struct {
+		name   string
+		events []InputEvent
+		tagKey string
+		field  string
+	}{
+		{"empty tag key", []InputEvent{opened}, "", "tagKey"},
+		{"no events", nil, "account_id", "events"},
+		{"event without the tag", []InputEvent{opened, untagged}, "account_id", "event[1]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := es.AppendIfNoneExist(context.Background(), tt.events, tt.tagKey)
+			validationErr, ok := GetValidationError(err)
+			if !ok {
+				t.Fatalf("expected ValidationError, got %v", err)
+			}
+			if validationErr.Field != tt.field {
+				t.Errorf("expected field %q, got %q", tt.field, validationErr.Field)
+			}
+		})
+	}
+}
+
+func TestBatchTagValues(t *testing.T) {
+	events := []InputEvent{
+		NewInputEvent("AccountOpened", NewTags("account_id", "b"), []byte(`{}`)),
+		NewInputEvent("AccountOpened", NewTags("account_id", "a"), []byte(`{}`)),
+		NewInputEvent("DepositMade", NewTags("account_id", "b", "kind", "initial"), []byte(`{}`)),
+	}
+	values, err := batchTagValues(events, "account_id")
+	if err != nil {
+		t.Fatalf("batchTagValues: %v", err)
+	}
+	if !slices.Equal(values, []string{"a", "b"}) {
+		t.Errorf("values = %v, want distinct sorted [a b]", values)
+	}
+}
+
+func TestConditionPredicates(t *testing.T) {
+	condition := NewAppendCondition(NewQuery(NewTags("account_id", "a1"), "AccountOpened"))
+	condition.setAfterCursor(&Cursor{TransactionID: 5, Position: 9})
+
+	predicates, args := conditionPredicates(condition)
+	want := "e.type = ANY($1::text[]) AND e.tags @> $2::text[] AND " +
+		"(e.transaction_id > $3::xid8 OR (e.transaction_id = $3::xid8 AND e.position > $4::bigint))"
+	if got := strings.Join(predicates, " AND "); got != want {
+		t.Errorf("unexpected predicates:\n got %s\nwant %s", got, want)
+	}
+	if len(args) != 4 {
+		t.Errorf("expected 4 arguments, got %d", len(args))
+	}
+
+	predicates, args = conditionPredicates(NewAppendCondition(NewQueryAll()))
+	if len(predicates) != 1 || predicates[0] != "TRUE" || len(args) != 0 {
+		t.Errorf("expected a TRUE predicate for a match-all condition, got %v %v", predicates, args)
+	}
+}
+
+func TestCreatePredicatesMatchAnyItem(t *testing.T) {
+	condition := FailIfAnyExists(
+		TagMatch{Type: "UserCreated", Key: "user_id", Value: "u1"},
+		TagMatch{Key: "email", Value: "ann@example.com"},
+	)
+
+	predicates, args := createPredicates(condition)
+	want := "((e.type = ANY($1::text[]) AND e.tags @> $2::text[]) OR (e.tags @> $3::text[]))"
+	if got := strings.Join(predicates, " AND "); got != want {
+		t.Errorf("unexpected predicates:\n got %s\nwant %s", got, want)
+	}
+	if len(args) != 3 {
+		t.Errorf("expected 3 arguments, got %d", len(args))
+	}
+
+	// Each match locks the key of the equivalent single-value create
+	keys := createLockKeys(condition)
+	wantKeys := []string{
+		createLockKeys(FailIfEventType("UserCreated", "user_id", "u1"))[0],
+		createLockKeys(FailIfExists("email", "ann@example.com"))[0],
+	}
+	if !slices.Equal(keys, wantKeys) {
+		t.Errorf("lock keys = %v, want %v", keys, wantKeys)
+	}
+
+	// A single match is an ordinary condition
+	single := FailIfAnyExists(TagMatch{Key: "account_id", Value: "a1"})
+	got, _ := createPredicates(single)
+	plain, _ := conditionPredicates(single)
+	if !slices.Equal(got, plain) {
+		t.Errorf("expected a single match to use conditionPredicates, got %v", got)
+	}
+}
+
+func TestValidateCombinedConditionRejectsAnyItem(t *testing.T) {
+	several := FailIfAnyExists(
+		TagMatch{Type: "UserCreated", Key: "user_id", Value: "u1"},
+		TagMatch{Type: "UserCreated", Key: "email", Value: "ann@example.com"},
+	)
+	if err := validateCombinedCondition(several); !IsValidationError(err) {
+		t.Errorf("expected validation error for several FailIfAnyExists matches, got %v", err)
+	}
+	if err := validateConditionQuery(several); err != nil {
+		t.Errorf("expected AppendIfNotExists validation to accept it, got %v", err)
+	}
+
+	// One match, or the same items built as an ordinary condition, are combined matches by intent
+	if err := validateCombinedCondition(FailIfAnyExists(TagMatch{Key: "user_id", Value: "u1"})); err != nil {
+		t.Errorf("expected a single match to pass, got %v", err)
+	}
+	plain := NewAppendCondition(NewQueryFromItems((*several.getFailIfEventsMatch()).GetItems()...))
+	if err := validateCombinedCondition(plain); err != nil {
+		t.Errorf("expected an ordinary multi-item condition to pass, got %v", err)
+	}
+}
+
+func TestConditionFromQueryValidatesFirst(t *testing.T) {
+	// es has no pool: queries a condition can't evaluate must be rejected before the database
+	es := &eventStore{}
+
+	tests := []struct {
+		name  string
+		query Query
+		field string
+	}{
+		{"nil query", nil, "query"},
+		{"extended predicate", NewQueryBuilder().WithType("AccountOpened").WithCausedBy(3).Build(), "condition.item[0]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := es.ConditionFromQuery(context.Background(), tt.query)
+			validationErr, ok := GetValidationError(err)
+			if !ok {
+				t.Fatalf("expected ValidationError, got %v", err)
+			}
+			if validationErr.Field != tt.field {
+				t.Errorf("expected field %q, got %q", tt.field, validationErr.Field)
+			}
+		})
+	}
+}
+
+func TestAppendColumnsTagOrder(t *testing.T) {
+	events := []InputEvent{
+		NewInputEvent("Enrolled", NewTags("student_id", "s1", "course_id", "c1", "a0", "y", "a", "x"), []byte(`{}`)),
+		NewInputEvent("Enrolled", NewTags("a", "x", "course_id", "c1", "a0", "y", "student_id", "s1"), []byte(`{}`)),
+	}
+
+	es := &eventStore{}
+	_, tags, _, _, err := es.appendColumns(context.Background(), events)
+	if err != nil {
+		t.Fatalf("appendColumns failed: %v", err)
+	}
+	want := `{"a:x","a0:y","course_id:c1","student_id:s1"}`
+	if tags[0] != want || tags[1] != want {
+		t.Errorf("expected both events stored as %s, got %s and %s", want, tags[0], tags[1])
+	}
+
+	es.config.PreserveTagOrder = true
+	_, tags, _, _, err = es.appendColumns(context.Background(), events[:1])
+	if err != nil {
+		t.Fatalf("appendColumns failed: %v", err)
+	}
+	if want := `{"student_id:s1","course_id:c1","a0:y","a:x"}`; tags[0] != want {
+		t.Errorf("expected tags in given order %s, got %s", want, tags[0])
+	}
+}
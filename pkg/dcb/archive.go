@@ -0,0 +1,138 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Event Archival
+// =============================================================================
+
+// tableNamePattern matches plain (unqualified) PostgreSQL identifiers accepted as table names
+var tableNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]{0,62}$`)
+
+// eventColumns lists the events table columns in storage order
+const eventColumns = "type, tags, data, transaction_id, position, occurred_at, metadata, command_transaction_id"
+
+// archiveTablePrefix starts every archive table name, so Archive's CREATE TABLE and INSERT
+// can never target the store's own tables (events, commands, dcb_*)
+const archiveTablePrefix = "events_archive"
+
+// validateArchiveTableName validates a user-provided archive table name
+// It must be a plain identifier that is archiveTablePrefix or starts with archiveTablePrefix + "_"
+func validateArchiveTableName(op, name string) error {
+	if !tableNamePattern.MatchString(name) || (name != archiveTablePrefix && !strings.HasPrefix(name, archiveTablePrefix+"_")) {
+		return &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  op,
+				Err: fmt.Errorf("invalid archive table name %q: must be %s or start with %s_", name, archiveTablePrefix, archiveTablePrefix),
+			},
+			Field: "archiveTable",
+			Value: name,
+		}
+	}
+	return nil
+}
+
+// eventsSource returns the FROM source for reads: the events table, or the events table
+// combined with the configured archive table so archived events stay readable
+func (es *eventStore) eventsSource() string {
+	if es.config.ArchiveTable == "" {
+		return "events"
+	}
+	archive := pgx.Identifier{es.config.ArchiveTable}.Sanitize()
+	return "(SELECT " + eventColumns + " FROM events UNION ALL SELECT " + eventColumns + " FROM " + archive + ") AS events"
+}
+
+// Archive moves all events with position < beforePosition from the events table into archiveTable
+// within a single transaction and returns the number of events moved.
+// The archive table is created on first use with the same structure as the events table.
+//
+// Positions and cursors are preserved: archived events keep their position and transaction id,
+// and new events keep using the same position sequence, so a cursor taken before archiving
+// stays valid afterwards. Reads (Query, QueryStream, Project, ProjectStream) include archived
+// events only when EventStoreConfig.ArchiveTable names the archive; otherwise archived events
+// are no longer returned. Append conditions are always evaluated against the events table only,
+// so archive only events that no longer take part in consistency decisions.
+// Only committed events are moved; pick a beforePosition safely below the current head.
+// archiveTable must be "events_archive" or start with "events_archive_" (e.g. "events_archive_2024").
+func (es *eventStore) Archive(ctx context.Context, beforePosition int64, archiveTable string) (int64, error) {
+	if err := validateArchiveTableName("archive", archiveTable); err != nil {
+		return 0, err
+	}
+	if beforePosition <= 0 {
+		return 0, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "archive",
+				Err: fmt.Errorf("before position must be positive: %d", beforePosition),
+			},
+			Field: "beforePosition",
+			Value: fmt.Sprintf("%d", beforePosition),
+		}
+	}
+
+	tx, err := es.beginTx(ctx, pgx.TxOptions{
+		IsoLevel: toPgxIsoLevel(es.config.DefaultAppendIsolation),
+	})
+	if err != nil {
+		return 0, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "archive",
+				Err: fmt.Errorf("failed to begin transaction: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	defer tx.Rollback(ctx)
+
+	// Bound lock waits by the configured lock timeout and the caller's deadline
+	if err := es.applyLockTimeout(ctx, tx, "archive"); err != nil {
+		return 0, err
+	}
+
+	archive := pgx.Identifier{archiveTable}.Sanitize()
+	if _, err := tx.Exec(ctx, `CREATE TABLE IF NOT EXISTS `+archive+` (LIKE events INCLUDING ALL)`); err != nil {
+		return 0, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "archive",
+				Err: fmt.Errorf("failed to create archive table %s: %w", archiveTable, err),
+			},
+			Resource: "database",
+		}
+	}
+
+	tag, err := tx.Exec(ctx, `
+		WITH moved AS (
+			DELETE FROM events WHERE position < $1
+			RETURNING `+eventColumns+`
+		)
+		INSERT INTO `+archive+` (`+eventColumns+`)
+		SELECT `+eventColumns+` FROM moved
+	`, beforePosition)
+	if err != nil {
+		return 0, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "archive",
+				Err: fmt.Errorf("failed to move events: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "archive",
+				Err: fmt.Errorf("failed to commit transaction: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,16 @@
+package dcb
+
+import "testing"
+
+func TestValidateArchiveTableName(t *testing.T) {
+	for _, name := range []string{"events_archive", "events_archive_2024"} {
+		if err := validateArchiveTableName("archive", name); err != nil {
+			t.Errorf("expected %q to be accepted, got %v", name, err)
+		}
+	}
+	for _, name := range []string{"events", "commands", "dcb_failed_commands", "dcb_aggregate_versions", "dcb_scheduled_commands", "events_archived", "archive; DROP TABLE events"} {
+		if err := validateArchiveTableName("archive", name); !IsValidationError(err) {
+			t.Errorf("expected %q to be rejected, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package dcb
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// =============================================================================
+// Causation Metadata
+// =============================================================================
+
+// Metadata keys used to record causation on appended events
+// Causation is stored in the events.metadata column, not as a tag, so it never
+// takes part in tag queries or append conditions unless explicitly requested
+const (
+	MetadataCausationPosition      = "causation_position"
+	MetadataCausationTransactionID = "causation_transaction_id"
+)
+
+// CausedBy records the given event as the cause of the event being built
+// The source event's position and transaction id are stored in the event metadata
+func (eb *EventBuilder) CausedBy(event Event) *EventBuilder {
+	if eb.metadata == nil {
+		eb.metadata = make(map[string]any)
+	}
+	eb.metadata[MetadataCausationPosition] = event.Position
+	eb.metadata[MetadataCausationTransactionID] = event.TransactionID
+	return eb
+}
+
+// CausationPosition returns the position of the event that caused this one, if recorded
+func (e Event) CausationPosition() (int64, bool) {
+	if len(e.Metadata) == 0 {
+		return 0, false
+	}
+
+	var metadata struct {
+		CausationPosition *int64 `json:"causation_position"`
+	}
+	if err := json.Unmarshal(e.Metadata, &metadata); err != nil || metadata.CausationPosition == nil {
+		return 0, false
+	}
+	return *metadata.CausationPosition, true
+}
+
+// WithCausedBy restricts the current QueryItem to events caused by the event at the given position (AND)
+// This is meant for reads and projections (e.g. walking a causality graph);
+// append conditions only support event types and tags and reject it
+func (qb *QueryBuilder) WithCausedBy(position int64) *QueryBuilder {
+	qb.currentItem.causedBy = &position
+	return qb
+}
+
+// causationMetadataFilter builds the JSONB containment filter for a causation position
+func causationMetadataFilter(position int64) string {
+	return fmt.Sprintf(`{"%s":%d}`, MetadataCausationPosition, position)
+}
@@ -0,0 +1,106 @@
+package dcb
+
+import (
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// Clock
+// =============================================================================
+
+// Clock supplies the time used by the store: event timestamps, read retry backoff, condition
+// cost cache expiry and projection durations. Set EventStoreConfig.Clock to inject one, e.g.
+// NewFakeClock in tests; nil uses the real clock.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// After returns a channel that receives the time once d has elapsed, like time.After
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the system clock
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// clock returns the configured Clock, or the real clock when none is set
+func (es *eventStore) clock() Clock {
+	if es.config.Clock != nil {
+		return es.config.Clock
+	}
+	return realClock{}
+}
+
+// occurredAt returns the timestamp stored as occurred_at for appended events and stored commands
+// nil lets PostgreSQL use the transaction timestamp, which is what happens without a Clock
+func (es *eventStore) occurredAt() *time.Time {
+	if es.config.Clock == nil {
+		return nil
+	}
+	now := es.config.Clock.Now()
+	return &now
+}
+
+// FakeClock is a deterministic Clock for tests. Time only moves when Advance is called or when
+// the store waits: After advances the clock by d and fires immediately, so backoff never sleeps
+// and every wait is recorded for assertions (see Waits).
+type FakeClock struct {
+	t     TestingT
+	mu    sync.Mutex
+	now   time.Time
+	waits []time.Duration
+}
+
+// TestingT is the part of testing.TB a FakeClock uses; *testing.T and GinkgoT() satisfy it
+type TestingT interface {
+	Helper()
+	Fatalf(format string, args ...any)
+}
+
+// fakeClockStart is the initial time of a FakeClock
+var fakeClockStart = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// NewFakeClock returns a FakeClock set to 2025-01-01T00:00:00Z
+// Example: clock := dcb.NewFakeClock(t); config.Clock = clock
+func NewFakeClock(t TestingT) *FakeClock {
+	t.Helper()
+	return &FakeClock{t: t, now: fakeClockStart}
+}
+
+// Now returns the fake current time
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After records the wait, advances the clock by d and returns an already fired channel
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.waits = append(c.waits, d)
+	c.now = c.now.Add(d)
+	fired := make(chan time.Time, 1)
+	fired <- c.now
+	return fired
+}
+
+// Advance moves the clock forward by d
+func (c *FakeClock) Advance(d time.Duration) {
+	c.t.Helper()
+	if d < 0 {
+		c.t.Fatalf("FakeClock.Advance: negative duration %v", d)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Waits returns the durations passed to After, in call order
+func (c *FakeClock) Waits() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration(nil), c.waits...)
+}
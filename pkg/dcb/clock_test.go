@@ -0,0 +1,47 @@
+package dcb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	clock := NewFakeClock(t)
+	start := clock.Now()
+	if !start.Equal(time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected start %v", start)
+	}
+
+	clock.Advance(time.Hour)
+	if got := clock.Now().Sub(start); got != time.Hour {
+		t.Fatalf("Advance: expected 1h, got %v", got)
+	}
+
+	select {
+	case fired := <-clock.After(time.Minute):
+		if !fired.Equal(start.Add(time.Hour + time.Minute)) {
+			t.Errorf("After fired at %v", fired)
+		}
+	default:
+		t.Fatal("After should fire immediately")
+	}
+	if waits := clock.Waits(); len(waits) != 1 || waits[0] != time.Minute {
+		t.Errorf("expected one recorded wait of 1m, got %v", waits)
+	}
+}
+
+func TestStoreClock(t *testing.T) {
+	es := &eventStore{}
+	if _, ok := es.clock().(realClock); !ok {
+		t.Errorf("expected the real clock by default, got %T", es.clock())
+	}
+	if es.occurredAt() != nil {
+		t.Error("without a Clock occurred_at must be left to the database")
+	}
+
+	clock := NewFakeClock(t)
+	es = &eventStore{config: EventStoreConfig{Clock: clock}}
+	if occurredAt := es.occurredAt(); occurredAt == nil || !occurredAt.Equal(clock.Now()) {
+		t.Errorf("expected occurred_at from the clock, got %v", occurredAt)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 )
@@ -15,22 +16,66 @@ import (
 // CommandExecutor executes commands and generates events
 // This is an optional convenience API for command-driven event generation
 type CommandExecutor interface {
-	ExecuteCommand(ctx context.Context, command Command, handler CommandHandler, condition *AppendCondition) ([]InputEvent, error)
+	ExecuteCommand(ctx context.Context, command Command, handler CommandHandler, condition *AppendCondition) (CommandResult, error)
+
+	// ExecuteCommandWithRetry executes command with the condition handler returns, re-running the
+	// handler on a ConcurrencyError up to policy.MaxAttempts times
+	ExecuteCommandWithRetry(ctx context.Context, command Command, handler ConditionalCommandHandler, policy RetryPolicy) (CommandResult, error)
+
+	// RetryFailedCommand re-executes a dead-lettered command (see CommandExecutorConfig.DeadLetter)
+	// with handler and marks it resolved when it succeeds
+	RetryFailedCommand(ctx context.Context, id int64, handler CommandHandler, condition *AppendCondition) (CommandResult, error)
+
+	// ScheduleCommand stores command for execution by RunDue once at has passed; returns its id
+	ScheduleCommand(ctx context.Context, command Command, at time.Time) (int64, error)
+
+	// RunDue executes the scheduled commands that are due with handler, each in a transaction that
+	// marks it done, and returns how many succeeded. Failed commands stay pending for a later run
+	RunDue(ctx context.Context, handler CommandHandler) (int, error)
+
+	// SubscribeCommands streams the commands stored after transaction fromTxID in transaction id
+	// order, then follows newly executed commands until ctx is cancelled
+	SubscribeCommands(ctx context.Context, fromTxID uint64) (<-chan StoredCommand, error)
+}
+
+// CommandResult describes the outcome of a successfully executed command
+type CommandResult struct {
+	// Events are the events generated by the handler, in append order
+	Events []InputEvent
+	// Positions are the positions assigned to Events, in the same order
+	Positions []int64
+	// TransactionID is the transaction that appended the events and stored the command
+	TransactionID uint64
+	// Output is the optional value returned by the handler (e.g. a generated ID); nil if none
+	Output any
+	// FailedCommandID is set when ExecuteCommand fails and the command was dead-lettered:
+	// the id of its dcb_failed_commands row, for RetryFailedCommand
+	FailedCommandID int64
+	// Attempts is the number of times ExecuteCommandWithRetry ran the command; 0 for ExecuteCommand
+	Attempts int
 }
 
 // CommandHandler handles command execution and generates events
+// The output value is passed through unchanged as CommandResult.Output; return nil when there is none
 // This is an optional convenience API for users - not used by core abstractions
 type CommandHandler interface {
-	Handle(ctx context.Context, store EventStore, command Command) ([]InputEvent, error)
+	Handle(ctx context.Context, store EventStore, command Command) (events []InputEvent, output any, err error)
 }
 
 // CommandHandlerFunc allows using functions as CommandHandler implementations
-type CommandHandlerFunc func(ctx context.Context, store EventStore, command Command) ([]InputEvent, error)
+type CommandHandlerFunc func(ctx context.Context, store EventStore, command Command) ([]InputEvent, any, error)
 
-func (f CommandHandlerFunc) Handle(ctx context.Context, store EventStore, command Command) ([]InputEvent, error) {
+func (f CommandHandlerFunc) Handle(ctx context.Context, store EventStore, command Command) ([]InputEvent, any, error) {
 	return f(ctx, store, command)
 }
 
+// RunCommand executes a command and discards its CommandResult
+// Convenience for callers that only care whether the command succeeded
+func RunCommand(ctx context.Context, executor CommandExecutor, command Command, handler CommandHandler, condition *AppendCondition) error {
+	_, err := executor.ExecuteCommand(ctx, command, handler, condition)
+	return err
+}
+
 // Command represents a command that triggers event generation
 type Command interface {
 	GetType() string
@@ -50,22 +95,65 @@ func (c *command) GetType() string                     { return c.commandType }
 func (c *command) GetData() []byte                     { return c.data }
 func (c *command) GetMetadata() map[string]interface{} { return c.metadata }
 
+// CommandExecutorConfig configures a CommandExecutor
+type CommandExecutorConfig struct {
+	// DeadLetter records commands that fail (handler error or panic, invalid events, storage
+	// failure) in the dcb_failed_commands table for inspection and RetryFailedCommand.
+	// Concurrency errors are expected outcomes and are not recorded. Default false
+	DeadLetter bool `json:"dead_letter"`
+
+	// MaxScheduledAttempts is the number of times RunDue executes a failing scheduled command
+	// before moving it to dcb_failed_commands and no longer retrying it. Zero means
+	// DefaultScheduledCommandMaxAttempts; a negative value retries it forever
+	MaxScheduledAttempts int `json:"max_scheduled_attempts"`
+
+	// NotifyCommands makes ExecuteCommand NOTIFY dcb_commands, so SubscribeCommands delivers new
+	// commands as soon as they commit instead of at its next poll. The notification costs a round
+	// trip per command, and Postgres serializes the commits of notifying transactions on its
+	// notification queue lock, so enable it only when subscribers need the lower latency. Default false
+	NotifyCommands bool `json:"notify_commands"`
+}
+
 type commandExecutor struct {
 	eventStore EventStore
+	config     CommandExecutorConfig
 }
 
 func (ce *commandExecutor) isCommandExecutor() {}
 
 func NewCommandExecutor(eventStore EventStore) CommandExecutor {
+	return NewCommandExecutorWithConfig(eventStore, CommandExecutorConfig{})
+}
+
+// NewCommandExecutorWithConfig creates a CommandExecutor with the given configuration
+func NewCommandExecutorWithConfig(eventStore EventStore, config CommandExecutorConfig) CommandExecutor {
 	return &commandExecutor{
 		eventStore: eventStore,
+		config:     config,
+	}
+}
+
+// ExecuteCommand runs handler for command and appends the generated events together with the command
+// A panicking handler is recovered and reported as a ResourceError with Resource "handler"
+func (ce *commandExecutor) ExecuteCommand(ctx context.Context, command Command, handler CommandHandler, condition *AppendCondition) (CommandResult, error) {
+	result, err := ce.executeCommand(ctx, command, handler, condition)
+	if err != nil && ce.config.DeadLetter && command != nil && !IsConcurrencyError(err) {
+		result.FailedCommandID = ce.deadLetter(ctx, command, err)
+	}
+	if adapter, ok := handler.(*conditionalHandler); ok {
+		condition = adapter.condition
+	}
+	if recorder, ok := ce.eventStore.(*RecordingStore); ok {
+		recorder.recordCommand(result, condition, err)
 	}
+	return result, err
 }
 
-func (ce *commandExecutor) ExecuteCommand(ctx context.Context, command Command, handler CommandHandler, condition *AppendCondition) ([]InputEvent, error) {
+// executeCommand executes command in a single transaction
+func (ce *commandExecutor) executeCommand(ctx context.Context, command Command, handler CommandHandler, condition *AppendCondition) (CommandResult, error) {
 	// Validate inputs
 	if command == nil {
-		return nil, &ValidationError{
+		return CommandResult{}, &ValidationError{
 			EventStoreError: EventStoreError{
 				Op:  "ExecuteCommand",
 				Err: fmt.Errorf("command cannot be nil"),
@@ -76,7 +164,7 @@ func (ce *commandExecutor) ExecuteCommand(ctx context.Context, command Command,
 	}
 
 	if handler == nil {
-		return nil, &ValidationError{
+		return CommandResult{}, &ValidationError{
 			EventStoreError: EventStoreError{
 				Op:  "ExecuteCommand",
 				Err: fmt.Errorf("handler cannot be nil"),
@@ -92,7 +180,7 @@ func (ce *commandExecutor) ExecuteCommand(ctx context.Context, command Command,
 		var err error
 		commandMetadata, err = json.Marshal(command.GetMetadata())
 		if err != nil {
-			return nil, &ResourceError{
+			return CommandResult{}, &ResourceError{
 				EventStoreError: EventStoreError{
 					Op:  "ExecuteCommand",
 					Err: fmt.Errorf("failed to marshal command metadata: %w", err),
@@ -105,13 +193,25 @@ func (ce *commandExecutor) ExecuteCommand(ctx context.Context, command Command,
 	// Get config from EventStore
 	config := ce.eventStore.GetConfig()
 
-	// Get pool and start transaction
-	pool := ce.eventStore.GetPool()
-	tx, err := pool.BeginTx(ctx, pgx.TxOptions{
-		IsoLevel: toPgxIsoLevel(config.DefaultAppendIsolation),
+	// Resolve the internal store (unwrapping decorators) to access beginTx and appendInTx
+	es, ok := asEventStore(ce.eventStore)
+	if !ok {
+		return CommandResult{}, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "ExecuteCommand",
+				Err: fmt.Errorf("unsupported EventStore implementation %T", ce.eventStore),
+			},
+			Field: "eventStore",
+			Value: fmt.Sprintf("%T", ce.eventStore),
+		}
+	}
+
+	// Start transaction (a savepoint when the store is transaction-scoped)
+	tx, err := es.beginTx(ctx, pgx.TxOptions{
+		IsoLevel: appendIsolation(ctx, config),
 	})
 	if err != nil {
-		return nil, &ResourceError{
+		return CommandResult{}, &ResourceError{
 			EventStoreError: EventStoreError{
 				Op:  "ExecuteCommand",
 				Err: fmt.Errorf("failed to begin transaction: %w", err),
@@ -122,9 +222,12 @@ func (ce *commandExecutor) ExecuteCommand(ctx context.Context, command Command,
 	defer tx.Rollback(ctx)
 
 	// 1. Generate events using the handler with access to EventStore
-	events, handlerErr := handler.Handle(ctx, ce.eventStore, command)
+	events, output, handlerErr, panicErr := handleRecovered(ctx, handler, ce.eventStore, command)
+	if panicErr != nil {
+		return CommandResult{}, panicErr
+	}
 	if handlerErr != nil {
-		return nil, &ValidationError{
+		return CommandResult{}, &ValidationError{
 			EventStoreError: EventStoreError{
 				Op:  "ExecuteCommand",
 				Err: handlerErr,
@@ -134,9 +237,18 @@ func (ce *commandExecutor) ExecuteCommand(ctx context.Context, command Command,
 		}
 	}
 
+	// A ConditionalCommandHandler decides the condition along with the events
+	if adapter, ok := handler.(*conditionalHandler); ok {
+		condition = adapter.condition
+	}
+
 	// 3. Validate generated events
+	// With AllowEmptyAppend a handler may decide that nothing happened: nothing is stored
+	if es.skipEmptyAppend(events) {
+		return CommandResult{Output: output}, nil
+	}
 	if len(events) == 0 {
-		return nil, &ValidationError{
+		return CommandResult{}, &ValidationError{
 			EventStoreError: EventStoreError{
 				Op:  "ExecuteCommand",
 				Err: fmt.Errorf("handler generated no events"),
@@ -149,7 +261,7 @@ func (ce *commandExecutor) ExecuteCommand(ctx context.Context, command Command,
 	// Validate individual events
 	for i, event := range events {
 		if event.GetType() == "" {
-			return nil, &ValidationError{
+			return CommandResult{}, &ValidationError{
 				EventStoreError: EventStoreError{
 					Op:  "ExecuteCommand",
 					Err: fmt.Errorf("event at index %d has empty type", i),
@@ -163,7 +275,7 @@ func (ce *commandExecutor) ExecuteCommand(ctx context.Context, command Command,
 		tagKeys := make(map[string]bool)
 		for j, tag := range event.GetTags() {
 			if tag.GetKey() == "" {
-				return nil, &ValidationError{
+				return CommandResult{}, &ValidationError{
 					EventStoreError: EventStoreError{
 						Op:  "ExecuteCommand",
 						Err: fmt.Errorf("empty tag key at index %d", j),
@@ -173,7 +285,7 @@ func (ce *commandExecutor) ExecuteCommand(ctx context.Context, command Command,
 				}
 			}
 			if tag.GetValue() == "" {
-				return nil, &ValidationError{
+				return CommandResult{}, &ValidationError{
 					EventStoreError: EventStoreError{
 						Op:  "ExecuteCommand",
 						Err: fmt.Errorf("empty tag value for key %s", tag.GetKey()),
@@ -183,7 +295,7 @@ func (ce *commandExecutor) ExecuteCommand(ctx context.Context, command Command,
 				}
 			}
 			if tagKeys[tag.GetKey()] {
-				return nil, &ValidationError{
+				return CommandResult{}, &ValidationError{
 					EventStoreError: EventStoreError{
 						Op:  "ExecuteCommand",
 						Err: fmt.Errorf("event at index %d has duplicate tag key: %s", i, tag.GetKey()),
@@ -196,25 +308,30 @@ func (ce *commandExecutor) ExecuteCommand(ctx context.Context, command Command,
 		}
 	}
 
-	// 4. Append events FIRST (primary data)
-	// Use type assertion to access internal appendInTx method
-	es := ce.eventStore.(*eventStore)
+	// Enforce the store's batch and event limits before appending
+	if err := es.validateAppendEvents(events, "ExecuteCommand"); err != nil {
+		return CommandResult{}, err
+	}
+
+	// 4. Append events FIRST (primary data), stamped with the command that produced them
+	var appended appendedEvents
+	stamped := context.WithValue(ctx, commandContextKey{}, command.GetType())
 	if condition != nil {
-		err = es.appendInTx(ctx, tx, events, *condition, nil)
+		appended, err = es.appendInTx(stamped, tx, events, *condition, nil)
 	} else {
-		err = es.appendInTx(ctx, tx, events, nil, nil)
+		appended, err = es.appendInTx(stamped, tx, events, nil, nil)
 	}
 	if err != nil {
-		return nil, err // If events fail, don't store command
+		return CommandResult{}, err // If events fail, don't store command
 	}
 
 	// 5. Store command AFTER events (metadata) - now using pre-marshaled data
 	_, err = tx.Exec(ctx, `
-		INSERT INTO commands (transaction_id, type, data, metadata)
-		VALUES (pg_current_xact_id(), $1, $2, $3)
-	`, command.GetType(), command.GetData(), commandMetadata)
+		INSERT INTO commands (transaction_id, type, data, metadata, occurred_at)
+		VALUES (pg_current_xact_id(), $1, $2, $3, COALESCE($4, CURRENT_TIMESTAMP))
+	`, command.GetType(), command.GetData(), commandMetadata, es.occurredAt())
 	if err != nil {
-		return nil, &ResourceError{
+		return CommandResult{}, &ResourceError{
 			EventStoreError: EventStoreError{
 				Op:  "ExecuteCommand",
 				Err: fmt.Errorf("failed to store command: %w", err),
@@ -223,9 +340,22 @@ func (ce *commandExecutor) ExecuteCommand(ctx context.Context, command Command,
 		}
 	}
 
+	// Wake command subscribers once the transaction commits (opt-in, see NotifyCommands)
+	if ce.config.NotifyCommands {
+		if _, err := tx.Exec(ctx, "NOTIFY "+commandsChannel); err != nil {
+			return CommandResult{}, &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "ExecuteCommand",
+					Err: fmt.Errorf("failed to notify command subscribers: %w", err),
+				},
+				Resource: "database",
+			}
+		}
+	}
+
 	// Commit transaction
 	if err := tx.Commit(ctx); err != nil {
-		return nil, &ResourceError{
+		return CommandResult{}, &ResourceError{
 			EventStoreError: EventStoreError{
 				Op:  "ExecuteCommand",
 				Err: fmt.Errorf("failed to commit transaction: %w", err),
@@ -234,5 +364,10 @@ func (ce *commandExecutor) ExecuteCommand(ctx context.Context, command Command,
 		}
 	}
 
-	return events, nil
+	return CommandResult{
+		Events:        events,
+		Positions:     appended.Positions,
+		TransactionID: appended.TransactionID,
+		Output:        output,
+	}, nil
 }
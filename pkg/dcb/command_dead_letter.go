@@ -0,0 +1,195 @@
+package dcb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Command Dead-Lettering
+// =============================================================================
+
+// deadLetterTimeout bounds the write of a dead-lettered command, which doesn't use the caller's
+// context: a cancelled context or expired deadline is a failure worth recording
+const deadLetterTimeout = 5 * time.Second
+
+// handleRecovered calls handler, converting a panic into panicErr instead of crashing the process
+// The panic value and stack trace are logged
+func handleRecovered(ctx context.Context, handler CommandHandler, store EventStore, command Command) (events []InputEvent, output any, err error, panicErr error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("ExecuteCommand: handler for %s panicked: %v\n%s", command.GetType(), r, debug.Stack())
+			events, output, err = nil, nil, nil
+			panicErr = &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "ExecuteCommand",
+					Err: fmt.Errorf("handler panicked: %v", r),
+				},
+				Resource: "handler",
+			}
+		}
+	}()
+	events, output, err = handler.Handle(ctx, store, command)
+	return events, output, err, nil
+}
+
+// isHandlerPanic reports whether err is a recovered handler panic
+func isHandlerPanic(err error) bool {
+	resourceErr, ok := GetResourceError(err)
+	return ok && resourceErr.Resource == "handler"
+}
+
+// deadLetter records command and cause in dcb_failed_commands and returns the row id
+// It writes through the pool, outside any transaction-scoped store, so the record survives the
+// rollback of the failed command, and with its own deadline, so commands that failed because ctx
+// was cancelled or timed out are recorded too. Failing to record is logged, not returned: cause
+// is the error the caller needs, and 0 is returned as id.
+func (ce *commandExecutor) deadLetter(ctx context.Context, command Command, cause error) int64 {
+	es, ok := asEventStore(ce.eventStore)
+	if !ok || (es.pool == nil && es.sqlDB == nil) {
+		log.Printf("ExecuteCommand: cannot dead-letter command %s: unsupported EventStore implementation %T", command.GetType(), ce.eventStore)
+		return 0
+	}
+
+	// Metadata that can't be marshaled (the cause of some failures) is dropped from the record
+	var metadata []byte
+	if command.GetMetadata() != nil {
+		metadata, _ = json.Marshal(command.GetMetadata())
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), deadLetterTimeout)
+	defer cancel()
+	var id int64
+	err := es.poolDB().QueryRow(ctx, `
+		INSERT INTO dcb_failed_commands (type, data, metadata, error, panicked)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, command.GetType(), command.GetData(), metadata, cause.Error(), isHandlerPanic(cause)).Scan(&id)
+	if err != nil {
+		log.Printf("ExecuteCommand: failed to dead-letter command %s: %v", command.GetType(), err)
+		return 0
+	}
+	return id
+}
+
+// RetryFailedCommand loads the dead-lettered command id and executes it again with handler
+// The row is claimed (FOR UPDATE) in the transaction that runs the command, and on success marked
+// resolved (resolved_at, resolved_transaction_id) in it, so the resolution commits or rolls back
+// with the events and concurrent retries of one id run the command at most once. The row is kept
+// for inspection; on failure its error, attempts and failed_at are updated and no new row is added.
+// A command that is unknown or already resolved is a ValidationError
+func (ce *commandExecutor) RetryFailedCommand(ctx context.Context, id int64, handler CommandHandler, condition *AppendCondition) (CommandResult, error) {
+	es, ok := asEventStore(ce.eventStore)
+	if !ok {
+		return CommandResult{}, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "RetryFailedCommand",
+				Err: fmt.Errorf("unsupported EventStore implementation %T", ce.eventStore),
+			},
+			Field: "eventStore",
+			Value: fmt.Sprintf("%T", ce.eventStore),
+		}
+	}
+
+	var (
+		result  CommandResult
+		execErr error
+	)
+	err := es.WithTransaction(ctx, func(txStore EventStore) error {
+		scoped, _ := asEventStore(txStore)
+		db, err := scoped.db()
+		if err != nil {
+			return err
+		}
+
+		var (
+			commandType string
+			data        []byte
+			rawMetadata []byte
+			resolved    bool
+		)
+		err = db.QueryRow(ctx, `
+			SELECT type, data, metadata, resolved_at IS NOT NULL
+			FROM dcb_failed_commands WHERE id = $1
+			FOR UPDATE
+		`, id).Scan(&commandType, &data, &rawMetadata, &resolved)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && resolved) {
+			reason := "no failed command with this id"
+			if resolved {
+				reason = "failed command is already resolved"
+			}
+			return &ValidationError{
+				EventStoreError: EventStoreError{
+					Op:  "RetryFailedCommand",
+					Err: fmt.Errorf("%s: %d", reason, id),
+				},
+				Field: "id",
+				Value: fmt.Sprintf("%d", id),
+			}
+		}
+		if err != nil {
+			if configErr := asMissingTableError("RetryFailedCommand", "dcb_failed_commands", err); configErr != nil {
+				return configErr
+			}
+			return wrapDatabaseError("RetryFailedCommand", fmt.Sprintf("failed to claim failed command %d", id), err)
+		}
+
+		var metadata map[string]interface{}
+		if len(rawMetadata) > 0 {
+			if err := json.Unmarshal(rawMetadata, &metadata); err != nil {
+				return &ResourceError{
+					EventStoreError: EventStoreError{
+						Op:  "RetryFailedCommand",
+						Err: fmt.Errorf("failed to decode metadata of failed command %d: %w", id, err),
+					},
+					Resource: "json",
+				}
+			}
+		}
+
+		// The command runs in a savepoint: a failure undoes its events but keeps the claim
+		executor := &commandExecutor{eventStore: txStore, config: ce.config}
+		result, execErr = executor.executeCommand(ctx, NewCommand(commandType, data, metadata), handler, condition)
+		if execErr != nil {
+			_, err = db.Exec(ctx, `
+				UPDATE dcb_failed_commands
+				SET error = $2, panicked = $3, attempts = attempts + 1, failed_at = CURRENT_TIMESTAMP
+				WHERE id = $1
+			`, id, execErr.Error(), isHandlerPanic(execErr))
+			if err != nil {
+				log.Printf("RetryFailedCommand: failed to update failed command %d: %v", id, err)
+			}
+			return nil
+		}
+
+		// A retry that appended nothing (AllowEmptyAppend) has no transaction to record
+		var transactionID *uint64
+		if result.TransactionID != 0 {
+			transactionID = &result.TransactionID
+		}
+		_, err = db.Exec(ctx, `
+			UPDATE dcb_failed_commands
+			SET resolved_at = CURRENT_TIMESTAMP, resolved_transaction_id = $2
+			WHERE id = $1
+		`, id, transactionID)
+		if err != nil {
+			return wrapDatabaseError("RetryFailedCommand", fmt.Sprintf("failed to mark failed command %d resolved", id), err)
+		}
+		return nil
+	})
+	if err != nil {
+		return CommandResult{}, err
+	}
+	if execErr != nil {
+		result.FailedCommandID = id
+		return result, execErr
+	}
+	return result, nil
+}
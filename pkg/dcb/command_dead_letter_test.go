@@ -0,0 +1,48 @@
+package dcb
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandleRecoveredConvertsPanics(t *testing.T) {
+	command := NewCommand("OpenAccount", []byte(`{}`), nil)
+
+	panicking := CommandHandlerFunc(func(ctx context.Context, store EventStore, command Command) ([]InputEvent, any, error) {
+		panic("boom")
+	})
+	events, output, err, panicErr := handleRecovered(context.Background(), panicking, nil, command)
+	if events != nil || output != nil || err != nil {
+		t.Errorf("expected no handler results after a panic, got %v %v %v", events, output, err)
+	}
+	if !isHandlerPanic(panicErr) || !strings.Contains(panicErr.Error(), "boom") {
+		t.Fatalf("expected handler panic error mentioning the panic value, got %v", panicErr)
+	}
+
+	failing := CommandHandlerFunc(func(ctx context.Context, store EventStore, command Command) ([]InputEvent, any, error) {
+		return nil, nil, errors.New("insufficient funds")
+	})
+	_, _, err, panicErr = handleRecovered(context.Background(), failing, nil, command)
+	if panicErr != nil || err == nil || isHandlerPanic(err) {
+		t.Errorf("expected a plain handler error, got %v (panic %v)", err, panicErr)
+	}
+}
+
+func TestScheduleCommandValidation(t *testing.T) {
+	executor := NewCommandExecutor(&eventStore{})
+	at := time.Date(2025, time.January, 1, 0, 15, 0, 0, time.UTC)
+
+	if _, err := executor.ScheduleCommand(context.Background(), nil, at); !IsValidationError(err) {
+		t.Errorf("expected validation error for a nil command, got %v", err)
+	}
+	command := NewCommand("ExpireBooking", []byte(`{}`), nil)
+	if _, err := executor.ScheduleCommand(context.Background(), command, time.Time{}); !IsValidationError(err) {
+		t.Errorf("expected validation error for a zero time, got %v", err)
+	}
+	if _, err := executor.RunDue(context.Background(), nil); !IsValidationError(err) {
+		t.Errorf("expected validation error for a nil handler, got %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// =============================================================================
+// Command Retry
+// =============================================================================
+
+// DefaultCommandMaxAttempts is the number of attempts ExecuteCommandWithRetry makes when
+// RetryPolicy.MaxAttempts is 0
+const DefaultCommandMaxAttempts = 3
+
+// RetryPolicy bounds how often ExecuteCommandWithRetry re-runs a command that lost a race
+type RetryPolicy struct {
+	// MaxAttempts is the most times the handler runs, the first attempt included;
+	// 0 means DefaultCommandMaxAttempts
+	MaxAttempts int `json:"max_attempts"`
+	// Backoff is the delay (in milliseconds) before the second attempt, doubled before each
+	// further one; 0 retries immediately
+	Backoff int `json:"backoff"`
+}
+
+// ConditionalCommandHandler handles a command like CommandHandler and also returns the
+// AppendCondition its decision depends on, usually the condition Project returned with the state
+// it decided on. The events are appended only if that condition still holds; nil appends them
+// unconditionally
+type ConditionalCommandHandler interface {
+	Handle(ctx context.Context, store EventStore, command Command) (events []InputEvent, condition *AppendCondition, output any, err error)
+}
+
+// ConditionalCommandHandlerFunc allows using functions as ConditionalCommandHandler implementations
+type ConditionalCommandHandlerFunc func(ctx context.Context, store EventStore, command Command) ([]InputEvent, *AppendCondition, any, error)
+
+func (f ConditionalCommandHandlerFunc) Handle(ctx context.Context, store EventStore, command Command) ([]InputEvent, *AppendCondition, any, error) {
+	return f(ctx, store, command)
+}
+
+// conditionalHandler adapts a ConditionalCommandHandler to executeCommand, keeping the condition
+// of its last run for the append
+type conditionalHandler struct {
+	handler   ConditionalCommandHandler
+	condition *AppendCondition
+}
+
+func (h *conditionalHandler) Handle(ctx context.Context, store EventStore, command Command) ([]InputEvent, any, error) {
+	events, condition, output, err := h.handler.Handle(ctx, store, command)
+	h.condition = condition
+	return events, output, err
+}
+
+// ExecuteCommandWithRetry executes command like ExecuteCommand, with the append guarded by the
+// condition handler returns, and runs it again while it fails with a ConcurrencyError: each
+// attempt projects fresh state, decides and appends conditionally, so a command that lost a race
+// is decided again on the events that beat it. It gives up after policy.MaxAttempts attempts and
+// returns the last ConcurrencyError; any other error is returned at once. CommandResult.Attempts
+// is the number of attempts made, also when an error is returned
+func (ce *commandExecutor) ExecuteCommandWithRetry(ctx context.Context, command Command, handler ConditionalCommandHandler, policy RetryPolicy) (CommandResult, error) {
+	if policy.MaxAttempts < 0 || policy.Backoff < 0 {
+		return CommandResult{}, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "ExecuteCommandWithRetry",
+				Err: fmt.Errorf("retry policy cannot be negative: %d attempts, %dms backoff", policy.MaxAttempts, policy.Backoff),
+			},
+			Field: "policy",
+			Value: fmt.Sprintf("%+v", policy),
+		}
+	}
+	if handler == nil {
+		return CommandResult{}, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "ExecuteCommandWithRetry",
+				Err: fmt.Errorf("handler cannot be nil"),
+			},
+			Field: "handler",
+			Value: "nil",
+		}
+	}
+
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = DefaultCommandMaxAttempts
+	}
+	var clock Clock = realClock{}
+	if es, ok := asEventStore(ce.eventStore); ok {
+		clock = es.clock()
+	}
+
+	backoff := time.Duration(policy.Backoff) * time.Millisecond
+	adapter := &conditionalHandler{handler: handler}
+	for attempt := 1; ; attempt++ {
+		result, err := ce.ExecuteCommand(ctx, command, adapter, nil)
+		result.Attempts = attempt
+		if err == nil || !IsConcurrencyError(err) || attempt == maxAttempts {
+			return result, err
+		}
+		if backoff > 0 {
+			select {
+			case <-ctx.Done():
+				return result, err
+			case <-clock.After(backoff):
+			}
+			backoff *= 2
+		}
+	}
+}
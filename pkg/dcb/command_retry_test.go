@@ -0,0 +1,26 @@
+package dcb
+
+import (
+	"context"
+	"testing"
+)
+
+func TestExecuteCommandWithRetryValidation(t *testing.T) {
+	executor := NewCommandExecutor(&stubStore{})
+	command := NewCommand("test_command", []byte(`{}`), nil)
+	handler := ConditionalCommandHandlerFunc(func(ctx context.Context, store EventStore, command Command) ([]InputEvent, *AppendCondition, any, error) {
+		return nil, nil, nil, nil
+	})
+
+	for name, policy := range map[string]RetryPolicy{
+		"negative attempts": {MaxAttempts: -1},
+		"negative backoff":  {Backoff: -1},
+	} {
+		if _, err := executor.ExecuteCommandWithRetry(context.Background(), command, handler, policy); !IsValidationError(err) {
+			t.Errorf("%s: expected validation error, got %v", name, err)
+		}
+	}
+	if _, err := executor.ExecuteCommandWithRetry(context.Background(), command, nil, RetryPolicy{}); !IsValidationError(err) {
+		t.Errorf("expected validation error for a nil handler, got %v", err)
+	}
+}
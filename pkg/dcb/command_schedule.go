@@ -0,0 +1,269 @@
+package dcb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Scheduled Commands
+// =============================================================================
+
+// DefaultScheduledCommandMaxAttempts is the number of RunDue executions a failing scheduled command
+// gets when CommandExecutorConfig.MaxScheduledAttempts is zero
+const DefaultScheduledCommandMaxAttempts = 5
+
+// ScheduleCommand stores command in the dcb_scheduled_commands table to be executed by RunDue
+// once at has passed (by the store's Clock), e.g. "expire booking in 15 minutes".
+// Returns the id of the scheduled command. On a transaction-scoped store (WithTransaction) the
+// command is only scheduled if the transaction commits. The table is created by
+// docker-entrypoint-initdb.d/schema.sql; without it ScheduleCommand and RunDue return a ConfigurationError
+func (ce *commandExecutor) ScheduleCommand(ctx context.Context, command Command, at time.Time) (int64, error) {
+	if command == nil {
+		return 0, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "ScheduleCommand",
+				Err: fmt.Errorf("command cannot be nil"),
+			},
+			Field: "command",
+			Value: "nil",
+		}
+	}
+	if at.IsZero() {
+		return 0, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "ScheduleCommand",
+				Err: fmt.Errorf("execution time must be set"),
+			},
+			Field: "at",
+			Value: "zero",
+		}
+	}
+
+	var metadata []byte
+	if command.GetMetadata() != nil {
+		var err error
+		metadata, err = json.Marshal(command.GetMetadata())
+		if err != nil {
+			return 0, &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "ScheduleCommand",
+					Err: fmt.Errorf("failed to marshal command metadata: %w", err),
+				},
+				Resource: "json",
+			}
+		}
+	}
+
+	es, err := ce.scheduleStore("ScheduleCommand")
+	if err != nil {
+		return 0, err
+	}
+	db, err := es.db()
+	if err != nil {
+		return 0, err
+	}
+	var id int64
+	err = db.QueryRow(ctx, `
+		INSERT INTO dcb_scheduled_commands (type, data, metadata, due_at, scheduled_at)
+		VALUES ($1, $2, $3, $4, COALESCE($5, CURRENT_TIMESTAMP))
+		RETURNING id
+	`, command.GetType(), command.GetData(), metadata, at, es.occurredAt()).Scan(&id)
+	if err != nil {
+		if configErr := asMissingTableError("ScheduleCommand", "dcb_scheduled_commands", err); configErr != nil {
+			return 0, configErr
+		}
+		return 0, wrapDatabaseError("ScheduleCommand", "failed to schedule command", err)
+	}
+	return id, nil
+}
+
+// RunDue executes every scheduled command whose time has arrived with handler and returns how
+// many succeeded. Each command runs in its own transaction that also marks it done, so its events
+// are appended at most once even when several workers call RunDue concurrently (rows are claimed
+// with FOR UPDATE SKIP LOCKED). A command that fails stays pending with its error and attempts
+// recorded and is retried by a later RunDue: execution is at least once, so handlers with effects
+// outside the database must be idempotent. After CommandExecutorConfig.MaxScheduledAttempts
+// failures it is moved to dcb_failed_commands (see RetryFailedCommand) and marked done with
+// failed_command_id set. Failures are logged; the returned error only reports that RunDue itself
+// could not proceed
+func (ce *commandExecutor) RunDue(ctx context.Context, handler CommandHandler) (int, error) {
+	if handler == nil {
+		return 0, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "RunDue",
+				Err: fmt.Errorf("handler cannot be nil"),
+			},
+			Field: "handler",
+			Value: "nil",
+		}
+	}
+
+	es, err := ce.scheduleStore("RunDue")
+	if err != nil {
+		return 0, err
+	}
+
+	// Commands that failed in this run are left for the next one instead of being retried in a loop
+	// (failed must not be nil: NOT (id = ANY(NULL)) matches nothing)
+	// Without a Clock, due times are compared with the database's time, like occurred_at
+	now := es.occurredAt()
+	failed := []int64{}
+	executed := 0
+	for {
+		ran, id, err := ce.runNextDue(ctx, es, handler, now, failed)
+		if err != nil {
+			return executed, err
+		}
+		switch {
+		case id == 0:
+			return executed, nil
+		case ran:
+			executed++
+		default:
+			failed = append(failed, id)
+		}
+	}
+}
+
+// runNextDue claims the next due command not in skip and executes it in one transaction
+// Returns whether it succeeded and its id (0 when no command is due)
+func (ce *commandExecutor) runNextDue(ctx context.Context, es *eventStore, handler CommandHandler, now *time.Time, skip []int64) (bool, int64, error) {
+	var (
+		ran bool
+		id  int64
+	)
+	err := es.WithTransaction(ctx, func(txStore EventStore) error {
+		scoped, _ := asEventStore(txStore)
+		db, err := scoped.db()
+		if err != nil {
+			return err
+		}
+
+		var (
+			commandType string
+			data        []byte
+			rawMetadata []byte
+		)
+		err = db.QueryRow(ctx, `
+			SELECT id, type, data, metadata FROM dcb_scheduled_commands
+			WHERE done_at IS NULL AND due_at <= COALESCE($1, CURRENT_TIMESTAMP) AND NOT (id = ANY($2))
+			ORDER BY due_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		`, now, skip).Scan(&id, &commandType, &data, &rawMetadata)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			if configErr := asMissingTableError("RunDue", "dcb_scheduled_commands", err); configErr != nil {
+				return configErr
+			}
+			return wrapDatabaseError("RunDue", "failed to claim scheduled command", err)
+		}
+
+		var metadata map[string]interface{}
+		var execErr error
+		if len(rawMetadata) > 0 {
+			if err := json.Unmarshal(rawMetadata, &metadata); err != nil {
+				execErr = fmt.Errorf("failed to decode metadata: %w", err)
+			}
+		}
+
+		// The command runs in a savepoint: a failure undoes its events but keeps the claim
+		var result CommandResult
+		if execErr == nil {
+			executor := &commandExecutor{eventStore: txStore, config: ce.config}
+			result, execErr = executor.executeCommand(ctx, NewCommand(commandType, data, metadata), handler, nil)
+		}
+		if execErr != nil {
+			log.Printf("RunDue: scheduled command %d (%s) failed: %v", id, commandType, execErr)
+			var attempts int
+			err = db.QueryRow(ctx, `
+				UPDATE dcb_scheduled_commands SET attempts = attempts + 1, last_error = $2 WHERE id = $1
+				RETURNING attempts
+			`, id, execErr.Error()).Scan(&attempts)
+			if err != nil {
+				return wrapDatabaseError("RunDue", fmt.Sprintf("failed to record failure of scheduled command %d", id), err)
+			}
+			if maxAttempts := ce.maxScheduledAttempts(); maxAttempts > 0 && attempts >= maxAttempts {
+				log.Printf("RunDue: scheduled command %d (%s) failed %d times, moving it to dcb_failed_commands", id, commandType, attempts)
+				return deadLetterScheduled(ctx, db, id, scoped.occurredAt())
+			}
+			return nil
+		}
+
+		// A command that appended nothing (AllowEmptyAppend) has no transaction to record
+		var transactionID *uint64
+		if result.TransactionID != 0 {
+			transactionID = &result.TransactionID
+		}
+		_, err = db.Exec(ctx, `
+			UPDATE dcb_scheduled_commands
+			SET attempts = attempts + 1, last_error = NULL, done_at = COALESCE($2, CURRENT_TIMESTAMP), done_transaction_id = $3
+			WHERE id = $1
+		`, id, scoped.occurredAt(), transactionID)
+		if err != nil {
+			return wrapDatabaseError("RunDue", fmt.Sprintf("failed to mark scheduled command %d done", id), err)
+		}
+		ran = true
+		return nil
+	})
+	if err != nil {
+		return false, 0, err
+	}
+	return ran, id, nil
+}
+
+// maxScheduledAttempts returns the configured MaxScheduledAttempts, 0 meaning no limit
+func (ce *commandExecutor) maxScheduledAttempts() int {
+	switch {
+	case ce.config.MaxScheduledAttempts == 0:
+		return DefaultScheduledCommandMaxAttempts
+	case ce.config.MaxScheduledAttempts < 0:
+		return 0
+	}
+	return ce.config.MaxScheduledAttempts
+}
+
+// deadLetterScheduled copies the scheduled command id to dcb_failed_commands and marks it done
+// with failed_command_id pointing at the copy, in the caller's transaction
+func deadLetterScheduled(ctx context.Context, db dbQuerier, id int64, now *time.Time) error {
+	_, err := db.Exec(ctx, `
+		WITH failed AS (
+			INSERT INTO dcb_failed_commands (type, data, metadata, error, attempts, failed_at)
+			SELECT type, data, metadata, last_error, attempts, COALESCE($2, CURRENT_TIMESTAMP)
+			FROM dcb_scheduled_commands WHERE id = $1
+			RETURNING id
+		)
+		UPDATE dcb_scheduled_commands
+		SET done_at = COALESCE($2, CURRENT_TIMESTAMP), failed_command_id = (SELECT id FROM failed)
+		WHERE id = $1
+	`, id, now)
+	if err != nil {
+		return wrapDatabaseError("RunDue", fmt.Sprintf("failed to dead-letter scheduled command %d", id), err)
+	}
+	return nil
+}
+
+// scheduleStore resolves the internal store behind the executor
+func (ce *commandExecutor) scheduleStore(op string) (*eventStore, error) {
+	es, ok := asEventStore(ce.eventStore)
+	if !ok {
+		return nil, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  op,
+				Err: fmt.Errorf("unsupported EventStore implementation %T", ce.eventStore),
+			},
+			Field: "eventStore",
+			Value: fmt.Sprintf("%T", ce.eventStore),
+		}
+	}
+	return es, nil
+}
@@ -0,0 +1,164 @@
+package dcb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// =============================================================================
+// Command Subscription
+// =============================================================================
+
+// commandsChannel is the notification channel ExecuteCommand signals when it stores a command
+const commandsChannel = "dcb_commands"
+
+// StoredCommand is a row of the commands table: an executed command and the transaction that
+// appended its events
+type StoredCommand struct {
+	TransactionID uint64    `json:"transaction_id"`
+	Type          string    `json:"type"`
+	Data          []byte    `json:"data"`
+	Metadata      []byte    `json:"metadata,omitempty"`
+	OccurredAt    time.Time `json:"occurred_at"`
+}
+
+// SubscribeCommands streams the commands stored after transaction fromTxID (all commands when it
+// is 0) and then stays open, delivering newly executed commands until ctx is cancelled. It lets an
+// external system mirror the command log, e.g. for audit: replay from the TransactionID of the
+// last command it received, then follow the executors.
+//
+// New commands are found by re-reading the table every DefaultSubscribePollInterval. The
+// subscription also LISTENs on a dedicated pool connection, so executors configured with
+// CommandExecutorConfig.NotifyCommands wake it as soon as their transaction commits. A
+// notification is only a wake-up: commands are read from the table with the visibility rule
+// Subscribe uses, so only commands of transactions older than every running one are delivered. Commands
+// arrive in transaction_id order, each exactly once per subscription, without gaps; resuming from
+// the last TransactionID received continues without gaps or repeats.
+//
+// The subscription holds one of the MaxConcurrentStreams slots and its connection until it ends.
+// Cancel ctx to end it: the channel is closed and both are released. The channel is also closed
+// if a read fails; resubscribe from the last TransactionID received to continue. It can't be used
+// with a transaction-scoped store, whose transaction would hold back the notifications, nor with
+// a store created by NewEventStoreFromSQLDB.
+func (ce *commandExecutor) SubscribeCommands(ctx context.Context, fromTxID uint64) (<-chan StoredCommand, error) {
+	es, err := ce.scheduleStore("subscribe_commands")
+	if err != nil {
+		return nil, err
+	}
+	if es.scope != nil {
+		return nil, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "subscribe_commands",
+				Err: fmt.Errorf("cannot subscribe to commands through a transaction-scoped store"),
+			},
+			Field: "eventStore",
+			Value: "transaction-scoped",
+		}
+	}
+	if es.pool == nil {
+		return nil, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "subscribe_commands",
+				Err: fmt.Errorf("cannot subscribe to commands without a pgxpool.Pool, which LISTEN needs"),
+			},
+			Field: "eventStore",
+			Value: "database/sql",
+		}
+	}
+
+	release, err := es.acquireStreamSlot("subscribe_commands")
+	if err != nil {
+		return nil, err
+	}
+	conn, err := es.pool.Acquire(ctx)
+	if err != nil {
+		release()
+		return nil, wrapDatabaseError("subscribe_commands", "failed to acquire connection", err)
+	}
+	if _, err := conn.Exec(ctx, "LISTEN "+commandsChannel); err != nil {
+		conn.Release()
+		release()
+		return nil, wrapDatabaseError("subscribe_commands", "failed to listen for commands", err)
+	}
+
+	commandChan := make(chan StoredCommand, es.config.StreamBuffer)
+	go func() {
+		defer release()
+		defer releaseListeningConn(conn)
+		defer close(commandChan)
+
+		after := fromTxID
+		for {
+			commands, err := readCommittedCommands(ctx, conn, after, subscribePageSize)
+			if err != nil {
+				return
+			}
+			for _, command := range commands {
+				select {
+				case commandChan <- command:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if len(commands) > 0 {
+				after = commands[len(commands)-1].TransactionID
+			}
+			if len(commands) == subscribePageSize {
+				continue
+			}
+
+			waitCtx, cancel := context.WithTimeout(ctx, DefaultSubscribePollInterval)
+			_, err = conn.Conn().WaitForNotification(waitCtx)
+			cancel()
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil && !errors.Is(waitCtx.Err(), context.DeadlineExceeded) {
+				return
+			}
+		}
+	}()
+
+	return commandChan, nil
+}
+
+// readCommittedCommands reads up to limit commands stored after transaction after, limited to
+// transactions older than every running one so no command can later commit before the page's end
+func readCommittedCommands(ctx context.Context, conn *pgxpool.Conn, after uint64, limit int) ([]StoredCommand, error) {
+	rows, err := conn.Query(ctx, `
+		SELECT transaction_id, type, data, metadata, occurred_at
+		FROM commands
+		WHERE transaction_id > $1::xid8 AND transaction_id < pg_snapshot_xmin(pg_current_snapshot())
+		ORDER BY transaction_id
+		LIMIT $2
+	`, after, limit)
+	if err != nil {
+		return nil, wrapDatabaseError("subscribe_commands", "failed to read commands", err)
+	}
+	commands, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (StoredCommand, error) {
+		var c StoredCommand
+		err := row.Scan(&c.TransactionID, &c.Type, &c.Data, &c.Metadata, &c.OccurredAt)
+		return c, err
+	})
+	if err != nil {
+		return nil, wrapDatabaseError("subscribe_commands", "failed to scan commands", err)
+	}
+	return commands, nil
+}
+
+// releaseListeningConn stops listening and returns conn to the pool; a connection that can't be
+// reset is closed instead, so no later user of the pool receives the notifications
+func releaseListeningConn(conn *pgxpool.Conn) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := conn.Exec(ctx, "UNLISTEN *"); err != nil {
+		conn.Hijack().Close(ctx)
+		return
+	}
+	conn.Release()
+}
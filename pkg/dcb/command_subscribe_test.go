@@ -0,0 +1,13 @@
+package dcb
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSubscribeCommandsRejectsTransactionScopedStores(t *testing.T) {
+	executor := NewCommandExecutor(&eventStore{scope: &txScope{}})
+	if _, err := executor.SubscribeCommands(context.Background(), 0); !IsValidationError(err) {
+		t.Errorf("expected validation error for a transaction-scoped store, got %v", err)
+	}
+}
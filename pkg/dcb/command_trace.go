@@ -0,0 +1,68 @@
+package dcb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// =============================================================================
+// Command Traceability
+// =============================================================================
+
+// MetadataCommandType is the metadata key ExecuteCommand stamps on every event its handler
+// produced, with the command's type. Like causation it is stored in the events.metadata column,
+// not as a tag. The key is reserved: appends of events whose own metadata sets it are rejected
+const MetadataCommandType = "command_type"
+
+// commandContextKey carries the type of the command whose events are being appended
+type commandContextKey struct{}
+
+// CommandTransactionID returns the transaction id of the command that produced the event, if it
+// was appended by ExecuteCommand. ExecuteCommand stores it in the events.command_transaction_id
+// column, which no other append writes. Events and their command are written in one transaction,
+// so it is also the event's own TransactionID and the commands table row with that
+// transaction_id; ReadByTransaction(ctx, id) returns every event the command produced
+func (e Event) CommandTransactionID() (uint64, bool) {
+	return e.CommandTxID, e.CommandTxID != 0
+}
+
+// CommandType returns the type of the command that produced the event, if it was appended by
+// ExecuteCommand
+func (e Event) CommandType() (string, bool) {
+	if e.CommandTxID == 0 || len(e.Metadata) == 0 {
+		return "", false
+	}
+
+	var metadata struct {
+		CommandType *string `json:"command_type"`
+	}
+	if err := json.Unmarshal(e.Metadata, &metadata); err != nil || metadata.CommandType == nil {
+		return "", false
+	}
+	return *metadata.CommandType, true
+}
+
+// validateCommandMetadata rejects an event whose metadata sets MetadataCommandType, which only
+// ExecuteCommand may write
+func validateCommandMetadata(e InputEvent, index int) error {
+	metadata := e.GetMetadata()
+	if !bytes.Contains(metadata, []byte(`"`+MetadataCommandType+`"`)) {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(metadata, &fields); err != nil {
+		return nil
+	}
+	if _, ok := fields[MetadataCommandType]; !ok {
+		return nil
+	}
+	return &ValidationError{
+		EventStoreError: EventStoreError{
+			Op:  "validateEvent",
+			Err: fmt.Errorf("metadata key %s of event %d is reserved for ExecuteCommand", MetadataCommandType, index),
+		},
+		Field: fmt.Sprintf("event[%d].metadata", index),
+		Value: MetadataCommandType,
+	}
+}
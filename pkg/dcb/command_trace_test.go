@@ -0,0 +1,39 @@
+package dcb
+
+import "testing"
+
+func TestEventCommandTransactionID(t *testing.T) {
+	stamped := Event{TransactionID: 42, CommandTxID: 42, Metadata: withMetadataField([]byte(`{"source":"api"}`), MetadataCommandType, "OpenAccount")}
+	if id, ok := stamped.CommandTransactionID(); !ok || id != 42 {
+		t.Errorf("expected the event's transaction id, got %d, %v", id, ok)
+	}
+	if commandType, ok := stamped.CommandType(); !ok || commandType != "OpenAccount" {
+		t.Errorf("expected the command type, got %q, %v", commandType, ok)
+	}
+
+	// Only the column links an event to a command, whatever its metadata says
+	forged := Event{TransactionID: 42, Metadata: []byte(`{"command_type":"OpenAccount"}`)}
+	if _, ok := forged.CommandTransactionID(); ok {
+		t.Error("expected no command without command_transaction_id")
+	}
+	if _, ok := forged.CommandType(); ok {
+		t.Error("expected no command type without command_transaction_id")
+	}
+}
+
+func TestValidateCommandMetadata(t *testing.T) {
+	es := &eventStore{config: EventStoreConfig{MaxAppendBatchSize: 10}}
+	event := func(metadata string) []InputEvent {
+		return []InputEvent{&inputEvent{eventType: "Deposited", tags: NewTags("account_id", "a1"), data: []byte(`{}`), metadata: []byte(metadata)}}
+	}
+
+	err := es.validateAppendEvents(event(`{"command_type":"OpenAccount"}`), "append")
+	if validationErr, ok := GetValidationError(err); !ok || validationErr.Field != "event[0].metadata" {
+		t.Errorf("expected the reserved metadata key to be rejected, got %v", err)
+	}
+	for _, metadata := range []string{"", `{"source":"api"}`, `{"note":"command_type"}`} {
+		if err := es.validateAppendEvents(event(metadata), "append"); err != nil {
+			t.Errorf("expected metadata %q to pass, got %v", metadata, err)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package dcb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Condition From Query
+// =============================================================================
+
+// ConditionFromQuery snapshots an AppendCondition that fails if an event matching query is
+// appended after this call: FailIfEventsMatch query, after the cursor of the latest matching event
+// (no cursor when none exists yet, so any matching event fails it). It is the optimistic concurrency
+// guard of Project without running projectors: take the condition, do the work, then AppendIf
+// with it. Like append conditions, query may only use event types and tags, and the archive
+// table is not consulted
+func (es *eventStore) ConditionFromQuery(ctx context.Context, query Query) (AppendCondition, error) {
+	if query == nil {
+		return nil, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "conditionFromQuery",
+				Err: fmt.Errorf("query cannot be nil"),
+			},
+			Field: "query",
+			Value: "nil",
+		}
+	}
+	condition := NewAppendCondition(query)
+	if err := validateConditionQuery(condition); err != nil {
+		return nil, err
+	}
+
+	innerSQL, args, err := es.buildReadQuerySQLFrom("events", query, nil, nil)
+	if err != nil {
+		return nil, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "conditionFromQuery",
+				Err: fmt.Errorf("failed to build SQL query: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	sqlQuery := "SELECT transaction_id, position FROM (" + strings.TrimSuffix(innerSQL, readOrderBy) + ") AS e" +
+		" ORDER BY transaction_id DESC, position DESC LIMIT 1"
+
+	err = es.executeReadInTx(ctx, func(tx pgx.Tx) error {
+		var latest Cursor
+		err := tx.QueryRow(ctx, sqlQuery, args...).Scan(&latest.TransactionID, &latest.Position)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "conditionFromQuery",
+					Err: fmt.Errorf("failed to read latest matching event: %w", err),
+				},
+				Resource: "database",
+			}
+		}
+		condition.setAfterCursor(&latest)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return condition, nil
+}
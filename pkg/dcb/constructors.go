@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -15,7 +17,7 @@ import (
 func newEventStore(pool *pgxpool.Pool, cfg EventStoreConfig) *eventStore {
 	// Set sensible defaults for required fields
 	if cfg.MaxAppendBatchSize <= 0 {
-		cfg.MaxAppendBatchSize = 1000 // Default batch size
+		cfg.MaxAppendBatchSize = DefaultMaxAppendBatchSize
 	}
 	if cfg.StreamBuffer <= 0 {
 		cfg.StreamBuffer = 1000 // Default stream buffer size
@@ -32,6 +34,9 @@ func newEventStore(pool *pgxpool.Pool, cfg EventStoreConfig) *eventStore {
 	if cfg.MaxProjectionGoroutines <= 0 {
 		cfg.MaxProjectionGoroutines = 50 // Default: 50 goroutines per projection
 	}
+	if cfg.MaxConcurrentStreams <= 0 {
+		cfg.MaxConcurrentStreams = 50 // Default: 50 open event streams
+	}
 
 	// Create semaphore with pre-filled tokens
 	semaphore := make(chan struct{}, cfg.MaxConcurrentProjections)
@@ -43,6 +48,10 @@ func newEventStore(pool *pgxpool.Pool, cfg EventStoreConfig) *eventStore {
 		pool:                pool,
 		config:              cfg,
 		projectionSemaphore: semaphore,
+		live:                newLiveSettings(cfg),
+		conditionCosts:      newConditionCostCache(),
+		querySQL:            newQuerySQLCache(),
+		tagBlocks:           newTagBlocks(),
 	}
 }
 
@@ -67,37 +76,144 @@ func NewEventStore(ctx context.Context, pool *pgxpool.Pool) (EventStore, error)
 		return nil, fmt.Errorf("failed to validate commands table: %w", err)
 	}
 
+	// Validate that the SQL functions used by appends are installed
+	if err := validateRequiredFunctionsExist(ctx, pool); err != nil {
+		return nil, fmt.Errorf("failed to validate schema functions: %w", err)
+	}
+
+	// Case-insensitive tag conditions use lower_tag_values (and its index) when installed
+	lowerTagValues, err := detectLowerTagValues(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+	// Numeric tag comparisons use numeric_tag_value (and its indexes) when installed
+	numericTagValue, err := detectNumericTagValue(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+
 	config := EventStoreConfig{
-		MaxAppendBatchSize:       1000,
+		MaxAppendBatchSize:       DefaultMaxAppendBatchSize,
 		StreamBuffer:             1000,
 		DefaultAppendIsolation:   IsolationLevelReadCommitted,
 		DefaultReadIsolation:     IsolationLevelReadCommitted, // Default to same as append for consistency
 		QueryTimeout:             10000,                       // 5 seconds default
 		AppendTimeout:            5000,                        // 5 seconds default
+		LockTimeout:              5000,                        // 5 seconds default
+		ReadRetries:              2,                           // Retry idempotent reads twice on transient errors
+		ReadRetryBackoff:         50,                          // 50ms, doubled on each retry
 		MaxConcurrentProjections: 100,                         // Default: 100 concurrent projections (supports ~200 users)
 		MaxProjectionGoroutines:  50,                          // Default: 50 goroutines per projection
+		MaxConcurrentStreams:     50,                          // Default: 50 open event streams
 	}
-	return newEventStore(pool, config), nil
+	es := newEventStore(pool, config)
+	es.lowerTagValues = lowerTagValues
+	es.numericTagValue = numericTagValue
+	return es, nil
 }
 
 // NewEventStoreWithConfig creates a new EventStore instance with custom configuration
 func NewEventStoreWithConfig(ctx context.Context, pool *pgxpool.Pool, config EventStoreConfig) (EventStore, error) {
+	if err := validateEventStoreConfig(config); err != nil {
+		return nil, err
+	}
+
 	// Test the connection
 	if err := pool.Ping(ctx); err != nil {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
+	lowerTagValues, numericTagValue, err := inspectSchema(ctx, pool, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Open the configured connections now rather than on the first requests
+	if err := warmupConnections(ctx, pool, config.WarmupConnections, warmupTimeout(config)); err != nil {
+		return nil, err
+	}
+
+	es := newEventStore(pool, config)
+	es.lowerTagValues = lowerTagValues
+	es.numericTagValue = numericTagValue
+	if config.SharedTagBlocks {
+		if err := es.loadTagBlocks(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return es, nil
+}
+
+// validateEventStoreConfig checks the settings NewEventStoreWithConfig validates before connecting
+func validateEventStoreConfig(config EventStoreConfig) error {
+	if config.ArchiveTable != "" {
+		if err := validateArchiveTableName("new_event_store", config.ArchiveTable); err != nil {
+			return err
+		}
+	}
+	for _, table := range config.ReadableTables {
+		if err := validateReadableTableName("new_event_store", table); err != nil {
+			return err
+		}
+	}
+	if _, err := parseProducer("new_event_store", config.ProducerTag); err != nil {
+		return err
+	}
+	if err := validateUniqueTags("new_event_store", config.UniqueTags); err != nil {
+		return err
+	}
+	if err := validateAggregateVersionTagKeys("new_event_store", config.AggregateVersionTagKeys); err != nil {
+		return err
+	}
+	if err := validateReservedTagKeyPrefixes("new_event_store", config.ReservedTagKeyPrefixes); err != nil {
+		return err
+	}
+	return nil
+}
+
+// inspectSchema validates the tables and functions the store uses, detects the optional
+// functions it can use, and checks the tables config needs. It returns whether lower_tag_values
+// and numeric_tag_value are installed
+func inspectSchema(ctx context.Context, db dbQuerier, config EventStoreConfig) (lowerTagValues, numericTagValue bool, err error) {
 	// Validate that the events table exists with correct structure
-	if err := validateEventsTableExists(ctx, pool); err != nil {
-		return nil, fmt.Errorf("failed to validate events table: %w", err)
+	if err := validateEventsTableExists(ctx, db); err != nil {
+		return false, false, fmt.Errorf("failed to validate events table: %w", err)
 	}
 
 	// Optionally validate commands table (if it exists)
-	if err := validateCommandsTableExists(ctx, pool); err != nil {
-		return nil, fmt.Errorf("failed to validate commands table: %w", err)
+	if err := validateCommandsTableExists(ctx, db); err != nil {
+		return false, false, fmt.Errorf("failed to validate commands table: %w", err)
+	}
+
+	// Validate that the SQL functions used by appends are installed
+	if err := validateRequiredFunctionsExist(ctx, db); err != nil {
+		return false, false, fmt.Errorf("failed to validate schema functions: %w", err)
 	}
 
-	return newEventStore(pool, config), nil
+	// Case-insensitive tag conditions use lower_tag_values (and its index) when installed
+	if lowerTagValues, err = detectLowerTagValues(ctx, db); err != nil {
+		return false, false, err
+	}
+	// Numeric tag comparisons use numeric_tag_value (and its indexes) when installed
+	if numericTagValue, err = detectNumericTagValue(ctx, db); err != nil {
+		return false, false, err
+	}
+
+	// Per-aggregate versions are counted in their own table
+	if len(config.AggregateVersionTagKeys) > 0 {
+		if err := validateFeatureTableExists(ctx, db, "dcb_aggregate_versions", "AggregateVersionTagKeys"); err != nil {
+			return false, false, err
+		}
+	}
+
+	// Shared tag blocks are kept in their own table
+	if config.SharedTagBlocks {
+		if err := validateFeatureTableExists(ctx, db, "dcb_blocked_tags", "SharedTagBlocks"); err != nil {
+			return false, false, err
+		}
+	}
+
+	return lowerTagValues, numericTagValue, nil
 }
 
 // =============================================================================
@@ -106,6 +222,8 @@ func NewEventStoreWithConfig(ctx context.Context, pool *pgxpool.Pool, config Eve
 
 // NewInputEvent creates a new InputEvent with the given type, tags, and data.
 // Validation is performed when the event is used in EventStore operations.
+// Every append path validates every event (JSON data, type, tags, size limits), whichever
+// constructor built it; no constructor bypasses this check.
 func NewInputEvent(eventType string, tags []Tag, data []byte) InputEvent {
 	return &inputEvent{
 		eventType: eventType,
@@ -114,11 +232,11 @@ func NewInputEvent(eventType string, tags []Tag, data []byte) InputEvent {
 	}
 }
 
-// NewEventBatch creates a slice of events from the given InputEvents.
+// NewEventBatch creates an EventBatch from the given InputEvents.
 // This is a convenience function for creating event batches, particularly useful
 // when appending multiple related events in a single operation.
-func NewEventBatch(events ...InputEvent) []InputEvent {
-	return events
+func NewEventBatch(events ...InputEvent) EventBatch {
+	return EventBatch(events)
 }
 
 // =============================================================================
@@ -147,6 +265,51 @@ func NewTags(kv ...string) []Tag {
 	return tags
 }
 
+// ParseTag parses the canonical "key:value" form of a tag, as used by transports and in storage
+// The key ends at the first colon, so keys can't contain colons while values can ("url:http://x").
+// A missing colon, an empty key or value, or a key with surrounding whitespace is a ValidationError
+func ParseTag(s string) (Tag, error) {
+	key, value, found := strings.Cut(s, ":")
+	var problem string
+	switch {
+	case !found:
+		problem = "missing ':' between key and value"
+	case key == "":
+		problem = "empty key"
+	case strings.TrimSpace(key) != key:
+		problem = "key has surrounding whitespace"
+	case value == "":
+		problem = "empty value"
+	}
+	if problem != "" {
+		return nil, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "parse_tag",
+				Err: fmt.Errorf("invalid tag %q: %s (expected key:value)", s, problem),
+			},
+			Field: "tag",
+			Value: s,
+		}
+	}
+	return NewTag(key, value), nil
+}
+
+// ParseTags parses each string with ParseTag, failing on the first malformed one
+func ParseTags(values []string) ([]Tag, error) {
+	tags := make([]Tag, 0, len(values))
+	for i, s := range values {
+		tag, err := ParseTag(s)
+		if err != nil {
+			if valErr, ok := GetValidationError(err); ok {
+				valErr.Field = fmt.Sprintf("tags[%d]", i)
+			}
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
 // =============================================================================
 // Query Constructors
 // =============================================================================
@@ -172,10 +335,12 @@ func NewQueryFromItems(items ...QueryItem) Query {
 }
 
 // NewQueryAll creates a query that matches all events.
+// This is the explicit way to match everything: items without event types and tags
+// built any other way are rejected by Query.Validate.
 func NewQueryAll() Query {
 	return &query{
 		Items: []QueryItem{
-			NewQueryItem([]string{}, []Tag{}),
+			&queryItem{EventTypes: []string{}, Tags: []Tag{}, MatchAll: true},
 		},
 	}
 }
@@ -238,8 +403,39 @@ type QueryBuilder struct {
 
 // queryItemBuilder builds a single QueryItem with AND conditions
 type queryItemBuilder struct {
-	eventTypes []string
-	tags       []Tag
+	eventTypes     []string
+	tags           []Tag
+	causedBy       *int64
+	anyTags        [][]Tag
+	ciTags         []Tag
+	fromPosition   *int64
+	toPosition     *int64
+	transactionID  *uint64
+	withoutTagKeys []string
+	numericTags    []NumericTagCondition
+}
+
+// isEmpty reports whether no condition has been added to the item
+func (ib *queryItemBuilder) isEmpty() bool {
+	return len(ib.eventTypes) == 0 && len(ib.tags) == 0 && ib.causedBy == nil && len(ib.anyTags) == 0 &&
+		len(ib.ciTags) == 0 && ib.fromPosition == nil && ib.toPosition == nil && ib.transactionID == nil &&
+		len(ib.withoutTagKeys) == 0 && len(ib.numericTags) == 0
+}
+
+// build creates the QueryItem
+func (ib *queryItemBuilder) build() QueryItem {
+	return &queryItem{
+		EventTypes:     ib.eventTypes,
+		Tags:           ib.tags,
+		CausedBy:       ib.causedBy,
+		AnyTags:        ib.anyTags,
+		CITags:         ib.ciTags,
+		FromPosition:   ib.fromPosition,
+		ToPosition:     ib.toPosition,
+		TransactionID:  ib.transactionID,
+		WithoutTagKeys: ib.withoutTagKeys,
+		NumericTags:    ib.numericTags,
+	}
 }
 
 // NewQueryBuilder creates a new QueryBuilder instance
@@ -254,9 +450,8 @@ func NewQueryBuilder() *QueryBuilder {
 // This creates a new QueryItem that will be combined with OR
 func (qb *QueryBuilder) AddItem() *QueryBuilder {
 	// Finalize current item if it has content
-	if len(qb.currentItem.eventTypes) > 0 || len(qb.currentItem.tags) > 0 {
-		item := NewQueryItem(qb.currentItem.eventTypes, qb.currentItem.tags)
-		qb.items = append(qb.items, item)
+	if !qb.currentItem.isEmpty() {
+		qb.items = append(qb.items, qb.currentItem.build())
 	}
 
 	// Start new item
@@ -283,6 +478,40 @@ func (qb *QueryBuilder) WithTags(kv ...string) *QueryBuilder {
 	return qb
 }
 
+// WithAnyTagValue adds a condition to the current QueryItem matching events that carry
+// key with any of the given values (OR within the key, AND with the item's other conditions)
+// Useful for events tagged with many values of one key (e.g. a PriceChanged event tagged with
+// every affected product_id). Meant for reads and projections; append conditions only support
+// event types and tags and reject it. An empty values list matches nothing and fails validation.
+func (qb *QueryBuilder) WithAnyTagValue(key string, values []string) *QueryBuilder {
+	anyTags := make([]Tag, 0, len(values))
+	for _, value := range values {
+		anyTags = append(anyTags, NewTag(key, value))
+	}
+	qb.currentItem.anyTags = append(qb.currentItem.anyTags, anyTags)
+	return qb
+}
+
+// WithoutTagKey adds a condition to the current QueryItem matching events that carry no tag
+// with the given key, whatever its value (AND with the item's other conditions)
+// Useful for data-quality audits, e.g. WithType("OrderPlaced").WithoutTagKey("region") finds
+// orders that were never classified. Meant for reads and projections; append conditions only
+// support event types and tags and reject it. The key must be non-empty and contain no ':'.
+func (qb *QueryBuilder) WithoutTagKey(key string) *QueryBuilder {
+	qb.currentItem.withoutTagKeys = append(qb.currentItem.withoutTagKeys, key)
+	return qb
+}
+
+// BetweenPositions restricts the current QueryItem to events with from <= position <= to (AND)
+// Combined with types and tags this expresses windowed and point-in-time reads in one Query.
+// Meant for reads and projections; append conditions only support event types and tags and reject it.
+// Note that positions follow commit order only approximately (see Cursor); use a cursor to resume reading
+func (qb *QueryBuilder) BetweenPositions(from, to int64) *QueryBuilder {
+	qb.currentItem.fromPosition = &from
+	qb.currentItem.toPosition = &to
+	return qb
+}
+
 // WithType adds a single event type condition to the current QueryItem (OR with existing types)
 func (qb *QueryBuilder) WithType(eventType string) *QueryBuilder {
 	qb.currentItem.eventTypes = append(qb.currentItem.eventTypes, eventType)
@@ -308,9 +537,8 @@ func (qb *QueryBuilder) WithTagAndType(key, value, eventType string) *QueryBuild
 // Build creates the final Query from the builder
 func (qb *QueryBuilder) Build() Query {
 	// Finalize current item if it has content
-	if len(qb.currentItem.eventTypes) > 0 || len(qb.currentItem.tags) > 0 {
-		item := NewQueryItem(qb.currentItem.eventTypes, qb.currentItem.tags)
-		qb.items = append(qb.items, item)
+	if !qb.currentItem.isEmpty() {
+		qb.items = append(qb.items, qb.currentItem.build())
 	}
 
 	if len(qb.items) == 0 {
@@ -342,6 +570,39 @@ func FailIfEventTypes(eventTypes []string, key, value string) AppendCondition {
 	return NewAppendCondition(query)
 }
 
+// TagMatch identifies events of Type (any type when empty) tagged Key:Value, e.g. a unique field
+type TagMatch struct {
+	Type  string
+	Key   string
+	Value string
+}
+
+// FailIfAnyExists creates an AppendCondition that fails if an event exists for any of the matches,
+// for creates that must keep several fields unique at once (e.g. a user's ID and email):
+//
+//	store.AppendIfNotExists(ctx, events, dcb.FailIfAnyExists(
+//		dcb.TagMatch{Type: "UserCreated", Key: "user_id", Value: id},
+//		dcb.TagMatch{Type: "UserCreated", Key: "email", Value: email},
+//	), dcb.OnConflictError)
+//
+// Use it with AppendIfNotExists, which checks each match on its own and serializes concurrent
+// creators that share any of them. AppendIf checks the items of a condition as one combined match,
+// so it rejects a condition of several matches with a ValidationError instead of silently
+// checking less. Without matches the condition is empty
+func FailIfAnyExists(matches ...TagMatch) AppendCondition {
+	items := make([]QueryItem, len(matches))
+	for i, match := range matches {
+		var types []string
+		if match.Type != "" {
+			types = []string{match.Type}
+		}
+		items[i] = NewQueryItem(types, NewTags(match.Key, match.Value))
+	}
+	condition := NewAppendCondition(NewQueryFromItems(items...)).(*appendCondition)
+	condition.anyItem = true
+	return condition
+}
+
 // =============================================================================
 // Simplified Tag Construction (Additive)
 // =============================================================================
@@ -386,6 +647,41 @@ func ProjectBoolean(id string, eventType string, key, value string) StateProject
 	}
 }
 
+// ProjectCountByTag creates a projector that counts events of eventType per value of their
+// groupKey tag, e.g. enrollments per course, as a map[string]int from tag value to count.
+// See ProjectCountByTagWithTypes
+func ProjectCountByTag(id string, eventType string, groupKey string) StateProjector {
+	return ProjectCountByTagWithTypes(id, []string{eventType}, groupKey)
+}
+
+// ProjectCountByTagWithTypes creates a projector that counts events of any of eventTypes per value
+// of their groupKey tag. Events without the tag are not counted, and an event carrying several
+// values of groupKey counts once for each. The state is nil until the first counted event, then a
+// map the projector updates in place, so copy it before projecting again from it
+func ProjectCountByTagWithTypes(id string, eventTypes []string, groupKey string) StateProjector {
+	return StateProjector{
+		ID:           id,
+		Query:        NewQueryBuilder().WithTypes(eventTypes...).Build(),
+		InitialState: map[string]int(nil),
+		TransitionFn: func(state any, event Event) any {
+			counts := state.(map[string]int)
+			var counted []string
+			for _, tag := range event.Tags {
+				if tag.GetKey() != groupKey || slices.Contains(counted, tag.GetValue()) {
+					continue
+				}
+				if counts == nil {
+					// A fresh map, so the projector's initial state is never shared between projections
+					counts = make(map[string]int)
+				}
+				counts[tag.GetValue()]++
+				counted = append(counted, tag.GetValue())
+			}
+			return counts
+		},
+	}
+}
+
 // ProjectState creates a projector with custom initial state and transition function
 func ProjectState(id string, eventType string, key, value string, initialState any, transitionFn func(any, Event) any) StateProjector {
 	return StateProjector{
@@ -420,6 +716,36 @@ func ProjectStateWithTags(id string, eventType string, tags Tags, initialState a
 	}
 }
 
+// ComposeProjectors creates a single projector that feeds every matching event to several named reducers
+// The projector state is a map[string]any keyed by reducer name, so one scan over query
+// computes all aspects (e.g. balance, transaction count and last activity) at once.
+// Since it is a single projector, Project treats it as one query for the append condition.
+func ComposeProjectors(id string, query Query, reducers map[string]Reducer) StateProjector {
+	initialState := make(map[string]any, len(reducers))
+	for name, reducer := range reducers {
+		initialState[name] = reducer.InitialState
+	}
+
+	return StateProjector{
+		ID:           id,
+		Query:        query,
+		InitialState: initialState,
+		TransitionFn: func(state any, event Event) any {
+			current := state.(map[string]any)
+			// Copy the state so the shared initial map is never mutated
+			next := make(map[string]any, len(current))
+			for name, reducer := range reducers {
+				if reducer.TransitionFn == nil {
+					next[name] = current[name]
+					continue
+				}
+				next[name] = reducer.TransitionFn(current[name], event)
+			}
+			return next
+		},
+	}
+}
+
 // =============================================================================
 // Event Builder Pattern (Additive - for better developer experience)
 // =============================================================================
@@ -429,6 +755,7 @@ type EventBuilder struct {
 	eventType string
 	tags      map[string]string
 	data      any
+	metadata  map[string]any
 }
 
 // NewEvent creates a new EventBuilder for fluent event construction
@@ -459,6 +786,15 @@ func (eb *EventBuilder) WithData(data any) *EventBuilder {
 	return eb
 }
 
+// WithMetadata sets a non-queryable metadata field of the event (will be JSON marshaled)
+func (eb *EventBuilder) WithMetadata(key string, value any) *EventBuilder {
+	if eb.metadata == nil {
+		eb.metadata = make(map[string]any)
+	}
+	eb.metadata[key] = value
+	return eb
+}
+
 // Build creates the final InputEvent
 func (eb *EventBuilder) Build() InputEvent {
 	tags := make([]Tag, 0, len(eb.tags))
@@ -471,7 +807,17 @@ func (eb *EventBuilder) Build() InputEvent {
 		data = ToJSON(eb.data)
 	}
 
-	return NewInputEvent(eb.eventType, tags, data)
+	var metadata []byte
+	if len(eb.metadata) > 0 {
+		metadata = ToJSON(eb.metadata)
+	}
+
+	return &inputEvent{
+		eventType: eb.eventType,
+		tags:      tags,
+		data:      data,
+		metadata:  metadata,
+	}
 }
 
 // =============================================================================
@@ -479,6 +825,8 @@ func (eb *EventBuilder) Build() InputEvent {
 // =============================================================================
 
 // BatchBuilder provides a fluent interface for building event batches
+//
+// Deprecated: use EventBatch, which has the same Add methods and is passed to Append directly.
 type BatchBuilder struct {
 	events []InputEvent
 }
@@ -509,6 +857,6 @@ func (bb *BatchBuilder) AddEventFromBuilder(builder *EventBuilder) *BatchBuilder
 }
 
 // Build creates the final event batch
-func (bb *BatchBuilder) Build() []InputEvent {
-	return bb.events
+func (bb *BatchBuilder) Build() EventBatch {
+	return EventBatch(bb.events)
 }
@@ -1,6 +1,7 @@
 package dcb
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -224,3 +225,148 @@ func TestNewTagsEdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestComposeProjectors(t *testing.T) {
+	t.Run("feeds each event to every reducer", func(t *testing.T) {
+		query := NewQuery(NewTags("account_id", "acc1"), "AccountOpened", "MoneyTransferred")
+		projector := ComposeProjectors("account", query, map[string]Reducer{
+			"count": {
+				InitialState: 0,
+				TransitionFn: func(state any, event Event) any { return state.(int) + 1 },
+			},
+			"lastType": {
+				InitialState: "",
+				TransitionFn: func(state any, event Event) any { return event.Type },
+			},
+		})
+
+		if projector.ID != "account" {
+			t.Errorf("expected ID 'account', got '%s'", projector.ID)
+		}
+		if len(projector.Query.GetItems()) != 1 {
+			t.Errorf("expected composed projector to use a single query item, got %d", len(projector.Query.GetItems()))
+		}
+
+		state := projector.InitialState
+		state = projector.TransitionFn(state, Event{Type: "AccountOpened"})
+		state = projector.TransitionFn(state, Event{Type: "MoneyTransferred"})
+
+		result := state.(map[string]any)
+		if result["count"] != 2 {
+			t.Errorf("expected count 2, got %v", result["count"])
+		}
+		if result["lastType"] != "MoneyTransferred" {
+			t.Errorf("expected lastType 'MoneyTransferred', got %v", result["lastType"])
+		}
+	})
+
+	t.Run("does not mutate initial state", func(t *testing.T) {
+		projector := ComposeProjectors("counter", NewQueryAll(), map[string]Reducer{
+			"count": {
+				InitialState: 0,
+				TransitionFn: func(state any, event Event) any { return state.(int) + 1 },
+			},
+		})
+
+		projector.TransitionFn(projector.InitialState, Event{Type: "Any"})
+
+		if projector.InitialState.(map[string]any)["count"] != 0 {
+			t.Errorf("expected initial state to remain 0, got %v", projector.InitialState.(map[string]any)["count"])
+		}
+	})
+}
+
+func TestProjectCountByTag(t *testing.T) {
+	projector := ProjectCountByTagWithTypes("enrollments", []string{"StudentEnrolled", "StudentTransferred"}, "course_id")
+	if got := projector.Query.GetItems()[0].GetEventTypes(); len(got) != 2 {
+		t.Fatalf("expected both event types in the query, got %v", got)
+	}
+
+	events := []Event{
+		{Type: "StudentEnrolled", Tags: NewTags("course_id", "c1", "student_id", "s1")},
+		{Type: "StudentEnrolled", Tags: NewTags("course_id", "c2", "student_id", "s1")},
+		{Type: "StudentTransferred", Tags: NewTags("course_id", "c1", "student_id", "s2")},
+		{Type: "StudentEnrolled", Tags: NewTags("student_id", "s3")},
+		{Type: "StudentEnrolled", Tags: []Tag{NewTag("course_id", "c3"), NewTag("course_id", "c3")}},
+	}
+	state := projector.InitialState
+	for _, event := range events {
+		state = projector.TransitionFn(state, event)
+	}
+	counts := state.(map[string]int)
+	want := map[string]int{"c1": 2, "c2": 1, "c3": 1}
+	if len(counts) != len(want) {
+		t.Fatalf("expected counts %v, got %v", want, counts)
+	}
+	for course, count := range want {
+		if counts[course] != count {
+			t.Errorf("expected %d events for %s, got %d", count, course, counts[course])
+		}
+	}
+
+	if projector.InitialState.(map[string]int) != nil {
+		t.Errorf("expected the initial state to stay nil, got %v", projector.InitialState)
+	}
+	if got := ProjectCountByTag("enrollments", "StudentEnrolled", "course_id").TransitionFn(projector.InitialState, events[0]); got.(map[string]int)["c1"] != 1 {
+		t.Errorf("expected a fresh count of 1 for c1, got %v", got)
+	}
+}
+
+func TestParseTag(t *testing.T) {
+	valid := map[string][2]string{
+		"course_id:c1":       {"course_id", "c1"},
+		"url:http://x.io:80": {"url", "http://x.io:80"},
+		"name:Ada Lovelace":  {"name", "Ada Lovelace"},
+		"k: v":               {"k", " v"},
+	}
+	for s, want := range valid {
+		tag, err := ParseTag(s)
+		if err != nil {
+			t.Errorf("ParseTag(%q): unexpected error %v", s, err)
+			continue
+		}
+		if tag.GetKey() != want[0] || tag.GetValue() != want[1] {
+			t.Errorf("ParseTag(%q) = %s:%s, want %s:%s", s, tag.GetKey(), tag.GetValue(), want[0], want[1])
+		}
+	}
+
+	for _, s := range []string{"", "course_id", "course_id:", ":c1", ":", " course_id:c1", "course_id :c1"} {
+		if _, err := ParseTag(s); !IsValidationError(err) {
+			t.Errorf("ParseTag(%q): expected ValidationError, got %v", s, err)
+		}
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	tags, err := ParseTags([]string{"course_id:c1", "student_id:s1"})
+	if err != nil || len(tags) != 2 || tags[1].GetKey() != "student_id" {
+		t.Fatalf("ParseTags = %v, %v", tags, err)
+	}
+
+	_, err = ParseTags([]string{"course_id:c1", "student_id"})
+	valErr, ok := GetValidationError(err)
+	if !ok || valErr.Field != "tags[1]" {
+		t.Fatalf("expected ValidationError on tags[1], got %v", err)
+	}
+}
+
+func FuzzParseTag(f *testing.F) {
+	for _, seed := range []string{"course_id:c1", "url:http://x:80", "", ":", "k:", ":v", " k:v", "k\x00:v"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		tag, err := ParseTag(s)
+		if err != nil {
+			if !IsValidationError(err) {
+				t.Fatalf("ParseTag(%q): expected ValidationError, got %v", s, err)
+			}
+			return
+		}
+		if tag.GetKey() == "" || tag.GetValue() == "" || strings.Contains(tag.GetKey(), ":") {
+			t.Fatalf("ParseTag(%q) produced invalid tag %q:%q", s, tag.GetKey(), tag.GetValue())
+		}
+		if tag.GetKey()+":"+tag.GetValue() != s {
+			t.Fatalf("ParseTag(%q) does not round-trip: %q:%q", s, tag.GetKey(), tag.GetValue())
+		}
+	})
+}
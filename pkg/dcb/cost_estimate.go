@@ -0,0 +1,186 @@
+package dcb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Append Condition Cost Estimate
+// =============================================================================
+
+// CostEstimate is PostgreSQL's plan estimate for the check an AppendIf runs against its condition
+type CostEstimate struct {
+	// EstimatedRows is the number of events the check is expected to visit
+	// The check counts every matching event, so this grows with the history the condition covers
+	EstimatedRows int64
+	// TotalCost is the planner's total cost in its arbitrary units, useful to compare conditions
+	TotalCost float64
+	// UsesIndex reports whether the plan reads events through an index
+	UsesIndex bool
+	// SequentialScan reports whether the plan scans the whole events table, which makes every
+	// conditional append slower as the table grows
+	SequentialScan bool
+	// Indexes names the indexes used by the plan
+	Indexes []string
+}
+
+// conditionCostTTL is how long EstimateConditionCost reuses an estimate for the same condition
+const conditionCostTTL = time.Minute
+
+// conditionCostCacheSize bounds the number of cached estimates; the cache is reset when full
+const conditionCostCacheSize = 1024
+
+// EstimateConditionCost explains (without executing) the condition check AppendIf would run and
+// returns the planner's estimate. A condition without query items costs nothing and returns a
+// zero CostEstimate without a database round trip.
+//
+// Estimates are cached per store for a minute, keyed by the condition's event types, tags and
+// whether it has an after cursor (not the cursor value, which changes on every Project), so it is
+// cheap to call before each append in a hot loop. Estimates are planner statistics, not
+// measurements: run ANALYZE after bulk loads for meaningful numbers.
+func (es *eventStore) EstimateConditionCost(ctx context.Context, condition AppendCondition) (CostEstimate, error) {
+	condition = effectiveCondition(condition)
+	if condition == nil {
+		return CostEstimate{}, nil
+	}
+	if err := validateConditionQuery(condition); err != nil {
+		return CostEstimate{}, err
+	}
+
+	eventTypes, conditionTags, afterCursorTxID, _ := extractConditionPrimitives(condition)
+	key := conditionKey(eventTypes, conditionTags, afterCursorTxID != nil)
+	if estimate, ok := es.conditionCosts.get(key, es.clock().Now()); ok {
+		return estimate, nil
+	}
+
+	// Mirrors the check in append_events_if, including its COUNT over all matching events
+	conditions, args := conditionPredicates(condition)
+	conditions = append(conditions, "e.transaction_id < pg_snapshot_xmin(pg_current_snapshot())")
+	sqlQuery := "EXPLAIN (FORMAT JSON) SELECT COUNT(*) FROM events e WHERE " + strings.Join(conditions, " AND ")
+
+	var plan []byte
+	err := es.executeReadInTx(ctx, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, sqlQuery, args...).Scan(&plan); err != nil {
+			return &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "estimate_condition_cost",
+					Err: fmt.Errorf("failed to explain condition check: %w", err),
+				},
+				Resource: "database",
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return CostEstimate{}, err
+	}
+
+	estimate, err := parseConditionPlan(plan)
+	if err != nil {
+		return CostEstimate{}, err
+	}
+	es.conditionCosts.put(key, estimate, es.clock().Now())
+	return estimate, nil
+}
+
+// explainNode is a node of PostgreSQL's EXPLAIN (FORMAT JSON) output
+type explainNode struct {
+	NodeType     string        `json:"Node Type"`
+	RelationName string        `json:"Relation Name"`
+	IndexName    string        `json:"Index Name"`
+	PlanRows     float64       `json:"Plan Rows"`
+	TotalCost    float64       `json:"Total Cost"`
+	Plans        []explainNode `json:"Plans"`
+}
+
+// parseConditionPlan summarizes the EXPLAIN (FORMAT JSON) output of the condition check
+func parseConditionPlan(plan []byte) (CostEstimate, error) {
+	var explained []struct {
+		Plan explainNode `json:"Plan"`
+	}
+	if err := json.Unmarshal(plan, &explained); err != nil || len(explained) == 0 {
+		if err == nil {
+			err = fmt.Errorf("empty plan")
+		}
+		return CostEstimate{}, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "estimate_condition_cost",
+				Err: fmt.Errorf("failed to parse plan: %w", err),
+			},
+			Resource: "json",
+		}
+	}
+
+	root := explained[0].Plan
+	estimate := CostEstimate{TotalCost: root.TotalCost}
+	var walk func(node explainNode)
+	walk = func(node explainNode) {
+		if strings.Contains(node.NodeType, "Index") {
+			estimate.UsesIndex = true
+		}
+		if node.NodeType == "Seq Scan" {
+			estimate.SequentialScan = true
+		}
+		if node.IndexName != "" {
+			estimate.Indexes = append(estimate.Indexes, node.IndexName)
+		}
+		// Rows produced by the events table scans are the rows the check visits
+		if node.RelationName == "events" {
+			estimate.EstimatedRows += int64(node.PlanRows)
+		}
+		for _, child := range node.Plans {
+			walk(child)
+		}
+	}
+	walk(root)
+	return estimate, nil
+}
+
+// conditionCostCache holds recent EstimateConditionCost results
+type conditionCostCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedCost
+}
+
+type cachedCost struct {
+	estimate CostEstimate
+	expires  time.Time
+}
+
+func newConditionCostCache() *conditionCostCache {
+	return &conditionCostCache{entries: make(map[string]cachedCost)}
+}
+
+// get returns an estimate for key unexpired at now (a nil cache never has one)
+func (c *conditionCostCache) get(key string, now time.Time) (CostEstimate, bool) {
+	if c == nil {
+		return CostEstimate{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || now.After(entry.expires) {
+		return CostEstimate{}, false
+	}
+	return entry.estimate, true
+}
+
+// put caches estimate for key as of now, resetting the cache when it is full
+func (c *conditionCostCache) put(key string, estimate CostEstimate, now time.Time) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= conditionCostCacheSize {
+		c.entries = make(map[string]cachedCost)
+	}
+	c.entries[key] = cachedCost{estimate: estimate, expires: now.Add(conditionCostTTL)}
+}
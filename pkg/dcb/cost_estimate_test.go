@@ -0,0 +1,82 @@
+package dcb
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseConditionPlan(t *testing.T) {
+	plan := []byte(`[{"Plan": {"Node Type": "Aggregate", "Total Cost": 12.5, "Plan Rows": 1, "Plans": [
+		{"Node Type": "Bitmap Heap Scan", "Relation Name": "events", "Plan Rows": 40, "Total Cost": 12.4, "Plans": [
+			{"Node Type": "Bitmap Index Scan", "Index Name": "idx_events_tags", "Plan Rows": 40, "Total Cost": 4.2}
+		]}
+	]}}]`)
+
+	estimate, err := parseConditionPlan(plan)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := CostEstimate{EstimatedRows: 40, TotalCost: 12.5, UsesIndex: true, Indexes: []string{"idx_events_tags"}}
+	if !reflect.DeepEqual(estimate, want) {
+		t.Errorf("expected %+v, got %+v", want, estimate)
+	}
+
+	seqScan := []byte(`[{"Plan": {"Node Type": "Aggregate", "Total Cost": 900, "Plans": [
+		{"Node Type": "Seq Scan", "Relation Name": "events", "Plan Rows": 25000, "Total Cost": 850}
+	]}}]`)
+	estimate, err = parseConditionPlan(seqScan)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !estimate.SequentialScan || estimate.UsesIndex || estimate.EstimatedRows != 25000 {
+		t.Errorf("expected an unindexed sequential scan of 25000 rows, got %+v", estimate)
+	}
+
+	if _, err := parseConditionPlan([]byte(`[]`)); !IsResourceError(err) {
+		t.Errorf("expected resource error for an empty plan, got %v", err)
+	}
+}
+
+func TestEstimateConditionCostWithoutQuery(t *testing.T) {
+	// An empty condition is an unconditional append: nothing to explain, no database needed
+	es := &eventStore{}
+	estimate, err := es.EstimateConditionCost(context.Background(), NewAppendCondition(NewQueryEmpty()))
+	if err != nil || !reflect.DeepEqual(estimate, CostEstimate{}) {
+		t.Fatalf("expected zero estimate, got %+v, %v", estimate, err)
+	}
+}
+
+func TestEstimateConditionCostRejectsExtendedPredicates(t *testing.T) {
+	es := &eventStore{}
+	condition := NewAppendCondition(NewQueryBuilder().WithType("A").WithCausedBy(3).Build())
+	if _, err := es.EstimateConditionCost(context.Background(), condition); !IsValidationError(err) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
+
+func TestConditionCostCache(t *testing.T) {
+	cache := newConditionCostCache()
+	now := time.Now()
+	key := conditionKey([]string{"A"}, []string{"k:v"}, true)
+	if _, ok := cache.get(key, now); ok {
+		t.Fatal("expected empty cache")
+	}
+	cache.put(key, CostEstimate{EstimatedRows: 3}, now)
+	if estimate, ok := cache.get(key, now); !ok || estimate.EstimatedRows != 3 {
+		t.Errorf("expected cached estimate, got %+v, %v", estimate, ok)
+	}
+	if _, ok := cache.get(conditionKey([]string{"A"}, []string{"k:v"}, false), now); ok {
+		t.Error("a condition without cursor must not share the estimate")
+	}
+	if _, ok := cache.get(key, now.Add(conditionCostTTL+time.Second)); ok {
+		t.Error("estimate must expire after the TTL")
+	}
+
+	var disabled *conditionCostCache
+	disabled.put(key, CostEstimate{}, now)
+	if _, ok := disabled.get(key, now); ok {
+		t.Error("nil cache must never hit")
+	}
+}
@@ -3,9 +3,10 @@ package dcb
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 // =============================================================================
@@ -14,19 +15,19 @@ import (
 
 // validateEventsTableExists validates that the events table exists with correct structure
 // This is a required table for EventStore to function
-func validateEventsTableExists(ctx context.Context, pool *pgxpool.Pool) error {
+func validateEventsTableExists(ctx context.Context, pool dbQuerier) error {
 	return validateTableExists(ctx, pool, "events", true)
 }
 
 // validateCommandsTableExists validates that the commands table exists with correct structure
 // This is an optional table for command tracking functionality
-func validateCommandsTableExists(ctx context.Context, pool *pgxpool.Pool) error {
+func validateCommandsTableExists(ctx context.Context, pool dbQuerier) error {
 	return validateTableExists(ctx, pool, "commands", false)
 }
 
 // validateTableExists validates that a table exists with correct structure
 // required: if true, missing table is an error; if false, missing table is just logged
-func validateTableExists(ctx context.Context, pool *pgxpool.Pool, tableName string, required bool) error {
+func validateTableExists(ctx context.Context, pool dbQuerier, tableName string, required bool) error {
 	// Check if table exists
 	var exists bool
 	err := pool.QueryRow(ctx, `
@@ -37,7 +38,13 @@ func validateTableExists(ctx context.Context, pool *pgxpool.Pool, tableName stri
 	`, tableName).Scan(&exists)
 
 	if err != nil {
-		return fmt.Errorf("failed to check table existence: %w", err)
+		return &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "validate_table_exists",
+				Err: fmt.Errorf("failed to check table existence: %w", err),
+			},
+			Resource: "database",
+		}
 	}
 
 	if !exists {
@@ -58,7 +65,8 @@ func validateTableExists(ctx context.Context, pool *pgxpool.Pool, tableName stri
 	// Table exists, validate its structure
 	if err := validateTableStructure(ctx, pool, tableName); err != nil {
 		// If it's already a TableStructureError, wrap it with more context
-		if tableErr, ok := err.(*TableStructureError); ok {
+		var tableErr *TableStructureError
+		if errors.As(err, &tableErr) {
 			tableErr.EventStoreError.Op = "validate_table_exists"
 			return tableErr
 		}
@@ -77,7 +85,7 @@ func validateTableExists(ctx context.Context, pool *pgxpool.Pool, tableName stri
 }
 
 // validateTableStructure checks that the table has the expected columns and types
-func validateTableStructure(ctx context.Context, pool *pgxpool.Pool, tableName string) error {
+func validateTableStructure(ctx context.Context, pool dbQuerier, tableName string) error {
 	// Query to check column structure
 	rows, err := pool.Query(ctx, `
 		SELECT column_name, data_type, is_nullable, column_default
@@ -111,12 +119,14 @@ func validateTableStructure(ctx context.Context, pool *pgxpool.Pool, tableName s
 			isNullable string
 			hasDefault bool
 		}{
-			"type":           {dataType: "character varying", isNullable: "NO", hasDefault: false},
-			"tags":           {dataType: "ARRAY", isNullable: "NO", hasDefault: false},
-			"data":           {dataType: "json", isNullable: "NO", hasDefault: false},
-			"transaction_id": {dataType: "xid8", isNullable: "NO", hasDefault: false},
-			"position":       {dataType: "bigint", isNullable: "NO", hasDefault: false},
-			"occurred_at":    {dataType: "timestamp with time zone", isNullable: "NO", hasDefault: true},
+			"type":                   {dataType: "character varying", isNullable: "NO", hasDefault: false},
+			"tags":                   {dataType: "ARRAY", isNullable: "NO", hasDefault: false},
+			"data":                   {dataType: "json", isNullable: "NO", hasDefault: false},
+			"transaction_id":         {dataType: "xid8", isNullable: "NO", hasDefault: false},
+			"position":               {dataType: "bigint", isNullable: "NO", hasDefault: false},
+			"occurred_at":            {dataType: "timestamp with time zone", isNullable: "NO", hasDefault: true},
+			"metadata":               {dataType: "jsonb", isNullable: "YES", hasDefault: false},
+			"command_transaction_id": {dataType: "xid8", isNullable: "YES", hasDefault: false},
 		}
 	case "commands":
 		expectedColumns = map[string]struct {
@@ -262,4 +272,117 @@ func validateTableStructure(ctx context.Context, pool *pgxpool.Pool, tableName s
 	return nil
 }
 
-// Remove ReadWithOptions and Read methods (now in read.go)
+// undefinedFunctionCode is the PostgreSQL error code raised when calling a function that doesn't exist
+const undefinedFunctionCode = "42883"
+
+// schemaRemedy tells users how to install the SQL functions the store calls
+const schemaRemedy = "apply the function definitions from docker-entrypoint-initdb.d/schema.sql (CREATE OR REPLACE FUNCTION, safe to re-run)"
+
+// requiredFunctions lists the SQL functions appends call, with their argument counts
+var requiredFunctions = []struct {
+	name  string
+	nargs int
+}{
+	{name: "append_events_batch", nargs: 7},
+	{name: "append_events_if", nargs: 11},
+}
+
+// validateRequiredFunctionsExist checks that the SQL functions used by appends are installed
+// with the expected signature, so an unmigrated schema fails at construction with a
+// ConfigurationError instead of deep inside the first append
+func validateRequiredFunctionsExist(ctx context.Context, pool dbQuerier) error {
+	for _, fn := range requiredFunctions {
+		var exists, otherSignature bool
+		err := pool.QueryRow(ctx, `
+			SELECT
+				EXISTS (SELECT 1 FROM pg_proc WHERE proname = $1 AND pronargs = $2 AND pg_function_is_visible(oid)),
+				EXISTS (SELECT 1 FROM pg_proc WHERE proname = $1 AND pronargs <> $2 AND pg_function_is_visible(oid))
+		`, fn.name, fn.nargs).Scan(&exists, &otherSignature)
+		if err != nil {
+			return &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "validate_required_functions",
+					Err: fmt.Errorf("failed to check function %s: %w", fn.name, err),
+				},
+				Resource: "database",
+			}
+		}
+		if exists {
+			continue
+		}
+
+		issue := "does not exist"
+		if otherSignature {
+			issue = fmt.Sprintf("exists with an outdated signature (expected %d arguments)", fn.nargs)
+		}
+		return &ConfigurationError{
+			EventStoreError: EventStoreError{
+				Op:  "validate_required_functions",
+				Err: fmt.Errorf("required SQL function %s %s; %s", fn.name, issue, schemaRemedy),
+			},
+			Component: "function " + fn.name,
+			Remedy:    schemaRemedy,
+		}
+	}
+	return nil
+}
+
+// undefinedTableCode is the PostgreSQL error code raised when reading a table that doesn't exist
+const undefinedTableCode = "42P01"
+
+// tableRemedy tells users how to create the tables optional features use
+const tableRemedy = "create it from docker-entrypoint-initdb.d/schema.sql"
+
+// validateFeatureTableExists checks that table, which the optional feature setting needs, exists.
+// The library never creates it: a missing table is a ConfigurationError pointing at schema.sql
+func validateFeatureTableExists(ctx context.Context, db dbQuerier, table, setting string) error {
+	var exists bool
+	if err := db.QueryRow(ctx, `SELECT to_regclass($1) IS NOT NULL`, table).Scan(&exists); err != nil {
+		return wrapDatabaseError("validate_table_exists", "failed to check table "+table, err)
+	}
+	if exists {
+		return nil
+	}
+	return &ConfigurationError{
+		EventStoreError: EventStoreError{
+			Op:  "validate_table_exists",
+			Err: fmt.Errorf("EventStoreConfig.%s needs table %s, which does not exist; %s", setting, table, tableRemedy),
+		},
+		Component: "table " + table,
+		Remedy:    tableRemedy,
+	}
+}
+
+// asMissingTableError converts an undefined_table error into a ConfigurationError naming table,
+// the table of an optional feature that schema.sql creates. Returns nil for other errors
+func asMissingTableError(op, table string, err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != undefinedTableCode {
+		return nil
+	}
+	return &ConfigurationError{
+		EventStoreError: EventStoreError{
+			Op:  op,
+			Err: fmt.Errorf("table %s does not exist; %s: %w", table, tableRemedy, err),
+		},
+		Component: "table " + table,
+		Remedy:    tableRemedy,
+	}
+}
+
+// asMissingFunctionError converts an undefined_function error into a ConfigurationError,
+// covering functions dropped after the store was constructed. Returns nil for other errors
+func asMissingFunctionError(op string, err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != undefinedFunctionCode {
+		return nil
+	}
+	return &ConfigurationError{
+		EventStoreError: EventStoreError{
+			Op:  op,
+			Err: fmt.Errorf("required SQL function is missing or outdated (%s); %s: %w", pgErr.Message, schemaRemedy, err),
+		},
+		Component: "function",
+		Remedy:    schemaRemedy,
+	}
+}
@@ -23,8 +23,11 @@ type (
 	// ConcurrencyError represents a concurrency conflict
 	ConcurrencyError struct {
 		EventStoreError
-		ExpectedPosition int64
-		ActualPosition   int64
+		ExpectedPosition  int64
+		ActualPosition    int64
+		ExpectedVersion   int      // Expected aggregate version (AppendToAggregate only)
+		ActualVersion     int      // Actual aggregate version, -1 if unknown (AppendToAggregate only)
+		ConflictingValues []string // Tag values that already exist (AppendIfNoneExist only)
 	}
 
 	// ResourceError represents an error related to resource management
@@ -43,6 +46,21 @@ type (
 		Issue        string // Description of the specific issue
 	}
 
+	// ConfigurationError represents a database setup problem detected by the store,
+	// such as a required SQL function that was never installed
+	ConfigurationError struct {
+		EventStoreError
+		Component string // The missing or misconfigured component (e.g. "function append_events_if")
+		Remedy    string // How to fix it
+	}
+
+	// PartialResultError is returned with the states folded so far when a projection is cancelled
+	// and EventStoreConfig.ReturnPartialOnCancel is set; it matches ErrPartial and context.Canceled
+	PartialResultError struct {
+		EventStoreError
+		Cursor *Cursor // Last event folded into the states (the starting cursor, or nil, if none was read)
+	}
+
 	// TooManyProjectionsError represents an error when too many projections are running concurrently
 	TooManyProjectionsError struct {
 		EventStoreError
@@ -51,6 +69,22 @@ type (
 	}
 )
 
+// =============================================================================
+// Sentinel Errors
+// =============================================================================
+
+// Sentinel errors matching each error category with errors.Is, even through wrapping
+// e.g. errors.Is(fmt.Errorf("transfer: %w", err), dcb.ErrConcurrency)
+var (
+	ErrValidation         = errors.New("dcb: validation error")
+	ErrConcurrency        = errors.New("dcb: concurrency error")
+	ErrResource           = errors.New("dcb: resource error")
+	ErrTableStructure     = errors.New("dcb: table structure error")
+	ErrConfiguration      = errors.New("dcb: configuration error")
+	ErrTooManyProjections = errors.New("dcb: too many projections")
+	ErrPartial            = errors.New("dcb: partial result")
+)
+
 // Error implements the error interface
 func (e EventStoreError) Error() string {
 	if e.Err != nil {
@@ -64,6 +98,41 @@ func (e EventStoreError) Unwrap() error {
 	return e.Err
 }
 
+// Is reports whether target is ErrValidation
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// Is reports whether target is ErrConcurrency
+func (e *ConcurrencyError) Is(target error) bool {
+	return target == ErrConcurrency
+}
+
+// Is reports whether target is ErrResource
+func (e *ResourceError) Is(target error) bool {
+	return target == ErrResource
+}
+
+// Is reports whether target is ErrTableStructure
+func (e *TableStructureError) Is(target error) bool {
+	return target == ErrTableStructure
+}
+
+// Is reports whether target is ErrConfiguration
+func (e *ConfigurationError) Is(target error) bool {
+	return target == ErrConfiguration
+}
+
+// Is reports whether target is ErrTooManyProjections
+func (e *TooManyProjectionsError) Is(target error) bool {
+	return target == ErrTooManyProjections
+}
+
+// Is reports whether target is ErrPartial
+func (e *PartialResultError) Is(target error) bool {
+	return target == ErrPartial
+}
+
 // =============================================================================
 // Error Detection Helpers
 // =============================================================================
@@ -92,12 +161,24 @@ func IsTableStructureError(err error) bool {
 	return errors.As(err, &tableStructureErr)
 }
 
+// IsConfigurationError checks if the error is a ConfigurationError
+func IsConfigurationError(err error) bool {
+	var configurationErr *ConfigurationError
+	return errors.As(err, &configurationErr)
+}
+
 // IsTooManyProjectionsError checks if the error is a TooManyProjectionsError
 func IsTooManyProjectionsError(err error) bool {
 	var tooManyProjectionsErr *TooManyProjectionsError
 	return errors.As(err, &tooManyProjectionsErr)
 }
 
+// IsPartialResultError checks if the error is a PartialResultError
+func IsPartialResultError(err error) bool {
+	var partialErr *PartialResultError
+	return errors.As(err, &partialErr)
+}
+
 // =============================================================================
 // Error Extraction Helpers
 // =============================================================================
@@ -138,6 +219,15 @@ func GetTableStructureError(err error) (*TableStructureError, bool) {
 	return nil, false
 }
 
+// GetConfigurationError extracts a ConfigurationError from the error chain
+func GetConfigurationError(err error) (*ConfigurationError, bool) {
+	var configurationErr *ConfigurationError
+	if errors.As(err, &configurationErr) {
+		return configurationErr, true
+	}
+	return nil, false
+}
+
 // GetTooManyProjectionsError extracts a TooManyProjectionsError from the error chain
 func GetTooManyProjectionsError(err error) (*TooManyProjectionsError, bool) {
 	var tooManyProjectionsErr *TooManyProjectionsError
@@ -147,6 +237,15 @@ func GetTooManyProjectionsError(err error) (*TooManyProjectionsError, bool) {
 	return nil, false
 }
 
+// GetPartialResultError extracts a PartialResultError from the error chain
+func GetPartialResultError(err error) (*PartialResultError, bool) {
+	var partialErr *PartialResultError
+	if errors.As(err, &partialErr) {
+		return partialErr, true
+	}
+	return nil, false
+}
+
 // =============================================================================
 // Error Type Assertion Helpers (Aliases for Get* functions)
 // =============================================================================
@@ -170,3 +269,19 @@ func AsResourceError(err error) (*ResourceError, bool) {
 func AsTableStructureError(err error) (*TableStructureError, bool) {
 	return GetTableStructureError(err)
 }
+
+// AsConfigurationError is an alias for GetConfigurationError
+func AsConfigurationError(err error) (*ConfigurationError, bool) {
+	return GetConfigurationError(err)
+}
+
+// wrapDatabaseError reports a failed database call of op as a ResourceError (Resource "database")
+func wrapDatabaseError(op, message string, err error) error {
+	return &ResourceError{
+		EventStoreError: EventStoreError{
+			Op:  op,
+			Err: fmt.Errorf("%s: %w", message, err),
+		},
+		Resource: "database",
+	}
+}
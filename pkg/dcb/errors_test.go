@@ -2,7 +2,10 @@ package dcb
 
 import (
 	"errors"
+	"fmt"
 	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 func TestIsConcurrencyError(t *testing.T) {
@@ -223,3 +226,109 @@ func TestGetTooManyProjectionsError(t *testing.T) {
 		}
 	})
 }
+
+func TestAsMissingFunctionError(t *testing.T) {
+	t.Run("converts undefined_function into ConfigurationError", func(t *testing.T) {
+		cause := &pgconn.PgError{Code: "42883", Message: "function append_events_if(text[]) does not exist"}
+		err := asMissingFunctionError("appendInTx", fmt.Errorf("exec: %w", cause))
+
+		configErr, ok := AsConfigurationError(err)
+		if !ok {
+			t.Fatalf("expected ConfigurationError, got %v", err)
+		}
+		if configErr.Remedy == "" {
+			t.Error("ConfigurationError should explain how to install the function")
+		}
+		if !errors.Is(err, cause) {
+			t.Error("the PostgreSQL error should stay reachable")
+		}
+	})
+
+	t.Run("ignores other errors", func(t *testing.T) {
+		if err := asMissingFunctionError("appendInTx", &pgconn.PgError{Code: "23505"}); err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+		if err := asMissingFunctionError("appendInTx", errors.New("connection reset")); err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+	})
+}
+
+func TestAsMissingTableError(t *testing.T) {
+	cause := &pgconn.PgError{Code: "42P01", Message: `relation "dcb_failed_commands" does not exist`}
+	err := asMissingTableError("RetryFailedCommand", "dcb_failed_commands", fmt.Errorf("query: %w", cause))
+
+	configErr, ok := AsConfigurationError(err)
+	if !ok {
+		t.Fatalf("expected ConfigurationError, got %v", err)
+	}
+	if configErr.Component != "table dcb_failed_commands" || configErr.Remedy == "" {
+		t.Errorf("expected the table and a remedy, got %q and %q", configErr.Component, configErr.Remedy)
+	}
+	if !errors.Is(err, cause) {
+		t.Error("the PostgreSQL error should stay reachable")
+	}
+	if err := asMissingTableError("RetryFailedCommand", "dcb_failed_commands", &pgconn.PgError{Code: "23505"}); err != nil {
+		t.Errorf("expected nil for other errors, got %v", err)
+	}
+}
+
+func TestSentinelErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		sentinel error
+	}{
+		{"ValidationError", &ValidationError{EventStoreError: EventStoreError{Op: "test", Err: errors.New("invalid")}}, ErrValidation},
+		{"ConcurrencyError", &ConcurrencyError{EventStoreError: EventStoreError{Op: "test", Err: errors.New("conflict")}}, ErrConcurrency},
+		{"ResourceError", &ResourceError{EventStoreError: EventStoreError{Op: "test", Err: errors.New("db down")}}, ErrResource},
+		{"TableStructureError", &TableStructureError{EventStoreError: EventStoreError{Op: "test", Err: errors.New("missing column")}}, ErrTableStructure},
+		{"TooManyProjectionsError", &TooManyProjectionsError{EventStoreError: EventStoreError{Op: "test", Err: errors.New("busy")}}, ErrTooManyProjections},
+		{"ConfigurationError", &ConfigurationError{EventStoreError: EventStoreError{Op: "test", Err: errors.New("missing function")}}, ErrConfiguration},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name+" matches its sentinel through multiple wraps", func(t *testing.T) {
+			wrapped := fmt.Errorf("outer: %w", fmt.Errorf("middle: %w", tc.err))
+
+			if !errors.Is(wrapped, tc.sentinel) {
+				t.Errorf("errors.Is should match %v", tc.sentinel)
+			}
+			for _, other := range []error{ErrValidation, ErrConcurrency, ErrResource, ErrTableStructure, ErrTooManyProjections, ErrConfiguration} {
+				if other != tc.sentinel && errors.Is(wrapped, other) {
+					t.Errorf("errors.Is should not match %v", other)
+				}
+			}
+		})
+	}
+
+	t.Run("errors.As extracts concrete type through multiple wraps", func(t *testing.T) {
+		original := &ConcurrencyError{
+			EventStoreError: EventStoreError{Op: "appendIf", Err: errors.New("append condition violated")},
+		}
+		wrapped := fmt.Errorf("handler: %w", fmt.Errorf("command: %w", original))
+
+		var concurrencyErr *ConcurrencyError
+		if !errors.As(wrapped, &concurrencyErr) {
+			t.Fatal("errors.As should extract ConcurrencyError")
+		}
+		if concurrencyErr != original {
+			t.Error("errors.As should return the original error")
+		}
+	})
+
+	t.Run("underlying cause is reachable via Unwrap", func(t *testing.T) {
+		cause := errors.New("connection reset")
+		err := fmt.Errorf("wrapped: %w", &ResourceError{
+			EventStoreError: EventStoreError{Op: "query", Err: fmt.Errorf("failed to execute query: %w", cause)},
+			Resource:        "database",
+		})
+
+		if !errors.Is(err, cause) {
+			t.Error("errors.Is should reach the underlying cause")
+		}
+		if !errors.Is(err, ErrResource) {
+			t.Error("errors.Is should match ErrResource")
+		}
+	})
+}
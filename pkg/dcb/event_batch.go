@@ -0,0 +1,88 @@
+package dcb
+
+import "fmt"
+
+// =============================================================================
+// Event Batch
+// =============================================================================
+
+// DefaultMaxAppendBatchSize is the MaxAppendBatchSize used when the configuration leaves it unset
+const DefaultMaxAppendBatchSize = 1000
+
+// EventBatch is an ordered list of events appended together
+// Its underlying type is []InputEvent, so a batch is passed directly to Append, AppendIf and
+// the other append methods. The zero value is an empty batch ready to use:
+//
+//	var batch dcb.EventBatch
+//	batch.AddFromStruct("UserCreated", user, "user_id", "email")
+//	if err := batch.Validate(); err != nil { ... }
+//	err := store.Append(ctx, batch)
+type EventBatch []InputEvent
+
+// Add appends events to the batch
+func (b *EventBatch) Add(events ...InputEvent) *EventBatch {
+	*b = append(*b, events...)
+	return b
+}
+
+// AddFromStruct adds an event of eventType whose data is v marshaled to JSON and whose tags are
+// taken from the named fields of v (see TagsFromStruct)
+func (b *EventBatch) AddFromStruct(eventType string, v any, tagFields ...string) *EventBatch {
+	return b.Add(NewEvent(eventType).WithTagsFromStruct(v, tagFields...).WithData(v).Build())
+}
+
+// Len returns the number of events in the batch
+func (b EventBatch) Len() int {
+	return len(b)
+}
+
+// TotalBytes returns the total size of the events' data and metadata
+func (b EventBatch) TotalBytes() int {
+	total := 0
+	for _, event := range b {
+		total += len(event.GetData()) + len(event.GetMetadata())
+	}
+	return total
+}
+
+// Validate checks the batch without a store: it must be non-empty, within
+// DefaultMaxAppendBatchSize, and every event needs a type, tags and valid JSON data.
+// Appends check the same rules against the store's own EventStoreConfig limits
+func (b EventBatch) Validate() error {
+	if len(b) == 0 {
+		return &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "validate_batch",
+				Err: fmt.Errorf("batch cannot be empty"),
+			},
+			Field: "events",
+			Value: "empty",
+		}
+	}
+	if len(b) > DefaultMaxAppendBatchSize {
+		return &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "validate_batch",
+				Err: fmt.Errorf("batch size %d exceeds maximum %d", len(b), DefaultMaxAppendBatchSize),
+			},
+			Field: "batchSize",
+			Value: fmt.Sprintf("%d", len(b)),
+		}
+	}
+	for i, event := range b {
+		if event == nil {
+			return &ValidationError{
+				EventStoreError: EventStoreError{
+					Op:  "validate_batch",
+					Err: fmt.Errorf("event %d is nil", i),
+				},
+				Field: fmt.Sprintf("event[%d]", i),
+				Value: "nil",
+			}
+		}
+		if err := validateEvent(event, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
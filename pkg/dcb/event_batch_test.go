@@ -0,0 +1,89 @@
+package dcb
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEventBatchAdd(t *testing.T) {
+	type accountOpened struct {
+		AccountID string `json:"account_id"`
+		Owner     string `json:"owner"`
+	}
+
+	var batch EventBatch
+	batch.Add(NewInputEvent("AccountCreated", NewTags("account_id", "a1"), []byte(`{"id":"a1"}`))).
+		AddFromStruct("AccountOpened", accountOpened{AccountID: "a2", Owner: "ann"}, "account_id")
+
+	if batch.Len() != 2 {
+		t.Fatalf("expected 2 events, got %d", batch.Len())
+	}
+	opened := batch[1]
+	if opened.GetType() != "AccountOpened" {
+		t.Errorf("expected type AccountOpened, got %q", opened.GetType())
+	}
+	tags := opened.GetTags()
+	if len(tags) != 1 || tags[0].GetKey() != "account_id" || tags[0].GetValue() != "a2" {
+		t.Errorf("expected tag account_id:a2, got %v", TagsToArray(tags))
+	}
+	if string(opened.GetData()) != `{"account_id":"a2","owner":"ann"}` {
+		t.Errorf("unexpected data %s", opened.GetData())
+	}
+	if err := batch.Validate(); err != nil {
+		t.Errorf("expected valid batch, got %v", err)
+	}
+}
+
+func TestEventBatchTotalBytes(t *testing.T) {
+	caused := NewEvent("B").WithTag("k", "v").WithData(map[string]int{}).CausedBy(Event{Position: 7, TransactionID: 3}).Build()
+	batch := NewEventBatch(
+		NewInputEvent("A", NewTags("k", "v"), []byte(`{"a":1}`)),
+		caused,
+	)
+	want := len(`{"a":1}`) + len(caused.GetData()) + len(caused.GetMetadata())
+	if len(caused.GetMetadata()) == 0 {
+		t.Fatal("expected causation metadata")
+	}
+	if got := batch.TotalBytes(); got != want {
+		t.Errorf("expected %d bytes, got %d", want, got)
+	}
+}
+
+func TestEventBatchValidate(t *testing.T) {
+	valid := NewInputEvent("A", NewTags("k", "v"), []byte(`{}`))
+
+	tests := []struct {
+		name  string
+		batch EventBatch
+		field string
+	}{
+		{name: "empty batch", batch: nil, field: "events"},
+		{name: "nil event", batch: EventBatch{valid, nil}, field: "event[1]"},
+		{name: "invalid JSON", batch: EventBatch{NewInputEvent("A", NewTags("k", "v"), []byte(`{`))}, field: "data"},
+		{name: "missing tags", batch: EventBatch{NewInputEvent("A", nil, []byte(`{}`))}, field: "tags"},
+		{name: "too many events", batch: make(EventBatch, DefaultMaxAppendBatchSize+1), field: "batchSize"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.batch.Validate()
+			if !IsValidationError(err) {
+				t.Fatalf("expected ValidationError, got %v", err)
+			}
+			if validationErr, _ := GetValidationError(err); validationErr.Field != tt.field {
+				t.Errorf("expected field %q, got %q", tt.field, validationErr.Field)
+			}
+		})
+	}
+
+	err := make(EventBatch, DefaultMaxAppendBatchSize+1).Validate()
+	if !strings.Contains(err.Error(), "exceeds maximum") {
+		t.Errorf("expected batch size error, got %v", err)
+	}
+}
+
+func TestBatchBuilderBuildReturnsEventBatch(t *testing.T) {
+	batch := NewBatch().AddEventFromBuilder(NewEvent("A").WithTag("k", "v").WithData(map[string]int{"a": 1})).Build()
+	if batch.Len() != 1 || batch.Validate() != nil {
+		t.Errorf("expected one valid event, got %d (%v)", batch.Len(), batch.Validate())
+	}
+}
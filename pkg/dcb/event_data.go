@@ -0,0 +1,140 @@
+package dcb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// Event Data Accessors
+// =============================================================================
+
+// Decoding event data into map[string]any with json.Unmarshal turns every number into a
+// float64, so transition functions end up writing int(data["quantity"].(float64)): that
+// panics when the field is missing or has another type, and silently loses precision for
+// integers above 2^53. DecodeData and the Data* accessors decode numbers as json.Number
+// and convert them explicitly, returning an error instead of panicking.
+
+// DecodeData decodes the event data into target (a pointer)
+// Typed fields (int64, float64, structs, ...) are decoded directly; numbers decoded into
+// interface values (any, map[string]any) become json.Number instead of float64
+func DecodeData(event Event, target any) error {
+	decoder := json.NewDecoder(bytes.NewReader(event.Data))
+	decoder.UseNumber()
+	if err := decoder.Decode(target); err != nil {
+		return &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "decode_data",
+				Err: fmt.Errorf("failed to decode data of %s event at position %d: %w", event.Type, event.Position, err),
+			},
+			Field: "data",
+			Value: event.Type,
+		}
+	}
+	return nil
+}
+
+// DataInt returns the integer at path in the event data
+// path is dot-separated like Aggregation.DataPath ("quantity", "payment.amount", "items.0.count");
+// a missing value, a non-number or a number that isn't an exact int64 is an error
+func (e Event) DataInt(path string) (int64, error) {
+	number, err := e.dataNumber(path)
+	if err != nil {
+		return 0, err
+	}
+	value, err := number.Int64()
+	if err != nil {
+		return 0, dataPathError(path, fmt.Errorf("value %s is not an int64", number))
+	}
+	return value, nil
+}
+
+// DataFloat returns the number at path in the event data as float64 (see DataInt for path syntax)
+func (e Event) DataFloat(path string) (float64, error) {
+	number, err := e.dataNumber(path)
+	if err != nil {
+		return 0, err
+	}
+	value, err := number.Float64()
+	if err != nil {
+		return 0, dataPathError(path, fmt.Errorf("value %s is not a float64", number))
+	}
+	return value, nil
+}
+
+// DataString returns the string at path in the event data (see DataInt for path syntax)
+// Numbers and booleans are not converted; use DataInt or DataFloat for them
+func (e Event) DataString(path string) (string, error) {
+	value, err := e.dataValue(path)
+	if err != nil {
+		return "", err
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", dataPathError(path, fmt.Errorf("value is %T, not a string", value))
+	}
+	return s, nil
+}
+
+// dataNumber returns the JSON number at path
+func (e Event) dataNumber(path string) (json.Number, error) {
+	value, err := e.dataValue(path)
+	if err != nil {
+		return "", err
+	}
+	number, ok := value.(json.Number)
+	if !ok {
+		return "", dataPathError(path, fmt.Errorf("value is %T, not a number", value))
+	}
+	return number, nil
+}
+
+// dataValue decodes the event data and walks the dot-separated path through objects and arrays
+func (e Event) dataValue(path string) (any, error) {
+	if path == "" || strings.Contains("."+path+".", "..") {
+		return nil, dataPathError(path, fmt.Errorf("path must be a dot-separated path of non-empty field names"))
+	}
+
+	var value any
+	if err := DecodeData(e, &value); err != nil {
+		return nil, err
+	}
+
+	for _, segment := range strings.Split(path, ".") {
+		switch node := value.(type) {
+		case map[string]any:
+			next, ok := node[segment]
+			if !ok {
+				return nil, dataPathError(path, fmt.Errorf("field %q not found", segment))
+			}
+			value = next
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, dataPathError(path, fmt.Errorf("index %q out of range for array of length %d", segment, len(node)))
+			}
+			value = node[index]
+		default:
+			return nil, dataPathError(path, fmt.Errorf("cannot look up %q in %T", segment, value))
+		}
+	}
+	if value == nil {
+		return nil, dataPathError(path, fmt.Errorf("value is null"))
+	}
+	return value, nil
+}
+
+// dataPathError reports a failed lookup of path in event data
+func dataPathError(path string, err error) error {
+	return &ValidationError{
+		EventStoreError: EventStoreError{
+			Op:  "event_data",
+			Err: fmt.Errorf("data path %q: %w", path, err),
+		},
+		Field: "dataPath",
+		Value: path,
+	}
+}
@@ -0,0 +1,81 @@
+package dcb
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEventDataAccessors(t *testing.T) {
+	event := Event{
+		Type: "TicketsBooked",
+		Data: []byte(`{"quantity":3,"price":12.5,"customer":"c1","big":9007199254740993,` +
+			`"payment":{"amount":40,"currency":"EUR"},"items":[{"count":2}],"note":null}`),
+	}
+
+	if v, err := event.DataInt("quantity"); err != nil || v != 3 {
+		t.Errorf("DataInt(quantity) = %d, %v", v, err)
+	}
+	if v, err := event.DataInt("big"); err != nil || v != 9007199254740993 {
+		t.Errorf("DataInt(big) = %d, %v; expected no precision loss", v, err)
+	}
+	if v, err := event.DataInt("payment.amount"); err != nil || v != 40 {
+		t.Errorf("DataInt(payment.amount) = %d, %v", v, err)
+	}
+	if v, err := event.DataInt("items.0.count"); err != nil || v != 2 {
+		t.Errorf("DataInt(items.0.count) = %d, %v", v, err)
+	}
+	if v, err := event.DataFloat("price"); err != nil || v != 12.5 {
+		t.Errorf("DataFloat(price) = %v, %v", v, err)
+	}
+	if v, err := event.DataFloat("quantity"); err != nil || v != 3 {
+		t.Errorf("DataFloat(quantity) = %v, %v", v, err)
+	}
+	if v, err := event.DataString("payment.currency"); err != nil || v != "EUR" {
+		t.Errorf("DataString(payment.currency) = %q, %v", v, err)
+	}
+
+	for name, lookup := range map[string]func() error{
+		"int from fraction":  func() error { _, err := event.DataInt("price"); return err },
+		"int from string":    func() error { _, err := event.DataInt("customer"); return err },
+		"string from number": func() error { _, err := event.DataString("quantity"); return err },
+		"missing field":      func() error { _, err := event.DataInt("missing"); return err },
+		"null value":         func() error { _, err := event.DataString("note"); return err },
+		"index out of range": func() error { _, err := event.DataInt("items.1.count"); return err },
+		"lookup in scalar":   func() error { _, err := event.DataInt("quantity.value"); return err },
+		"empty path segment": func() error { _, err := event.DataInt("payment..amount"); return err },
+		"invalid event data": func() error { _, err := Event{Data: []byte(`{`)}.DataInt("a"); return err },
+		"empty path":         func() error { _, err := event.DataFloat(""); return err },
+		"string from object": func() error { _, err := event.DataString("payment"); return err },
+		"float from object":  func() error { _, err := event.DataFloat("payment"); return err },
+	} {
+		if err := lookup(); !IsValidationError(err) {
+			t.Errorf("%s: expected ValidationError, got %v", name, err)
+		}
+	}
+}
+
+func TestDecodeData(t *testing.T) {
+	event := Event{Type: "TicketsBooked", Data: []byte(`{"quantity":3,"extra":{"big":9007199254740993}}`)}
+
+	var typed struct {
+		Quantity int `json:"quantity"`
+	}
+	if err := DecodeData(event, &typed); err != nil || typed.Quantity != 3 {
+		t.Fatalf("typed decode = %+v, %v", typed, err)
+	}
+
+	var untyped map[string]any
+	if err := DecodeData(event, &untyped); err != nil {
+		t.Fatalf("untyped decode: %v", err)
+	}
+	if _, ok := untyped["quantity"].(json.Number); !ok {
+		t.Errorf("expected json.Number, got %T", untyped["quantity"])
+	}
+	if big := untyped["extra"].(map[string]any)["big"].(json.Number); big.String() != "9007199254740993" {
+		t.Errorf("expected exact big number, got %s", big)
+	}
+
+	if err := DecodeData(Event{Data: []byte(`not json`)}, &untyped); !IsValidationError(err) {
+		t.Errorf("expected ValidationError for invalid data, got %v", err)
+	}
+}
@@ -2,7 +2,10 @@ package dcb
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -27,29 +30,203 @@ type EventStore interface {
 	// for efficient memory usage and Go-idiomatic streaming
 	QueryStream(ctx context.Context, query Query, after *Cursor) (<-chan Event, error)
 
+	// QueryGrouped streams events matching query grouped by the value of groupTagKey
+	// Each EventGroup holds one tag value and its events in (transaction_id, position) order
+	// This supports partitioned consumers without buffering the whole result
+	QueryGrouped(ctx context.Context, query Query, groupTagKey string) (<-chan EventGroup, error)
+
+	// Subscribe streams events matching query after the cursor, then stays open delivering newly
+	// committed matching events (checked every pollInterval) until ctx is cancelled
+	Subscribe(ctx context.Context, query Query, after *Cursor, pollInterval time.Duration) (<-chan Event, error)
+
+	// Replay calls handler for every committed event matching query from position from on, in
+	// batches, checkpointing progress under opts.Name so a restarted replay resumes where it stopped
+	Replay(ctx context.Context, query Query, from int64, handler func(Event) error, opts ReplayOptions) (ReplayResult, error)
+
+	// MaterializeView applies the events matching view.Query after the view's checkpoint to its
+	// rows in dcb_view_rows, moving the checkpoint in the same transaction, until it has caught up
+	MaterializeView(ctx context.Context, view ViewDefinition) (ViewResult, error)
+
+	// QueryView returns the state of key in a view maintained by MaterializeView; ok is false
+	// when the view has no row for key
+	QueryView(ctx context.Context, viewName, key string) (state json.RawMessage, ok bool, err error)
+
+	// ExistsAny reports which of the given tag values already have an event of eventType
+	// tagged with tagKey:value, answered in a single query (empty eventType matches any type)
+	// The returned map contains every requested value, set to true when such an event exists
+	ExistsAny(ctx context.Context, eventType string, tagKey string, values []string) (map[string]bool, error)
+
+	// Aggregate computes Sum/Min/Max/Avg/Count over a numeric data field of the events matching
+	// query in PostgreSQL, without loading the events (see Aggregation for paths and null handling)
+	Aggregate(ctx context.Context, query Query, agg Aggregation) (float64, error)
+
+	// ReadByPositions returns the events at the given positions in one query, in ascending
+	// position order; positions without an event are absent from the result
+	ReadByPositions(ctx context.Context, positions []int64) ([]Event, error)
+
+	// ReadMulti reads the events matching query from several allowed event tables in one query,
+	// ordered by occurred_at (or table by table); there is no global position order across tables
+	ReadMulti(ctx context.Context, tables []string, query Query, opts *ReadOptions) ([]Event, error)
+
+	// ReadByTransaction returns the events appended in the given transaction, in position order
+	ReadByTransaction(ctx context.Context, txID uint64) ([]Event, error)
+
+	// AppendIfNotExists appends events unless events matching condition exist; concurrent creators
+	// are serialized, and OnConflictIgnore turns a conflict into an idempotent success
+	AppendIfNotExists(ctx context.Context, events []InputEvent, condition AppendCondition, onConflict OnConflict) (CreateResult, error)
+
+	// AppendIfNoneExist atomically appends events unless an event already has one of their values
+	// for tagKey; the ConcurrencyError lists the existing values (FailIfExists for a batch)
+	AppendIfNoneExist(ctx context.Context, events []InputEvent, tagKey string) error
+
+	// EstimateConditionCost returns the planner's estimate for the condition check of an AppendIf
+	// (EXPLAIN, not executed), e.g. to catch conditions that scan the whole events table
+	EstimateConditionCost(ctx context.Context, condition AppendCondition) (CostEstimate, error)
+
+	// ConditionFromQuery returns an AppendCondition that fails if an event matching query is appended
+	// after this call, for optimistic concurrency without running projectors
+	ConditionFromQuery(ctx context.Context, query Query) (AppendCondition, error)
+
+	// Latest returns the most recent event matching query (the last one Query would return) with
+	// a single-row read, and ok=false when none matches; LatestTyped also decodes its data
+	Latest(ctx context.Context, query Query) (Event, bool, error)
+
+	// QueryETag returns a weak HTTP ETag of the events matching query (count and highest position),
+	// unchanged until a matching event is appended, for 304 Not Modified responses
+	QueryETag(ctx context.Context, query Query) (string, error)
+
+	// ReadIfChanged returns the events matching query and their head (highest matching position)
+	// only when the head moved past sinceHead; otherwise changed is false and no payloads are read
+	ReadIfChanged(ctx context.Context, query Query, sinceHead int64) (events []Event, head int64, changed bool, err error)
+
+	// Head returns the highest committed event position (0 when empty); see eventStore.Head
+	// for why a concurrent append may still commit below it
+	Head(ctx context.Context) (int64, error)
+
+	// ApproxStats returns estimated row counts and on-disk sizes of the store's tables from the
+	// catalog, without scanning them; the counts are refreshed by autovacuum and ANALYZE
+	ApproxStats(ctx context.Context) (TableStats, error)
+
 	// Append appends events to the store without any consistency/concurrency checks
 	// Use this only when there are no business rules or consistency requirements
 	// For operations that require DCB concurrency control, use AppendIf instead
+	// Events of one call share a transaction id and get strictly increasing positions in slice
+	// order, so reads return them in the order they were passed (this holds for every append method)
 	Append(ctx context.Context, events []InputEvent) error
 
 	// AppendIf appends events to the store with explicit DCB concurrency control
 	// This method makes it clear when consistency/concurrency checks are required
 	// Use this for operations that need to ensure data hasn't changed since projection
 	// Note: DCB uses its own concurrency control mechanism via AppendCondition
+	// A nil or empty condition (AppendCondition.IsEmpty) degrades to an unconditional Append,
+	// so transports can pass client conditions through without checking them first
 	AppendIf(ctx context.Context, events []InputEvent, condition AppendCondition) error
 
+	// AppendIfElse appends events if condition holds and otherwise appends onFail (e.g. a rejection
+	// event) in a second transaction; the result tells which branch was appended
+	AppendIfElse(ctx context.Context, events []InputEvent, condition AppendCondition, onFail []InputEvent) (AppendIfElseResult, error)
+
+	// AppendToAggregate appends events to the per-aggregate stream tagKey:tagValue using
+	// expected-version optimistic concurrency (version = number of events carrying the tag)
+	// A version mismatch returns a ConcurrencyError with ExpectedVersion and ActualVersion
+	AppendToAggregate(ctx context.Context, tagKey, tagValue string, expectedVersion int, events []InputEvent) error
+
+	// AppendInto appends like AppendIf and writes each event's assigned position and transaction id
+	// back into its entry, mutating the entries, so follow-up events can reference them without a re-read
+	AppendInto(ctx context.Context, entries []*AppendEntry, condition AppendCondition) error
+
+	// AppendSkipDuplicates appends events unconditionally, skipping those that violate an
+	// EventStoreConfig.UniqueTags constraint (ON CONFLICT DO NOTHING), and reports which it skipped
+	AppendSkipDuplicates(ctx context.Context, events []InputEvent) (SkipDuplicatesResult, error)
+
+	// AppendAndProject appends events (conditionally unless condition is nil or empty) and projects the projectors
+	// in the same transaction, so the states reflect exactly the post-append stream
+	// Returns states, the condition for the next decision and the appended positions
+	AppendAndProject(ctx context.Context, events []InputEvent, condition AppendCondition, projectors []StateProjector) (map[string]any, AppendCondition, []int64, error)
+
+	// BlockTag makes appends of events carrying the tag key:value fail with a ResourceError until
+	// UnblockTag; an advisory kill switch, see eventStore.BlockTag and EventStoreConfig.SharedTagBlocks
+	BlockTag(ctx context.Context, key, value string) error
+
+	// UnblockTag lifts a BlockTag
+	UnblockTag(ctx context.Context, key, value string) error
+
 	// Project projects state from events matching projectors with optional cursor
 	// after == nil: project from beginning of stream
 	// after != nil: project from specified cursor position
 	// Returns final aggregated states and append condition for DCB concurrency control
+	// With EventStoreConfig.ReturnPartialOnCancel, cancelling ctx returns the states folded so far
+	// with a PartialResultError (errors.Is ErrPartial) carrying the last cursor read
 	Project(ctx context.Context, projectors []StateProjector, after *Cursor) (map[string]any, AppendCondition, error)
 
+	// ProjectWithStats is Project that also returns the events and bytes the projection read
+	ProjectWithStats(ctx context.Context, projectors []StateProjector, after *Cursor) (map[string]any, AppendCondition, ProjectionStats, error)
+
+	// ProjectJSON projects like Project but returns each state marshaled to JSON once by the store
+	// Projector states must be JSON-serializable
+	ProjectJSON(ctx context.Context, projectors []StateProjector, after *Cursor) (map[string]json.RawMessage, AppendCondition, error)
+
 	// ProjectStream creates a channel-based stream of projected states with optional cursor
 	// after == nil: stream from beginning of stream
 	// after != nil: stream from specified cursor position
 	// Returns intermediate states and append conditions via channels for streaming projections
 	ProjectStream(ctx context.Context, projectors []StateProjector, after *Cursor) (<-chan map[string]any, <-chan AppendCondition, error)
 
+	// ProjectMany runs Project for each projector set with up to EventStoreConfig.ProjectionWorkers
+	// queries at a time and returns the results in order; the first error cancels the others
+	ProjectMany(ctx context.Context, projectorSets [][]StateProjector) ([]ProjectManyResult, error)
+
+	// ProjectTrace projects one projector and returns its state after every event it consumed,
+	// for debugging a fold; it keeps a copy of the state per step, unlike Project
+	ProjectTrace(ctx context.Context, projector StateProjector) ([]StateAtPosition, error)
+
+	// ProjectProgressive projects in a single scan and emits copies of the states every `every`
+	// events, then a final snapshot with the append condition, e.g. to show a long rebuild's progress
+	// (not bounded by WithDefaultTimeouts: long rebuilds are expected; use ctx to bound it)
+	ProjectProgressive(ctx context.Context, projectors []StateProjector, every int) (<-chan ProjectionSnapshot, error)
+
+	// ProjectTx projects like Project but reads through the caller-managed transaction tx
+	// The caller chooses the isolation level and must commit or roll back tx
+	ProjectTx(ctx context.Context, tx pgx.Tx, projectors []StateProjector, after *Cursor) (map[string]any, AppendCondition, error)
+
+	// AppendIfTx appends like AppendIf but inside the caller-managed transaction tx
+	// Events become visible when the caller commits; use READ COMMITTED or SERIALIZABLE isolation
+	AppendIfTx(ctx context.Context, tx pgx.Tx, events []InputEvent, condition AppendCondition) error
+
+	// Archive moves events with position < beforePosition into archiveTable (created if missing)
+	// in one transaction and returns the number moved. Positions and cursors are preserved;
+	// reads include the archive only when EventStoreConfig.ArchiveTable is set
+	Archive(ctx context.Context, beforePosition int64, archiveTable string) (int64, error)
+
+	// MissingIndexes returns the CREATE INDEX statements of the indexes on a fixed checklist of the
+	// ones the store's queries are planned around that the events table lacks (see eventStore.MissingIndexes)
+	MissingIndexes(ctx context.Context) ([]string, error)
+
+	// CreateMissingIndexes builds the indexes MissingIndexes reports, CONCURRENTLY, and returns the
+	// statements it ran; builds can be long, so no default timeout applies
+	CreateMissingIndexes(ctx context.Context) ([]string, error)
+
+	// WithTransaction runs fn with an EventStore whose operations share one transaction,
+	// committed when fn returns nil and rolled back otherwise
+	// txStore must not be used concurrently or after fn returns
+	WithTransaction(ctx context.Context, fn func(txStore EventStore) error) error
+
+	// Truncate DESTRUCTIVELY deletes all events and restarts the position sequence
+	// For tests and benchmarks only: disabled unless EventStoreConfig.AllowTruncate is true
+	Truncate(ctx context.Context) error
+
+	// Reconfigure changes timeouts and stream limits at runtime (see DynamicConfig)
+	// Settings that can't change live, like the pool size, are rejected with a ValidationError
+	Reconfigure(cfg DynamicConfig) error
+
+	// StreamStats returns the open stream count and limit, and how many dead connections streams
+	// evicted (EventStoreConfig.ValidateStreamConnections)
+	StreamStats() StreamStats
+
+	// QuerySQLCacheStats returns how often reads reused cached SQL text
+	// (EventStoreConfig.DisableQuerySQLCache)
+	QuerySQLCacheStats() QuerySQLCacheStats
+
 	// GetConfig returns the current EventStore configuration
 	GetConfig() EventStoreConfig
 
@@ -57,6 +234,7 @@ type EventStore interface {
 	// This is intended for advanced/internal use cases such as custom transaction management,
 	// integration testing, or infrastructure extensions. Regular application logic should NOT
 	// use this method, as it bypasses the event store's consistency and abstraction guarantees.
+	// It returns nil for a store created by NewEventStoreFromSQLDB.
 	GetPool() *pgxpool.Pool
 }
 
@@ -69,15 +247,68 @@ type eventStore struct {
 	pool   *pgxpool.Pool
 	config EventStoreConfig
 
+	// sqlDB replaces pool for stores created by NewEventStoreFromSQLDB, whose pool is nil
+	sqlDB *sqlDBConns
+
 	// projectionSemaphore limits concurrent projection operations
 	projectionSemaphore chan struct{}
+
+	// live holds the settings Reconfigure can change, including the open stream count
+	// (QueryStream, QueryGrouped) limited by MaxConcurrentStreams
+	live *liveSettings
+
+	// conditionCosts caches EstimateConditionCost results
+	conditionCosts *conditionCostCache
+
+	// querySQL caches the SQL text of reads by query shape; nil disables caching
+	querySQL *querySQLCache
+
+	// lowerTagValues is set when the lower_tag_values SQL function is installed (WithTagCI)
+	lowerTagValues bool
+
+	// numericTagValue is set when the numeric_tag_value SQL function is installed (WithTagNumeric)
+	numericTagValue bool
+
+	// tagBlocks holds the tags BlockTag rejects in appends
+	tagBlocks *tagBlocks
+
+	// scope is set on the transaction-scoped copies handed out by WithTransaction
+	scope *txScope
 }
 
 func (es *eventStore) isEventStore() {}
 
-// GetConfig returns the current EventStore configuration
+// eventStoreUnwrapper is implemented by EventStore decorators (e.g. WithDefaultTimeouts)
+// so internal helpers can reach the underlying PostgreSQL-backed implementation
+type eventStoreUnwrapper interface {
+	unwrapEventStore() EventStore
+}
+
+// asEventStore resolves store to the internal *eventStore, unwrapping any decorators
+func asEventStore(store EventStore) (*eventStore, bool) {
+	for store != nil {
+		switch s := store.(type) {
+		case *eventStore:
+			return s, true
+		case eventStoreUnwrapper:
+			store = s.unwrapEventStore()
+		default:
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// GetConfig returns the current EventStore configuration, including changes made by Reconfigure
 func (es *eventStore) GetConfig() EventStoreConfig {
-	return es.config
+	config := es.config
+	config.QueryTimeout = int(es.live.queryTimeout.Load())
+	config.AppendTimeout = int(es.live.appendTimeout.Load())
+	config.LockTimeout = int(es.live.lockTimeout.Load())
+	es.live.streamsMu.Lock()
+	config.MaxConcurrentStreams = es.live.maxStreams
+	es.live.streamsMu.Unlock()
+	return config
 }
 
 // GetPool returns the underlying database pool
@@ -87,8 +318,17 @@ func (es *eventStore) GetPool() *pgxpool.Pool {
 
 // executeReadInTx executes a read operation within a transaction using the configured read isolation level
 // This is an internal helper method that wraps read operations in transactions for consistency
+// The whole transaction is retried on transient errors (see withReadRetry), so operation must
+// reset any state it accumulates when it starts
 func (es *eventStore) executeReadInTx(ctx context.Context, operation func(tx pgx.Tx) error) error {
-	tx, err := es.pool.BeginTx(ctx, pgx.TxOptions{
+	return es.withReadRetry(ctx, func() error {
+		return es.executeReadInTxOnce(ctx, operation)
+	})
+}
+
+// executeReadInTxOnce runs operation in a single read transaction without retries
+func (es *eventStore) executeReadInTxOnce(ctx context.Context, operation func(tx pgx.Tx) error) error {
+	tx, err := es.beginTx(ctx, pgx.TxOptions{
 		IsoLevel: toPgxIsoLevel(es.config.DefaultReadIsolation),
 	})
 	if err != nil {
@@ -104,3 +344,20 @@ func (es *eventStore) executeReadInTx(ctx context.Context, operation func(tx pgx
 
 	return operation(tx)
 }
+
+// acquireStreamSlot reserves one of the MaxConcurrentStreams slots with fail-fast behavior
+// The returned function releases the slot and must be called exactly once when the stream ends
+func (es *eventStore) acquireStreamSlot(op string) (func(), error) {
+	limit, ok := es.live.acquireStream()
+	if !ok {
+		return nil, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  op,
+				Err: fmt.Errorf("too many open streams (max %d)", limit),
+			},
+			Resource: "streams",
+		}
+	}
+	var once sync.Once
+	return func() { once.Do(es.live.releaseStream) }, nil
+}
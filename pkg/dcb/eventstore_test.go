@@ -0,0 +1,63 @@
+package dcb
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAcquireStreamSlot(t *testing.T) {
+	es := newEventStore(nil, EventStoreConfig{MaxConcurrentStreams: 2})
+
+	first, err := es.acquireStreamSlot("query_stream")
+	if err != nil {
+		t.Fatalf("first slot: %v", err)
+	}
+	if _, err := es.acquireStreamSlot("query_stream"); err != nil {
+		t.Fatalf("second slot: %v", err)
+	}
+
+	if _, err := es.acquireStreamSlot("query_stream"); !errors.Is(err, ErrResource) {
+		t.Fatalf("expected ResourceError over the limit, got %v", err)
+	}
+
+	first()
+	if _, err := es.acquireStreamSlot("query_stream"); err != nil {
+		t.Fatalf("slot should be available after release: %v", err)
+	}
+}
+
+func TestSubscribeRejectsBeforeStarting(t *testing.T) {
+	es := newEventStore(nil, EventStoreConfig{MaxConcurrentStreams: 1})
+
+	if _, err := es.Subscribe(t.Context(), NewQueryFromItems(), nil, 0); !IsValidationError(err) {
+		t.Fatalf("expected ValidationError for an empty query, got %v", err)
+	}
+
+	release, err := es.acquireStreamSlot("query_stream")
+	if err != nil {
+		t.Fatalf("slot: %v", err)
+	}
+	defer release()
+	if _, err := es.Subscribe(t.Context(), NewQuery(NewTags("k", "v")), nil, 0); !errors.Is(err, ErrResource) {
+		t.Fatalf("expected ResourceError without a free stream slot, got %v", err)
+	}
+}
+
+func TestStreamStats(t *testing.T) {
+	es := newEventStore(nil, EventStoreConfig{MaxConcurrentStreams: 3})
+
+	release, err := es.acquireStreamSlot("query_stream")
+	if err != nil {
+		t.Fatalf("slot: %v", err)
+	}
+	es.live.evictedConns.Add(2)
+	if stats := es.StreamStats(); stats != (StreamStats{Open: 1, Limit: 3, EvictedConnections: 2}) {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	release()
+	if stats := WithDefaultTimeouts(es, time.Second, time.Second).StreamStats(); stats.Open != 0 || stats.EvictedConnections != 2 {
+		t.Fatalf("expected the wrapper to report the store's stats, got %+v", stats)
+	}
+}
@@ -0,0 +1,115 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// =============================================================================
+// Grouped Streaming
+// =============================================================================
+
+// QueryGrouped streams events matching query grouped by the value of groupTagKey
+// Groups are delivered in tag value order; within a group events are in (transaction_id, position) order.
+// Rows are ordered by (tag value, transaction_id, position) in SQL and split into groups while reading,
+// so only one group is buffered at a time.
+// Events without a groupTagKey tag are skipped; an event carrying several values for the key
+// is delivered in each of those groups.
+// Like QueryStream, the channel is closed when the stream ends, on error or on context cancellation.
+func (es *eventStore) QueryGrouped(ctx context.Context, query Query, groupTagKey string) (<-chan EventGroup, error) {
+	if groupTagKey == "" {
+		return nil, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "query_grouped",
+				Err: fmt.Errorf("group tag key cannot be empty"),
+			},
+			Field: "groupTagKey",
+			Value: "empty",
+		}
+	}
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	innerSQL, args, err := es.buildReadQuerySQL(query, nil, nil)
+	if err != nil {
+		return nil, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "query_grouped",
+				Err: fmt.Errorf("failed to build SQL query: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+
+	// Expand each event once per matching group tag and order by group first
+	prefixArg := len(args) + 1
+	args = append(args, groupTagKey+":")
+	var sqlQuery strings.Builder
+	sqlQuery.WriteString("SELECT substr(g.tag, length($" + fmt.Sprint(prefixArg) + ") + 1) AS group_value, ")
+	sqlQuery.WriteString("e.type, e.tags, e.data, e.transaction_id, e.position, e.occurred_at, e.metadata, e.command_transaction_id ")
+	sqlQuery.WriteString("FROM (" + innerSQL + ") AS e, unnest(e.tags) AS g(tag) ")
+	sqlQuery.WriteString(fmt.Sprintf("WHERE left(g.tag, length($%d)) = $%d ", prefixArg, prefixArg))
+	sqlQuery.WriteString("ORDER BY group_value ASC, e.transaction_id ASC, e.position ASC")
+
+	// Reserve a stream slot; released when the stream ends
+	release, err := es.acquireStreamSlot("query_grouped")
+	if err != nil {
+		return nil, err
+	}
+
+	groupChan := make(chan EventGroup, es.config.StreamBuffer)
+
+	go func() {
+		defer release()
+		defer close(groupChan)
+
+		// Execute query using caller's context (caller controls timeout)
+		rows, err := es.streamQuery(ctx, sqlQuery.String(), args...)
+		if err != nil {
+			return
+		}
+		defer rows.Close()
+
+		send := func(group EventGroup) bool {
+			select {
+			case groupChan <- group:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		var current *EventGroup
+		for rows.Next() {
+			var groupValue string
+			var row rowEvent
+			if err := rows.Scan(append([]any{&groupValue}, row.scanTargets()...)...); err != nil {
+				return
+			}
+
+			// Tag value changed: the previous group is complete
+			if current != nil && current.TagValue != groupValue {
+				if !send(*current) {
+					return
+				}
+				current = nil
+			}
+			if current == nil {
+				current = &EventGroup{TagValue: groupValue}
+			}
+			current.Events = append(current.Events, convertRowToEvent(row))
+		}
+
+		// Do not deliver a possibly truncated last group
+		if err := rows.Err(); err != nil {
+			return
+		}
+		if current != nil {
+			send(*current)
+		}
+	}()
+
+	return groupChan, nil
+}
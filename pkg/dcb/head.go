@@ -0,0 +1,40 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Stream Head
+// =============================================================================
+
+// Head returns the highest committed event position, or 0 when the store is empty
+// Only committed events count: positions reserved by in-flight appends are never reported.
+// Positions are not committed in order, though, so a concurrent append may still commit an
+// event below the returned head; use cursors ((transaction_id, position)) to resume reads
+// without missing events, and Head for cheap change detection and progress reporting.
+// Archived events count only when EventStoreConfig.ArchiveTable is set.
+func (es *eventStore) Head(ctx context.Context) (int64, error) {
+	sqlQuery := "SELECT COALESCE(MAX(position), 0) FROM " + es.eventsSource()
+
+	var head int64
+	err := es.executeReadInTx(ctx, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, sqlQuery).Scan(&head); err != nil {
+			return &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "head",
+					Err: fmt.Errorf("failed to read head position: %w", err),
+				},
+				Resource: "database",
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return head, nil
+}
@@ -0,0 +1,49 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+)
+
+// =============================================================================
+// Invariant Assertions
+// =============================================================================
+
+// AssertNoOverbook checks a capacity invariant after a concurrency test: it projects projector's
+// final state from the store and returns a ValidationError (Field "invariant") when usage reports
+// more booked than capacity, i.e. when concurrency control let an overbooking through. usage maps
+// the projected state to its booked and capacity counts, e.g. for a concert or a course:
+//
+//	err := dcb.AssertNoOverbook(ctx, store, concertProjector, func(state any) (int, int) {
+//		concert := state.(ConcertState)
+//		return concert.BookedSeats, concert.TotalSeats
+//	})
+func AssertNoOverbook(ctx context.Context, store EventStore, projector StateProjector, usage func(state any) (booked, capacity int)) error {
+	if usage == nil {
+		return &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "assertNoOverbook",
+				Err: fmt.Errorf("usage function cannot be nil"),
+			},
+			Field: "usage",
+			Value: "nil",
+		}
+	}
+
+	states, _, err := store.Project(ctx, []StateProjector{projector}, nil)
+	if err != nil {
+		return err
+	}
+	booked, capacity := usage(states[projector.ID])
+	if booked > capacity {
+		return &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "assertNoOverbook",
+				Err: fmt.Errorf("overbooked: projector %s has %d booked for a capacity of %d", projector.ID, booked, capacity),
+			},
+			Field: "invariant",
+			Value: fmt.Sprintf("%d/%d", booked, capacity),
+		}
+	}
+	return nil
+}
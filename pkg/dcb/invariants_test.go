@@ -0,0 +1,59 @@
+package dcb
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// projectStub answers Project with fixed states, or fails with err
+type projectStub struct {
+	EventStore
+	states map[string]any
+	err    error
+}
+
+func (s *projectStub) Project(ctx context.Context, projectors []StateProjector, after *Cursor) (map[string]any, AppendCondition, error) {
+	return s.states, nil, s.err
+}
+
+func TestAssertNoOverbook(t *testing.T) {
+	type seats struct{ booked, total int }
+	projector := StateProjector{
+		ID:           "concert",
+		Query:        NewQuery(NewTags("concert_id", "c1")),
+		InitialState: seats{},
+		TransitionFn: func(state any, _ Event) any { return state },
+	}
+	usage := func(state any) (int, int) {
+		s := state.(seats)
+		return s.booked, s.total
+	}
+	ctx := context.Background()
+
+	for _, full := range []seats{{booked: 18, total: 20}, {booked: 20, total: 20}} {
+		store := &projectStub{states: map[string]any{"concert": full}}
+		if err := AssertNoOverbook(ctx, store, projector, usage); err != nil {
+			t.Errorf("expected %+v to pass, got %v", full, err)
+		}
+	}
+
+	store := &projectStub{states: map[string]any{"concert": seats{booked: 22, total: 20}}}
+	err := AssertNoOverbook(ctx, store, projector, usage)
+	validationErr, ok := GetValidationError(err)
+	if !ok {
+		t.Fatalf("expected ValidationError for an overbooking, got %v", err)
+	}
+	if validationErr.Field != "invariant" || validationErr.Value != "22/20" || !strings.Contains(err.Error(), "overbooked") {
+		t.Errorf("unexpected overbooking error: %v (field %q value %q)", err, validationErr.Field, validationErr.Value)
+	}
+
+	projectErr := errors.New("connection refused")
+	if err := AssertNoOverbook(ctx, &projectStub{err: projectErr}, projector, usage); !errors.Is(err, projectErr) {
+		t.Errorf("expected the projection error, got %v", err)
+	}
+	if err := AssertNoOverbook(ctx, store, projector, nil); !IsValidationError(err) {
+		t.Errorf("expected ValidationError for a nil usage, got %v", err)
+	}
+}
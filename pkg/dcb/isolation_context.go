@@ -0,0 +1,38 @@
+package dcb
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Per-Request Append Isolation
+// =============================================================================
+
+// isolationContextKey carries the append isolation set by ContextWithIsolation
+type isolationContextKey struct{}
+
+// ContextWithIsolation returns a context whose appends run at level instead of
+// EventStoreConfig.DefaultAppendIsolation, so HTTP/gRPC middleware can choose the isolation once
+// per request instead of every call site (or one store per level). It applies to the transactions
+// the store begins for Append, AppendIf and the other append methods, ExecuteCommand and
+// WithTransaction. Precedence: a transaction the caller already controls (a transaction-scoped
+// store, whose isolation was fixed when it began) > the context > the config default
+func ContextWithIsolation(ctx context.Context, level IsolationLevel) context.Context {
+	return context.WithValue(ctx, isolationContextKey{}, level)
+}
+
+// IsolationFromContext returns the append isolation set with ContextWithIsolation, if any
+func IsolationFromContext(ctx context.Context) (IsolationLevel, bool) {
+	level, ok := ctx.Value(isolationContextKey{}).(IsolationLevel)
+	return level, ok
+}
+
+// appendIsolation resolves the isolation of a transaction begun for an append with ctx
+func appendIsolation(ctx context.Context, config EventStoreConfig) pgx.TxIsoLevel {
+	if level, ok := IsolationFromContext(ctx); ok {
+		return toPgxIsoLevel(level)
+	}
+	return toPgxIsoLevel(config.DefaultAppendIsolation)
+}
@@ -0,0 +1,34 @@
+package dcb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestAppendIsolation(t *testing.T) {
+	config := EventStoreConfig{DefaultAppendIsolation: IsolationLevelRepeatableRead}
+
+	if level := appendIsolation(context.Background(), config); level != pgx.RepeatableRead {
+		t.Fatalf("expected the config default without a context level, got %s", level)
+	}
+	if _, ok := IsolationFromContext(context.Background()); ok {
+		t.Fatal("expected no isolation in a plain context")
+	}
+
+	ctx := ContextWithIsolation(context.Background(), IsolationLevelSerializable)
+	if level := appendIsolation(ctx, config); level != pgx.Serializable {
+		t.Fatalf("expected the context level to win over the config, got %s", level)
+	}
+	if level, ok := IsolationFromContext(ctx); !ok || level != IsolationLevelSerializable {
+		t.Fatalf("expected SERIALIZABLE from the context, got %s, %v", level, ok)
+	}
+
+	// The level survives derived contexts, e.g. the timeouts added by WithDefaultTimeouts
+	derived, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if level := appendIsolation(derived, config); level != pgx.Serializable {
+		t.Fatalf("expected derived contexts to keep the level, got %s", level)
+	}
+}
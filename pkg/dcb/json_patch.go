@@ -0,0 +1,485 @@
+package dcb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// JSON Patch Events
+// =============================================================================
+
+// JSONPatchTypeSuffix marks event types whose data is a JSON Patch (RFC 6902) instead of a full
+// document, e.g. "ProfileUpdated+json-patch". Appends validate the data of such events as a patch
+// and ProjectJSONPatch applies them in order
+const JSONPatchTypeSuffix = "+json-patch"
+
+// JSONPatchType returns eventType marked as carrying a JSON Patch
+func JSONPatchType(eventType string) string {
+	return eventType + JSONPatchTypeSuffix
+}
+
+// IsJSONPatchType reports whether eventType carries a JSON Patch
+func IsJSONPatchType(eventType string) bool {
+	return strings.HasSuffix(eventType, JSONPatchTypeSuffix)
+}
+
+// NewJSONPatchEvent creates an InputEvent whose data is the JSON Patch patch, with eventType
+// marked by JSONPatchTypeSuffix. Like any event it is validated when appended: a malformed patch
+// is rejected with a ValidationError
+func NewJSONPatchEvent(eventType string, tags []Tag, patch []byte) InputEvent {
+	return NewInputEvent(JSONPatchType(eventType), tags, patch)
+}
+
+// JSONDocument is the state of a ProjectJSONPatch projector
+type JSONDocument struct {
+	// Document is the current document
+	Document json.RawMessage `json:"document"`
+	// Err records the first patch that could not be applied (e.g. a failed "test" operation or a
+	// missing path). That patch is skipped as a whole, as RFC 6902 requires, and later events
+	// still apply
+	Err error `json:"-"`
+}
+
+// ProjectJSONPatch creates a projector that rebuilds a document from the events matching query,
+// starting from initialJSON (null when empty). Events whose type is marked by JSONPatchTypeSuffix
+// are applied as JSON Patches in order; any other matching event, such as the one creating the
+// document, replaces the document with its data. The state is a JSONDocument
+func ProjectJSONPatch(id string, query Query, initialJSON []byte) StateProjector {
+	if len(initialJSON) == 0 {
+		initialJSON = []byte("null")
+	}
+	return StateProjector{
+		ID:           id,
+		Query:        query,
+		InitialState: JSONDocument{Document: initialJSON},
+		TransitionFn: func(state any, event Event) any {
+			current := state.(JSONDocument)
+			if !IsJSONPatchType(event.Type) {
+				current.Document = event.Data
+				return current
+			}
+			document, err := ApplyJSONPatch(current.Document, event.Data)
+			if err != nil {
+				if current.Err == nil {
+					current.Err = fmt.Errorf("%s event at position %d: %w", event.Type, event.Position, err)
+				}
+				return current
+			}
+			current.Document = document
+			return current
+		},
+	}
+}
+
+// ApplyJSONPatch applies the JSON Patch (RFC 6902) patch to document and returns the new document
+// The patch is applied atomically: if any operation fails, a ValidationError is returned and
+// document is left as it was. Numbers keep their original representation
+func ApplyJSONPatch(document, patch []byte) ([]byte, error) {
+	ops, err := parseJSONPatch(patch)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := decodeJSONValue(document)
+	if err != nil {
+		return nil, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "apply_json_patch",
+				Err: fmt.Errorf("invalid document: %w", err),
+			},
+			Field: "document",
+		}
+	}
+
+	for i, op := range ops {
+		doc, err = op.apply(doc)
+		if err != nil {
+			return nil, &ValidationError{
+				EventStoreError: EventStoreError{
+					Op:  "apply_json_patch",
+					Err: fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err),
+				},
+				Field: fmt.Sprintf("patch[%d]", i),
+				Value: op.Path,
+			}
+		}
+	}
+	return json.Marshal(doc)
+}
+
+// validateJSONPatchEvent validates the data of an event marked by JSONPatchTypeSuffix as a patch
+func validateJSONPatchEvent(e InputEvent, index int) error {
+	if _, err := parseJSONPatch(e.GetData()); err != nil {
+		return &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "validateEvent",
+				Err: fmt.Errorf("invalid JSON Patch in event %d: %w", index, err),
+			},
+			Field: "data",
+			Value: fmt.Sprintf("event[%d]", index),
+		}
+	}
+	return nil
+}
+
+// jsonPatchOperation is one operation of a JSON Patch
+type jsonPatchOperation struct {
+	Op   string
+	Path string
+	From string
+
+	path  []string // Path and From as reference tokens
+	from  []string
+	value any // decoded "value" member
+}
+
+// parseJSONPatch decodes and checks a patch: an array of operations with a known op, valid JSON
+// Pointers and the members each op requires
+func parseJSONPatch(patch []byte) ([]jsonPatchOperation, error) {
+	var raw []map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &raw); err != nil {
+		return nil, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "parse_json_patch",
+				Err: fmt.Errorf("a JSON Patch must be an array of operation objects: %w", err),
+			},
+			Field: "patch",
+		}
+	}
+
+	ops := make([]jsonPatchOperation, len(raw))
+	for i, members := range raw {
+		if err := ops[i].parse(members); err != nil {
+			return nil, &ValidationError{
+				EventStoreError: EventStoreError{
+					Op:  "parse_json_patch",
+					Err: fmt.Errorf("operation %d: %w", i, err),
+				},
+				Field: fmt.Sprintf("patch[%d]", i),
+				Value: ops[i].Op,
+			}
+		}
+	}
+	return ops, nil
+}
+
+// parse fills the operation from its JSON members and checks them
+func (op *jsonPatchOperation) parse(members map[string]json.RawMessage) error {
+	if err := unmarshalMember(members, "op", &op.Op); err != nil {
+		return err
+	}
+	if err := unmarshalMember(members, "path", &op.Path); err != nil {
+		return err
+	}
+	var err error
+	if op.path, err = parseJSONPointer(op.Path); err != nil {
+		return err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		value, ok := members["value"]
+		if !ok {
+			return fmt.Errorf("%q requires a value", op.Op)
+		}
+		if op.value, err = decodeJSONValue(value); err != nil {
+			return err
+		}
+	case "move", "copy":
+		if err := unmarshalMember(members, "from", &op.From); err != nil {
+			return err
+		}
+		if op.from, err = parseJSONPointer(op.From); err != nil {
+			return err
+		}
+		if op.Op == "move" && strings.HasPrefix(op.Path, op.From+"/") {
+			return fmt.Errorf("cannot move %q into its own child %q", op.From, op.Path)
+		}
+	case "remove":
+	default:
+		return fmt.Errorf("unknown op %q", op.Op)
+	}
+	return nil
+}
+
+// apply applies the operation to doc and returns the new document
+func (op *jsonPatchOperation) apply(doc any) (any, error) {
+	switch op.Op {
+	case "add":
+		return jsonAdd(doc, op.path, copyJSONValue(op.value))
+	case "remove":
+		return jsonRemove(doc, op.path)
+	case "replace":
+		if _, err := jsonGet(doc, op.path); err != nil {
+			return nil, err
+		}
+		return jsonSet(doc, op.path, copyJSONValue(op.value))
+	case "move":
+		if op.From == op.Path {
+			return doc, nil
+		}
+		value, err := jsonGet(doc, op.from)
+		if err != nil {
+			return nil, err
+		}
+		if doc, err = jsonRemove(doc, op.from); err != nil {
+			return nil, err
+		}
+		return jsonAdd(doc, op.path, value)
+	case "copy":
+		value, err := jsonGet(doc, op.from)
+		if err != nil {
+			return nil, err
+		}
+		return jsonAdd(doc, op.path, copyJSONValue(value))
+	case "test":
+		value, err := jsonGet(doc, op.path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(value, op.value) {
+			return nil, fmt.Errorf("test failed: value is %s", formatJSONValue(value))
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("unknown op %q", op.Op)
+}
+
+// unmarshalMember decodes the required string member name of an operation
+func unmarshalMember(members map[string]json.RawMessage, name string, target *string) error {
+	raw, ok := members[name]
+	if !ok {
+		return fmt.Errorf("missing %q", name)
+	}
+	if err := json.Unmarshal(raw, target); err != nil {
+		return fmt.Errorf("%q must be a string", name)
+	}
+	return nil
+}
+
+// parseJSONPointer splits a JSON Pointer (RFC 6901) into unescaped reference tokens
+// The empty pointer refers to the whole document and has no tokens
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("JSON Pointer %q must be empty or start with '/'", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+// decodeJSONValue decodes JSON keeping numbers as json.Number
+func decodeJSONValue(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// jsonGet returns the value at path
+func jsonGet(doc any, path []string) (any, error) {
+	for _, token := range path {
+		switch container := doc.(type) {
+		case map[string]any:
+			value, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("member %q not found", token)
+			}
+			doc = value
+		case []any:
+			index, err := jsonArrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = container[index]
+		default:
+			return nil, fmt.Errorf("cannot reference %q in a scalar", token)
+		}
+	}
+	return doc, nil
+}
+
+// jsonAdd adds value at path: it sets an object member or inserts into an array ("-" appends)
+func jsonAdd(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return jsonUpdateParent(doc, path, func(container any, token string) (any, error) {
+		switch container := container.(type) {
+		case map[string]any:
+			container[token] = value
+			return container, nil
+		case []any:
+			index := len(container)
+			if token != "-" {
+				var err error
+				if index, err = jsonArrayIndex(token, len(container)); err != nil {
+					return nil, err
+				}
+			}
+			return append(container[:index], append([]any{value}, container[index:]...)...), nil
+		}
+		return nil, fmt.Errorf("cannot add %q to a scalar", token)
+	})
+}
+
+// jsonSet replaces the existing value at path
+func jsonSet(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return jsonUpdateParent(doc, path, func(container any, token string) (any, error) {
+		switch container := container.(type) {
+		case map[string]any:
+			container[token] = value
+			return container, nil
+		case []any:
+			index, err := jsonArrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			container[index] = value
+			return container, nil
+		}
+		return nil, fmt.Errorf("cannot set %q in a scalar", token)
+	})
+}
+
+// jsonRemove removes the existing value at path
+func jsonRemove(doc any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("cannot remove the whole document")
+	}
+	return jsonUpdateParent(doc, path, func(container any, token string) (any, error) {
+		switch container := container.(type) {
+		case map[string]any:
+			if _, ok := container[token]; !ok {
+				return nil, fmt.Errorf("member %q not found", token)
+			}
+			delete(container, token)
+			return container, nil
+		case []any:
+			index, err := jsonArrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			return append(container[:index], container[index+1:]...), nil
+		}
+		return nil, fmt.Errorf("cannot remove %q from a scalar", token)
+	})
+}
+
+// jsonUpdateParent walks to the container holding the last token of path, replaces it with the
+// result of update, and returns the updated document (arrays may be reallocated on the way)
+func jsonUpdateParent(doc any, path []string, update func(container any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return update(doc, path[0])
+	}
+	child, err := jsonGet(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	updated, err := jsonUpdateParent(child, path[1:], update)
+	if err != nil {
+		return nil, err
+	}
+	switch container := doc.(type) {
+	case map[string]any:
+		container[path[0]] = updated
+	case []any:
+		index, _ := jsonArrayIndex(path[0], len(container)-1)
+		container[index] = updated
+	}
+	return doc, nil
+}
+
+// jsonArrayIndex parses an array index token, which must be a decimal without leading zeros
+// and at most maxIndex
+func jsonArrayIndex(token string, maxIndex int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') || strings.TrimLeft(token, "0123456789") != "" {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index > maxIndex {
+		return 0, fmt.Errorf("array index %s out of bounds", token)
+	}
+	return index, nil
+}
+
+// copyJSONValue deep-copies a decoded JSON value so patched documents never share containers
+func copyJSONValue(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		copied := make(map[string]any, len(value))
+		for key, member := range value {
+			copied[key] = copyJSONValue(member)
+		}
+		return copied
+	case []any:
+		copied := make([]any, len(value))
+		for i, element := range value {
+			copied[i] = copyJSONValue(element)
+		}
+		return copied
+	}
+	return value
+}
+
+// jsonEqual compares decoded JSON values as RFC 6902 "test" does: numbers by value, objects
+// regardless of member order
+func jsonEqual(a, b any) bool {
+	switch a := a.(type) {
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for key, member := range a {
+			other, ok := b[key]
+			if !ok || !jsonEqual(member, other) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !jsonEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, errA := a.Float64()
+		y, errB := b.Float64()
+		return errA == nil && errB == nil && x == y
+	}
+	return a == b
+}
+
+// formatJSONValue renders a decoded JSON value for error messages
+func formatJSONValue(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
@@ -0,0 +1,137 @@
+package dcb
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestApplyJSONPatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		patch    string
+		want     string
+	}{
+		{"add member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{"add array element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"append to array", `{"foo":[1]}`, `[{"op":"add","path":"/foo/-","value":2}]`, `{"foo":[1,2]}`},
+		{"remove member", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"remove array element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"replace", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{"replace document", `{"a":1}`, `[{"op":"replace","path":"","value":[1]}]`, `[1]`},
+		{"move", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{"move array element", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{"copy is independent", `{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`, `{"a":{"b":1},"c":{"b":2}}`},
+		{"escaped pointer", `{"a/b":{"m~n":1}}`, `[{"op":"replace","path":"/a~1b/m~0n","value":2}]`, `{"a/b":{"m~n":2}}`},
+		{"test passes", `{"n":10,"tags":["x"]}`, `[{"op":"test","path":"/n","value":10.0},{"op":"test","path":"/tags","value":["x"]},{"op":"add","path":"/ok","value":true}]`, `{"n":10,"ok":true,"tags":["x"]}`},
+		{"numbers kept", `{"big":12345678901234567890}`, `[{"op":"add","path":"/n","value":1.50}]`, `{"big":12345678901234567890,"n":1.50}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyJSONPatch([]byte(tt.document), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("ApplyJSONPatch: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestApplyJSONPatchFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		patch    string
+		field    string
+	}{
+		{"failed test", `{"a":1}`, `[{"op":"add","path":"/b","value":2},{"op":"test","path":"/a","value":2}]`, "patch[1]"},
+		{"missing member", `{"a":1}`, `[{"op":"replace","path":"/b","value":2}]`, "patch[0]"},
+		{"missing parent", `{"a":1}`, `[{"op":"add","path":"/b/c","value":2}]`, "patch[0]"},
+		{"index out of bounds", `{"a":[1]}`, `[{"op":"add","path":"/a/2","value":2}]`, "patch[0]"},
+		{"leading zero index", `{"a":[1,2]}`, `[{"op":"remove","path":"/a/01"}]`, "patch[0]"},
+		{"remove document", `{"a":1}`, `[{"op":"remove","path":""}]`, "patch[0]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document := []byte(tt.document)
+			_, err := ApplyJSONPatch(document, []byte(tt.patch))
+			validationErr, ok := GetValidationError(err)
+			if !ok {
+				t.Fatalf("expected ValidationError, got %v", err)
+			}
+			if validationErr.Field != tt.field {
+				t.Errorf("expected field %q, got %q", tt.field, validationErr.Field)
+			}
+			if string(document) != tt.document {
+				t.Errorf("document modified: %s", document)
+			}
+		})
+	}
+}
+
+func TestValidateJSONPatchEvent(t *testing.T) {
+	tags := NewTags("profile_id", "p1")
+	valid := NewJSONPatchEvent("ProfileUpdated", tags, []byte(`[{"op":"replace","path":"/name","value":"Ada"}]`))
+	if valid.GetType() != "ProfileUpdated+json-patch" || !IsJSONPatchType(valid.GetType()) {
+		t.Fatalf("unexpected patch event type %q", valid.GetType())
+	}
+	if err := validateEvent(valid, 0); err != nil {
+		t.Fatalf("expected a valid patch event, got %v", err)
+	}
+
+	for _, patch := range []string{
+		`{"op":"remove","path":"/a"}`,
+		`[{"op":"delete","path":"/a"}]`,
+		`[{"op":"add","path":"/a"}]`,
+		`[{"op":"remove","path":"a"}]`,
+		`[{"op":"move","path":"/a"}]`,
+		`[{"op":"move","from":"/a","path":"/a/b"}]`,
+		`[{"op":"remove","path":3}]`,
+	} {
+		err := validateEvent(NewJSONPatchEvent("ProfileUpdated", tags, []byte(patch)), 2)
+		validationErr, ok := GetValidationError(err)
+		if !ok || validationErr.Field != "data" || validationErr.Value != "event[2]" {
+			t.Errorf("expected a data ValidationError for %s, got %v", patch, err)
+		}
+	}
+
+	// Plain events may carry any JSON data
+	if err := validateEvent(NewInputEvent("ProfileUpdated", tags, []byte(`{"op":"delete"}`)), 0); err != nil {
+		t.Fatalf("expected a plain event to be valid, got %v", err)
+	}
+}
+
+func TestProjectJSONPatch(t *testing.T) {
+	projector := ProjectJSONPatch("profile", NewQuery(NewTags("profile_id", "p1")), nil)
+	events := []Event{
+		{Type: "ProfileCreated", Data: []byte(`{"name":"Ada","emails":[]}`), Position: 1},
+		{Type: JSONPatchType("ProfileUpdated"), Data: []byte(`[{"op":"add","path":"/emails/-","value":"ada@example.com"}]`), Position: 2},
+		{Type: JSONPatchType("ProfileUpdated"), Data: []byte(`[{"op":"test","path":"/name","value":"Grace"},{"op":"replace","path":"/name","value":"Bob"}]`), Position: 3},
+		{Type: JSONPatchType("ProfileUpdated"), Data: []byte(`[{"op":"replace","path":"/name","value":"Ada Lovelace"}]`), Position: 4},
+	}
+
+	state := projector.InitialState
+	if string(state.(JSONDocument).Document) != "null" {
+		t.Fatalf("expected a null initial document, got %s", state.(JSONDocument).Document)
+	}
+	for _, event := range events {
+		state = projector.TransitionFn(state, event)
+	}
+
+	document := state.(JSONDocument)
+	var profile struct {
+		Name   string   `json:"name"`
+		Emails []string `json:"emails"`
+	}
+	if err := json.Unmarshal(document.Document, &profile); err != nil {
+		t.Fatalf("unmarshal document: %v", err)
+	}
+	if profile.Name != "Ada Lovelace" || len(profile.Emails) != 1 || profile.Emails[0] != "ada@example.com" {
+		t.Errorf("unexpected document %s", document.Document)
+	}
+	if document.Err == nil || !IsValidationError(document.Err) {
+		t.Errorf("expected the failed patch at position 3 to be recorded, got %v", document.Err)
+	}
+}
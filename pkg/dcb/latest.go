@@ -0,0 +1,93 @@
+package dcb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Latest Event
+// =============================================================================
+
+// latestOrderBy reverses readOrderBy so the first row is the last event a read would return
+const latestOrderBy = " ORDER BY transaction_id DESC, position DESC LIMIT 1"
+
+// Latest returns the most recent event matching query, i.e. the last event Query would return,
+// and ok=false when none matches. It reads a single row, so "last known state" lookups such as
+// the latest AddressChanged of a customer don't need to read the stream or run a projector.
+// Events in the archive table are included when EventStoreConfig.ArchiveTable is set
+func (es *eventStore) Latest(ctx context.Context, query Query) (Event, bool, error) {
+	if query == nil {
+		return Event{}, false, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "latest",
+				Err: fmt.Errorf("query cannot be nil"),
+			},
+			Field: "query",
+			Value: "nil",
+		}
+	}
+	if err := query.Validate(); err != nil {
+		return Event{}, false, err
+	}
+	if err := validateQueryTags(query); err != nil {
+		return Event{}, false, err
+	}
+
+	sqlQuery, args, err := es.buildReadQuerySQL(query, nil, nil)
+	if err != nil {
+		return Event{}, false, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "latest",
+				Err: fmt.Errorf("failed to build SQL query: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	sqlQuery = strings.TrimSuffix(sqlQuery, readOrderBy) + latestOrderBy
+
+	var (
+		latest Event
+		found  bool
+	)
+	err = es.executeReadInTx(ctx, func(tx pgx.Tx) error {
+		var row rowEvent
+		err := tx.QueryRow(ctx, sqlQuery, args...).Scan(row.scanTargets()...)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "latest",
+					Err: fmt.Errorf("failed to read latest matching event: %w", err),
+				},
+				Resource: "database",
+			}
+		}
+		latest, found = convertRowToEvent(row), true
+		return nil
+	})
+	if err != nil {
+		return Event{}, false, err
+	}
+	return latest, found, nil
+}
+
+// LatestTyped returns the data of the most recent event matching query decoded into E (see
+// Latest and DecodeData), and ok=false when none matches
+func LatestTyped[E any](ctx context.Context, store EventStore, query Query) (E, bool, error) {
+	var data E
+	event, ok, err := store.Latest(ctx, query)
+	if err != nil || !ok {
+		return data, false, err
+	}
+	if err := DecodeData(event, &data); err != nil {
+		return data, false, err
+	}
+	return data, true, nil
+}
@@ -0,0 +1,43 @@
+package dcb
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLatestRejectsNilQuery(t *testing.T) {
+	es := newEventStore(nil, EventStoreConfig{})
+	if _, _, err := es.Latest(context.Background(), nil); !IsValidationError(err) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+}
+
+func TestLatestTyped(t *testing.T) {
+	type addressChanged struct {
+		City string `json:"city"`
+	}
+	ctx := context.Background()
+	query := NewQuery(NewTags("customer_id", "c1"), "AddressChanged")
+
+	address, ok, err := LatestTyped[addressChanged](ctx, &stubStore{}, query)
+	if err != nil || ok || address.City != "" {
+		t.Fatalf("expected no event, got %+v, %v, %v", address, ok, err)
+	}
+
+	store := &stubStore{events: []Event{
+		{Type: "AddressChanged", Position: 1, Data: []byte(`{"city": "Lisbon"}`)},
+		{Type: "AddressChanged", Position: 2, Data: []byte(`{"city": "Porto"}`)},
+	}}
+	address, ok, err = LatestTyped[addressChanged](ctx, store, query)
+	if err != nil || !ok {
+		t.Fatalf("expected the latest event, got %v, %v", ok, err)
+	}
+	if address.City != "Porto" {
+		t.Errorf("expected Porto, got %q", address.City)
+	}
+
+	store.events = []Event{{Type: "AddressChanged", Position: 3, Data: []byte(`{"city": 7}`)}}
+	if _, ok, err := LatestTyped[addressChanged](ctx, store, query); !IsValidationError(err) || ok {
+		t.Errorf("expected a decode ValidationError, got %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,92 @@
+package dcb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// =============================================================================
+// Lock Timeout
+// =============================================================================
+
+// lockNotAvailableCode is the PostgreSQL error code raised when lock_timeout expires
+const lockNotAvailableCode = "55P03"
+
+// maxLockTimeoutHeadroom caps the time kept between a deadline-bound lock_timeout and the ctx
+// deadline
+const maxLockTimeoutHeadroom = 100 * time.Millisecond
+
+// effectiveLockTimeout returns min(configured, time remaining until the ctx deadline), keeping a
+// fifth of the remaining time (at most maxLockTimeoutHeadroom) as headroom so that lock_timeout
+// fires before the deadline cancels the statement and the wait is reported as a lock timeout.
+// A zero result means no lock timeout applies (no configured value and no deadline).
+// An expired deadline returns the context error.
+func effectiveLockTimeout(ctx context.Context, configured time.Duration) (time.Duration, error) {
+	timeout := configured
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return 0, context.DeadlineExceeded
+		}
+		remaining -= min(remaining/5, maxLockTimeoutHeadroom)
+		if timeout <= 0 || remaining < timeout {
+			timeout = remaining
+		}
+	}
+	if timeout > 0 && timeout < time.Millisecond {
+		// lock_timeout has millisecond resolution; 0 would mean "wait forever"
+		timeout = time.Millisecond
+	}
+	return timeout, nil
+}
+
+// applyLockTimeout bounds lock waits in tx by the effective lock timeout via SET LOCAL lock_timeout,
+// so a caller with a short request budget fails fast instead of waiting on a held lock
+func (es *eventStore) applyLockTimeout(ctx context.Context, tx pgx.Tx, op string) error {
+	timeout, err := effectiveLockTimeout(ctx, time.Duration(es.live.lockTimeout.Load())*time.Millisecond)
+	if err != nil {
+		return &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  op,
+				Err: fmt.Errorf("no time left to acquire locks: %w", err),
+			},
+			Resource: "lock",
+		}
+	}
+	if timeout == 0 {
+		return nil
+	}
+
+	// set_config(..., true) is the parameterized form of SET LOCAL
+	if _, err := tx.Exec(ctx, `SELECT set_config('lock_timeout', $1, true)`, fmt.Sprintf("%dms", timeout.Milliseconds())); err != nil {
+		return &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  op,
+				Err: fmt.Errorf("failed to set lock timeout: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	return nil
+}
+
+// applyVersionLockTimeout applies the lock timeout to the appends that lock dcb_aggregate_versions
+// rows (EventStoreConfig.AggregateVersionTagKeys). Other plain and conditional appends take no
+// explicit locks, so they skip its round trip
+func (es *eventStore) applyVersionLockTimeout(ctx context.Context, tx pgx.Tx, op string) error {
+	if len(es.config.AggregateVersionTagKeys) == 0 {
+		return nil
+	}
+	return es.applyLockTimeout(ctx, tx, op)
+}
+
+// isLockTimeout reports whether err was raised because lock_timeout expired
+func isLockTimeout(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == lockNotAvailableCode
+}
@@ -0,0 +1,63 @@
+package dcb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEffectiveLockTimeout(t *testing.T) {
+	t.Run("uses configured timeout without deadline", func(t *testing.T) {
+		got, err := effectiveLockTimeout(context.Background(), 5*time.Second)
+		if err != nil || got != 5*time.Second {
+			t.Fatalf("expected 5s, got %v (err %v)", got, err)
+		}
+	})
+
+	t.Run("shorter deadline wins", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+
+		got, err := effectiveLockTimeout(ctx, 5*time.Second)
+		if err != nil || got <= 0 || got > 400*time.Millisecond {
+			t.Fatalf("expected at most 400ms, leaving headroom before the deadline, got %v (err %v)", got, err)
+		}
+	})
+
+	t.Run("shorter configured timeout wins", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		got, err := effectiveLockTimeout(ctx, time.Second)
+		if err != nil || got != time.Second {
+			t.Fatalf("expected 1s, got %v (err %v)", got, err)
+		}
+	})
+
+	t.Run("no configured timeout and no deadline", func(t *testing.T) {
+		got, err := effectiveLockTimeout(context.Background(), 0)
+		if err != nil || got != 0 {
+			t.Fatalf("expected no timeout, got %v (err %v)", got, err)
+		}
+	})
+
+	t.Run("expired deadline", func(t *testing.T) {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+
+		if _, err := effectiveLockTimeout(ctx, time.Second); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected DeadlineExceeded, got %v", err)
+		}
+	})
+}
+
+func TestApplyVersionLockTimeoutSkipsUnversionedStores(t *testing.T) {
+	es := newEventStore(nil, EventStoreConfig{LockTimeout: 5000})
+	es.live.lockTimeout.Store(5000)
+
+	// Without AggregateVersionTagKeys nothing is sent: a nil transaction would panic otherwise
+	if err := es.applyVersionLockTimeout(context.Background(), nil, "append"); err != nil {
+		t.Errorf("expected no lock timeout for an unversioned store, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package dcb
+
+import (
+	"context"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Index Checklist
+// =============================================================================
+
+// checklistIndex is an index of the events table that the store's reads use
+type checklistIndex struct {
+	method  string   // access method, e.g. "btree" or "gin"
+	columns []string // key columns, in order
+	sql     string   // statement creating it
+}
+
+// indexChecklist is the fixed list of indexes the store's queries are planned around: tag
+// containment (every query and condition), cursor order (reads, subscriptions and conditions
+// after a cursor), and per-type newest first (Latest, LatestTyped, and projections of a few types)
+var indexChecklist = []checklistIndex{
+	{method: "gin", columns: []string{"tags"},
+		sql: "CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_events_tags ON events USING GIN (tags)"},
+	{method: "btree", columns: []string{"transaction_id", "position"},
+		sql: "CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_events_transaction_position_btree ON events (transaction_id, position)"},
+	{method: "btree", columns: []string{"type", "transaction_id", "position"},
+		sql: "CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_events_type_transaction_position ON events (type, transaction_id, position)"},
+}
+
+// existingIndex is an index found on the events table
+type existingIndex struct {
+	method  string
+	columns []string
+}
+
+// covers reports whether the existing index serves the checklist one: the same access method
+// with the checklist key columns leading its own (for GIN, among them)
+func (e existingIndex) covers(r checklistIndex) bool {
+	if e.method != r.method {
+		return false
+	}
+	if r.method == "btree" {
+		return len(e.columns) >= len(r.columns) && slices.Equal(e.columns[:len(r.columns)], r.columns)
+	}
+	for _, column := range r.columns {
+		if !slices.Contains(e.columns, column) {
+			return false
+		}
+	}
+	return true
+}
+
+// uncoveredIndexes returns the statements of the checklist indexes no existing index covers
+func uncoveredIndexes(existing []existingIndex) []string {
+	var statements []string
+	for _, r := range indexChecklist {
+		if !slices.ContainsFunc(existing, func(e existingIndex) bool { return e.covers(r) }) {
+			statements = append(statements, r.sql)
+		}
+	}
+	return statements
+}
+
+// MissingIndexes checks the events table against a fixed checklist of the indexes the store's
+// queries are planned around and returns the CREATE INDEX statements of those it lacks: a GIN
+// index on tags, a B-tree on (transaction_id, position) and one on (type, transaction_id,
+// position) for Latest and per-type reads. The checklist is static, not derived from the queries
+// the application runs. An existing index counts when its leading columns match, and partial and
+// expression indexes don't count. It returns nothing for a schema that has them all; review the
+// statements, or run them with CreateMissingIndexes
+func (es *eventStore) MissingIndexes(ctx context.Context) ([]string, error) {
+	rows, err := es.poolDB().Query(ctx, `
+		SELECT am.amname, array_agg(a.attname::text ORDER BY k.ord)
+		FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indexrelid
+		JOIN pg_am am ON am.oid = c.relam
+		CROSS JOIN LATERAL unnest(i.indkey::int2[]) WITH ORDINALITY AS k(attnum, ord)
+		LEFT JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = k.attnum
+		WHERE i.indrelid = 'events'::regclass AND i.indpred IS NULL AND i.indisvalid
+		GROUP BY i.indexrelid, am.amname
+		HAVING bool_and(a.attname IS NOT NULL)
+	`)
+	var existing []existingIndex
+	if err == nil {
+		existing, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (existingIndex, error) {
+			var index existingIndex
+			err := row.Scan(&index.method, &index.columns)
+			return index, err
+		})
+	}
+	if err != nil {
+		return nil, wrapDatabaseError("missing_indexes", "failed to read the events table's indexes", err)
+	}
+	return uncoveredIndexes(existing), nil
+}
+
+// CreateMissingIndexes creates the indexes MissingIndexes reports and returns the statements it
+// ran. The indexes are built CONCURRENTLY so appends are not blocked, on a pool connection even
+// for a transaction-scoped store; on a large table that takes a while, so run it at a quiet time.
+// A build that fails leaves an INVALID index behind: drop it before retrying
+func (es *eventStore) CreateMissingIndexes(ctx context.Context) ([]string, error) {
+	statements, err := es.MissingIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i, statement := range statements {
+		if _, err := es.poolDB().Exec(ctx, statement); err != nil {
+			return statements[:i], wrapDatabaseError("create_missing_indexes", "failed to create missing index", err)
+		}
+	}
+	return statements, nil
+}
@@ -0,0 +1,31 @@
+package dcb
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestUncoveredIndexes(t *testing.T) {
+	schema := []existingIndex{
+		{method: "btree", columns: []string{"position"}},
+		{method: "btree", columns: []string{"transaction_id", "position"}},
+		{method: "gin", columns: []string{"tags"}},
+		{method: "btree", columns: []string{"type"}},
+	}
+	if got := uncoveredIndexes(schema); !slices.Equal(got, []string{indexChecklist[2].sql}) {
+		t.Errorf("expected only the (type, transaction_id, position) index, got %v", got)
+	}
+
+	// A longer B-tree serves its leading columns; column order matters
+	covered := append(slices.Clone(schema), existingIndex{method: "btree", columns: []string{"type", "transaction_id", "position", "occurred_at"}})
+	if got := uncoveredIndexes(covered); len(got) != 0 {
+		t.Errorf("expected no missing indexes, got %v", got)
+	}
+	reordered := []existingIndex{
+		{method: "btree", columns: []string{"position", "transaction_id"}},
+		{method: "btree", columns: []string{"tags"}},
+	}
+	if got := uncoveredIndexes(reordered); len(got) != len(indexChecklist) {
+		t.Errorf("expected every index missing, got %v", got)
+	}
+}
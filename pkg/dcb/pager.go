@@ -0,0 +1,119 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+)
+
+// =============================================================================
+// Pager
+// =============================================================================
+
+// Pager reads the events matching a query in pages of at most pageSize events,
+// advancing a cursor past the last returned event. It is not safe for concurrent use.
+//
+//	pager := dcb.NewPager(store, query, 500)
+//	for {
+//		events, more, err := pager.Next(ctx)
+//		if err != nil { ... }
+//		handle(events)
+//		if !more { break }
+//		save(pager.Cursor()) // resume later with NewPagerFrom
+//	}
+type Pager struct {
+	store    EventStore
+	query    Query
+	pageSize int
+	after    *Cursor
+	done     bool
+}
+
+// NewPager creates a Pager reading from the beginning of the stream
+func NewPager(store EventStore, query Query, pageSize int) *Pager {
+	return NewPagerFrom(store, query, pageSize, nil)
+}
+
+// NewPagerFrom creates a Pager reading the events after the given cursor (nil = from the beginning)
+// Use it with a cursor saved from Pager.Cursor to resume paging after a restart
+func NewPagerFrom(store EventStore, query Query, pageSize int, after *Cursor) *Pager {
+	var cursor *Cursor
+	if after != nil {
+		copied := *after
+		cursor = &copied
+	}
+	return &Pager{
+		store:    store,
+		query:    query,
+		pageSize: pageSize,
+		after:    cursor,
+	}
+}
+
+// Next reads the next page. more is false once a page shorter than pageSize was returned
+// (the last page); further calls then return no events. On error the cursor is not
+// advanced, so Next can be retried
+func (p *Pager) Next(ctx context.Context) (events []Event, more bool, err error) {
+	if p.pageSize <= 0 {
+		return nil, false, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "pager_next",
+				Err: fmt.Errorf("page size must be positive, got %d", p.pageSize),
+			},
+			Field: "pageSize",
+			Value: fmt.Sprintf("%d", p.pageSize),
+		}
+	}
+	if p.done {
+		return nil, false, nil
+	}
+
+	events, err = queryPage(ctx, p.store, p.query, p.after, p.pageSize)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(events) > 0 {
+		last := events[len(events)-1]
+		p.after = &Cursor{TransactionID: last.TransactionID, Position: last.Position}
+	}
+	if len(events) < p.pageSize {
+		p.done = true
+	}
+	return events, !p.done, nil
+}
+
+// Cursor returns the position after the last event returned so far (the cursor passed in
+// to NewPagerFrom if no event was returned yet, nil when starting from the beginning)
+func (p *Pager) Cursor() *Cursor {
+	if p.after == nil {
+		return nil
+	}
+	cursor := *p.after
+	return &cursor
+}
+
+// pageQuerier is implemented by event stores (and decorators) that can read a limited page
+type pageQuerier interface {
+	queryPage(ctx context.Context, query Query, after *Cursor, limit int) ([]Event, error)
+}
+
+// queryPage reads at most limit events after the cursor through the given store
+func queryPage(ctx context.Context, store EventStore, query Query, after *Cursor, limit int) ([]Event, error) {
+	querier, ok := store.(pageQuerier)
+	if !ok {
+		return nil, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "pager_next",
+				Err: fmt.Errorf("unsupported EventStore implementation %T", store),
+			},
+			Field: "eventStore",
+			Value: fmt.Sprintf("%T", store),
+		}
+	}
+	return querier.queryPage(ctx, query, after, limit)
+}
+
+// queryPage implements pageQuerier
+func (es *eventStore) queryPage(ctx context.Context, query Query, after *Cursor, limit int) ([]Event, error) {
+	return es.queryLimit(ctx, query, after, &limit)
+}
@@ -0,0 +1,112 @@
+package dcb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// slicePageStore serves pages from an in-memory event slice ordered by (transaction_id, position)
+type slicePageStore struct {
+	EventStore
+	events []Event
+	calls  int
+}
+
+func (s *slicePageStore) queryPage(ctx context.Context, query Query, after *Cursor, limit int) ([]Event, error) {
+	s.calls++
+	var page []Event
+	for _, event := range s.events {
+		if after != nil && (event.TransactionID < after.TransactionID ||
+			(event.TransactionID == after.TransactionID && event.Position <= after.Position)) {
+			continue
+		}
+		if len(page) == limit {
+			break
+		}
+		page = append(page, event)
+	}
+	return page, nil
+}
+
+func newSlicePageStore(n int) *slicePageStore {
+	store := &slicePageStore{}
+	for i := 1; i <= n; i++ {
+		store.events = append(store.events, Event{Type: "E", TransactionID: uint64(100 + i/2), Position: int64(i)})
+	}
+	return store
+}
+
+func TestPagerReadsAllPages(t *testing.T) {
+	store := newSlicePageStore(5)
+	pager := NewPager(store, NewQueryAll(), 2)
+
+	var sizes []int
+	for {
+		events, more, err := pager.Next(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		sizes = append(sizes, len(events))
+		if !more {
+			break
+		}
+	}
+
+	if len(sizes) != 3 || sizes[0] != 2 || sizes[1] != 2 || sizes[2] != 1 {
+		t.Fatalf("unexpected page sizes %v", sizes)
+	}
+	if cursor := pager.Cursor(); cursor == nil || cursor.Position != 5 {
+		t.Fatalf("cursor should point at the last event, got %+v", cursor)
+	}
+
+	// Exhausted pagers don't query again
+	events, more, err := pager.Next(context.Background())
+	if err != nil || more || len(events) != 0 || store.calls != 3 {
+		t.Fatalf("expected an empty final result without querying, got %d events, more=%v, err=%v, calls=%d", len(events), more, err, store.calls)
+	}
+}
+
+func TestPagerResumesFromSerializedCursor(t *testing.T) {
+	store := newSlicePageStore(4)
+	pager := NewPager(store, NewQueryAll(), 2)
+	if _, _, err := pager.Next(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	saved, err := json.Marshal(pager.Cursor())
+	if err != nil {
+		t.Fatalf("marshal cursor: %v", err)
+	}
+	var restored Cursor
+	if err := json.Unmarshal(saved, &restored); err != nil {
+		t.Fatalf("unmarshal cursor: %v", err)
+	}
+
+	resumed := NewPagerFrom(store, NewQueryAll(), 2, &restored)
+	events, more, err := resumed.Next(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 2 || events[0].Position != 3 || !more {
+		t.Fatalf("expected events 3 and 4 with more pages possible, got %+v (more=%v)", events, more)
+	}
+}
+
+func TestPagerThroughTimeoutDecorator(t *testing.T) {
+	store := WithDefaultTimeouts(newSlicePageStore(1), time.Second, time.Second)
+
+	events, more, err := NewPager(store, NewQueryAll(), 10).Next(context.Background())
+	if err != nil || more || len(events) != 1 {
+		t.Fatalf("expected one event and no more pages, got %d events, more=%v, err=%v", len(events), more, err)
+	}
+}
+
+func TestPagerRejectsInvalidPageSize(t *testing.T) {
+	_, _, err := NewPager(newSlicePageStore(1), NewQueryAll(), 0).Next(context.Background())
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Position Allocation
+// =============================================================================
+
+// PositionAllocator assigns positions to appended events
+// Set EventStoreConfig.PositionAllocator to inject one (e.g. predictable positions in tests or a
+// Snowflake-style allocator in sharded deployments). Without one, the store uses the events
+// position sequence inside the append SQL functions.
+//
+// Contract the allocator must uphold:
+//   - Allocate is called inside the append transaction tx, before the events are inserted;
+//     any state it keeps in the database must be written through tx so it rolls back with the append
+//   - it returns exactly n positions, strictly increasing, in the order of the events
+//   - positions are unique across all events ever stored, including archived ones,
+//     and never collide with positions assigned by the events sequence if both are in use
+//   - positions must be positive
+//
+// Events are read in (transaction_id, position) order, so positions only order events within
+// a transaction; global ordering and the append-condition visibility rules come from transaction ids
+// and do not depend on the allocator. Gaps (e.g. from rolled-back appends) are allowed.
+type PositionAllocator interface {
+	Allocate(ctx context.Context, tx pgx.Tx, n int) ([]int64, error)
+}
+
+// sequencePositionAllocator allocates positions from the events position sequence
+type sequencePositionAllocator struct{}
+
+// NewSequencePositionAllocator returns the default allocator backed by the events position sequence
+// It behaves like leaving EventStoreConfig.PositionAllocator unset but costs an extra round trip;
+// use it to wrap or decorate the default behavior
+func NewSequencePositionAllocator() PositionAllocator {
+	return sequencePositionAllocator{}
+}
+
+// Allocate draws n values from the events position sequence within tx
+func (sequencePositionAllocator) Allocate(ctx context.Context, tx pgx.Tx, n int) ([]int64, error) {
+	rows, err := tx.Query(ctx, `SELECT nextval(pg_get_serial_sequence('events', 'position')) FROM generate_series(1, $1) ORDER BY 1`, n)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int64])
+}
+
+// allocatePositions returns explicit positions for n events, or nil when the sequence default applies
+func (es *eventStore) allocatePositions(ctx context.Context, tx pgx.Tx, n int) ([]int64, error) {
+	if es.config.PositionAllocator == nil {
+		return nil, nil
+	}
+
+	positions, err := es.config.PositionAllocator.Allocate(ctx, tx, n)
+	if err != nil {
+		return nil, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "allocate_positions",
+				Err: fmt.Errorf("position allocator failed: %w", err),
+			},
+			Resource: "position_allocator",
+		}
+	}
+	if err := validateAllocatedPositions(positions, n); err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
+// validateAllocatedPositions checks the allocator contract: n positive, strictly increasing positions
+func validateAllocatedPositions(positions []int64, n int) error {
+	if len(positions) != n {
+		return &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "allocate_positions",
+				Err: fmt.Errorf("position allocator returned %d positions for %d events", len(positions), n),
+			},
+			Field: "positionAllocator",
+			Value: fmt.Sprintf("%d", len(positions)),
+		}
+	}
+	for i, position := range positions {
+		if position <= 0 || (i > 0 && position <= positions[i-1]) {
+			return &ValidationError{
+				EventStoreError: EventStoreError{
+					Op:  "allocate_positions",
+					Err: fmt.Errorf("position allocator returned invalid position %d at index %d (must be positive and strictly increasing)", position, i),
+				},
+				Field: "positionAllocator",
+				Value: fmt.Sprintf("%d", position),
+			}
+		}
+	}
+	return nil
+}
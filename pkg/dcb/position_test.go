@@ -0,0 +1,33 @@
+package dcb
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateAllocatedPositions(t *testing.T) {
+	tests := []struct {
+		name      string
+		positions []int64
+		n         int
+		wantErr   bool
+	}{
+		{"valid", []int64{10, 11, 20}, 3, false},
+		{"wrong count", []int64{10, 11}, 3, true},
+		{"not increasing", []int64{10, 10}, 2, true},
+		{"decreasing", []int64{11, 10}, 2, true},
+		{"not positive", []int64{0, 1}, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAllocatedPositions(tt.positions, tt.n)
+			if tt.wantErr && !errors.Is(err, ErrValidation) {
+				t.Errorf("expected validation error, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package dcb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// =============================================================================
+// Producer Tag
+// =============================================================================
+
+// producerContextKey carries the per-append producer set by WithProducer
+type producerContextKey struct{}
+
+// WithProducer overrides EventStoreConfig.ProducerTag for appends made with the returned context,
+// e.g. a shared worker appending on behalf of another service. producer has the same "key:value"
+// form; an empty producer attaches nothing to those appends
+func WithProducer(ctx context.Context, producer string) context.Context {
+	return context.WithValue(ctx, producerContextKey{}, producer)
+}
+
+// producer is a parsed "key:value" producer tag
+type producer struct {
+	key   string
+	value string
+}
+
+// parseProducer parses a ProducerTag or WithProducer value; empty means no producer (nil)
+func parseProducer(op, raw string) (*producer, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	key, value, ok := strings.Cut(raw, ":")
+	if !ok || key == "" || value == "" {
+		return nil, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  op,
+				Err: fmt.Errorf("producer tag must have the form key:value, got %q", raw),
+			},
+			Field: "producerTag",
+			Value: raw,
+		}
+	}
+	return &producer{key: key, value: value}, nil
+}
+
+// appendProducer returns the producer attached to the events appended with ctx:
+// the WithProducer override if present, otherwise EventStoreConfig.ProducerTag
+func (es *eventStore) appendProducer(ctx context.Context) (*producer, error) {
+	if raw, ok := ctx.Value(producerContextKey{}).(string); ok {
+		return parseProducer("appendInTx", raw)
+	}
+	return parseProducer("appendInTx", es.config.ProducerTag)
+}
+
+// withProducerMetadata adds the producer to an event's metadata under its key (see withMetadataField)
+func (p *producer) withProducerMetadata(metadata []byte) []byte {
+	return withMetadataField(metadata, p.key, p.value)
+}
+
+// withMetadataField adds key with a JSON-encoded value to an event's metadata
+// Metadata the caller already set under that key wins, and metadata that isn't a JSON object
+// is left unchanged
+func withMetadataField(metadata []byte, key string, value any) []byte {
+	fields := map[string]json.RawMessage{}
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &fields); err != nil || fields == nil {
+			return metadata
+		}
+		if _, ok := fields[key]; ok {
+			return metadata
+		}
+	}
+	fields[key], _ = json.Marshal(value)
+	merged, err := json.Marshal(fields)
+	if err != nil {
+		return metadata
+	}
+	return merged
+}
+
+// withProducerTag adds the producer as a "key:value" tag unless the event already has a tag
+// with the producer's key
+func (p *producer) withProducerTag(tags []string) []string {
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, p.key+":") {
+			return tags
+		}
+	}
+	return append(tags, p.key+":"+p.value)
+}
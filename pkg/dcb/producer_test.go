@@ -0,0 +1,74 @@
+package dcb
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestParseProducer(t *testing.T) {
+	p, err := parseProducer("test", "source:orders-service")
+	if err != nil || p == nil || p.key != "source" || p.value != "orders-service" {
+		t.Fatalf("expected source/orders-service, got %+v, %v", p, err)
+	}
+	if p, err := parseProducer("test", ""); p != nil || err != nil {
+		t.Fatalf("expected no producer, got %+v, %v", p, err)
+	}
+	for _, raw := range []string{"orders-service", ":orders", "source:"} {
+		if _, err := parseProducer("test", raw); !IsValidationError(err) {
+			t.Fatalf("expected ValidationError for %q, got %v", raw, err)
+		}
+	}
+}
+
+func TestAppendProducer(t *testing.T) {
+	es := &eventStore{config: EventStoreConfig{ProducerTag: "source:orders"}}
+
+	p, err := es.appendProducer(context.Background())
+	if err != nil || p.value != "orders" {
+		t.Fatalf("expected the configured producer, got %+v, %v", p, err)
+	}
+	p, err = es.appendProducer(WithProducer(context.Background(), "source:billing"))
+	if err != nil || p.value != "billing" {
+		t.Fatalf("expected the override, got %+v, %v", p, err)
+	}
+	if p, err := es.appendProducer(WithProducer(context.Background(), "")); p != nil || err != nil {
+		t.Fatalf("expected an empty override to attach nothing, got %+v, %v", p, err)
+	}
+	if _, err := es.appendProducer(WithProducer(context.Background(), "billing")); !IsValidationError(err) {
+		t.Fatalf("expected ValidationError for a malformed override, got %v", err)
+	}
+}
+
+func TestProducerMetadataAndTag(t *testing.T) {
+	p := &producer{key: "source", value: "orders"}
+
+	tests := []struct {
+		name     string
+		metadata string
+		want     string
+	}{
+		{name: "no metadata", metadata: "", want: `{"source":"orders"}`},
+		{name: "merged", metadata: `{"causation_position":7}`, want: `{"causation_position":7,"source":"orders"}`},
+		{name: "caller wins", metadata: `{"source":"import"}`, want: `{"source":"import"}`},
+		{name: "not an object", metadata: `[1,2]`, want: `[1,2]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var metadata []byte
+			if tt.metadata != "" {
+				metadata = []byte(tt.metadata)
+			}
+			if got := string(p.withProducerMetadata(metadata)); got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	if got := p.withProducerTag([]string{"order_id:o1"}); !slices.Equal(got, []string{"order_id:o1", "source:orders"}) {
+		t.Fatalf("expected the producer tag appended, got %v", got)
+	}
+	if got := p.withProducerTag([]string{"source:import"}); !slices.Equal(got, []string{"source:import"}) {
+		t.Fatalf("expected the event's own tag to win, got %v", got)
+	}
+}
@@ -0,0 +1,111 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// =============================================================================
+// Parallel Projections
+// =============================================================================
+
+// DefaultProjectionWorkers is the number of projections ProjectMany runs at once when
+// EventStoreConfig.ProjectionWorkers is not set
+const DefaultProjectionWorkers = 4
+
+// ProjectManyResult is the outcome of one projector set passed to ProjectMany
+type ProjectManyResult struct {
+	States    map[string]any  // Final states keyed by projector ID, as returned by Project
+	Condition AppendCondition // Condition for a decision based on States
+}
+
+// ProjectMany runs Project for each projector set, at most EventStoreConfig.ProjectionWorkers at a
+// time, and returns the results in the order of projectorSets. Use it to load several independent
+// decision models (e.g. one per entity on a page) without one query per set in sequence.
+// Every set is validated before any query runs. The first error cancels the projections still
+// running and is returned without results. Each running projection takes a slot of
+// MaxConcurrentProjections, so workers are capped at that limit; inside WithTransaction the sets
+// are projected one at a time because the transaction runs one query at a time
+func (es *eventStore) ProjectMany(ctx context.Context, projectorSets [][]StateProjector) ([]ProjectManyResult, error) {
+	for i, projectors := range projectorSets {
+		if err := validateProjectors("ProjectMany", projectors); err != nil {
+			if verr, ok := GetValidationError(err); ok {
+				verr.Err = fmt.Errorf("projector set %d: %w", i, verr.Err)
+			}
+			return nil, err
+		}
+	}
+	results := make([]ProjectManyResult, len(projectorSets))
+	if len(projectorSets) == 0 {
+		return results, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	jobs := make(chan int)
+	for range es.projectionWorkers(len(projectorSets)) {
+		wg.Go(func() {
+			for i := range jobs {
+				states, condition, err := es.Project(ctx, projectorSets[i], nil)
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+						cancel() // Stop the sibling projections
+					}
+					mu.Unlock()
+					continue
+				}
+				results[i] = ProjectManyResult{States: states, Condition: condition}
+			}
+		})
+	}
+
+	fed := 0
+feed:
+	for i := range projectorSets {
+		select {
+		case jobs <- i:
+			fed++
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if fed < len(projectorSets) {
+		// The caller's context ended before every set was handed to a worker
+		return nil, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "ProjectMany",
+				Err: fmt.Errorf("projection cancelled: %w", ctx.Err()),
+			},
+			Resource: "database",
+		}
+	}
+	return results, nil
+}
+
+// projectionWorkers is the number of workers ProjectMany starts for sets projector sets
+func (es *eventStore) projectionWorkers(sets int) int {
+	if es.scope != nil {
+		return 1
+	}
+	workers := es.config.ProjectionWorkers
+	if workers <= 0 {
+		workers = DefaultProjectionWorkers
+	}
+	workers = min(workers, es.config.MaxConcurrentProjections, sets)
+	return max(workers, 1)
+}
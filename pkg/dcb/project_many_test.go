@@ -0,0 +1,57 @@
+package dcb
+
+import (
+	"context"
+	"testing"
+)
+
+func TestProjectManyValidatesEverySetFirst(t *testing.T) {
+	es := newEventStore(nil, EventStoreConfig{})
+	valid := StateProjector{
+		ID:           "count",
+		Query:        NewQuery(NewTags("course_id", "c1")),
+		InitialState: 0,
+		TransitionFn: func(state any, _ Event) any { return state },
+	}
+	invalid := valid
+	invalid.TransitionFn = nil
+
+	// Validation fails before the nil pool is used
+	_, err := es.ProjectMany(context.Background(), [][]StateProjector{{valid}, {invalid}})
+	if !IsValidationError(err) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+
+	results, err := es.ProjectMany(context.Background(), nil)
+	if err != nil || len(results) != 0 {
+		t.Fatalf("expected no results for no sets, got %v, %v", results, err)
+	}
+}
+
+func TestProjectionWorkers(t *testing.T) {
+	tests := []struct {
+		name   string
+		config EventStoreConfig
+		sets   int
+		want   int
+	}{
+		{"default", EventStoreConfig{}, 10, DefaultProjectionWorkers},
+		{"configured", EventStoreConfig{ProjectionWorkers: 8}, 10, 8},
+		{"fewer sets", EventStoreConfig{ProjectionWorkers: 8}, 3, 3},
+		{"capped by projection slots", EventStoreConfig{ProjectionWorkers: 8, MaxConcurrentProjections: 2}, 10, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es := newEventStore(nil, tt.config)
+			if got := es.projectionWorkers(tt.sets); got != tt.want {
+				t.Errorf("expected %d workers, got %d", tt.want, got)
+			}
+		})
+	}
+
+	scoped := newEventStore(nil, EventStoreConfig{ProjectionWorkers: 8})
+	scoped.scope = &txScope{}
+	if got := scoped.projectionWorkers(10); got != 1 {
+		t.Errorf("expected one worker inside a transaction, got %d", got)
+	}
+}
@@ -0,0 +1,186 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+)
+
+// =============================================================================
+// Progressive Projection
+// =============================================================================
+
+// ProjectionSnapshot is one emission of ProjectProgressive
+type ProjectionSnapshot struct {
+	// States are copies of the projector states after Events events, safe to read and keep
+	// while the projection goes on
+	States map[string]any
+	// Events is the number of events folded so far
+	Events int
+	// Cursor is the last event folded, nil before the first one
+	Cursor *Cursor
+	// Final marks the last emission, sent once every event was folded
+	Final bool
+	// Condition is set on the final emission, as Project returns it
+	Condition AppendCondition
+	// Err is set on a final emission when the projection failed; States are those before the failure
+	Err error
+}
+
+// ProjectProgressive projects like ProjectStream, in a single scan, and emits a snapshot of the
+// states every `every` events plus a final one with the append condition, e.g. for a UI showing an
+// aggregate while a long rebuild runs. Snapshot states are deep copies (maps, slices, pointers and
+// exported struct fields), so they don't race with the ongoing fold; unexported reference fields
+// of struct states stay shared. The channel is buffered by StreamBuffer and closed after the final
+// snapshot, or without one when ctx ends. Like ProjectStream it holds a projection slot until then
+func (es *eventStore) ProjectProgressive(ctx context.Context, projectors []StateProjector, every int) (<-chan ProjectionSnapshot, error) {
+	if every <= 0 {
+		return nil, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "ProjectProgressive",
+				Err: fmt.Errorf("emission interval must be positive, got %d", every),
+			},
+			Field: "every",
+			Value: fmt.Sprintf("%d", every),
+		}
+	}
+
+	rows, query, err := es.openProjectionStream(ctx, "ProjectProgressive", projectors, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make(chan ProjectionSnapshot, es.config.StreamBuffer)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("ProjectProgressive panic recovered: %v", r)
+			}
+			rows.Close()
+			close(snapshots)
+			es.projectionSemaphore <- struct{}{}
+		}()
+
+		started := es.clock().Now()
+		fold := newProjectionFold(projectors)
+		fold.maxStateBytes = int64(es.config.MaxProjectionStateBytes)
+
+		// emit sends a snapshot unless ctx ended, reporting whether it was sent
+		emit := func(snapshot ProjectionSnapshot) bool {
+			select {
+			case snapshots <- snapshot:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		events := 0
+		var last *Cursor
+		cancelled := false
+		latestCursor, err := foldProjectionRows(ctx, rows, fold, func(event Event) {
+			events++
+			last = &Cursor{TransactionID: event.TransactionID, Position: event.Position}
+			if events%every == 0 && !cancelled {
+				cancelled = !emit(ProjectionSnapshot{States: copyStates(fold.states), Events: events, Cursor: last})
+			}
+		})
+		if cancelled || ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			emit(ProjectionSnapshot{States: copyStates(fold.states), Events: events, Cursor: last, Final: true, Err: err})
+			return
+		}
+
+		if latestCursor, err = es.stoppedStreamHead(ctx, rows, fold, "ProjectProgressive", query, latestCursor); err != nil {
+			emit(ProjectionSnapshot{States: copyStates(fold.states), Events: events, Cursor: last, Final: true, Err: err})
+			return
+		}
+
+		fold.stats.Op = "ProjectProgressive"
+		fold.stats.Duration = es.clock().Now().Sub(started)
+		es.reportProjectionStats(fold.stats)
+
+		condition := BuildAppendConditionFromQuery(query)
+		condition.setAfterCursor(latestCursor)
+		emit(ProjectionSnapshot{States: fold.states, Events: events, Cursor: latestCursor, Final: true, Condition: condition})
+	}()
+
+	return snapshots, nil
+}
+
+// copyStates deep-copies projector states for a snapshot (see copyState)
+func copyStates(states map[string]any) map[string]any {
+	copied := make(map[string]any, len(states))
+	for id, state := range states {
+		copied[id] = copyState(state)
+	}
+	return copied
+}
+
+// copyState returns a deep copy of state: maps, slices, arrays, pointers, interfaces and exported
+// struct fields are copied recursively; other values (including unexported fields) are copied as is
+func copyState(state any) any {
+	if state == nil {
+		return nil
+	}
+	return copyValue(reflect.ValueOf(state)).Interface()
+}
+
+// copyValue deep-copies v (see copyState)
+func copyValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), copyValue(iter.Value()))
+		}
+		return copied
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			copied.Index(i).Set(copyValue(v.Index(i)))
+		}
+		return copied
+	case reflect.Array:
+		copied := reflect.New(v.Type()).Elem()
+		for i := range v.Len() {
+			copied.Index(i).Set(copyValue(v.Index(i)))
+		}
+		return copied
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(copyValue(v.Elem()))
+		return copied
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(copyValue(v.Elem()))
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		for i := range v.NumField() {
+			if copied.Field(i).CanSet() {
+				copied.Field(i).Set(copyValue(v.Field(i)))
+			}
+		}
+		return copied
+	default:
+		return v
+	}
+}
@@ -0,0 +1,58 @@
+package dcb
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestCopyState(t *testing.T) {
+	type course struct {
+		Name     string
+		Students map[string]bool
+		Waiting  []string
+		Stats    *struct{ Enrolled int }
+	}
+	original := course{
+		Name:     "CS101",
+		Students: map[string]bool{"s1": true},
+		Waiting:  []string{"s2"},
+		Stats:    &struct{ Enrolled int }{Enrolled: 1},
+	}
+
+	copied := copyState(original).(course)
+	if !reflect.DeepEqual(copied, original) {
+		t.Fatalf("expected an equal copy, got %+v", copied)
+	}
+
+	// Mutating the original (as the ongoing fold does) must not show in the copy
+	original.Students["s3"] = true
+	original.Waiting[0] = "s4"
+	original.Stats.Enrolled = 2
+	if len(copied.Students) != 1 || copied.Waiting[0] != "s2" || copied.Stats.Enrolled != 1 {
+		t.Errorf("expected the copy to be independent, got %+v (stats %+v)", copied, *copied.Stats)
+	}
+
+	states := map[string]any{"counts": map[string]any{"a": []any{1, 2}}, "none": nil, "total": 3}
+	copiedStates := copyStates(states)
+	states["counts"].(map[string]any)["a"].([]any)[0] = 9
+	if got := copiedStates["counts"].(map[string]any)["a"].([]any)[0]; got != 1 {
+		t.Errorf("expected nested values to be copied, got %v", got)
+	}
+	if copiedStates["none"] != nil || copiedStates["total"] != 3 {
+		t.Errorf("expected nil and scalar states as is, got %v", copiedStates)
+	}
+}
+
+func TestProjectProgressiveValidatesInterval(t *testing.T) {
+	es := newEventStore(nil, EventStoreConfig{})
+	projector := StateProjector{
+		ID:           "count",
+		Query:        NewQuery(NewTags("course_id", "c1")),
+		InitialState: 0,
+		TransitionFn: func(state any, _ Event) any { return state },
+	}
+	if _, err := es.ProjectProgressive(context.Background(), []StateProjector{projector}, 0); !IsValidationError(err) {
+		t.Fatalf("expected ValidationError for a zero interval, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+)
+
+// =============================================================================
+// Projection Trace
+// =============================================================================
+
+// StateAtPosition is one step of a ProjectTrace: an event the projector consumed and its state
+// right after it
+type StateAtPosition struct {
+	Position   int64 // Position of Event
+	Event      Event // The event the projector's TransitionFn was called with
+	StateAfter any   // Copy of the state returned for Event (see ProjectProgressive for what is copied)
+}
+
+// ProjectTrace projects a single projector like Project and returns the state after every event
+// it consumed, in order, e.g. to find the event that put a decision model into an unexpected
+// state. Events read after its StopFn reported a final state don't appear. Every step keeps a
+// deep copy of the state, so memory grows with the number of events times the state size: it is
+// a debugging tool, use Project otherwise. Without matching events the trace is empty.
+// A failed projection returns no trace
+func (es *eventStore) ProjectTrace(ctx context.Context, projector StateProjector) ([]StateAtPosition, error) {
+	projectors := []StateProjector{projector}
+	rows, _, err := es.openProjectionStream(ctx, "ProjectTrace", projectors, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		rows.Close()
+		es.projectionSemaphore <- struct{}{}
+	}()
+
+	fold := newProjectionFold(projectors)
+	fold.maxStateBytes = int64(es.config.MaxProjectionStateBytes)
+
+	trace := []StateAtPosition{}
+	consumed := 0
+	_, err = foldProjectionRows(ctx, rows, fold, func(event Event) {
+		// The fold counts the events each projector applied; an unchanged count means skipped
+		if applied := fold.stats.EventsByProjector[projector.ID]; applied > consumed {
+			consumed = applied
+			trace = append(trace, StateAtPosition{
+				Position:   event.Position,
+				Event:      event,
+				StateAfter: copyState(fold.states[projector.ID]),
+			})
+		}
+	})
+	if err != nil {
+		if IsResourceError(err) {
+			return nil, err
+		}
+		return nil, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "ProjectTrace",
+				Err: fmt.Errorf("projection failed: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	return trace, nil
+}
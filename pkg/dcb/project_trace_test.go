@@ -0,0 +1,22 @@
+package dcb
+
+import (
+	"context"
+	"testing"
+)
+
+func TestProjectTraceValidatesProjector(t *testing.T) {
+	// es has no pool: an invalid projector must be rejected before the database
+	es := newEventStore(nil, EventStoreConfig{MaxConcurrentProjections: 1})
+	projector := StateProjector{ID: "count", Query: NewQuery(NewTags("course_id", "c1")), InitialState: 0}
+	if _, err := es.ProjectTrace(context.Background(), projector); !IsValidationError(err) {
+		t.Fatalf("expected ValidationError for a nil transition function, got %v", err)
+	}
+
+	// The projection slot is released after the rejection
+	projector.TransitionFn = func(state any, _ Event) any { return state }
+	projector.Query = NewQueryEmpty()
+	if _, err := es.ProjectTrace(context.Background(), projector); !IsValidationError(err) {
+		t.Fatalf("expected ValidationError for an empty query, got %v", err)
+	}
+}
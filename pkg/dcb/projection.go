@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -21,6 +22,26 @@ type StateProjector struct {
 	Query        Query                            `json:"query"`
 	InitialState any                              `json:"initial_state"`
 	TransitionFn func(state any, event Event) any `json:"-"`
+	// StopFn optionally reports that the state is final (e.g. "exists" became true): the projector
+	// ignores further events, and once every projector has stopped the store stops reading rows.
+	// The returned append condition still covers the whole query: its cursor is the latest matching
+	// event (one extra single-row read), so a later AppendIf only fails on events appended after
+	// the projection. nil means fold all events
+	StopFn func(state any) bool `json:"-"`
+	// AppliesTo optionally lists the event types TransitionFn reacts to. Other events matching
+	// Query are still read, and still count for the append condition, but TransitionFn isn't
+	// called for them: the "query broad, react narrow" case of a condition covering more types than
+	// the fold needs. Empty (default) applies every matching event
+	AppliesTo []string `json:"applies_to,omitempty"`
+
+	existsOnly bool // Set by NewExistsProjector; allows LIMIT 1 reads
+}
+
+// Reducer is a named sub-fold used by ComposeProjectors
+// Each reducer keeps its own state inside the composed projector's map state
+type Reducer struct {
+	InitialState any
+	TransitionFn func(state any, event Event) any
 }
 
 // rowEvent is a helper struct for scanning database rows.
@@ -31,10 +52,22 @@ type rowEvent struct {
 	Position      int64
 	TransactionID uint64
 	OccurredAt    time.Time
+	Metadata      []byte
+	// CommandTransactionID is NULL for events not appended by ExecuteCommand
+	CommandTransactionID *uint64
+}
+
+// scanTargets returns the destinations of the eventColumns, in order
+func (row *rowEvent) scanTargets() []any {
+	return []any{&row.Type, &row.Tags, &row.Data, &row.TransactionID, &row.Position, &row.OccurredAt, &row.Metadata, &row.CommandTransactionID}
 }
 
 // convertRowToEvent converts a database row to an Event
 func convertRowToEvent(row rowEvent) Event {
+	var commandTxID uint64
+	if row.CommandTransactionID != nil {
+		commandTxID = *row.CommandTransactionID
+	}
 	return Event{
 		Type:          row.Type,
 		Tags:          ParseTagsArray(row.Tags),
@@ -42,11 +75,41 @@ func convertRowToEvent(row rowEvent) Event {
 		Position:      row.Position,
 		TransactionID: row.TransactionID,
 		OccurredAt:    row.OccurredAt,
+		Metadata:      row.Metadata,
+		CommandTxID:   commandTxID,
 	}
 }
 
+// readOrderBy is the ordering clause buildReadQuerySQL appends (before any LIMIT)
+const readOrderBy = " ORDER BY transaction_id ASC, position ASC"
+
 // buildReadQuerySQL builds the SQL query for reading events
 func (es *eventStore) buildReadQuerySQL(query Query, after *Cursor, limit *int) (string, []interface{}, error) {
+	return es.buildReadQuerySQLFrom(es.eventsSource(), query, after, limit)
+}
+
+// buildReadQuerySQLFrom builds the SQL query for reading events from source (a table or subquery)
+// Queries of a shape seen before reuse its SQL text from the store's cache (see querySQLCache)
+func (es *eventStore) buildReadQuerySQLFrom(source string, query Query, after *Cursor, limit *int) (string, []interface{}, error) {
+	if es.querySQL == nil || es.config.DisableQuerySQLCache {
+		return es.renderReadQuerySQL(source, query, after, limit)
+	}
+	shape, args, ok := es.readQueryShape(source, query, after, limit)
+	if ok {
+		if sqlQuery, hit := es.querySQL.get(shape); hit {
+			return sqlQuery, args, nil
+		}
+	}
+	sqlQuery, args, err := es.renderReadQuerySQL(source, query, after, limit)
+	if err == nil && ok {
+		es.querySQL.put(shape, sqlQuery)
+	}
+	return sqlQuery, args, err
+}
+
+// renderReadQuerySQL generates the SQL query for reading events from source and its arguments.
+// readQueryShape must add the same arguments in the same order
+func (es *eventStore) renderReadQuerySQL(source string, query Query, after *Cursor, limit *int) (string, []interface{}, error) {
 	// Pre-allocate slices with reasonable capacity
 	conditions := make([]string, 0, 4) // Usually 1-4 conditions
 	args := make([]interface{}, 0, 8)  // Usually 2-8 args
@@ -74,6 +137,74 @@ func (es *eventStore) buildReadQuerySQL(query Query, after *Cursor, limit *int)
 				argIndex++
 			}
 
+			// Add any-tag-value conditions - overlap operator (&&) is served by the tags GIN index
+			if qi, ok := asQueryItem(item); ok {
+				for _, anyTags := range qi.AnyTags {
+					andConditions = append(andConditions, fmt.Sprintf("tags && $%d::text[]", argIndex))
+					args = append(args, TagsToArray(anyTags))
+					argIndex++
+				}
+			}
+
+			// Add missing-key conditions (WithoutTagKey) - tags are stored as "key:value"
+			if qi, ok := asQueryItem(item); ok {
+				for _, key := range qi.WithoutTagKeys {
+					andConditions = append(andConditions,
+						fmt.Sprintf("NOT EXISTS (SELECT 1 FROM unnest(tags) AS t WHERE starts_with(t, $%d::text))", argIndex))
+					args = append(args, key+":")
+					argIndex++
+				}
+			}
+
+			// Add case-insensitive tag conditions (WithTagCI)
+			if qi, ok := asQueryItem(item); ok {
+				for _, ciTag := range qi.CITags {
+					condition, ciArgs := es.caseInsensitiveTagCondition(ciTag, argIndex)
+					andConditions = append(andConditions, condition)
+					args = append(args, ciArgs...)
+					argIndex += len(ciArgs)
+				}
+			}
+
+			// Add numeric tag comparisons (WithTagNumeric)
+			if qi, ok := asQueryItem(item); ok {
+				for _, numericTag := range qi.NumericTags {
+					condition, numericArgs, err := es.numericTagCondition(numericTag, argIndex)
+					if err != nil {
+						return "", nil, err
+					}
+					andConditions = append(andConditions, condition)
+					args = append(args, numericArgs...)
+					argIndex += len(numericArgs)
+				}
+			}
+
+			// Add causation condition - matches the metadata written by EventBuilder.CausedBy
+			if qi, ok := asQueryItem(item); ok && qi.CausedBy != nil {
+				andConditions = append(andConditions, fmt.Sprintf("metadata @> $%d::jsonb", argIndex))
+				args = append(args, causationMetadataFilter(*qi.CausedBy))
+				argIndex++
+			}
+
+			// Add position range conditions - served by the position primary key
+			if qi, ok := asQueryItem(item); ok && qi.FromPosition != nil {
+				andConditions = append(andConditions, fmt.Sprintf("position >= $%d", argIndex))
+				args = append(args, *qi.FromPosition)
+				argIndex++
+			}
+			if qi, ok := asQueryItem(item); ok && qi.ToPosition != nil {
+				andConditions = append(andConditions, fmt.Sprintf("position <= $%d", argIndex))
+				args = append(args, *qi.ToPosition)
+				argIndex++
+			}
+
+			// Add transaction condition - served by the (transaction_id, position) index
+			if qi, ok := asQueryItem(item); ok && qi.TransactionID != nil {
+				andConditions = append(andConditions, fmt.Sprintf("transaction_id = $%d", argIndex))
+				args = append(args, *qi.TransactionID)
+				argIndex++
+			}
+
 			// Combine AND conditions for this item
 			if len(andConditions) > 0 {
 				orConditions = append(orConditions, "("+strings.Join(andConditions, " AND ")+")")
@@ -97,7 +228,7 @@ func (es *eventStore) buildReadQuerySQL(query Query, after *Cursor, limit *int)
 
 	// Build final query efficiently
 	var sqlQuery strings.Builder
-	sqlQuery.WriteString("SELECT type, tags, data, transaction_id, position, occurred_at FROM events")
+	sqlQuery.WriteString("SELECT " + eventColumns + " FROM " + source)
 
 	if len(conditions) > 0 {
 		sqlQuery.WriteString(" WHERE ")
@@ -105,7 +236,7 @@ func (es *eventStore) buildReadQuerySQL(query Query, after *Cursor, limit *int)
 	}
 
 	// Use transaction_id ordering for proper event ordering guarantees
-	sqlQuery.WriteString(" ORDER BY transaction_id ASC, position ASC")
+	sqlQuery.WriteString(readOrderBy)
 
 	// Add limit if specified
 	if limit != nil {
@@ -117,6 +248,8 @@ func (es *eventStore) buildReadQuerySQL(query Query, after *Cursor, limit *int)
 
 // CombineProjectorQueries optimizes by merging QueryItems with the same tags but different event types
 // This is useful for consumers who want to optimize their projector queries
+// Extended predicates (causation, any-tag-value) are not carried over, so a combined item can be broader
+// than the projector items it came from; EventMatchesProjector still filters events per projector
 func CombineProjectorQueries(projectors []StateProjector) Query {
 	// Use a map to group QueryItems by their tags (as a key)
 	tagGroups := make(map[string]*queryItem)
@@ -128,7 +261,12 @@ func CombineProjectorQueries(projectors []StateProjector) Query {
 
 			if existingItem, exists := tagGroups[tagKey]; exists {
 				// Merge event types with existing item
-				existingItem.EventTypes = append(existingItem.EventTypes, item.GetEventTypes()...)
+				// An item without event types matches any type, so the merged item must too
+				if len(existingItem.EventTypes) == 0 || len(item.GetEventTypes()) == 0 {
+					existingItem.EventTypes = []string{}
+				} else {
+					existingItem.EventTypes = append(existingItem.EventTypes, item.GetEventTypes()...)
+				}
 			} else {
 				// Create new item
 				tagGroups[tagKey] = &queryItem{
@@ -136,6 +274,9 @@ func CombineProjectorQueries(projectors []StateProjector) Query {
 					Tags:       append([]Tag{}, item.GetTags()...),
 				}
 			}
+			// Only match-all items (NewQueryAll) combine into an item without types and tags
+			merged := tagGroups[tagKey]
+			merged.MatchAll = len(merged.EventTypes) == 0 && len(merged.Tags) == 0
 		}
 	}
 
@@ -165,6 +306,25 @@ func tagsToKey(tags []Tag) string {
 	return strings.Join(tagPairs, ",")
 }
 
+// eventTagSet returns the event's tags as a set of "key:value" strings
+func eventTagSet(event Event) map[string]bool {
+	tags := make(map[string]bool, len(event.Tags))
+	for _, tag := range event.Tags {
+		tags[tag.GetKey()+":"+tag.GetValue()] = true
+	}
+	return tags
+}
+
+// eventHasAnyTagKey reports whether the event carries a tag with one of the keys
+func eventHasAnyTagKey(event Event, keys []string) bool {
+	for _, tag := range event.Tags {
+		if slices.Contains(keys, tag.GetKey()) {
+			return true
+		}
+	}
+	return false
+}
+
 // EventMatchesProjector checks if an event matches a projector's query
 // This is useful for consumers who want to do their own event filtering or validation
 func EventMatchesProjector(event Event, projector StateProjector) bool {
@@ -191,16 +351,14 @@ func EventMatchesProjector(event Event, projector StateProjector) bool {
 
 		// Check tags if specified
 		if len(item.GetTags()) > 0 {
-			// Convert tags to map for easy lookup
-			eventTags := make(map[string]string)
-			for _, tag := range event.Tags {
-				eventTags[tag.GetKey()] = tag.GetValue()
-			}
+			// Use a set of key:value pairs so events carrying several values for one key match
+			// like the SQL containment operator (tags @> ...)
+			eventTags := eventTagSet(event)
 
 			// Check if ALL required tags match
 			allTagsMatch := true
 			for _, requiredTag := range item.GetTags() {
-				if eventTags[requiredTag.GetKey()] != requiredTag.GetValue() {
+				if !eventTags[requiredTag.GetKey()+":"+requiredTag.GetValue()] {
 					allTagsMatch = false
 					break
 				}
@@ -210,6 +368,85 @@ func EventMatchesProjector(event Event, projector StateProjector) bool {
 			}
 		}
 
+		// Check any-tag-value sets if specified (OR within each set, AND across sets)
+		if qi, ok := asQueryItem(item); ok && len(qi.AnyTags) > 0 {
+			eventTags := eventTagSet(event)
+			allSetsMatch := true
+			for _, anyTags := range qi.AnyTags {
+				setMatches := false
+				for _, candidate := range anyTags {
+					if eventTags[candidate.GetKey()+":"+candidate.GetValue()] {
+						setMatches = true
+						break
+					}
+				}
+				if !setMatches {
+					allSetsMatch = false
+					break
+				}
+			}
+			if !allSetsMatch {
+				continue // No listed value matches, try next item
+			}
+		}
+
+		// Check missing tag keys if specified
+		if qi, ok := asQueryItem(item); ok && len(qi.WithoutTagKeys) > 0 {
+			if eventHasAnyTagKey(event, qi.WithoutTagKeys) {
+				continue // The event carries an excluded key, try next item
+			}
+		}
+
+		// Check case-insensitive tags if specified
+		if qi, ok := asQueryItem(item); ok && len(qi.CITags) > 0 {
+			allMatch := true
+			for _, ciTag := range qi.CITags {
+				if !matchesTagCI(event, ciTag) {
+					allMatch = false
+					break
+				}
+			}
+			if !allMatch {
+				continue // A case-insensitive tag doesn't match, try next item
+			}
+		}
+
+		// Check numeric tag comparisons if specified
+		if qi, ok := asQueryItem(item); ok && len(qi.NumericTags) > 0 {
+			allMatch := true
+			for _, numericTag := range qi.NumericTags {
+				if !matchesNumericTag(event, numericTag) {
+					allMatch = false
+					break
+				}
+			}
+			if !allMatch {
+				continue // A numeric tag comparison fails, try next item
+			}
+		}
+
+		// Check causation if specified
+		if qi, ok := asQueryItem(item); ok && qi.CausedBy != nil {
+			if position, ok := event.CausationPosition(); !ok || position != *qi.CausedBy {
+				continue // Causation doesn't match, try next item
+			}
+		}
+
+		// Check position range if specified
+		if qi, ok := asQueryItem(item); ok {
+			if qi.FromPosition != nil && event.Position < *qi.FromPosition {
+				continue // Before the range, try next item
+			}
+			if qi.ToPosition != nil && event.Position > *qi.ToPosition {
+				continue // After the range, try next item
+			}
+		}
+
+		// Check transaction if specified
+		if qi, ok := asQueryItem(item); ok && qi.TransactionID != nil && event.TransactionID != *qi.TransactionID {
+			continue // Appended in another transaction, try next item
+		}
+
 		// If we get here, this item matches
 		return true
 	}
@@ -222,7 +459,15 @@ func EventMatchesProjector(event Event, projector StateProjector) bool {
 // cursor == nil: project from beginning of stream
 // cursor != nil: project from specified cursor position
 // Returns final aggregated states and append condition for DCB concurrency control
+// With ReturnPartialOnCancel, a cancelled ctx yields the states folded so far and a PartialResultError
 func (es *eventStore) Project(ctx context.Context, projectors []StateProjector, after *Cursor) (map[string]any, AppendCondition, error) {
+	states, appendCondition, _, err := es.ProjectWithStats(ctx, projectors, after)
+	return states, appendCondition, err
+}
+
+// ProjectWithStats is Project that also returns how many events and bytes the projection read
+// The same stats are passed to EventStoreConfig.OnProjectionStats when it is set
+func (es *eventStore) ProjectWithStats(ctx context.Context, projectors []StateProjector, after *Cursor) (map[string]any, AppendCondition, ProjectionStats, error) {
 	// Acquire projection semaphore with fail-fast behavior
 	select {
 	case <-es.projectionSemaphore:
@@ -230,7 +475,7 @@ func (es *eventStore) Project(ctx context.Context, projectors []StateProjector,
 		defer func() { es.projectionSemaphore <- struct{}{} }() // Release slot when done
 	default:
 		// No semaphore available - fail fast instead of blocking
-		return nil, nil, &TooManyProjectionsError{
+		return nil, nil, ProjectionStats{}, &TooManyProjectionsError{
 			EventStoreError: EventStoreError{
 				Op:  "Project",
 				Err: fmt.Errorf("too many concurrent projections"),
@@ -241,21 +486,56 @@ func (es *eventStore) Project(ctx context.Context, projectors []StateProjector,
 	}
 
 	// Validate projectors
-	for _, bp := range projectors {
-		if bp.ID == "" {
-			return nil, nil, &ValidationError{
-				EventStoreError: EventStoreError{
-					Op:  "Project",
-					Err: fmt.Errorf("projector ID cannot be empty"),
-				},
-				Field: "projector.id",
-				Value: "empty",
-			}
+	if err := validateProjectors("Project", projectors); err != nil {
+		return nil, nil, ProjectionStats{}, err
+	}
+
+	// Combine all projector queries for the append condition
+	combinedQuery := CombineProjectorQueries(projectors)
+
+	// Use cursor-based or full projection based on cursor parameter
+	started := es.clock().Now()
+	var (
+		states          map[string]any
+		appendCondition AppendCondition
+		stats           ProjectionStats
+		err             error
+	)
+	if after != nil {
+		states, appendCondition, stats, err = es.projectDecisionModelWithQueryFromCursor(ctx, combinedQuery, projectors, after)
+	} else {
+		states, appendCondition, stats, err = es.projectDecisionModelWithQuery(ctx, combinedQuery, projectors)
+	}
+	if partial, ok := err.(*PartialResultError); ok {
+		// ReturnPartialOnCancel: hand back what was folded; stats only report complete projections
+		stats.Op = "Project"
+		stats.Duration = es.clock().Now().Sub(started)
+		return states, appendCondition, stats, partial
+	}
+	if err != nil {
+		// Name the combined query, so a failed projection logs what it was reading
+		if resourceErr, ok := GetResourceError(err); ok {
+			resourceErr.Err = fmt.Errorf("%w (query %s)", resourceErr.Err, combinedQuery)
 		}
+		return nil, nil, ProjectionStats{}, err
+	}
+
+	stats.Op = "Project"
+	stats.Duration = es.clock().Now().Sub(started)
+	es.reportProjectionStats(stats)
+	return states, appendCondition, stats, nil
+}
+
+// validateProjectors checks that every projector has a unique ID, a transition function and a non-empty query
+func validateProjectors(op string, projectors []StateProjector) error {
+	if err := validateProjectorIDs(op, projectors); err != nil {
+		return err
+	}
+	for _, bp := range projectors {
 		if bp.TransitionFn == nil {
-			return nil, nil, &ValidationError{
+			return &ValidationError{
 				EventStoreError: EventStoreError{
-					Op:  "Project",
+					Op:  op,
 					Err: fmt.Errorf("projector %s has nil transition function", bp.ID),
 				},
 				Field: "transitionFn",
@@ -263,32 +543,57 @@ func (es *eventStore) Project(ctx context.Context, projectors []StateProjector,
 			}
 		}
 		if len(bp.Query.GetItems()) == 0 {
-			return nil, nil, &ValidationError{
+			return &ValidationError{
 				EventStoreError: EventStoreError{
-					Op:  "Project",
+					Op:  op,
 					Err: fmt.Errorf("projector %s has empty query", bp.ID),
 				},
 				Field: "query",
 				Value: "empty",
 			}
 		}
+		if err := bp.Query.Validate(); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	// Combine all projector queries for the append condition
-	combinedQuery := CombineProjectorQueries(projectors)
-
-	// Use cursor-based or full projection based on cursor parameter
-	if after != nil {
-		return es.projectDecisionModelWithQueryFromCursor(ctx, combinedQuery, projectors, after)
+// validateProjectorIDs checks that projector IDs are non-empty and unique: the states map is keyed
+// by ID, so a projector sharing another's ID would silently overwrite its state
+func validateProjectorIDs(op string, projectors []StateProjector) error {
+	seen := make(map[string]int, len(projectors))
+	for i, bp := range projectors {
+		if bp.ID == "" {
+			return &ValidationError{
+				EventStoreError: EventStoreError{
+					Op:  op,
+					Err: fmt.Errorf("projector ID cannot be empty"),
+				},
+				Field: "projector.id",
+				Value: "empty",
+			}
+		}
+		if first, ok := seen[bp.ID]; ok {
+			return &ValidationError{
+				EventStoreError: EventStoreError{
+					Op:  op,
+					Err: fmt.Errorf("duplicate projector ID %q (projectors %d and %d)", bp.ID, first, i),
+				},
+				Field: "projector.id",
+				Value: bp.ID,
+			}
+		}
+		seen[bp.ID] = i
 	}
-	return es.projectDecisionModelWithQuery(ctx, combinedQuery, projectors)
+	return nil
 }
 
 // projectDecisionModelWithQuery uses query-based approach for all datasets
-func (es *eventStore) projectDecisionModelWithQuery(ctx context.Context, query Query, projectors []StateProjector) (map[string]any, AppendCondition, error) {
+func (es *eventStore) projectDecisionModelWithQuery(ctx context.Context, query Query, projectors []StateProjector) (map[string]any, AppendCondition, ProjectionStats, error) {
 	// Validate query
 	if query == nil {
-		return nil, nil, &ValidationError{
+		return nil, nil, ProjectionStats{}, &ValidationError{
 			EventStoreError: EventStoreError{
 				Op:  "Project",
 				Err: fmt.Errorf("query cannot be nil"),
@@ -299,9 +604,9 @@ func (es *eventStore) projectDecisionModelWithQuery(ctx context.Context, query Q
 	}
 
 	// Build SQL query
-	sqlQuery, args, err := es.buildReadQuerySQL(query, nil, nil)
+	sqlQuery, args, err := es.buildReadQuerySQL(query, nil, projectionLimit(projectors))
 	if err != nil {
-		return nil, nil, &ResourceError{
+		return nil, nil, ProjectionStats{}, &ResourceError{
 			EventStoreError: EventStoreError{
 				Op:  "Project",
 				Err: fmt.Errorf("failed to build query: %w", err),
@@ -310,98 +615,132 @@ func (es *eventStore) projectDecisionModelWithQuery(ctx context.Context, query Q
 		}
 	}
 
-	// Initialize states with initial values
-	states := make(map[string]any)
-	for _, projector := range projectors {
-		states[projector.ID] = projector.InitialState
-	}
+	var states map[string]any
+	var stats ProjectionStats
 
 	// Track latest cursor for append condition
 	var latestCursor *Cursor
 
 	// Execute query within a transaction for consistency
 	err = es.executeReadInTx(ctx, func(tx pgx.Tx) error {
-		rows, err := tx.Query(ctx, sqlQuery, args...)
-		if err != nil {
-			return &ResourceError{
-				EventStoreError: EventStoreError{
-					Op:  "Project",
-					Err: fmt.Errorf("query failed: %w", err),
-				},
-				Resource: "database",
-			}
+		var err error
+		states, latestCursor, stats, err = es.projectRowsInTx(ctx, tx, "Project", query, sqlQuery, args, projectors)
+		return err
+	})
+
+	var partial *PartialResultError
+	if err != nil {
+		if partial = es.partialProjection(ctx, "Project", latestCursor); partial == nil {
+			return nil, nil, ProjectionStats{}, err
+		}
+		if states == nil {
+			// Cancelled before the query ran: nothing was folded yet
+			fold := newProjectionFold(projectors)
+			states, stats = fold.states, fold.stats
 		}
-		defer rows.Close()
+	}
 
-		// Process events
-		for rows.Next() {
-			var row rowEvent
-			err := rows.Scan(&row.Type, &row.Tags, &row.Data, &row.TransactionID, &row.Position, &row.OccurredAt)
-			if err != nil {
-				return &ResourceError{
-					EventStoreError: EventStoreError{
-						Op:  "Project",
-						Err: fmt.Errorf("failed to scan row: %w", err),
-					},
-					Resource: "database",
-				}
-			}
+	// Build append condition from projector queries for DCB concurrency control
+	appendCondition := BuildAppendConditionFromQuery(query)
 
-			// Convert row to event
-			event := convertRowToEvent(row)
+	// Set cursor in append condition if we have events
+	if latestCursor != nil {
+		appendCondition.setAfterCursor(latestCursor)
+	}
 
-			// Update latest cursor (events are ordered by transaction_id ASC, position ASC)
-			if latestCursor == nil ||
-				event.TransactionID > latestCursor.TransactionID ||
-				(event.TransactionID == latestCursor.TransactionID && event.Position > latestCursor.Position) {
-				latestCursor = &Cursor{
-					TransactionID: event.TransactionID,
-					Position:      event.Position,
-				}
-			}
+	if partial != nil {
+		return states, appendCondition, stats, partial
+	}
+	return states, appendCondition, stats, nil
+}
 
-			// Apply event to matching projectors
-			for _, projector := range projectors {
-				if EventMatchesProjector(event, projector) {
-					states[projector.ID] = projector.TransitionFn(states[projector.ID], event)
-				}
-			}
+// projectRowsInTx runs the projection SQL (built from query) in tx and folds the rows into fresh
+// projector states. Returns the final states, the append condition cursor (the last event read,
+// or the head of query once every projector stopped; nil if none) and what was read
+// If reading fails, the states folded so far are returned with the error (see partialProjection)
+func (es *eventStore) projectRowsInTx(ctx context.Context, tx pgx.Tx, op string, query Query, sqlQuery string, args []interface{}, projectors []StateProjector) (map[string]any, *Cursor, ProjectionStats, error) {
+	// Initialize states with initial values
+	fold := newProjectionFold(projectors)
+	fold.maxStateBytes = int64(es.config.MaxProjectionStateBytes)
+
+	// Track latest cursor for append condition
+	var latestCursor *Cursor
+
+	rows, err := tx.Query(ctx, sqlQuery, args...)
+	if err != nil {
+		return fold.states, nil, fold.stats, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  op,
+				Err: fmt.Errorf("query failed: %w", err),
+			},
+			Resource: "database",
 		}
+	}
+	defer rows.Close()
 
-		// Check for row iteration errors
-		if err := rows.Err(); err != nil {
-			return &ResourceError{
+	// Process events
+	for rows.Next() {
+		var row rowEvent
+		err := rows.Scan(row.scanTargets()...)
+		if err != nil {
+			return nil, nil, ProjectionStats{}, &ResourceError{
 				EventStoreError: EventStoreError{
-					Op:  "Project",
-					Err: fmt.Errorf("row iteration failed: %w", err),
+					Op:  op,
+					Err: fmt.Errorf("failed to scan row: %w", err),
 				},
 				Resource: "database",
 			}
 		}
 
-		return nil
-	})
+		// Convert row to event
+		event := convertRowToEvent(row)
 
-	if err != nil {
-		return nil, nil, err
+		// Update latest cursor (events are ordered by transaction_id ASC, position ASC)
+		if latestCursor == nil ||
+			event.TransactionID > latestCursor.TransactionID ||
+			(event.TransactionID == latestCursor.TransactionID && event.Position > latestCursor.Position) {
+			latestCursor = &Cursor{
+				TransactionID: event.TransactionID,
+				Position:      event.Position,
+			}
+		}
+
+		// Apply event to matching projectors; stop reading once every projector is final
+		if err := fold.apply(event); err != nil {
+			return nil, nil, ProjectionStats{}, err
+		}
+		if fold.done() {
+			break
+		}
 	}
 
-	// Build append condition from projector queries for DCB concurrency control
-	appendCondition := BuildAppendConditionFromQuery(query)
+	// Check for row iteration errors
+	if err := rows.Err(); err != nil {
+		return fold.states, latestCursor, fold.stats, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  op,
+				Err: fmt.Errorf("row iteration failed: %w", err),
+			},
+			Resource: "database",
+		}
+	}
 
-	// Set cursor in append condition if we have events
-	if latestCursor != nil {
-		appendCondition.setAfterCursor(latestCursor)
+	// Stopped states don't depend on the rows left unread: take the cursor from the head instead
+	if fold.done() {
+		rows.Close()
+		if latestCursor, err = es.stoppedHead(ctx, tx, op, query, latestCursor); err != nil {
+			return fold.states, nil, fold.stats, err
+		}
 	}
 
-	return states, appendCondition, nil
+	return fold.states, latestCursor, fold.stats, nil
 }
 
 // projectDecisionModelWithQueryFromCursor uses query-based approach for all datasets with cursor
-func (es *eventStore) projectDecisionModelWithQueryFromCursor(ctx context.Context, query Query, projectors []StateProjector, after *Cursor) (map[string]any, AppendCondition, error) {
+func (es *eventStore) projectDecisionModelWithQueryFromCursor(ctx context.Context, query Query, projectors []StateProjector, after *Cursor) (map[string]any, AppendCondition, ProjectionStats, error) {
 	// Validate query
 	if query == nil {
-		return nil, nil, &ValidationError{
+		return nil, nil, ProjectionStats{}, &ValidationError{
 			EventStoreError: EventStoreError{
 				Op:  "ProjectFromCursor",
 				Err: fmt.Errorf("query cannot be nil"),
@@ -412,9 +751,9 @@ func (es *eventStore) projectDecisionModelWithQueryFromCursor(ctx context.Contex
 	}
 
 	// Build SQL query
-	sqlQuery, args, err := es.buildReadQuerySQL(query, after, nil)
+	sqlQuery, args, err := es.buildReadQuerySQL(query, after, projectionLimit(projectors))
 	if err != nil {
-		return nil, nil, &ResourceError{
+		return nil, nil, ProjectionStats{}, &ResourceError{
 			EventStoreError: EventStoreError{
 				Op:  "ProjectFromCursor",
 				Err: fmt.Errorf("failed to build query: %w", err),
@@ -424,9 +763,13 @@ func (es *eventStore) projectDecisionModelWithQueryFromCursor(ctx context.Contex
 	}
 
 	// Execute query
-	rows, err := es.pool.Query(ctx, sqlQuery, args...)
+	rows, err := es.queryWithRetry(ctx, sqlQuery, args...)
 	if err != nil {
-		return nil, nil, &ResourceError{
+		if partial := es.partialProjection(ctx, "ProjectFromCursor", after); partial != nil {
+			fold := newProjectionFold(projectors)
+			return fold.states, BuildAppendConditionFromQuery(query), fold.stats, partial
+		}
+		return nil, nil, ProjectionStats{}, &ResourceError{
 			EventStoreError: EventStoreError{
 				Op:  "ProjectFromCursor",
 				Err: fmt.Errorf("query failed: %w", err),
@@ -437,10 +780,8 @@ func (es *eventStore) projectDecisionModelWithQueryFromCursor(ctx context.Contex
 	defer rows.Close()
 
 	// Initialize states with initial values
-	states := make(map[string]any)
-	for _, projector := range projectors {
-		states[projector.ID] = projector.InitialState
-	}
+	fold := newProjectionFold(projectors)
+	fold.maxStateBytes = int64(es.config.MaxProjectionStateBytes)
 
 	// Track latest cursor for append condition
 	var latestCursor *Cursor
@@ -448,9 +789,9 @@ func (es *eventStore) projectDecisionModelWithQueryFromCursor(ctx context.Contex
 	// Process events
 	for rows.Next() {
 		var row rowEvent
-		err := rows.Scan(&row.Type, &row.Tags, &row.Data, &row.TransactionID, &row.Position, &row.OccurredAt)
+		err := rows.Scan(row.scanTargets()...)
 		if err != nil {
-			return nil, nil, &ResourceError{
+			return nil, nil, ProjectionStats{}, &ResourceError{
 				EventStoreError: EventStoreError{
 					Op:  "ProjectFromCursor",
 					Err: fmt.Errorf("failed to scan row: %w", err),
@@ -472,22 +813,46 @@ func (es *eventStore) projectDecisionModelWithQueryFromCursor(ctx context.Contex
 			}
 		}
 
-		// Apply event to matching projectors
-		for _, projector := range projectors {
-			if EventMatchesProjector(event, projector) {
-				states[projector.ID] = projector.TransitionFn(states[projector.ID], event)
-			}
+		// Apply event to matching projectors; stop reading once every projector is final
+		if err := fold.apply(event); err != nil {
+			return nil, nil, ProjectionStats{}, err
+		}
+		if fold.done() {
+			break
 		}
 	}
 
 	// Check for row iteration errors
+	var partial *PartialResultError
 	if err := rows.Err(); err != nil {
-		return nil, nil, &ResourceError{
-			EventStoreError: EventStoreError{
-				Op:  "ProjectFromCursor",
-				Err: fmt.Errorf("row iteration failed: %w", err),
-			},
-			Resource: "database",
+		resumeFrom := latestCursor
+		if resumeFrom == nil {
+			resumeFrom = after
+		}
+		if partial = es.partialProjection(ctx, "ProjectFromCursor", resumeFrom); partial == nil {
+			return nil, nil, ProjectionStats{}, &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "ProjectFromCursor",
+					Err: fmt.Errorf("row iteration failed: %w", err),
+				},
+				Resource: "database",
+			}
+		}
+	}
+
+	// Stopped states don't depend on the rows left unread: take the cursor from the head instead
+	if partial == nil && fold.done() {
+		rows.Close()
+		last := latestCursor
+		if last == nil {
+			last = after
+		}
+		db, err := es.db()
+		if err != nil {
+			return nil, nil, ProjectionStats{}, err
+		}
+		if latestCursor, err = es.stoppedHead(ctx, db, "ProjectFromCursor", query, last); err != nil {
+			return nil, nil, ProjectionStats{}, err
 		}
 	}
 
@@ -499,7 +864,10 @@ func (es *eventStore) projectDecisionModelWithQueryFromCursor(ctx context.Contex
 		appendCondition.setAfterCursor(latestCursor)
 	}
 
-	return states, appendCondition, nil
+	if partial != nil {
+		return fold.states, appendCondition, fold.stats, partial
+	}
+	return fold.states, appendCondition, fold.stats, nil
 }
 
 // BuildAppendConditionFromQuery builds an AppendCondition from a specific query
@@ -509,22 +877,19 @@ func BuildAppendConditionFromQuery(query Query) AppendCondition {
 	return NewAppendCondition(query)
 }
 
-// ProjectStream projects multiple states using channel-based streaming with optional cursor
-// cursor == nil: stream from beginning of stream
-// cursor != nil: stream from specified cursor position
-// This is optimized for large datasets and provides backpressure through channels
-// for efficient memory usage and Go-idiomatic streaming
-// Returns final aggregated states (same as batch version) via streaming
-func (es *eventStore) ProjectStream(ctx context.Context, projectors []StateProjector, after *Cursor) (<-chan map[string]any, <-chan AppendCondition, error) {
+// openProjectionStream takes a projection slot, validates projectors and opens the rows of their
+// combined query for a streaming projection (ProjectStream, ProjectProgressive). On success the
+// caller owns the slot and the rows and must release both when the stream ends
+func (es *eventStore) openProjectionStream(ctx context.Context, op string, projectors []StateProjector, after *Cursor) (pgx.Rows, Query, error) {
 	// Acquire projection semaphore with fail-fast behavior
 	select {
 	case <-es.projectionSemaphore:
-		// Acquired semaphore slot - will be released when channels are closed
+		// Acquired semaphore slot - released by the caller when the stream ends
 	default:
 		// No semaphore available - fail fast instead of blocking
 		return nil, nil, &TooManyProjectionsError{
 			EventStoreError: EventStoreError{
-				Op:  "ProjectStream",
+				Op:  op,
 				Err: fmt.Errorf("too many concurrent projections"),
 			},
 			MaxConcurrent: es.config.MaxConcurrentProjections,
@@ -532,22 +897,45 @@ func (es *eventStore) ProjectStream(ctx context.Context, projectors []StateProje
 		}
 	}
 
+	// Release the slot on early (error) returns; the caller owns it once the rows are open
+	streaming := false
+	defer func() {
+		if !streaming {
+			es.projectionSemaphore <- struct{}{}
+		}
+	}()
+
 	if len(projectors) == 0 {
-		return nil, nil, fmt.Errorf("at least one projector is required")
+		return nil, nil, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  op,
+				Err: fmt.Errorf("at least one projector is required"),
+			},
+			Field: "projectors",
+			Value: "empty",
+		}
 	}
 
 	// Validate projectors
+	if err := validateProjectorIDs(op, projectors); err != nil {
+		return nil, nil, err
+	}
 	for _, bp := range projectors {
 		if bp.TransitionFn == nil {
 			return nil, nil, &ValidationError{
 				EventStoreError: EventStoreError{
-					Op:  "ProjectStream",
+					Op:  op,
 					Err: fmt.Errorf("projector %s has nil transition function", bp.ID),
 				},
 				Field: "transitionFn",
 				Value: "nil",
 			}
 		}
+		if bp.Query != nil && len(bp.Query.GetItems()) > 0 {
+			if err := bp.Query.Validate(); err != nil {
+				return nil, nil, err
+			}
+		}
 	}
 
 	// Build combined query from all projectors
@@ -557,7 +945,7 @@ func (es *eventStore) ProjectStream(ctx context.Context, projectors []StateProje
 	if len(query.GetItems()) == 0 {
 		return nil, nil, &ValidationError{
 			EventStoreError: EventStoreError{
-				Op:  "ProjectStream",
+				Op:  op,
 				Err: fmt.Errorf("query must contain at least one item"),
 			},
 			Field: "query",
@@ -571,23 +959,45 @@ func (es *eventStore) ProjectStream(ctx context.Context, projectors []StateProje
 	}
 
 	// Build the SQL query with cursor
-	sqlQuery, args, err := es.buildReadQuerySQL(query, after, nil)
+	sqlQuery, args, err := es.buildReadQuerySQL(query, after, projectionLimit(projectors))
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to build query: %w", err)
+		return nil, nil, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  op,
+				Err: fmt.Errorf("failed to build query: %w", err),
+			},
+			Resource: "database",
+		}
 	}
 
 	// Use caller's context directly (caller controls timeout)
-	rows, err := es.pool.Query(ctx, sqlQuery, args...)
+	rows, err := es.streamQuery(ctx, sqlQuery, args...)
 	if err != nil {
 		return nil, nil, &ResourceError{
 			EventStoreError: EventStoreError{
-				Op:  "ProjectStream",
+				Op:  op,
 				Err: fmt.Errorf("query failed: %w", err),
 			},
 			Resource: "database",
 		}
 	}
 
+	streaming = true
+	return rows, query, nil
+}
+
+// ProjectStream projects multiple states using channel-based streaming with optional cursor
+// cursor == nil: stream from beginning of stream
+// cursor != nil: stream from specified cursor position
+// This is optimized for large datasets and provides backpressure through channels
+// for efficient memory usage and Go-idiomatic streaming
+// Returns final aggregated states (same as batch version) via streaming
+func (es *eventStore) ProjectStream(ctx context.Context, projectors []StateProjector, after *Cursor) (<-chan map[string]any, <-chan AppendCondition, error) {
+	rows, query, err := es.openProjectionStream(ctx, "ProjectStream", projectors, after)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Create result channel with configurable buffer
 	resultChan := make(chan map[string]any, es.config.StreamBuffer)
 
@@ -610,84 +1020,38 @@ func (es *eventStore) ProjectStream(ctx context.Context, projectors []StateProje
 		}()
 
 		// Initialize projector states
-		projectorStates := make(map[string]interface{})
-		for _, projector := range projectors {
-			projectorStates[projector.ID] = projector.InitialState
-		}
+		started := es.clock().Now()
+		fold := newProjectionFold(projectors)
+		fold.maxStateBytes = int64(es.config.MaxProjectionStateBytes)
 
 		// Build AppendCondition from projector queries for DCB concurrency control (same as Project)
 		appendCondition := BuildAppendConditionFromQuery(query)
 
-		// Track latest cursor (same as Project)
-		var latestCursor *Cursor
-		var hasEvents bool
-
 		// Process events using the same context as the database query
-		for rows.Next() {
-			select {
-			case <-ctx.Done():
-				// Context cancelled - exit cleanly
-				return
-			default:
-				var row rowEvent
-				err := rows.Scan(
-					&row.Type,
-					&row.Tags,
-					&row.Data,
-					&row.TransactionID,
-					&row.Position,
-					&row.OccurredAt,
-				)
-				if err != nil {
-					// Log error and exit
-					log.Printf("Error scanning row in ProjectStream: %v", err)
-					return
-				}
-
-				// Update latest cursor (events are ordered by transaction_id ASC, position ASC) - same as Project
-				latestCursor = &Cursor{
-					TransactionID: row.TransactionID,
-					Position:      row.Position,
-				}
-				hasEvents = true
-
-				event := convertRowToEvent(row)
-
-				// Process event with each projector
-				for _, projector := range projectors {
-					// Check if projector should process this event
-					if !EventMatchesProjector(event, projector) {
-						continue
-					}
-
-					// Get current state for this projector
-					currentState := projectorStates[projector.ID]
-
-					// Project the event using the transition function
-					newState := projector.TransitionFn(currentState, event)
-
-					// Update state
-					projectorStates[projector.ID] = newState
-				}
+		latestCursor, err := foldProjectionRows(ctx, rows, fold, nil)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Error in ProjectStream: %v", err)
 			}
+			return
 		}
-
-		// Check for row iteration errors
-		if err := rows.Err(); err != nil {
-			log.Printf("Row iteration error in ProjectStream: %v", err)
+		if latestCursor, err = es.stoppedStreamHead(ctx, rows, fold, "ProjectStream", query, latestCursor); err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Error in ProjectStream: %v", err)
+			}
 			return
 		}
 
-		// Set cursor in AppendCondition (same logic as Project)
-		if !hasEvents {
-			appendCondition.setAfterCursor(nil)
-		} else {
-			appendCondition.setAfterCursor(latestCursor)
-		}
+		fold.stats.Op = "ProjectStream"
+		fold.stats.Duration = es.clock().Now().Sub(started)
+		es.reportProjectionStats(fold.stats)
+
+		// Set cursor in AppendCondition (same logic as Project; nil without events)
+		appendCondition.setAfterCursor(latestCursor)
 
 		// Send final aggregated states (same as batch version)
 		select {
-		case resultChan <- projectorStates:
+		case resultChan <- fold.states:
 		case <-ctx.Done():
 			// Context cancelled while trying to send final states
 		}
@@ -702,3 +1066,45 @@ func (es *eventStore) ProjectStream(ctx context.Context, projectors []StateProje
 
 	return resultChan, appendConditionChan, nil
 }
+
+// foldProjectionRows folds the events of a streaming projection into fold until the rows end,
+// ctx ends or every projector stopped, calling afterEvent (if set) with each folded event.
+// It returns the cursor of the last event folded, nil when there was none
+func foldProjectionRows(ctx context.Context, rows pgx.Rows, fold *projectionFold, afterEvent func(event Event)) (*Cursor, error) {
+	var latestCursor *Cursor
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var row rowEvent
+		err := rows.Scan(row.scanTargets()...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		// Update latest cursor (events are ordered by transaction_id ASC, position ASC) - same as Project
+		latestCursor = &Cursor{
+			TransactionID: row.TransactionID,
+			Position:      row.Position,
+		}
+
+		// Process event with each projector that hasn't stopped
+		event := convertRowToEvent(row)
+		if err := fold.apply(event); err != nil {
+			return nil, err
+		}
+		if afterEvent != nil {
+			afterEvent(event)
+		}
+		if fold.done() {
+			break
+		}
+	}
+
+	// Check for row iteration errors
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration failed: %w", err)
+	}
+	return latestCursor, nil
+}
@@ -0,0 +1,42 @@
+package dcb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ProjectJSON projects like Project but returns each projector state as JSON, marshaled once by the store
+// This suits generic servers (HTTP/gRPC) that pass states through without an intermediate Go type.
+// Every projector state must be JSON-serializable; a state that is already a json.RawMessage is passed through.
+func (es *eventStore) ProjectJSON(ctx context.Context, projectors []StateProjector, after *Cursor) (map[string]json.RawMessage, AppendCondition, error) {
+	states, condition, err := es.Project(ctx, projectors, after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := marshalStates("ProjectJSON", states)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, condition, nil
+}
+
+// marshalStates marshals each projector state to JSON
+func marshalStates(op string, states map[string]any) (map[string]json.RawMessage, error) {
+	result := make(map[string]json.RawMessage, len(states))
+	for id, state := range states {
+		data, err := json.Marshal(state)
+		if err != nil {
+			return nil, &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  op,
+					Err: fmt.Errorf("failed to marshal state of projector %s: %w", id, err),
+				},
+				Resource: "json",
+			}
+		}
+		result[id] = data
+	}
+	return result, nil
+}
@@ -0,0 +1,36 @@
+package dcb
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestMarshalStates(t *testing.T) {
+	states := map[string]any{
+		"count":  3,
+		"course": map[string]any{"id": "c1", "open": true},
+		"raw":    json.RawMessage(`{"already":"json"}`),
+	}
+
+	result, err := marshalStates("ProjectJSON", states)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"count":  `3`,
+		"course": `{"id":"c1","open":true}`,
+		"raw":    `{"already":"json"}`,
+	}
+	for id, want := range expected {
+		if got := string(result[id]); got != want {
+			t.Errorf("state %s = %s, want %s", id, got, want)
+		}
+	}
+
+	_, err = marshalStates("ProjectJSON", map[string]any{"bad": make(chan int)})
+	if !errors.Is(err, ErrResource) {
+		t.Errorf("expected resource error for unserializable state, got %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package dcb
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// =============================================================================
+// Projection State Memory Guard
+// =============================================================================
+
+// stateCheckMinInterval is the minimum number of events folded into a projector between two
+// state size checks; larger states are checked every quarter of their event count, so the total
+// cost of checking stays linear in the number of events
+const stateCheckMinInterval = 64
+
+// checkStateSize fails when the approximate size of the projector's state exceeds maxStateBytes
+// The state is measured after its first event and then as its event count grows
+func (f *projectionFold) checkStateSize(projector StateProjector) error {
+	if f.maxStateBytes <= 0 {
+		return nil
+	}
+	applied := f.stats.EventsByProjector[projector.ID]
+	if applied < f.nextStateCheck[projector.ID] {
+		return nil
+	}
+	f.nextStateCheck[projector.ID] = applied + max(stateCheckMinInterval, applied/4)
+
+	size := approxStateBytes(f.states[projector.ID])
+	if size <= f.maxStateBytes {
+		return nil
+	}
+	return &ResourceError{
+		EventStoreError: EventStoreError{
+			Op: "project",
+			Err: fmt.Errorf("projector %q state is about %d bytes after %d events, over MaxProjectionStateBytes %d; "+
+				"use Aggregate or narrower projector queries for unbounded cardinality", projector.ID, size, applied, f.maxStateBytes),
+		},
+		Resource: "projection_memory",
+	}
+}
+
+// approxStateBytes estimates the memory held by a projector state: the value itself plus what it
+// references through strings, slices, maps, pointers and interfaces. Map and allocator overheads
+// are ignored, so real usage is higher; shared pointers are counted once
+func approxStateBytes(state any) int64 {
+	value := reflect.ValueOf(state)
+	if !value.IsValid() {
+		return 0
+	}
+	seen := make(map[uintptr]bool)
+	return int64(value.Type().Size()) + referencedBytes(value, seen)
+}
+
+// referencedBytes returns the memory referenced by value, excluding value's own inline size
+func referencedBytes(value reflect.Value, seen map[uintptr]bool) int64 {
+	switch value.Kind() {
+	case reflect.String:
+		return int64(value.Len())
+	case reflect.Slice:
+		if value.IsNil() || seen[value.Pointer()] {
+			return 0
+		}
+		seen[value.Pointer()] = true
+		size := int64(value.Cap()) * int64(value.Type().Elem().Size())
+		for i := range value.Len() {
+			size += referencedBytes(value.Index(i), seen)
+		}
+		return size
+	case reflect.Map:
+		if value.IsNil() || seen[value.Pointer()] {
+			return 0
+		}
+		seen[value.Pointer()] = true
+		entrySize := int64(value.Type().Key().Size() + value.Type().Elem().Size())
+		size := int64(value.Len()) * entrySize
+		iter := value.MapRange()
+		for iter.Next() {
+			size += referencedBytes(iter.Key(), seen) + referencedBytes(iter.Value(), seen)
+		}
+		return size
+	case reflect.Pointer:
+		if value.IsNil() || seen[value.Pointer()] {
+			return 0
+		}
+		seen[value.Pointer()] = true
+		return int64(value.Type().Elem().Size()) + referencedBytes(value.Elem(), seen)
+	case reflect.Interface:
+		if value.IsNil() {
+			return 0
+		}
+		elem := value.Elem()
+		return int64(elem.Type().Size()) + referencedBytes(elem, seen)
+	case reflect.Struct:
+		var size int64
+		for i := range value.NumField() {
+			size += referencedBytes(value.Field(i), seen)
+		}
+		return size
+	case reflect.Array:
+		var size int64
+		for i := range value.Len() {
+			size += referencedBytes(value.Index(i), seen)
+		}
+		return size
+	default:
+		return 0
+	}
+}
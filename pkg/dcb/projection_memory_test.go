@@ -0,0 +1,82 @@
+package dcb
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestApproxStateBytes(t *testing.T) {
+	if got := approxStateBytes(nil); got != 0 {
+		t.Errorf("nil state = %d, want 0", got)
+	}
+	if got := approxStateBytes(42); got != 8 {
+		t.Errorf("int state = %d, want 8", got)
+	}
+	if got := approxStateBytes("hello"); got != 16+5 {
+		t.Errorf("string state = %d, want 21", got)
+	}
+
+	type course struct {
+		Name     string
+		Students []string
+	}
+	small := map[string]*course{"c1": {Name: "Math", Students: []string{"a"}}}
+	large := map[string]*course{}
+	for i := range 100 {
+		large[fmt.Sprintf("c%d", i)] = &course{Name: "Math", Students: []string{"a", "b"}}
+	}
+	if approxStateBytes(large) <= 50*approxStateBytes(small) {
+		t.Errorf("state size should grow with entries: small %d, large %d", approxStateBytes(small), approxStateBytes(large))
+	}
+
+	shared := &course{Name: "shared"}
+	twice := []*course{shared, shared}
+	once := []*course{shared, nil}
+	if approxStateBytes(twice) != approxStateBytes(once) {
+		t.Errorf("shared pointers should be counted once: %d vs %d", approxStateBytes(twice), approxStateBytes(once))
+	}
+
+	type node struct{ Next *node }
+	cycle := &node{}
+	cycle.Next = cycle
+	if approxStateBytes(cycle) <= 0 {
+		t.Error("cyclic state should be measured without looping")
+	}
+}
+
+func TestProjectionFoldStateLimit(t *testing.T) {
+	accumulator := StateProjector{
+		ID:           "all",
+		Query:        NewQueryAll(),
+		InitialState: map[string]string{},
+		TransitionFn: func(state any, event Event) any {
+			entities := state.(map[string]string)
+			entities[fmt.Sprintf("entity-%d", len(entities))] = string(event.Data)
+			return entities
+		},
+	}
+
+	fold := newProjectionFold([]StateProjector{accumulator})
+	fold.maxStateBytes = 4096
+	var err error
+	for i := 0; i < 10_000 && err == nil; i++ {
+		err = fold.apply(Event{Type: "E", Data: []byte(`{"name":"some entity"}`)})
+	}
+	if !IsResourceError(err) {
+		t.Fatalf("expected ResourceError for an unbounded state, got %v", err)
+	}
+	if re, _ := GetResourceError(err); re.Resource != "projection_memory" {
+		t.Errorf("Resource = %q, want projection_memory", re.Resource)
+	}
+	if events := fold.stats.EventsByProjector["all"]; events > 1000 {
+		t.Errorf("limit should fire soon after the state passes it, fired after %d events", events)
+	}
+
+	accumulator.InitialState = map[string]string{}
+	unlimited := newProjectionFold([]StateProjector{accumulator})
+	for range 1000 {
+		if err := unlimited.apply(Event{Type: "E"}); err != nil {
+			t.Fatalf("no limit configured, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package dcb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// =============================================================================
+// Partial Projections
+// =============================================================================
+
+// partialProjection returns the PartialResultError for a projection whose read failed, or nil
+// when ReturnPartialOnCancel is off or the failure was not a cancellation of ctx
+// Deadlines are not partial results: a caller that set a timeout asked for a complete answer
+func (es *eventStore) partialProjection(ctx context.Context, op string, cursor *Cursor) *PartialResultError {
+	if !es.config.ReturnPartialOnCancel || !errors.Is(ctx.Err(), context.Canceled) {
+		return nil
+	}
+	return &PartialResultError{
+		EventStoreError: EventStoreError{
+			Op:  op,
+			Err: fmt.Errorf("projection cancelled before reading all events: %w", context.Canceled),
+		},
+		Cursor: cursor,
+	}
+}
@@ -0,0 +1,37 @@
+package dcb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPartialProjection(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	cursor := &Cursor{TransactionID: 3, Position: 12}
+
+	strict := &eventStore{config: EventStoreConfig{}}
+	if partial := strict.partialProjection(cancelled, "Project", cursor); partial != nil {
+		t.Fatalf("expected no partial result by default, got %v", partial)
+	}
+
+	es := &eventStore{config: EventStoreConfig{ReturnPartialOnCancel: true}}
+	if partial := es.partialProjection(context.Background(), "Project", cursor); partial != nil {
+		t.Fatalf("expected no partial result without cancellation, got %v", partial)
+	}
+	if partial := es.partialProjection(expired, "Project", cursor); partial != nil {
+		t.Fatalf("expected no partial result for a deadline, got %v", partial)
+	}
+
+	var err error = es.partialProjection(cancelled, "Project", cursor)
+	if !errors.Is(err, ErrPartial) || !errors.Is(err, context.Canceled) || !IsPartialResultError(err) {
+		t.Fatalf("expected ErrPartial wrapping context.Canceled, got %v", err)
+	}
+	if partial, ok := GetPartialResultError(err); !ok || partial.Cursor != cursor {
+		t.Fatalf("expected the last cursor, got %+v", partial)
+	}
+}
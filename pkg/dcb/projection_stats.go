@@ -0,0 +1,31 @@
+package dcb
+
+import "time"
+
+// =============================================================================
+// Projection Stats
+// =============================================================================
+
+// ProjectionStats describes how much a projection read
+// A projection reads the events matching any of its projectors' queries once, so EventsScanned
+// counts every row read while EventsByProjector counts the events each projector folded. A
+// projector folding far more events than its state needs has a query that is too broad.
+type ProjectionStats struct {
+	// Op is the projecting operation ("Project" or "ProjectStream")
+	Op string
+	// EventsScanned is the number of events read from the database
+	EventsScanned int
+	// BytesScanned is the size of the data and metadata of the events read
+	BytesScanned int64
+	// EventsByProjector maps each projector ID to the number of events it folded
+	EventsByProjector map[string]int
+	// Duration is the time spent reading and folding the events
+	Duration time.Duration
+}
+
+// reportProjectionStats passes stats to EventStoreConfig.OnProjectionStats, if set
+func (es *eventStore) reportProjectionStats(stats ProjectionStats) {
+	if es.config.OnProjectionStats != nil {
+		es.config.OnProjectionStats(stats)
+	}
+}
@@ -0,0 +1,157 @@
+package dcb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Early-Stopping Projections
+// =============================================================================
+
+// NewExistsProjector creates a projector whose state is true once any event matches query
+// It stops at the first matching event, and when it is the only projector in a Project call the
+// read is issued with LIMIT 1, which makes "does X already exist" checks cheap regardless of
+// how many events X has
+func NewExistsProjector(id string, query Query) StateProjector {
+	return StateProjector{
+		ID:           id,
+		Query:        query,
+		InitialState: false,
+		TransitionFn: func(state any, event Event) any { return true },
+		StopFn:       func(state any) bool { return state.(bool) },
+		existsOnly:   true,
+	}
+}
+
+// projectionLimit returns the row limit for reading the events of projectors (nil = no limit)
+// Only a single existence projector can be answered by the first row, and only when its query
+// has no extended predicates (CombineProjectorQueries drops those, so the first row might not match)
+// and no AppliesTo (the first row might be of a type it ignores)
+func projectionLimit(projectors []StateProjector) *int {
+	if len(projectors) != 1 || !projectors[0].existsOnly || projectors[0].Query == nil || len(projectors[0].AppliesTo) > 0 {
+		return nil
+	}
+	for _, item := range projectors[0].Query.GetItems() {
+		if qi, ok := asQueryItem(item); !ok || qi.hasExtendedPredicates() {
+			return nil
+		}
+	}
+	limit := 1
+	return &limit
+}
+
+// projectionFold folds events into projector states, honouring StopFn
+type projectionFold struct {
+	projectors []StateProjector
+	states     map[string]any
+	stopped    map[string]bool
+	stats      ProjectionStats
+
+	// maxStateBytes bounds the approximate size of each state (0 = no limit, see checkStateSize)
+	maxStateBytes  int64
+	nextStateCheck map[string]int
+}
+
+// newProjectionFold initializes projector states; projectors whose StopFn already
+// holds for the initial state never receive events
+func newProjectionFold(projectors []StateProjector) *projectionFold {
+	fold := &projectionFold{
+		projectors: projectors,
+		states:     make(map[string]any, len(projectors)),
+		stopped:    make(map[string]bool),
+		stats:      ProjectionStats{EventsByProjector: make(map[string]int, len(projectors))},
+
+		nextStateCheck: make(map[string]int, len(projectors)),
+	}
+	for _, projector := range projectors {
+		fold.states[projector.ID] = projector.InitialState
+		if projector.StopFn != nil && projector.StopFn(projector.InitialState) {
+			fold.stopped[projector.ID] = true
+		}
+	}
+	return fold
+}
+
+// apply applies event to every matching projector that hasn't stopped yet and whose AppliesTo,
+// if set, lists its type. It fails with a ResourceError when a state grows over maxStateBytes
+func (f *projectionFold) apply(event Event) error {
+	f.stats.EventsScanned++
+	f.stats.BytesScanned += int64(len(event.Data) + len(event.Metadata))
+	for _, projector := range f.projectors {
+		if f.stopped[projector.ID] || !EventMatchesProjector(event, projector) {
+			continue
+		}
+		if len(projector.AppliesTo) > 0 && !slices.Contains(projector.AppliesTo, event.Type) {
+			continue
+		}
+		f.stats.EventsByProjector[projector.ID]++
+		state := projector.TransitionFn(f.states[projector.ID], event)
+		f.states[projector.ID] = state
+		if err := f.checkStateSize(projector); err != nil {
+			return err
+		}
+		if projector.StopFn != nil && projector.StopFn(state) {
+			f.stopped[projector.ID] = true
+		}
+	}
+	return nil
+}
+
+// done reports whether every projector has stopped, so no further rows need to be read
+func (f *projectionFold) done() bool {
+	return len(f.stopped) == len(f.projectors)
+}
+
+// stoppedHead returns the append condition cursor of a projection whose projectors all stopped:
+// the latest event matching query after last (the last event read), or last when there is none.
+// Stopped states are final, so the condition covers the whole query and a later AppendIf only
+// fails on events appended after the projection, not on matching events it didn't need to read
+func (es *eventStore) stoppedHead(ctx context.Context, db dbQuerier, op string, query Query, last *Cursor) (*Cursor, error) {
+	innerSQL, args, err := es.buildReadQuerySQL(query, last, nil)
+	if err != nil {
+		return nil, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  op,
+				Err: fmt.Errorf("failed to build query: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	sqlQuery := "SELECT transaction_id, position FROM (" + strings.TrimSuffix(innerSQL, readOrderBy) + ") AS e" + latestOrderBy
+
+	var head Cursor
+	err = db.QueryRow(ctx, sqlQuery, args...).Scan(&head.TransactionID, &head.Position)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return last, nil
+	}
+	if err != nil {
+		return nil, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  op,
+				Err: fmt.Errorf("failed to read latest matching event: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	return &head, nil
+}
+
+// stoppedStreamHead is stoppedHead for a streaming projection: once every projector in fold
+// stopped it closes rows and reads the head of query, otherwise it returns last unchanged
+func (es *eventStore) stoppedStreamHead(ctx context.Context, rows pgx.Rows, fold *projectionFold, op string, query Query, last *Cursor) (*Cursor, error) {
+	if !fold.done() {
+		return last, nil
+	}
+	rows.Close()
+	db, err := es.db()
+	if err != nil {
+		return nil, err
+	}
+	return es.stoppedHead(ctx, db, op, query, last)
+}
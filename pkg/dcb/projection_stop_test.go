@@ -0,0 +1,124 @@
+package dcb
+
+import "testing"
+
+func TestProjectionFoldStopsProjectors(t *testing.T) {
+	counter := StateProjector{
+		ID:           "count",
+		Query:        NewQueryAll(),
+		InitialState: 0,
+		TransitionFn: func(state any, event Event) any { return state.(int) + 1 },
+		StopFn:       func(state any) bool { return state.(int) >= 2 },
+	}
+	exists := NewExistsProjector("exists", NewQueryAll())
+
+	fold := newProjectionFold([]StateProjector{counter, exists})
+	fold.apply(Event{Type: "E"})
+	if fold.done() {
+		t.Fatal("counter should still be running after one event")
+	}
+	if fold.states["exists"] != true {
+		t.Errorf("exists should be true after the first event, got %v", fold.states["exists"])
+	}
+
+	fold.apply(Event{Type: "E"})
+	fold.apply(Event{Type: "E"}) // ignored: every projector has stopped
+	if !fold.done() {
+		t.Fatal("fold should be done once every projector stopped")
+	}
+	if fold.states["count"] != 2 {
+		t.Errorf("stopped projector should ignore further events, got %v", fold.states["count"])
+	}
+}
+
+func TestProjectionFoldWithoutStopFnNeverDone(t *testing.T) {
+	fold := newProjectionFold([]StateProjector{{
+		ID:           "count",
+		Query:        NewQueryAll(),
+		InitialState: 0,
+		TransitionFn: func(state any, event Event) any { return state.(int) + 1 },
+	}})
+	for i := 0; i < 3; i++ {
+		fold.apply(Event{Type: "E"})
+	}
+	if fold.done() || fold.states["count"] != 3 {
+		t.Fatalf("expected all events folded, got %v (done=%v)", fold.states["count"], fold.done())
+	}
+}
+
+func TestProjectionLimit(t *testing.T) {
+	exists := NewExistsProjector("exists", NewQuery(NewTags("user_id", "u1"), "UserCreated"))
+	if limit := projectionLimit([]StateProjector{exists}); limit == nil || *limit != 1 {
+		t.Errorf("single existence projector should read one row, got %v", limit)
+	}
+
+	other := NewExistsProjector("other", NewQuery(NewTags("email", "a@b.c"), "UserCreated"))
+	if limit := projectionLimit([]StateProjector{exists, other}); limit != nil {
+		t.Errorf("several projectors need all rows, got limit %d", *limit)
+	}
+
+	extended := NewExistsProjector("caused", NewQueryBuilder().WithCausedBy(3).Build())
+	if limit := projectionLimit([]StateProjector{extended}); limit != nil {
+		t.Errorf("extended predicates are filtered in Go and need all rows, got limit %d", *limit)
+	}
+
+	narrow := NewExistsProjector("narrow", NewQuery(NewTags("user_id", "u1"), "UserCreated", "UserRenamed"))
+	narrow.AppliesTo = []string{"UserRenamed"}
+	if limit := projectionLimit([]StateProjector{narrow}); limit != nil {
+		t.Errorf("a projector ignoring some types needs all rows, got limit %d", *limit)
+	}
+}
+
+func TestProjectionFoldAppliesTo(t *testing.T) {
+	fold := newProjectionFold([]StateProjector{{
+		ID:           "renames",
+		Query:        NewQuery(NewTags("user_id", "u1"), "UserCreated", "UserRenamed"),
+		InitialState: 0,
+		TransitionFn: func(state any, event Event) any {
+			if event.Type != "UserRenamed" {
+				t.Errorf("transition called for %s, which the projector doesn't apply", event.Type)
+			}
+			return state.(int) + 1
+		},
+		AppliesTo: []string{"UserRenamed"},
+	}})
+
+	tags := NewTags("user_id", "u1")
+	for _, eventType := range []string{"UserCreated", "UserRenamed", "UserRenamed"} {
+		if err := fold.apply(Event{Type: eventType, Tags: tags}); err != nil {
+			t.Fatalf("apply: %v", err)
+		}
+	}
+	if fold.states["renames"] != 2 || fold.stats.EventsScanned != 3 || fold.stats.EventsByProjector["renames"] != 2 {
+		t.Errorf("expected 2 of 3 scanned events applied, got state %v and stats %+v", fold.states["renames"], fold.stats)
+	}
+}
+
+func TestProjectionFoldStats(t *testing.T) {
+	count := func(id string, query Query) StateProjector {
+		return StateProjector{
+			ID:           id,
+			Query:        query,
+			InitialState: 0,
+			TransitionFn: func(state any, event Event) any { return state.(int) + 1 },
+		}
+	}
+	fold := newProjectionFold([]StateProjector{
+		count("courses", NewQuery(nil, "CourseDefined")),
+		count("all", NewQueryAll()),
+	})
+
+	fold.apply(Event{Type: "CourseDefined", Data: []byte(`{"id":1}`), Metadata: []byte(`{}`)})
+	fold.apply(Event{Type: "StudentRegistered", Data: []byte(`{}`)})
+
+	stats := fold.stats
+	if stats.EventsScanned != 2 {
+		t.Errorf("expected 2 events scanned, got %d", stats.EventsScanned)
+	}
+	if want := int64(len(`{"id":1}`) + len(`{}`) + len(`{}`)); stats.BytesScanned != want {
+		t.Errorf("expected %d bytes scanned, got %d", want, stats.BytesScanned)
+	}
+	if stats.EventsByProjector["courses"] != 1 || stats.EventsByProjector["all"] != 2 {
+		t.Errorf("unexpected per-projector counts %v", stats.EventsByProjector)
+	}
+}
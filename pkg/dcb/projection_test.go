@@ -0,0 +1,36 @@
+package dcb
+
+import "testing"
+
+func TestValidateProjectorsRejectsDuplicateIDs(t *testing.T) {
+	projector := func(id string) StateProjector {
+		return StateProjector{
+			ID:           id,
+			Query:        NewQuery(NewTags("course_id", "c1")),
+			InitialState: 0,
+			TransitionFn: func(state any, _ Event) any { return state },
+		}
+	}
+
+	if err := validateProjectors("Project", []StateProjector{projector("a"), projector("b")}); err != nil {
+		t.Fatalf("expected distinct IDs to be valid, got %v", err)
+	}
+
+	err := validateProjectors("Project", []StateProjector{projector("a"), projector("b"), projector("a")})
+	validationErr, ok := GetValidationError(err)
+	if !ok {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	if validationErr.Field != "projector.id" || validationErr.Value != "a" {
+		t.Errorf("expected the duplicate ID to be named, got field %q value %q", validationErr.Field, validationErr.Value)
+	}
+
+	// A duplicate is reported before other projector problems, without building any query
+	broken := projector("a")
+	broken.TransitionFn = nil
+	if err := validateProjectors("Project", []StateProjector{projector("a"), broken}); !IsValidationError(err) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	} else if validationErr, _ := GetValidationError(err); validationErr.Field != "projector.id" {
+		t.Errorf("expected the duplicate ID to be reported first, got field %q", validationErr.Field)
+	}
+}
@@ -0,0 +1,29 @@
+package dcb
+
+// =============================================================================
+// Method-Based Projectors
+// =============================================================================
+
+// Applier is a projection state that folds events itself: Apply returns the state after event.
+// Implement it on a value type so each call returns a new state instead of changing the old one
+type Applier[S any] interface {
+	Apply(event Event) S
+}
+
+// ProjectorFor creates a projector whose transition calls state.Apply(event), so the fold is a
+// method of the state type with its own unit tests rather than a closure, e.g.
+//
+//	func (s AccountState) Apply(event dcb.Event) AccountState { ... }
+//	projector := dcb.ProjectorFor("account", query, AccountState{AccountID: id})
+//
+// The projected state is an S, as initial
+func ProjectorFor[S Applier[S]](id string, query Query, initial S) StateProjector {
+	return StateProjector{
+		ID:           id,
+		Query:        query,
+		InitialState: initial,
+		TransitionFn: func(state any, event Event) any {
+			return state.(S).Apply(event)
+		},
+	}
+}
@@ -0,0 +1,44 @@
+package dcb
+
+import "testing"
+
+// balance is an Applier summing the amounts carried by Deposited and Withdrawn events
+type balance struct {
+	Amount int
+	Events int
+}
+
+func (b balance) Apply(event Event) balance {
+	switch event.Type {
+	case "Deposited":
+		b.Amount += len(event.Data)
+	case "Withdrawn":
+		b.Amount -= len(event.Data)
+	}
+	b.Events++
+	return b
+}
+
+func TestProjectorFor(t *testing.T) {
+	query := NewQuery(NewTags("account_id", "a1"), "Deposited", "Withdrawn")
+	projector := ProjectorFor("balance", query, balance{Amount: 10})
+
+	if projector.ID != "balance" || projector.Query != query {
+		t.Fatalf("expected the given id and query, got %q and %v", projector.ID, projector.Query)
+	}
+
+	state := projector.InitialState
+	for _, event := range []Event{{Type: "Deposited", Data: []byte("12345")}, {Type: "Withdrawn", Data: []byte("12")}} {
+		state = projector.TransitionFn(state, event)
+	}
+	got, ok := state.(balance)
+	if !ok {
+		t.Fatalf("expected the state to stay a balance, got %T", state)
+	}
+	if got != (balance{Amount: 13, Events: 2}) {
+		t.Errorf("expected amount 13 after 2 events, got %+v", got)
+	}
+	if projector.InitialState.(balance) != (balance{Amount: 10}) {
+		t.Errorf("expected the initial state to be unchanged, got %+v", projector.InitialState)
+	}
+}
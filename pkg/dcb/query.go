@@ -16,11 +16,22 @@ import (
 // Query represents a composite query with multiple conditions combined with OR logic
 // This is opaque to consumers - they can only construct it via helper functions
 // Now exposes GetItems for public access
+//
+// Semantics: a query matches an event if any of its items matches. An item matches if the event
+// has one of the item's event types (any type if none are given) AND carries all of its tags.
+// An item with neither event types nor tags would match every event, so it is only accepted when
+// it comes from NewQueryAll; otherwise Validate treats it as a forgotten condition.
+// A query without items (NewQueryEmpty) is rejected by reads, projections and append conditions.
 type Query interface {
 	// isQuery is a marker method to make this interface unexported
 	isQuery()
 	// GetItems returns the internal query items (used by event store)
 	GetItems() []QueryItem
+	// Validate returns a ValidationError for empty or contradictory queries
+	// It runs at the start of Query, Project and AppendIf
+	Validate() error
+	// String returns a compact, human-readable form, e.g. (type IN [A,B] AND tags{k=v}) OR (type=C)
+	String() string
 }
 
 // QueryItem represents a single atomic query condition
@@ -33,6 +44,8 @@ type QueryItem interface {
 	GetEventTypes() []string
 	// GetTags returns the internal tags (used by event store)
 	GetTags() []Tag
+	// String returns a compact, human-readable form, e.g. (type=A AND tags{k=v})
+	String() string
 }
 
 // query is the internal implementation
@@ -50,8 +63,17 @@ func (q *query) GetItems() []QueryItem {
 
 // queryItem is the internal implementation
 type queryItem struct {
-	EventTypes []string `json:"event_types"`
-	Tags       []Tag    `json:"tags"`
+	EventTypes     []string              `json:"event_types"`
+	Tags           []Tag                 `json:"tags"`
+	CausedBy       *int64                `json:"caused_by,omitempty"`
+	AnyTags        [][]Tag               `json:"any_tags,omitempty"`         // Each set matches events carrying any of its tags
+	WithoutTagKeys []string              `json:"without_tag_keys,omitempty"` // Tag keys the event must not carry (WithoutTagKey)
+	CITags         []Tag                 `json:"ci_tags,omitempty"`          // Tags whose values match case-insensitively (WithTagCI)
+	NumericTags    []NumericTagCondition `json:"numeric_tags,omitempty"`     // Numeric tag value comparisons (WithTagNumeric)
+	FromPosition   *int64                `json:"from_position,omitempty"`    // Inclusive lower position bound (BetweenPositions)
+	ToPosition     *int64                `json:"to_position,omitempty"`      // Inclusive upper position bound (BetweenPositions)
+	TransactionID  *uint64               `json:"transaction_id,omitempty"`   // Appending transaction (WithTransactionID)
+	MatchAll       bool                  `json:"match_all,omitempty"`        // Intentional match-all item (NewQueryAll)
 }
 
 // isQueryItem implements QueryItem
@@ -67,43 +89,205 @@ func (qi *queryItem) GetTags() []Tag {
 	return qi.Tags
 }
 
-// Query reads events matching the query with optional cursor
-// cursor == nil: query from beginning of stream
-// cursor != nil: query from specified cursor position
-func (es *eventStore) Query(ctx context.Context, query Query, after *Cursor) ([]Event, error) {
-	if len(query.GetItems()) == 0 {
-		return nil, &ValidationError{
+// hasExtendedPredicates reports whether the item uses predicates beyond event types and tags
+// Such predicates are supported by reads and projections but not by append conditions
+func (qi *queryItem) hasExtendedPredicates() bool {
+	return qi.CausedBy != nil || len(qi.AnyTags) > 0 || len(qi.CITags) > 0 || qi.FromPosition != nil || qi.ToPosition != nil ||
+		qi.TransactionID != nil || len(qi.WithoutTagKeys) > 0 || len(qi.NumericTags) > 0
+}
+
+// asQueryItem returns the internal implementation of a QueryItem
+func asQueryItem(item QueryItem) (*queryItem, bool) {
+	qi, ok := item.(*queryItem)
+	return qi, ok && qi != nil
+}
+
+// Validate returns a ValidationError for empty or contradictory queries:
+// a query without items, an item without event types, tags or other predicates
+// (use NewQueryAll to match everything), empty tag keys/values or event types,
+// and causation positions or position ranges that can never match
+func (q *query) Validate() error {
+	if len(q.Items) == 0 {
+		return &ValidationError{
 			EventStoreError: EventStoreError{
-				Op:  "query",
-				Err: fmt.Errorf("query must contain at least one item"),
+				Op:  "validate_query",
+				Err: fmt.Errorf("query must contain at least one item (use NewQueryAll to match all events)"),
 			},
 			Field: "query",
 			Value: "empty",
 		}
 	}
 
+	for itemIndex, item := range q.Items {
+		qi, ok := asQueryItem(item)
+		if !ok {
+			return &ValidationError{
+				EventStoreError: EventStoreError{
+					Op:  "validate_query",
+					Err: fmt.Errorf("item %d is nil", itemIndex),
+				},
+				Field: fmt.Sprintf("item[%d]", itemIndex),
+				Value: "nil",
+			}
+		}
+
+		if !qi.MatchAll && len(qi.EventTypes) == 0 && len(qi.Tags) == 0 && !qi.hasExtendedPredicates() {
+			return &ValidationError{
+				EventStoreError: EventStoreError{
+					Op:  "validate_query",
+					Err: fmt.Errorf("item %d has no event types and no tags (use NewQueryAll to match all events)", itemIndex),
+				},
+				Field: fmt.Sprintf("item[%d]", itemIndex),
+				Value: "empty",
+			}
+		}
+
+		for setIndex, anyTags := range qi.AnyTags {
+			if len(anyTags) == 0 {
+				return &ValidationError{
+					EventStoreError: EventStoreError{
+						Op:  "validate_query",
+						Err: fmt.Errorf("item %d has an empty any-tag-value set, which matches no event", itemIndex),
+					},
+					Field: fmt.Sprintf("item[%d].anyTags[%d]", itemIndex, setIndex),
+					Value: "empty",
+				}
+			}
+			for i, t := range anyTags {
+				if t.GetKey() == "" || t.GetValue() == "" {
+					return &ValidationError{
+						EventStoreError: EventStoreError{
+							Op:  "validate_query",
+							Err: fmt.Errorf("empty key or value in any-tag-value set %d of item %d", setIndex, itemIndex),
+						},
+						Field: fmt.Sprintf("item[%d].anyTags[%d][%d]", itemIndex, setIndex, i),
+						Value: t.GetKey(),
+					}
+				}
+			}
+		}
+
+		for i, key := range qi.WithoutTagKeys {
+			if key == "" || strings.Contains(key, ":") {
+				return &ValidationError{
+					EventStoreError: EventStoreError{
+						Op:  "validate_query",
+						Err: fmt.Errorf("invalid tag key %q in without-tag-key condition %d of item %d", key, i, itemIndex),
+					},
+					Field: fmt.Sprintf("item[%d].withoutTagKeys[%d]", itemIndex, i),
+					Value: key,
+				}
+			}
+		}
+
+		for i, t := range qi.CITags {
+			if t.GetKey() == "" || t.GetValue() == "" {
+				return &ValidationError{
+					EventStoreError: EventStoreError{
+						Op:  "validate_query",
+						Err: fmt.Errorf("empty key or value in case-insensitive tag %d of item %d", i, itemIndex),
+					},
+					Field: fmt.Sprintf("item[%d].ciTags[%d]", itemIndex, i),
+					Value: t.GetKey(),
+				}
+			}
+		}
+
+		for i, c := range qi.NumericTags {
+			if err := validateNumericTag(c, itemIndex, i); err != nil {
+				return err
+			}
+		}
+
+		if qi.CausedBy != nil && *qi.CausedBy <= 0 {
+			return &ValidationError{
+				EventStoreError: EventStoreError{
+					Op:  "validate_query",
+					Err: fmt.Errorf("item %d filters on causation position %d, which no event can have", itemIndex, *qi.CausedBy),
+				},
+				Field: fmt.Sprintf("item[%d].causedBy", itemIndex),
+				Value: fmt.Sprintf("%d", *qi.CausedBy),
+			}
+		}
+
+		if qi.ToPosition != nil && *qi.ToPosition <= 0 {
+			return &ValidationError{
+				EventStoreError: EventStoreError{
+					Op:  "validate_query",
+					Err: fmt.Errorf("item %d has upper position bound %d, which no event can have", itemIndex, *qi.ToPosition),
+				},
+				Field: fmt.Sprintf("item[%d].toPosition", itemIndex),
+				Value: fmt.Sprintf("%d", *qi.ToPosition),
+			}
+		}
+		if qi.FromPosition != nil && qi.ToPosition != nil && *qi.FromPosition > *qi.ToPosition {
+			return &ValidationError{
+				EventStoreError: EventStoreError{
+					Op:  "validate_query",
+					Err: fmt.Errorf("item %d has an empty position range [%d, %d]", itemIndex, *qi.FromPosition, *qi.ToPosition),
+				},
+				Field: fmt.Sprintf("item[%d].positions", itemIndex),
+				Value: fmt.Sprintf("%d..%d", *qi.FromPosition, *qi.ToPosition),
+			}
+		}
+
+		if qi.TransactionID != nil && *qi.TransactionID == 0 {
+			return &ValidationError{
+				EventStoreError: EventStoreError{
+					Op:  "validate_query",
+					Err: fmt.Errorf("item %d filters on transaction id 0, which no event can have", itemIndex),
+				},
+				Field: fmt.Sprintf("item[%d].transactionID", itemIndex),
+				Value: "0",
+			}
+		}
+	}
+
+	return validateQueryTags(q)
+}
+
+// Query reads events matching the query with optional cursor
+// cursor == nil: query from beginning of stream
+// cursor != nil: query from specified cursor position
+func (es *eventStore) Query(ctx context.Context, query Query, after *Cursor) ([]Event, error) {
+	return es.queryLimit(ctx, query, after, nil)
+}
+
+// queryLimit is Query with an optional maximum number of events (nil = no limit)
+func (es *eventStore) queryLimit(ctx context.Context, query Query, after *Cursor, limit *int) ([]Event, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Validate query items
 	if err := validateQueryTags(query); err != nil {
 		return nil, err
 	}
 
 	// Build SQL query based on query items with cursor
-	sqlQuery, args, err := es.buildReadQuerySQL(query, after, nil)
+	sqlQuery, args, err := es.buildReadQuerySQL(query, after, limit)
 	if err != nil {
-		return nil, &EventStoreError{
-			Op:  "query",
-			Err: fmt.Errorf("failed to build SQL query: %w", err),
+		return nil, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "query",
+				Err: fmt.Errorf("failed to build SQL query: %w", err),
+			},
+			Resource: "database",
 		}
 	}
 
 	// Execute query within a transaction for consistency
 	var events []Event
 	err = es.executeReadInTx(ctx, func(tx pgx.Tx) error {
+		events = nil
 		rows, err := tx.Query(ctx, sqlQuery, args...)
 		if err != nil {
-			return &EventStoreError{
-				Op:  "query",
-				Err: fmt.Errorf("failed to execute query: %w", err),
+			return &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "query",
+					Err: fmt.Errorf("failed to execute query: %w", err),
+				},
+				Resource: "database",
 			}
 		}
 		defer rows.Close()
@@ -111,27 +295,26 @@ func (es *eventStore) Query(ctx context.Context, query Query, after *Cursor) ([]
 		// Scan results
 		for rows.Next() {
 			var row rowEvent
-			err := rows.Scan(
-				&row.Type,
-				&row.Tags,
-				&row.Data,
-				&row.TransactionID,
-				&row.Position,
-				&row.OccurredAt,
-			)
+			err := rows.Scan(row.scanTargets()...)
 			if err != nil {
-				return &EventStoreError{
-					Op:  "query",
-					Err: fmt.Errorf("failed to scan event: %w", err),
+				return &ResourceError{
+					EventStoreError: EventStoreError{
+						Op:  "query",
+						Err: fmt.Errorf("failed to scan event: %w", err),
+					},
+					Resource: "database",
 				}
 			}
 			events = append(events, convertRowToEvent(row))
 		}
 
 		if err := rows.Err(); err != nil {
-			return &EventStoreError{
-				Op:  "query",
-				Err: fmt.Errorf("error iterating over rows: %w", err),
+			return &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "query",
+					Err: fmt.Errorf("error iterating over rows: %w", err),
+				},
+				Resource: "database",
 			}
 		}
 
@@ -151,19 +334,13 @@ func (es *eventStore) Query(ctx context.Context, query Query, after *Cursor) ([]
 // This is optimized for large datasets and provides backpressure through channels
 // for efficient memory usage and Go-idiomatic streaming
 func (es *eventStore) QueryStream(ctx context.Context, query Query, after *Cursor) (<-chan Event, error) {
-	if len(query.GetItems()) == 0 {
-		return nil, &ValidationError{
-			EventStoreError: EventStoreError{
-				Op:  "query_stream",
-				Err: fmt.Errorf("query must contain at least one item"),
-			},
-			Field: "query",
-			Value: "empty",
-		}
+	if err := query.Validate(); err != nil {
+		return nil, err
 	}
 
-	// Validate query items
-	if err := validateQueryTags(query); err != nil {
+	// Reserve a stream slot; released when the stream ends
+	release, err := es.acquireStreamSlot("query_stream")
+	if err != nil {
 		return nil, err
 	}
 
@@ -172,6 +349,7 @@ func (es *eventStore) QueryStream(ctx context.Context, query Query, after *Curso
 
 	// Start goroutine to stream events
 	go func() {
+		defer release()
 		defer close(eventChan)
 
 		// Build SQL query with cursor
@@ -182,7 +360,7 @@ func (es *eventStore) QueryStream(ctx context.Context, query Query, after *Curso
 		}
 
 		// Execute query using caller's context (caller controls timeout)
-		rows, err := es.pool.Query(ctx, sqlQuery, args...)
+		rows, err := es.streamQuery(ctx, sqlQuery, args...)
 		if err != nil {
 			return
 		}
@@ -191,14 +369,7 @@ func (es *eventStore) QueryStream(ctx context.Context, query Query, after *Curso
 		// Stream events
 		for rows.Next() {
 			var row rowEvent
-			err := rows.Scan(
-				&row.Type,
-				&row.Tags,
-				&row.Data,
-				&row.TransactionID,
-				&row.Position,
-				&row.OccurredAt,
-			)
+			err := rows.Scan(row.scanTargets()...)
 			if err != nil {
 				return
 			}
@@ -220,6 +391,96 @@ func (es *eventStore) QueryStream(ctx context.Context, query Query, after *Curso
 	return eventChan, nil
 }
 
+// ExistsAny reports which of the given tag values already have an event of eventType
+// This replaces one existence projector per value with a single round trip using tag overlap (&&)
+func (es *eventStore) ExistsAny(ctx context.Context, eventType string, tagKey string, values []string) (map[string]bool, error) {
+	if tagKey == "" {
+		return nil, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "exists_any",
+				Err: fmt.Errorf("tag key cannot be empty"),
+			},
+			Field: "tagKey",
+			Value: "empty",
+		}
+	}
+
+	result := make(map[string]bool, len(values))
+	candidates := make([]string, 0, len(values))
+	for i, value := range values {
+		if value == "" {
+			return nil, &ValidationError{
+				EventStoreError: EventStoreError{
+					Op:  "exists_any",
+					Err: fmt.Errorf("empty value at index %d for key %s", i, tagKey),
+				},
+				Field: fmt.Sprintf("values[%d]", i),
+				Value: "empty",
+			}
+		}
+		result[value] = false
+		candidates = append(candidates, tagKey+":"+value)
+	}
+
+	if len(candidates) == 0 {
+		return result, nil
+	}
+
+	// Only the tags present in the candidate list are returned
+	sqlQuery := `SELECT DISTINCT t FROM ` + es.eventsSource() + `, unnest(tags) AS t WHERE tags && $1::text[] AND t = ANY($1::text[])`
+	args := []interface{}{candidates}
+	if eventType != "" {
+		sqlQuery += ` AND type = $2`
+		args = append(args, eventType)
+	}
+
+	err := es.executeReadInTx(ctx, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, sqlQuery, args...)
+		if err != nil {
+			return &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "exists_any",
+					Err: fmt.Errorf("failed to execute query: %w", err),
+				},
+				Resource: "database",
+			}
+		}
+		defer rows.Close()
+
+		prefix := tagKey + ":"
+		for rows.Next() {
+			var found string
+			if err := rows.Scan(&found); err != nil {
+				return &ResourceError{
+					EventStoreError: EventStoreError{
+						Op:  "exists_any",
+						Err: fmt.Errorf("failed to scan tag: %w", err),
+					},
+					Resource: "database",
+				}
+			}
+			result[strings.TrimPrefix(found, prefix)] = true
+		}
+
+		if err := rows.Err(); err != nil {
+			return &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "exists_any",
+					Err: fmt.Errorf("error iterating over rows: %w", err),
+				},
+				Resource: "database",
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 // TagsToArray converts a slice of Tags to a PostgreSQL TEXT[] array
 func TagsToArray(tags []Tag) []string {
 	if len(tags) == 0 {
@@ -0,0 +1,214 @@
+package dcb
+
+import (
+	"fmt"
+	"slices"
+)
+
+// =============================================================================
+// Query Combination
+// =============================================================================
+
+// UnionQueries returns a query matching the events any of queries matches: the items of all
+// queries in order (OR), without duplicates. Use it to read, project or build one AppendCondition
+// over several sub-queries, e.g. those of a command handler's decision models. Items are
+// duplicates when they have the same conditions, whatever the order of their event types and tags.
+// Nil queries and items are skipped; without items the result is an empty query, which Validate rejects.
+// As with any multi-item condition, AppendIf checks the event types and tags of all items as one
+// combined match, while AppendIfNotExists checks each item on its own
+func UnionQueries(queries ...Query) Query {
+	var items []QueryItem
+	seen := make(map[string]bool)
+	for _, q := range queries {
+		if q == nil {
+			continue
+		}
+		for _, item := range q.GetItems() {
+			qi, ok := asQueryItem(item)
+			if !ok {
+				continue
+			}
+			if key := qi.hash(); !seen[key] {
+				seen[key] = true
+				items = append(items, qi)
+			}
+		}
+	}
+	if items == nil {
+		return NewQueryEmpty()
+	}
+	return &query{Items: items}
+}
+
+// IntersectQueries returns a query matching the events every one of queries matches (AND).
+// Each query is an OR of items, so the result has one item per combination of items that can
+// match together: their tags and other conditions are ANDed and their event types intersected
+// (an item without event types allows any). Combinations that can never match, such as disjoint
+// event types or different causation positions, are dropped, and duplicates are removed as in
+// UnionQueries. When no combination can match the result is an empty query, which Validate rejects
+func IntersectQueries(queries ...Query) Query {
+	var (
+		combined []*queryItem
+		started  bool
+	)
+	for _, q := range queries {
+		if q == nil {
+			continue
+		}
+		var items []*queryItem
+		for _, item := range q.GetItems() {
+			if qi, ok := asQueryItem(item); ok {
+				items = append(items, qi)
+			}
+		}
+		if !started {
+			combined, started = items, true
+			continue
+		}
+		var next []*queryItem
+		for _, left := range combined {
+			for _, right := range items {
+				if item, ok := intersectItems(left, right); ok {
+					next = append(next, item)
+				}
+			}
+		}
+		combined = next
+	}
+
+	items := make([]QueryItem, len(combined))
+	for i, item := range combined {
+		items[i] = item
+	}
+	return UnionQueries(&query{Items: items})
+}
+
+// intersectItems returns the item matching the events both a and b match, or false if none can
+func intersectItems(a, b *queryItem) (*queryItem, bool) {
+	item := &queryItem{
+		Tags:           mergeTags(a.Tags, b.Tags),
+		AnyTags:        slices.Concat(a.AnyTags, b.AnyTags),
+		WithoutTagKeys: slices.Concat(a.WithoutTagKeys, b.WithoutTagKeys),
+		CITags:         slices.Concat(a.CITags, b.CITags),
+		NumericTags:    slices.Concat(a.NumericTags, b.NumericTags),
+		MatchAll:       a.MatchAll && b.MatchAll,
+	}
+
+	switch {
+	case len(a.EventTypes) == 0:
+		item.EventTypes = slices.Clone(b.EventTypes)
+	case len(b.EventTypes) == 0:
+		item.EventTypes = slices.Clone(a.EventTypes)
+	default:
+		for _, eventType := range a.EventTypes {
+			if slices.Contains(b.EventTypes, eventType) && !slices.Contains(item.EventTypes, eventType) {
+				item.EventTypes = append(item.EventTypes, eventType)
+			}
+		}
+		if len(item.EventTypes) == 0 {
+			return nil, false
+		}
+	}
+
+	var ok bool
+	if item.CausedBy, ok = samePointer(a.CausedBy, b.CausedBy); !ok {
+		return nil, false
+	}
+	if item.TransactionID, ok = samePointer(a.TransactionID, b.TransactionID); !ok {
+		return nil, false
+	}
+	item.FromPosition = boundPointer(a.FromPosition, b.FromPosition, true)
+	item.ToPosition = boundPointer(a.ToPosition, b.ToPosition, false)
+	if item.FromPosition != nil && item.ToPosition != nil && *item.FromPosition > *item.ToPosition {
+		return nil, false
+	}
+
+	// A match-all item ANDed with conditions is just those conditions
+	if !item.MatchAll && len(item.EventTypes) == 0 && len(item.Tags) == 0 && !item.hasExtendedPredicates() {
+		item.MatchAll = true
+	}
+	return item, true
+}
+
+// mergeTags returns the tags of a followed by those of b that a doesn't have
+func mergeTags(a, b []Tag) []Tag {
+	merged := slices.Clone(a)
+	for _, t := range b {
+		if !slices.ContainsFunc(merged, func(m Tag) bool { return m.GetKey() == t.GetKey() && m.GetValue() == t.GetValue() }) {
+			merged = append(merged, t)
+		}
+	}
+	return merged
+}
+
+// samePointer returns whichever of a and b is set, or false if both are set to different values
+func samePointer[T comparable](a, b *T) (*T, bool) {
+	switch {
+	case a == nil:
+		return b, true
+	case b == nil || *a == *b:
+		return a, true
+	default:
+		return nil, false
+	}
+}
+
+// boundPointer returns the tighter of two optional bounds: the greater lower or the smaller upper bound
+func boundPointer(a, b *int64, lower bool) *int64 {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	default:
+		if (*a > *b) == lower {
+			return a
+		}
+		return b
+	}
+}
+
+// hash returns a key identifying the item's conditions: items with the same hash match the same
+// events. Event types and tags are sorted, so their order doesn't matter
+func (qi *queryItem) hash() string {
+	anyTags := make([][]string, len(qi.AnyTags))
+	for i, set := range qi.AnyTags {
+		anyTags[i] = sortedTagStrings(set)
+	}
+	slices.SortFunc(anyTags, slices.Compare)
+	numericTags := make([]string, len(qi.NumericTags))
+	for i, c := range qi.NumericTags {
+		numericTags[i] = fmt.Sprintf("%s%s%v", c.Key, c.Op, c.Value)
+	}
+	slices.Sort(numericTags)
+	return fmt.Sprintf("%q|%q|%q|%q|%q|%q|%s|%s|%s|%s|%t",
+		slices.Sorted(slices.Values(qi.EventTypes)),
+		sortedTagStrings(qi.Tags),
+		anyTags,
+		slices.Sorted(slices.Values(qi.WithoutTagKeys)),
+		sortedTagStrings(qi.CITags),
+		numericTags,
+		formatPointer(qi.CausedBy),
+		formatPointer(qi.FromPosition),
+		formatPointer(qi.ToPosition),
+		formatPointer(qi.TransactionID),
+		qi.MatchAll)
+}
+
+// sortedTagStrings returns tags as sorted "key:value" strings
+func sortedTagStrings(tags []Tag) []string {
+	strs := make([]string, len(tags))
+	for i, t := range tags {
+		strs[i] = t.GetKey() + ":" + t.GetValue()
+	}
+	slices.Sort(strs)
+	return strs
+}
+
+// formatPointer formats an optional value, "-" when unset
+func formatPointer[T any](p *T) string {
+	if p == nil {
+		return "-"
+	}
+	return fmt.Sprint(*p)
+}
@@ -0,0 +1,63 @@
+package dcb
+
+import "testing"
+
+func TestUnionQueries(t *testing.T) {
+	course := NewQuery(NewTags("course_id", "c1"), "CourseDefined", "CourseChanged")
+	student := NewQuery(NewTags("student_id", "s1"), "StudentRegistered")
+	// Same item with types and tags in another order
+	courseAgain := NewQuery(NewTags("course_id", "c1"), "CourseChanged", "CourseDefined")
+
+	union := UnionQueries(course, nil, student, courseAgain)
+	want := "(type IN [CourseDefined,CourseChanged] AND tags{course_id=c1}) OR (type=StudentRegistered AND tags{student_id=s1})"
+	if got := union.String(); got != want {
+		t.Errorf("unexpected union:\n got %s\nwant %s", got, want)
+	}
+	if err := union.Validate(); err != nil {
+		t.Errorf("expected a valid union, got %v", err)
+	}
+
+	// Different extended predicates are different items
+	ranged := NewQueryBuilder().WithType("CourseDefined").BetweenPositions(1, 10).Build()
+	if items := UnionQueries(NewQuery(nil, "CourseDefined"), ranged).GetItems(); len(items) != 2 {
+		t.Errorf("expected 2 items, got %d", len(items))
+	}
+
+	if err := UnionQueries().Validate(); err == nil {
+		t.Error("expected the union of no queries to be rejected")
+	}
+}
+
+func TestIntersectQueries(t *testing.T) {
+	types := NewQueryFromItems(
+		NewQueryItem([]string{"CourseDefined", "CourseChanged"}, nil),
+		NewQueryItem([]string{"StudentRegistered"}, nil),
+	)
+	course := NewQuery(NewTags("course_id", "c1"), "CourseChanged", "SeatReserved")
+
+	got := IntersectQueries(types, nil, course).String()
+	// StudentRegistered has no type in common with the course item, so that combination is dropped
+	if want := "(type=CourseChanged AND tags{course_id=c1})"; got != want {
+		t.Errorf("unexpected intersection:\n got %s\nwant %s", got, want)
+	}
+
+	// Without event types an item only adds its tags; match-all items add nothing
+	tagged := IntersectQueries(NewQueryAll(), NewQuery(NewTags("course_id", "c1")), NewQuery(NewTags("course_id", "c1"), "CourseDefined"))
+	if want := "(type=CourseDefined AND tags{course_id=c1})"; tagged.String() != want {
+		t.Errorf("unexpected intersection:\n got %s\nwant %s", tagged, want)
+	}
+	if got := IntersectQueries(NewQueryAll(), NewQueryAll()).String(); got != "(all)" {
+		t.Errorf("expected a match-all query, got %s", got)
+	}
+
+	// Disjoint position ranges can never match together
+	early := NewQueryBuilder().WithType("CourseDefined").BetweenPositions(1, 5).Build()
+	late := NewQueryBuilder().WithType("CourseDefined").BetweenPositions(6, 10).Build()
+	if err := IntersectQueries(early, late).Validate(); err == nil {
+		t.Error("expected an intersection that can never match to be rejected")
+	}
+	overlap := NewQueryBuilder().WithType("CourseDefined").BetweenPositions(3, 8).Build()
+	if got := IntersectQueries(early, overlap).String(); got != "(type=CourseDefined AND position>=3 AND position<=5)" {
+		t.Errorf("expected the overlapping range, got %s", got)
+	}
+}
@@ -0,0 +1,76 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Query ETag
+// =============================================================================
+
+// QueryETag returns a weak HTTP ETag (W/"...") for the events matching query, so an HTTP layer
+// can answer 304 Not Modified while it is unchanged. It hashes the count and highest position of
+// the matching events, computed in one aggregate query without reading them: appends only add
+// positions, and the count also catches an event that commits below the highest position
+// already seen (positions commit out of order). Events in the archive table are included when
+// EventStoreConfig.ArchiveTable is set, so archiving doesn't change the tag
+func (es *eventStore) QueryETag(ctx context.Context, query Query) (string, error) {
+	if query == nil {
+		return "", &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "queryETag",
+				Err: fmt.Errorf("query cannot be nil"),
+			},
+			Field: "query",
+			Value: "nil",
+		}
+	}
+	if err := query.Validate(); err != nil {
+		return "", err
+	}
+	if err := validateQueryTags(query); err != nil {
+		return "", err
+	}
+
+	innerSQL, args, err := es.buildReadQuerySQL(query, nil, nil)
+	if err != nil {
+		return "", &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "queryETag",
+				Err: fmt.Errorf("failed to build SQL query: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	sqlQuery := "SELECT COUNT(*), COALESCE(MAX(position), 0) FROM (" + strings.TrimSuffix(innerSQL, readOrderBy) + ") AS e"
+
+	var count, maxPosition int64
+	err = es.executeReadInTx(ctx, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, sqlQuery, args...).Scan(&count, &maxPosition); err != nil {
+			return &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "queryETag",
+					Err: fmt.Errorf("failed to read query checksum: %w", err),
+				},
+				Resource: "database",
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return queryETag(count, maxPosition), nil
+}
+
+// queryETag formats the weak ETag of a query result with count events up to maxPosition
+func queryETag(count, maxPosition int64) string {
+	hash := fnv.New64a()
+	fmt.Fprintf(hash, "%d:%d", count, maxPosition)
+	return fmt.Sprintf(`W/"%016x"`, hash.Sum64())
+}
@@ -0,0 +1,27 @@
+package dcb
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestQueryETag(t *testing.T) {
+	etag := queryETag(3, 42)
+	if !strings.HasPrefix(etag, `W/"`) || !strings.HasSuffix(etag, `"`) {
+		t.Errorf("expected a weak ETag, got %s", etag)
+	}
+	if queryETag(3, 42) != etag {
+		t.Error("expected the same result to give the same ETag")
+	}
+	for _, changed := range []string{queryETag(4, 42), queryETag(3, 43), queryETag(0, 0)} {
+		if changed == etag {
+			t.Errorf("expected a different ETag for a different result, got %s", changed)
+		}
+	}
+
+	es := newEventStore(nil, EventStoreConfig{})
+	if _, err := es.QueryETag(context.Background(), nil); !IsValidationError(err) {
+		t.Errorf("expected ValidationError for a nil query, got %v", err)
+	}
+}
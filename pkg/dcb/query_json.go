@@ -0,0 +1,177 @@
+package dcb
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// =============================================================================
+// Query and Condition Serialization
+// =============================================================================
+
+// Query, QueryItem, Tag and AppendCondition marshal to JSON (and, through MarshalBinary, to the same
+// bytes), so HTTP, gRPC or a queue can carry them without converting to their own types. The wire
+// format is stable:
+//
+//	Tag:             {"key": "course_id", "value": "c1"}
+//	QueryItem:       {"event_types": ["A", "B"], "tags": [Tag, ...]} plus, when set, "caused_by",
+//	                 "any_tags" ([[Tag, ...], ...]), "without_tag_keys", "ci_tags" ([Tag, ...]),
+//	                 "numeric_tags" ([{"key": "priority", "op": ">=", "value": 3}, ...]),
+//	                 "from_position", "to_position", "transaction_id" and "match_all" (NewQueryAll)
+//	Query:           {"items": [QueryItem, ...]}
+//	AppendCondition: {"fail_if_events_match": Query or null,
+//	                  "after_cursor": {"transaction_id": 42, "position": 7} or null}
+//
+// The types are interfaces, so decode them with UnmarshalQuery, UnmarshalQueryItem, UnmarshalTag and
+// UnmarshalAppendCondition. Decoding doesn't validate: Validate and the store reject invalid queries
+// when they are used, as for queries built in code
+
+// UnmarshalQuery decodes a Query from its JSON wire format
+func UnmarshalQuery(data []byte) (Query, error) {
+	q := &query{}
+	if err := unmarshalWire("unmarshal_query", data, q); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// UnmarshalQueryItem decodes a QueryItem from its JSON wire format
+func UnmarshalQueryItem(data []byte) (QueryItem, error) {
+	qi := &queryItem{}
+	if err := unmarshalWire("unmarshal_query_item", data, qi); err != nil {
+		return nil, err
+	}
+	return qi, nil
+}
+
+// UnmarshalTag decodes a Tag from its JSON wire format
+func UnmarshalTag(data []byte) (Tag, error) {
+	t := &tag{}
+	if err := unmarshalWire("unmarshal_tag", data, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// UnmarshalAppendCondition decodes an AppendCondition from its JSON wire format
+func UnmarshalAppendCondition(data []byte) (AppendCondition, error) {
+	ac := &appendCondition{}
+	if err := unmarshalWire("unmarshal_append_condition", data, ac); err != nil {
+		return nil, err
+	}
+	return ac, nil
+}
+
+// unmarshalWire decodes data into v, reporting malformed input as a ValidationError
+func unmarshalWire(op string, data []byte, v any) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return &ValidationError{
+			EventStoreError: EventStoreError{Op: op, Err: fmt.Errorf("invalid JSON: %w", err)},
+			Field:           "data",
+			Value:           string(data),
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes {"key": ..., "value": ...}
+func (t *tag) UnmarshalJSON(data []byte) error {
+	var wire struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	t.key, t.value = wire.Key, wire.Value
+	return nil
+}
+
+// UnmarshalJSON decodes the item's fields, with tags in the Tag wire format
+func (qi *queryItem) UnmarshalJSON(data []byte) error {
+	type fields queryItem // without the method, so decoding it doesn't recurse
+	var wire struct {
+		fields
+		Tags    []*tag   `json:"tags"`
+		AnyTags [][]*tag `json:"any_tags"`
+		CITags  []*tag   `json:"ci_tags"`
+	}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+
+	item := queryItem(wire.fields)
+	var err error
+	if item.Tags, err = wireTags(wire.Tags); err != nil {
+		return err
+	}
+	if item.CITags, err = wireTags(wire.CITags); err != nil {
+		return err
+	}
+	for _, set := range wire.AnyTags {
+		tags, err := wireTags(set)
+		if err != nil {
+			return err
+		}
+		item.AnyTags = append(item.AnyTags, tags)
+	}
+	*qi = item
+	return nil
+}
+
+// UnmarshalJSON decodes {"items": [...]}
+func (q *query) UnmarshalJSON(data []byte) error {
+	var wire struct {
+		Items []*queryItem `json:"items"`
+	}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	q.Items = make([]QueryItem, len(wire.Items))
+	for i, item := range wire.Items {
+		if item == nil {
+			return fmt.Errorf("query item %d is null", i)
+		}
+		q.Items[i] = item
+	}
+	return nil
+}
+
+// wireTags converts decoded tags to []Tag, keeping nil as nil and rejecting null entries
+func wireTags(decoded []*tag) ([]Tag, error) {
+	if decoded == nil {
+		return nil, nil
+	}
+	tags := make([]Tag, len(decoded))
+	for i, t := range decoded {
+		if t == nil {
+			return nil, fmt.Errorf("tag %d is null", i)
+		}
+		tags[i] = t
+	}
+	return tags, nil
+}
+
+// MarshalBinary encodes the tag in its JSON wire format
+func (t *tag) MarshalBinary() ([]byte, error) { return json.Marshal(t) }
+
+// UnmarshalBinary decodes the tag from its JSON wire format
+func (t *tag) UnmarshalBinary(data []byte) error { return json.Unmarshal(data, t) }
+
+// MarshalBinary encodes the item in its JSON wire format
+func (qi *queryItem) MarshalBinary() ([]byte, error) { return json.Marshal(qi) }
+
+// UnmarshalBinary decodes the item from its JSON wire format
+func (qi *queryItem) UnmarshalBinary(data []byte) error { return json.Unmarshal(data, qi) }
+
+// MarshalBinary encodes the query in its JSON wire format
+func (q *query) MarshalBinary() ([]byte, error) { return json.Marshal(q) }
+
+// UnmarshalBinary decodes the query from its JSON wire format
+func (q *query) UnmarshalBinary(data []byte) error { return json.Unmarshal(data, q) }
+
+// MarshalBinary encodes the condition in its JSON wire format
+func (ac *appendCondition) MarshalBinary() ([]byte, error) { return json.Marshal(ac) }
+
+// UnmarshalBinary decodes the condition from its JSON wire format
+func (ac *appendCondition) UnmarshalBinary(data []byte) error { return json.Unmarshal(data, ac) }
@@ -0,0 +1,111 @@
+package dcb
+
+import (
+	"encoding"
+	"encoding/json"
+	"testing"
+)
+
+func TestQueryJSONRoundTrip(t *testing.T) {
+	queries := map[string]Query{
+		"empty":     NewQueryEmpty(),
+		"all":       NewQueryAll(),
+		"tags only": NewQuery(NewTags("course_id", "c1")),
+		"extended": NewQueryBuilder().
+			WithTypes("Enrolled", "Dropped").WithTag("course_id", "c1").
+			WithAnyTagValue("student_id", []string{"s1", "s2"}).WithoutTagKey("archived").
+			WithTagCI("email", "A@B.c").BetweenPositions(3, 9).WithCausedBy(2).WithTransactionID(42).
+			AddItem().WithType("CourseDefined").
+			Build(),
+	}
+	for name, q := range queries {
+		data, err := json.Marshal(q)
+		if err != nil {
+			t.Fatalf("%s: marshal failed: %v", name, err)
+		}
+		decoded, err := UnmarshalQuery(data)
+		if err != nil {
+			t.Fatalf("%s: unmarshal failed: %v", name, err)
+		}
+		again, _ := json.Marshal(decoded)
+		if string(again) != string(data) {
+			t.Errorf("%s: round trip changed the query:\n%s\n%s", name, data, again)
+		}
+		if decoded.String() != q.String() {
+			t.Errorf("%s: expected %s, got %s", name, q, decoded)
+		}
+	}
+}
+
+func TestQueryWireFormat(t *testing.T) {
+	data, _ := json.Marshal(NewQuery(NewTags("course_id", "c1"), "Enrolled"))
+	want := `{"items":[{"event_types":["Enrolled"],"tags":[{"key":"course_id","value":"c1"}]}]}`
+	if string(data) != want {
+		t.Errorf("expected %s, got %s", want, data)
+	}
+
+	tag, err := UnmarshalTag([]byte(`{"key":"course_id","value":"c1"}`))
+	if err != nil || tag.GetKey() != "course_id" || tag.GetValue() != "c1" {
+		t.Errorf("expected course_id:c1, got %v, %v", tag, err)
+	}
+	item, err := UnmarshalQueryItem([]byte(`{"event_types":["Enrolled"],"tags":[{"key":"course_id","value":"c1"}]}`))
+	if err != nil || item.String() != NewQueryItem([]string{"Enrolled"}, NewTags("course_id", "c1")).String() {
+		t.Errorf("unexpected item %v, %v", item, err)
+	}
+}
+
+func TestAppendConditionJSONRoundTrip(t *testing.T) {
+	afterPosition := NewAppendCondition(NewQuery(NewTags("course_id", "c1"), "Enrolled"))
+	afterPosition.setAfterCursor(&Cursor{TransactionID: 42, Position: 7})
+
+	conditions := map[string]AppendCondition{
+		"after position": afterPosition,
+		"without after":  FailIfExists("course_id", "c1"),
+		"empty":          NewAppendCondition(nil),
+	}
+	for name, condition := range conditions {
+		data, err := json.Marshal(condition)
+		if err != nil {
+			t.Fatalf("%s: marshal failed: %v", name, err)
+		}
+		decoded, err := UnmarshalAppendCondition(data)
+		if err != nil {
+			t.Fatalf("%s: unmarshal failed: %v", name, err)
+		}
+		again, _ := json.Marshal(decoded)
+		if string(again) != string(data) {
+			t.Errorf("%s: round trip changed the condition:\n%s\n%s", name, data, again)
+		}
+		if describeCondition(decoded) != describeCondition(condition) || decoded.IsEmpty() != condition.IsEmpty() {
+			t.Errorf("%s: expected %s, got %s", name, describeCondition(condition), describeCondition(decoded))
+		}
+	}
+
+	decoded, _ := UnmarshalAppendCondition([]byte(`{"fail_if_events_match":null,"after_cursor":{"transaction_id":42,"position":7}}`))
+	if position, ok := decoded.AfterPosition(); !ok || position != 7 {
+		t.Errorf("expected after position 7, got %d, %v", position, ok)
+	}
+}
+
+func TestQueryBinaryRoundTrip(t *testing.T) {
+	q := NewQuery(NewTags("course_id", "c1"), "Enrolled")
+	data, err := q.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	decoded := NewQueryEmpty()
+	if err := decoded.(encoding.BinaryUnmarshaler).UnmarshalBinary(data); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if decoded.String() != q.String() {
+		t.Errorf("expected %s, got %s", q, decoded)
+	}
+}
+
+func TestUnmarshalQueryInvalid(t *testing.T) {
+	for _, data := range []string{`not json`, `{"items":[null]}`, `{"items":[{"tags":[null]}]}`, `{"items":"x"}`} {
+		if _, err := UnmarshalQuery([]byte(data)); !IsValidationError(err) {
+			t.Errorf("expected a validation error for %s, got %v", data, err)
+		}
+	}
+}
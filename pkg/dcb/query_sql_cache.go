@@ -0,0 +1,165 @@
+package dcb
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// =============================================================================
+// Query SQL Cache
+// =============================================================================
+
+// querySQLCacheSize bounds the number of cached query shapes; the cache is reset when full
+const querySQLCacheSize = 1024
+
+// QuerySQLCacheStats reports how often reads reused cached SQL (EventStoreConfig.DisableQuerySQLCache)
+type QuerySQLCacheStats struct {
+	Hits   int64 // Reads whose SQL text came from the cache
+	Misses int64 // Reads whose SQL text was generated, and cached for their shape
+	Size   int   // Query shapes currently cached
+}
+
+// HitRate returns the share of reads served from the cache, 0 before the first read
+func (s QuerySQLCacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// QuerySQLCacheStats returns the counters of the store's query SQL cache. They are shared by all
+// copies of a store, e.g. WithDefaultTimeouts wrappers and transaction-scoped stores
+func (es *eventStore) QuerySQLCacheStats() QuerySQLCacheStats {
+	return es.querySQL.stats()
+}
+
+// querySQLCache maps query shapes (see readQueryShape) to their SQL text. Only values differ
+// between queries of one shape, and they are bound as arguments, so the text can be reused as is.
+// Reusing the exact text also lets pgx's per-connection statement cache reuse the prepared
+// statement, so the server doesn't parse and plan it again
+type querySQLCache struct {
+	mu      sync.RWMutex
+	entries map[string]string
+	hits    atomic.Int64
+	misses  atomic.Int64
+}
+
+func newQuerySQLCache() *querySQLCache {
+	return &querySQLCache{entries: make(map[string]string)}
+}
+
+// get returns the SQL text cached for shape, counting the hit or miss
+func (c *querySQLCache) get(shape string) (string, bool) {
+	c.mu.RLock()
+	sqlQuery, ok := c.entries[shape]
+	c.mu.RUnlock()
+	if ok {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return sqlQuery, ok
+}
+
+// put caches the SQL text of shape, resetting the cache when it is full
+func (c *querySQLCache) put(shape, sqlQuery string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= querySQLCacheSize {
+		c.entries = make(map[string]string)
+	}
+	c.entries[shape] = sqlQuery
+}
+
+// stats returns the cache counters (zero for a nil cache)
+func (c *querySQLCache) stats() QuerySQLCacheStats {
+	if c == nil {
+		return QuerySQLCacheStats{}
+	}
+	c.mu.RLock()
+	size := len(c.entries)
+	c.mu.RUnlock()
+	return QuerySQLCacheStats{Hits: c.hits.Load(), Misses: c.misses.Load(), Size: size}
+}
+
+// readQueryShape returns the shape of a read, i.e. everything renderReadQuerySQL writes into the
+// SQL text (the source, which predicates each item has, inlined operators and keys, the cursor and
+// the limit), together with the arguments renderReadQuerySQL would bind, in its order. Reads with
+// the same shape have the same SQL text. ok is false when the query can't be rendered, so the
+// renderer reports the error
+func (es *eventStore) readQueryShape(source string, query Query, after *Cursor, limit *int) (shape string, args []any, ok bool) {
+	key := make([]byte, 0, 64)
+	key = append(key, source...)
+	key = append(key, '|')
+	args = make([]any, 0, 8)
+
+	for _, item := range query.GetItems() {
+		key = append(key, '(')
+		if len(item.GetEventTypes()) > 0 {
+			key = append(key, 't')
+			args = append(args, item.GetEventTypes())
+		}
+		if len(item.GetTags()) > 0 {
+			key = append(key, 'g')
+			args = append(args, TagsToArray(item.GetTags()))
+		}
+		if qi, isItem := asQueryItem(item); isItem {
+			for _, anyTags := range qi.AnyTags {
+				key = append(key, 'a')
+				args = append(args, TagsToArray(anyTags))
+			}
+			for _, tagKey := range qi.WithoutTagKeys {
+				key = append(key, 'w')
+				args = append(args, tagKey+":")
+			}
+			for _, ciTag := range qi.CITags {
+				key = append(key, 'c')
+				args = append(args, ciTag.GetKey(), ciTag.GetValue())
+			}
+			for _, numericTag := range qi.NumericTags {
+				if !numericTag.Op.valid() {
+					return "", nil, false
+				}
+				key = append(key, 'n')
+				key = append(key, numericTag.Op...)
+				if es.numericTagValue {
+					// The key is inlined, length-prefixed so it can't run into the next token
+					key = strconv.AppendInt(key, int64(len(numericTag.Key)), 10)
+					key = append(key, ':')
+					key = append(key, numericTag.Key...)
+					args = append(args, numericTag.Value)
+				} else {
+					args = append(args, numericTag.Key, numericTag.Value)
+				}
+			}
+			if qi.CausedBy != nil {
+				key = append(key, 'b')
+				args = append(args, causationMetadataFilter(*qi.CausedBy))
+			}
+			if qi.FromPosition != nil {
+				key = append(key, 'f')
+				args = append(args, *qi.FromPosition)
+			}
+			if qi.ToPosition != nil {
+				key = append(key, 'p')
+				args = append(args, *qi.ToPosition)
+			}
+			if qi.TransactionID != nil {
+				key = append(key, 'x')
+				args = append(args, *qi.TransactionID)
+			}
+		}
+		key = append(key, ')')
+	}
+
+	if after != nil {
+		key = append(key, '@')
+		args = append(args, after.TransactionID, after.Position, after.TransactionID)
+	}
+	if limit != nil {
+		key = append(key, 'L')
+		key = strconv.AppendInt(key, int64(*limit), 10)
+	}
+	return string(key), args, true
+}
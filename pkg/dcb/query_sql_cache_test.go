@@ -0,0 +1,104 @@
+package dcb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadQueryShapeMatchesRenderer(t *testing.T) {
+	limit := 10
+	queries := []Query{
+		NewQueryAll(),
+		NewQuery(NewTags("course_id", "c1"), "CourseDefined", "CourseChanged"),
+		NewQueryBuilder().
+			WithType("TicketOpened").WithTag("project", "p1").WithAnyTagValue("team", []string{"a", "b"}).
+			WithoutTagKey("closed").WithTagCI("owner", "Ann").WithTagNumeric("priority", OpGte, 3).
+			WithCausedBy(7).BetweenPositions(1, 100).
+			AddItem().WithTransactionID(42).
+			AddItem().WithTagNumeric("level", OpLt, 2.5).
+			Build(),
+	}
+	cursors := []*Cursor{nil, {TransactionID: 5, Position: 9}}
+	limits := []*int{nil, &limit}
+
+	for _, indexed := range []bool{false, true} {
+		es := &eventStore{numericTagValue: indexed, lowerTagValues: indexed}
+		for _, query := range queries {
+			for _, after := range cursors {
+				for _, limit := range limits {
+					_, want, err := es.renderReadQuerySQL("events", query, after, limit)
+					if err != nil {
+						t.Fatalf("renderReadQuerySQL: %v", err)
+					}
+					_, got, ok := es.readQueryShape("events", query, after, limit)
+					if !ok || !reflect.DeepEqual(got, want) {
+						t.Errorf("indexed=%v query %s: shape arguments %v, renderer arguments %v", indexed, query, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestQuerySQLCache(t *testing.T) {
+	es := &eventStore{querySQL: newQuerySQLCache()}
+	build := func(courseID string, priority float64) (string, []any) {
+		query := NewQueryBuilder().WithType("CourseDefined").WithTag("course_id", courseID).WithTagNumeric("priority", OpGt, priority).Build()
+		sqlQuery, args, err := es.buildReadQuerySQL(query, nil, nil)
+		if err != nil {
+			t.Fatalf("buildReadQuerySQL: %v", err)
+		}
+		return sqlQuery, args
+	}
+
+	first, firstArgs := build("c1", 1)
+	second, secondArgs := build("c2", 2)
+	if first != second {
+		t.Errorf("expected one SQL text for one shape, got %q and %q", first, second)
+	}
+	if reflect.DeepEqual(firstArgs, secondArgs) || secondArgs[1].([]string)[0] != "course_id:c2" || secondArgs[3] != 2.0 {
+		t.Errorf("expected the second query's own arguments, got %v", secondArgs)
+	}
+	stats := es.QuerySQLCacheStats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Size != 1 || stats.HitRate() != 0.5 {
+		t.Errorf("expected one miss then one hit, got %+v", stats)
+	}
+
+	// Another shape gets its own entry
+	if _, _, err := es.buildReadQuerySQL(NewQuery(NewTags("course_id", "c1")), &Cursor{TransactionID: 1, Position: 1}, nil); err != nil {
+		t.Fatalf("buildReadQuerySQL: %v", err)
+	}
+	if stats := es.QuerySQLCacheStats(); stats.Misses != 2 || stats.Size != 2 {
+		t.Errorf("expected a second shape to miss, got %+v", stats)
+	}
+
+	es.config.DisableQuerySQLCache = true
+	build("c3", 3)
+	if stats := es.QuerySQLCacheStats(); stats.Hits != 1 || stats.Misses != 2 {
+		t.Errorf("expected a disabled cache to be bypassed, got %+v", stats)
+	}
+}
+
+func BenchmarkBuildReadQuerySQL(b *testing.B) {
+	query := NewQueryBuilder().
+		WithTypes("StudentEnrolled", "StudentDropped").WithTag("course_id", "c1").
+		AddItem().WithType("CourseDefined").WithTags("course_id", "c1", "term", "2025").
+		Build()
+	after := &Cursor{TransactionID: 5, Position: 9}
+
+	for _, cached := range []bool{false, true} {
+		name := "uncached"
+		es := &eventStore{}
+		if cached {
+			name, es.querySQL = "cached", newQuerySQLCache()
+		}
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, _, err := es.buildReadQuerySQL(query, after, nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package dcb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// =============================================================================
+// Query String Representation
+// =============================================================================
+
+// String returns a compact, human-readable form of the query for logs and error messages
+// Items are joined with OR, e.g. (type IN [A,B] AND tags{k=v}) OR (type=C);
+// a query without items is "<empty>"
+func (q *query) String() string {
+	if q == nil || len(q.Items) == 0 {
+		return "<empty>"
+	}
+	items := make([]string, len(q.Items))
+	for i, item := range q.Items {
+		if item == nil {
+			items[i] = "(<nil>)"
+			continue
+		}
+		items[i] = item.String()
+	}
+	return strings.Join(items, " OR ")
+}
+
+// String returns a compact, human-readable form of the item: its conditions joined with AND
+// in parentheses, e.g. (type=A AND tags{course_id=c1}); a NewQueryAll item is (all)
+func (qi *queryItem) String() string {
+	if qi == nil {
+		return "(<nil>)"
+	}
+	var conditions []string
+	switch len(qi.EventTypes) {
+	case 0:
+	case 1:
+		conditions = append(conditions, "type="+qi.EventTypes[0])
+	default:
+		conditions = append(conditions, "type IN ["+strings.Join(qi.EventTypes, ",")+"]")
+	}
+	if len(qi.Tags) > 0 {
+		conditions = append(conditions, "tags"+formatTags(qi.Tags, ","))
+	}
+	for _, anyTags := range qi.AnyTags {
+		conditions = append(conditions, "anyTag"+formatTags(anyTags, "|"))
+	}
+	for _, key := range qi.WithoutTagKeys {
+		conditions = append(conditions, "noTag{"+key+"}")
+	}
+	if len(qi.CITags) > 0 {
+		conditions = append(conditions, "tagsCI"+formatTags(qi.CITags, ","))
+	}
+	for _, c := range qi.NumericTags {
+		conditions = append(conditions, fmt.Sprintf("tag{%s%s%v}", c.Key, c.Op, c.Value))
+	}
+	if qi.CausedBy != nil {
+		conditions = append(conditions, fmt.Sprintf("causedBy=%d", *qi.CausedBy))
+	}
+	if qi.FromPosition != nil {
+		conditions = append(conditions, fmt.Sprintf("position>=%d", *qi.FromPosition))
+	}
+	if qi.ToPosition != nil {
+		conditions = append(conditions, fmt.Sprintf("position<=%d", *qi.ToPosition))
+	}
+	if qi.TransactionID != nil {
+		conditions = append(conditions, fmt.Sprintf("transaction=%d", *qi.TransactionID))
+	}
+	if len(conditions) == 0 {
+		if qi.MatchAll {
+			return "(all)"
+		}
+		return "(empty)"
+	}
+	return "(" + strings.Join(conditions, " AND ") + ")"
+}
+
+// formatTags formats tags as {k=v<sep>k=v}
+func formatTags(tags []Tag, sep string) string {
+	pairs := make([]string, len(tags))
+	for i, t := range tags {
+		pairs[i] = t.GetKey() + "=" + t.GetValue()
+	}
+	return "{" + strings.Join(pairs, sep) + "}"
+}
+
+// describeCondition formats an append condition for error messages
+func describeCondition(condition AppendCondition) string {
+	if condition == nil {
+		return "<none>"
+	}
+	description := "<empty>"
+	if q := condition.Query(); q != nil {
+		description = q.String()
+	}
+	if position, ok := condition.AfterPosition(); ok {
+		description += fmt.Sprintf(" after position %d", position)
+	}
+	return description
+}
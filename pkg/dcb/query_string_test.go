@@ -0,0 +1,45 @@
+package dcb
+
+import "testing"
+
+func TestQueryString(t *testing.T) {
+	tests := []struct {
+		name  string
+		query Query
+		want  string
+	}{
+		{
+			"types and tags OR single type",
+			NewQueryBuilder().WithTypes("A", "B").WithTag("k", "v").AddItem().WithType("C").Build(),
+			"(type IN [A,B] AND tags{k=v}) OR (type=C)",
+		},
+		{"match all", NewQueryAll(), "(all)"},
+		{"without tag key", NewQueryBuilder().WithType("A").WithoutTagKey("region").Build(), "(type=A AND noTag{region})"},
+		{"empty", NewQueryEmpty(), "<empty>"},
+		{"empty item", NewQueryFromItems(NewQueryItem(nil, nil)), "(empty)"},
+		{
+			"extended predicates",
+			NewQueryBuilder().WithAnyTagValue("product_id", []string{"p1", "p2"}).WithTagCI("customer", "ABC").
+				WithCausedBy(3).BetweenPositions(10, 20).WithTransactionID(7).Build(),
+			"(anyTag{product_id=p1|product_id=p2} AND tagsCI{customer=ABC} AND causedBy=3 AND position>=10 AND position<=20 AND transaction=7)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribeCondition(t *testing.T) {
+	condition := NewAppendCondition(NewQuery(NewTags("course_id", "c1"), "CourseDefined"))
+	condition.setAfterCursor(&Cursor{TransactionID: 2, Position: 41})
+	if got, want := describeCondition(condition), "(type=CourseDefined AND tags{course_id=c1}) after position 41"; got != want {
+		t.Errorf("describeCondition() = %q, want %q", got, want)
+	}
+	if got := describeCondition(nil); got != "<none>" {
+		t.Errorf("describeCondition(nil) = %q", got)
+	}
+}
@@ -0,0 +1,228 @@
+package dcb
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestQueryValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   Query
+		wantErr bool
+	}{
+		{"match all", NewQueryAll(), false},
+		{"tags and types", NewQuery(NewTags("course_id", "c1"), "CourseDefined"), false},
+		{"types only", NewQueryFromItems(NewQueryItem([]string{"CourseDefined"}, nil)), false},
+		{"causation only", NewQueryBuilder().WithCausedBy(3).Build(), false},
+		{"empty query", NewQueryEmpty(), true},
+		{"item without conditions", NewQueryFromItems(NewQueryItem(nil, nil)), true},
+		{"empty event type", NewQueryFromItems(NewQueryItem([]string{""}, nil)), true},
+		{"empty tag value", NewQuery(NewTags("course_id", "")), true},
+		{"impossible causation", NewQueryBuilder().WithCausedBy(0).Build(), true},
+		{"any tag value", NewQueryBuilder().WithAnyTagValue("product_id", []string{"p1", "p2"}).Build(), false},
+		{"empty any tag value set", NewQueryBuilder().WithAnyTagValue("product_id", nil).Build(), true},
+		{"position range", NewQueryBuilder().WithType("CourseDefined").BetweenPositions(10, 20).Build(), false},
+		{"position range only", NewQueryBuilder().BetweenPositions(5, 5).Build(), false},
+		{"inverted position range", NewQueryBuilder().BetweenPositions(20, 10).Build(), true},
+		{"position range before first event", NewQueryBuilder().BetweenPositions(-5, 0).Build(), true},
+		{"case-insensitive tag only", NewQueryBuilder().WithTagCI("customer_id", "ABC").Build(), false},
+		{"case-insensitive tag with empty value", NewQueryBuilder().WithTagCI("customer_id", "").Build(), true},
+		{"transaction only", NewQueryBuilder().WithTransactionID(42).Build(), false},
+		{"transaction zero", NewQueryBuilder().WithTransactionID(0).Build(), true},
+		{"without tag key only", NewQueryBuilder().WithoutTagKey("region").Build(), false},
+		{"without empty tag key", NewQueryBuilder().WithoutTagKey("").Build(), true},
+		{"without tag key containing a colon", NewQueryBuilder().WithoutTagKey("region:eu").Build(), true},
+		{"numeric tag only", NewQueryBuilder().WithTagNumeric("priority", OpGte, 3).Build(), false},
+		{"numeric tag with empty key", NewQueryBuilder().WithTagNumeric("", OpGte, 3).Build(), true},
+		{"numeric tag with unknown operator", NewQueryBuilder().WithTagNumeric("priority", Op("~"), 3).Build(), true},
+		{"numeric tag compared with NaN", NewQueryBuilder().WithTagNumeric("priority", OpLt, math.NaN()).Build(), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.query.Validate()
+			if tt.wantErr && !errors.Is(err, ErrValidation) {
+				t.Errorf("expected validation error, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestCombineProjectorQueriesKeepsMatchAll(t *testing.T) {
+	projectors := []StateProjector{
+		{ID: "all", Query: NewQueryAll()},
+		{ID: "typed", Query: NewQueryFromItems(NewQueryItem([]string{"CourseDefined"}, nil))},
+	}
+
+	combined := CombineProjectorQueries(projectors)
+	if err := combined.Validate(); err != nil {
+		t.Fatalf("combined query should be valid: %v", err)
+	}
+	items := combined.GetItems()
+	if len(items) != 1 || len(items[0].GetEventTypes()) != 0 {
+		t.Fatalf("expected a single match-all item, got %+v", items)
+	}
+}
+
+func TestEventMatchesProjectorMultiValueTags(t *testing.T) {
+	event := Event{
+		Type:          "PriceChanged",
+		Tags:          NewTags("product_id", "p1", "product_id", "p2", "currency", "EUR", "priority", "5", "level", "high", "level", "2.5e0"),
+		TransactionID: 7,
+	}
+	projector := func(query Query) StateProjector {
+		return StateProjector{ID: "p", Query: query}
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  bool
+	}{
+		{"one of several values for a key", NewQuery(NewTags("product_id", "p2")), true},
+		{"any tag value matches", NewQueryBuilder().WithAnyTagValue("product_id", []string{"p9", "p1"}).Build(), true},
+		{"no listed value", NewQueryBuilder().WithAnyTagValue("product_id", []string{"p8", "p9"}).Build(), false},
+		{
+			"any tag value AND tag",
+			NewQueryBuilder().WithAnyTagValue("product_id", []string{"p2"}).WithTag("currency", "USD").Build(),
+			false,
+		},
+		{"case-insensitive value", NewQueryBuilder().WithTagCI("currency", "eur").Build(), true},
+		{"case-insensitive value of a multi-value key", NewQueryBuilder().WithTagCI("product_id", "P2").Build(), true},
+		{"case-insensitive tag keeps the key exact", NewQueryBuilder().WithTagCI("Currency", "EUR").Build(), false},
+		{"case-insensitive tag AND type", NewQueryBuilder().WithTagCI("currency", "Eur").WithType("Other").Build(), false},
+		{"same transaction", NewQueryBuilder().WithTransactionID(7).Build(), true},
+		{"other transaction", NewQueryBuilder().WithTransactionID(8).Build(), false},
+		{"tag key absent", NewQueryBuilder().WithoutTagKey("region").Build(), true},
+		{"tag key present", NewQueryBuilder().WithoutTagKey("currency").Build(), false},
+		{"tag key present with several values", NewQueryBuilder().WithoutTagKey("product_id").Build(), false},
+		{"tag key absent AND type", NewQueryBuilder().WithType("PriceChanged").WithoutTagKey("region").Build(), true},
+		{"tag key absent AND other type", NewQueryBuilder().WithType("Other").WithoutTagKey("region").Build(), false},
+		{"tag key absent AND tag", NewQueryBuilder().WithTag("currency", "EUR").WithoutTagKey("region").Build(), true},
+		{"numeric tag in range", NewQueryBuilder().WithTagNumeric("priority", OpGte, 3).Build(), true},
+		{"numeric tag out of range", NewQueryBuilder().WithTagNumeric("priority", OpGt, 5).Build(), false},
+		{"numeric tag equal", NewQueryBuilder().WithTagNumeric("priority", OpEq, 5).Build(), true},
+		{"numeric tag skips non-numeric values", NewQueryBuilder().WithTagNumeric("level", OpLt, 10).Build(), true},
+		{"numeric tag on non-numeric key", NewQueryBuilder().WithTagNumeric("currency", OpNe, 0).Build(), false},
+		{"numeric tag on absent key", NewQueryBuilder().WithTagNumeric("region", OpNe, 0).Build(), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EventMatchesProjector(event, projector(tt.query)); got != tt.want {
+				t.Errorf("EventMatchesProjector() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadByPositionsEmpty(t *testing.T) {
+	// No positions means no query: the store without a pool must not be touched
+	es := &eventStore{}
+	events, err := es.ReadByPositions(context.Background(), nil)
+	if err != nil || events != nil {
+		t.Fatalf("expected nil, nil; got %v, %v", events, err)
+	}
+}
+
+func TestReadByTransactionRejectsZero(t *testing.T) {
+	es := &eventStore{}
+	if _, err := es.ReadByTransaction(context.Background(), 0); !IsValidationError(err) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
+
+func TestCaseInsensitiveTagSQL(t *testing.T) {
+	query := NewQueryBuilder().WithType("OrderPlaced").WithTagCI("customer_id", "ABC").Build()
+
+	for _, indexed := range []bool{false, true} {
+		es := &eventStore{lowerTagValues: indexed}
+		sqlQuery, args, err := es.buildReadQuerySQL(query, nil, nil)
+		if err != nil {
+			t.Fatalf("buildReadQuerySQL: %v", err)
+		}
+		usesFunction := strings.Contains(sqlQuery, "lower_tag_values(tags) @>")
+		if usesFunction != indexed {
+			t.Errorf("lowerTagValues=%v: unexpected SQL %s", indexed, sqlQuery)
+		}
+		if len(args) != 3 || args[1] != "customer_id" || args[2] != "ABC" {
+			t.Errorf("lowerTagValues=%v: unexpected args %v", indexed, args)
+		}
+	}
+
+	condition := NewAppendCondition(query)
+	if err := validateConditionQuery(condition); !IsValidationError(err) {
+		t.Errorf("expected append conditions to reject case-insensitive tags, got %v", err)
+	}
+}
+
+func TestWithoutTagKeySQL(t *testing.T) {
+	query := NewQueryBuilder().WithType("OrderPlaced").WithoutTagKey("region").Build()
+
+	sqlQuery, args, err := (&eventStore{}).buildReadQuerySQL(query, nil, nil)
+	if err != nil {
+		t.Fatalf("buildReadQuerySQL: %v", err)
+	}
+	if !strings.Contains(sqlQuery, "type = ANY($1::text[]) AND NOT EXISTS (SELECT 1 FROM unnest(tags) AS t WHERE starts_with(t, $2::text))") {
+		t.Errorf("unexpected SQL %s", sqlQuery)
+	}
+	if len(args) != 2 || args[1] != "region:" {
+		t.Errorf("unexpected args %v", args)
+	}
+
+	condition := NewAppendCondition(query)
+	if err := validateConditionQuery(condition); !IsValidationError(err) {
+		t.Errorf("expected append conditions to reject missing-key conditions, got %v", err)
+	}
+}
+
+func TestNumericTagSQL(t *testing.T) {
+	query := NewQueryBuilder().WithType("TicketOpened").WithTagNumeric("priority", OpGte, 3).Build()
+
+	for _, indexed := range []bool{false, true} {
+		es := &eventStore{numericTagValue: indexed}
+		sqlQuery, args, err := es.buildReadQuerySQL(query, nil, nil)
+		if err != nil {
+			t.Fatalf("buildReadQuerySQL: %v", err)
+		}
+		if indexed {
+			if !strings.Contains(sqlQuery, "numeric_tag_value(tags, 'priority') >= $2::numeric") || len(args) != 2 || args[1] != 3.0 {
+				t.Errorf("numericTagValue=true: unexpected SQL %s with args %v", sqlQuery, args)
+			}
+			continue
+		}
+		if !strings.Contains(sqlQuery, "ORDER BY ord LIMIT 1) >= $3::numeric") || len(args) != 3 || args[1] != "priority" || args[2] != 3.0 {
+			t.Errorf("numericTagValue=false: unexpected SQL %s with args %v", sqlQuery, args)
+		}
+	}
+
+	// The operator is inlined, so an unvalidated one never reaches the SQL
+	invalid := NewQueryBuilder().WithTagNumeric("priority", Op("; DROP TABLE events"), 3).Build()
+	if _, _, err := (&eventStore{}).buildReadQuerySQL(invalid, nil, nil); err == nil {
+		t.Error("expected an error for an invalid operator")
+	}
+
+	condition := NewAppendCondition(query)
+	if err := validateConditionQuery(condition); !IsValidationError(err) {
+		t.Errorf("expected append conditions to reject numeric tag comparisons, got %v", err)
+	}
+}
+
+// numeric_tag_value in schema.sql must accept exactly the values matchesNumericTag does
+func TestSchemaNumericTagPattern(t *testing.T) {
+	schema, err := os.ReadFile("../../docker-entrypoint-initdb.d/schema.sql")
+	if err != nil {
+		t.Fatalf("read schema.sql: %v", err)
+	}
+	if !strings.Contains(string(schema), "~ '"+numericTagPattern+"'") {
+		t.Errorf("numeric_tag_value in schema.sql doesn't use numericTagPattern %s", numericTagPattern)
+	}
+}
@@ -0,0 +1,124 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Conditional Read
+// =============================================================================
+
+// ReadIfChanged is a compare-and-read for caches and polling consumers: it returns the events
+// matching query and their head (the highest matching position) only when the head moved past
+// sinceHead, the head returned by the previous call (0 the first time). Otherwise it returns no
+// events, the current head and changed=false after a single aggregate query, without
+// transferring any event payloads. Positions commit out of order, so an event committing below a
+// head already returned is only noticed once a later one moves the head; use QueryETag when that
+// matters. Events in the archive table are included when EventStoreConfig.ArchiveTable is set
+func (es *eventStore) ReadIfChanged(ctx context.Context, query Query, sinceHead int64) ([]Event, int64, bool, error) {
+	if query == nil {
+		return nil, 0, false, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "readIfChanged",
+				Err: fmt.Errorf("query cannot be nil"),
+			},
+			Field: "query",
+			Value: "nil",
+		}
+	}
+	if sinceHead < 0 {
+		return nil, 0, false, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "readIfChanged",
+				Err: fmt.Errorf("since head must not be negative, got %d", sinceHead),
+			},
+			Field: "sinceHead",
+			Value: fmt.Sprintf("%d", sinceHead),
+		}
+	}
+	if err := query.Validate(); err != nil {
+		return nil, 0, false, err
+	}
+	if err := validateQueryTags(query); err != nil {
+		return nil, 0, false, err
+	}
+
+	sqlQuery, args, err := es.buildReadQuerySQL(query, nil, nil)
+	if err != nil {
+		return nil, 0, false, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "readIfChanged",
+				Err: fmt.Errorf("failed to build SQL query: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	headSQL := "SELECT COALESCE(MAX(position), 0) FROM (" + strings.TrimSuffix(sqlQuery, readOrderBy) + ") AS e"
+
+	var (
+		events []Event
+		head   int64
+	)
+	err = es.executeReadInTx(ctx, func(tx pgx.Tx) error {
+		events = nil
+		if err := tx.QueryRow(ctx, headSQL, args...).Scan(&head); err != nil {
+			return &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "readIfChanged",
+					Err: fmt.Errorf("failed to read query head: %w", err),
+				},
+				Resource: "database",
+			}
+		}
+		if head <= sinceHead {
+			return nil
+		}
+
+		rows, err := tx.Query(ctx, sqlQuery, args...)
+		if err != nil {
+			return &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "readIfChanged",
+					Err: fmt.Errorf("failed to execute query: %w", err),
+				},
+				Resource: "database",
+			}
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var row rowEvent
+			err := rows.Scan(row.scanTargets()...)
+			if err != nil {
+				return &ResourceError{
+					EventStoreError: EventStoreError{
+						Op:  "readIfChanged",
+						Err: fmt.Errorf("failed to scan event: %w", err),
+					},
+					Resource: "database",
+				}
+			}
+			// The head of the events returned, which may include appends committed since the head query
+			head = max(head, row.Position)
+			events = append(events, convertRowToEvent(row))
+		}
+		if err := rows.Err(); err != nil {
+			return &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "readIfChanged",
+					Err: fmt.Errorf("error iterating over rows: %w", err),
+				},
+				Resource: "database",
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, false, err
+	}
+	return events, head, head > sinceHead, nil
+}
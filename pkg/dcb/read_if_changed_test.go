@@ -0,0 +1,21 @@
+package dcb
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReadIfChangedValidatesFirst(t *testing.T) {
+	// es has no pool: invalid arguments must be rejected before the database
+	es := newEventStore(nil, EventStoreConfig{})
+	ctx := context.Background()
+
+	if _, _, _, err := es.ReadIfChanged(ctx, nil, 0); !IsValidationError(err) {
+		t.Errorf("expected ValidationError for a nil query, got %v", err)
+	}
+	query := NewQuery(NewTags("course_id", "c1"), "StudentEnrolled")
+	_, _, _, err := es.ReadIfChanged(ctx, query, -1)
+	if validationErr, ok := GetValidationError(err); !ok || validationErr.Field != "sinceHead" {
+		t.Errorf("expected a sinceHead ValidationError, got %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Multi-Table Reads
+// =============================================================================
+
+// ReadOrder selects how ReadMulti orders events coming from several tables
+type ReadOrder string
+
+const (
+	// ReadOrderOccurredAt merges all tables by occurred_at (the default). Ties, such as the events
+	// of one append, are broken by the table's index in tables, then by transaction id and position
+	ReadOrderOccurredAt ReadOrder = "occurred_at"
+	// ReadOrderByTable returns the events of each table in turn, in the order tables were given,
+	// each table's events in the usual (transaction_id, position) order
+	ReadOrderByTable ReadOrder = "table"
+)
+
+// ReadOptions tunes ReadMulti; a nil *ReadOptions uses the defaults
+type ReadOptions struct {
+	// Order selects the ordering of the combined result (default ReadOrderOccurredAt)
+	Order ReadOrder
+	// Limit caps the number of events returned across all tables (0 = no limit)
+	Limit int
+}
+
+// ReadMulti reads the events matching query from several event tables in one statement, e.g.
+// tenant-partitioned tables for analytics. Every table must have the events table's structure and
+// be allowed: "events", the configured ArchiveTable or one of EventStoreConfig.ReadableTables.
+//
+// Ordering: positions and transaction ids come from per-table sequences and transactions, so
+// there is no global order across tables that agrees with how each table was written. By default
+// events are merged by occurred_at, which is wall-clock time and only as comparable as the clocks
+// (and Clock settings) that wrote the tables; ReadOrderByTable keeps each table's own order
+// instead. Event carries no table name: tag events (e.g. with a tenant id) when the source matters.
+//
+// Reads go to the named tables only; ArchiveTable is not added implicitly, list it to include it.
+func (es *eventStore) ReadMulti(ctx context.Context, tables []string, query Query, opts *ReadOptions) ([]Event, error) {
+	options := ReadOptions{Order: ReadOrderOccurredAt}
+	if opts != nil {
+		options = *opts
+		if options.Order == "" {
+			options.Order = ReadOrderOccurredAt
+		}
+	}
+	if err := es.validateReadMulti(tables, options); err != nil {
+		return nil, err
+	}
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Every branch filters with the same query, so they share the same arguments
+	branches := make([]string, len(tables))
+	var args []any
+	for i, table := range tables {
+		branchSQL, branchArgs, err := es.buildReadQuerySQLFrom(pgx.Identifier{table}.Sanitize(), query, nil, nil)
+		if err != nil {
+			return nil, &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "read_multi",
+					Err: fmt.Errorf("failed to build SQL query: %w", err),
+				},
+				Resource: "database",
+			}
+		}
+		args = branchArgs
+		branchSQL = strings.TrimSuffix(branchSQL, readOrderBy)
+		branches[i] = fmt.Sprintf("SELECT %s, %d AS source_index FROM (%s) AS t", eventColumns, i, branchSQL)
+	}
+
+	orderBy := " ORDER BY occurred_at ASC, source_index ASC, transaction_id ASC, position ASC"
+	if options.Order == ReadOrderByTable {
+		orderBy = " ORDER BY source_index ASC, transaction_id ASC, position ASC"
+	}
+	sqlQuery := "SELECT " + eventColumns + " FROM (" + strings.Join(branches, " UNION ALL ") + ") AS e" + orderBy
+	if options.Limit > 0 {
+		sqlQuery += fmt.Sprintf(" LIMIT %d", options.Limit)
+	}
+
+	var events []Event
+	err := es.executeReadInTx(ctx, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, sqlQuery, args...)
+		if err != nil {
+			return &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "read_multi",
+					Err: fmt.Errorf("failed to execute query: %w", err),
+				},
+				Resource: "database",
+			}
+		}
+		rowEvents, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (rowEvent, error) {
+			var r rowEvent
+			err := row.Scan(r.scanTargets()...)
+			return r, err
+		})
+		if err != nil {
+			return &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "read_multi",
+					Err: fmt.Errorf("failed to scan events: %w", err),
+				},
+				Resource: "database",
+			}
+		}
+		events = make([]Event, len(rowEvents))
+		for i, row := range rowEvents {
+			events[i] = convertRowToEvent(row)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// validateReadMulti checks the tables against the allow-list and the options
+func (es *eventStore) validateReadMulti(tables []string, options ReadOptions) error {
+	if len(tables) == 0 {
+		return &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "read_multi",
+				Err: fmt.Errorf("at least one table is required"),
+			},
+			Field: "tables",
+			Value: "empty",
+		}
+	}
+	for i, table := range tables {
+		if !es.readableTable(table) {
+			return &ValidationError{
+				EventStoreError: EventStoreError{
+					Op:  "read_multi",
+					Err: fmt.Errorf("table %q is not readable (allowed: events, ArchiveTable and EventStoreConfig.ReadableTables)", table),
+				},
+				Field: fmt.Sprintf("tables[%d]", i),
+				Value: table,
+			}
+		}
+		if slices.Contains(tables[:i], table) {
+			return &ValidationError{
+				EventStoreError: EventStoreError{
+					Op:  "read_multi",
+					Err: fmt.Errorf("table %q is listed twice", table),
+				},
+				Field: fmt.Sprintf("tables[%d]", i),
+				Value: table,
+			}
+		}
+	}
+	if options.Order != ReadOrderOccurredAt && options.Order != ReadOrderByTable {
+		return &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "read_multi",
+				Err: fmt.Errorf("unsupported read order %q", options.Order),
+			},
+			Field: "order",
+			Value: string(options.Order),
+		}
+	}
+	if options.Limit < 0 {
+		return &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "read_multi",
+				Err: fmt.Errorf("limit must not be negative: %d", options.Limit),
+			},
+			Field: "limit",
+			Value: fmt.Sprintf("%d", options.Limit),
+		}
+	}
+	return nil
+}
+
+// readableTable reports whether ReadMulti may read table
+func (es *eventStore) readableTable(table string) bool {
+	if table == "events" || (table == es.config.ArchiveTable && table != "") {
+		return true
+	}
+	return slices.Contains(es.config.ReadableTables, table)
+}
+
+// validateReadableTableName validates an EventStoreConfig.ReadableTables entry
+// It must be a plain identifier other than the store's own tables
+func validateReadableTableName(op, name string) error {
+	if !tableNamePattern.MatchString(name) || name == "events" || name == "commands" {
+		return &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  op,
+				Err: fmt.Errorf("invalid readable table name %q", name),
+			},
+			Field: "readableTables",
+			Value: name,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package dcb
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReadMultiValidatesFirst(t *testing.T) {
+	// es has no pool: invalid arguments must be rejected before reaching the database
+	es := &eventStore{config: EventStoreConfig{ArchiveTable: "events_archive", ReadableTables: []string{"events_tenant_b"}}}
+	query := NewQuery(NewTags("tenant", "a"))
+
+	tests := []struct {
+		name   string
+		tables []string
+		opts   *ReadOptions
+		field  string
+	}{
+		{"no tables", nil, nil, "tables"},
+		{"table not allowed", []string{"events", "commands"}, nil, "tables[1]"},
+		{"injection attempt", []string{"events; DROP TABLE events"}, nil, "tables[0]"},
+		{"duplicate table", []string{"events_tenant_b", "events_tenant_b"}, nil, "tables[1]"},
+		{"unknown order", []string{"events"}, &ReadOptions{Order: "position"}, "order"},
+		{"negative limit", []string{"events"}, &ReadOptions{Limit: -1}, "limit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := es.ReadMulti(context.Background(), tt.tables, query, tt.opts)
+			validationErr, ok := GetValidationError(err)
+			if !ok {
+				t.Fatalf("expected ValidationError, got %v", err)
+			}
+			if validationErr.Field != tt.field {
+				t.Errorf("expected field %q, got %q", tt.field, validationErr.Field)
+			}
+		})
+	}
+}
+
+func TestReadableTable(t *testing.T) {
+	es := &eventStore{config: EventStoreConfig{ArchiveTable: "events_archive", ReadableTables: []string{"events_tenant_b"}}}
+	for table, want := range map[string]bool{
+		"events":          true,
+		"events_archive":  true,
+		"events_tenant_b": true,
+		"events_tenant_c": false,
+		"commands":        false,
+		"":                false,
+	} {
+		if got := es.readableTable(table); got != want {
+			t.Errorf("readableTable(%q) = %v, want %v", table, got, want)
+		}
+	}
+
+	if err := validateReadableTableName("test", "events"); !IsValidationError(err) {
+		t.Errorf("the events table must not be listed as an extra readable table, got %v", err)
+	}
+	if err := validateReadableTableName("test", "events_tenant_b"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
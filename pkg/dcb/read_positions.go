@@ -0,0 +1,71 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Read By Positions
+// =============================================================================
+
+// ReadByPositions returns the events at the given positions in a single query
+// Events are returned in ascending position order (not in the order of positions), each at
+// most once even if its position is listed repeatedly. Positions without an event (never used,
+// or archived while EventStoreConfig.ArchiveTable is unset) are simply absent from the result.
+func (es *eventStore) ReadByPositions(ctx context.Context, positions []int64) ([]Event, error) {
+	if len(positions) == 0 {
+		return nil, nil
+	}
+
+	sqlQuery := "SELECT " + eventColumns + " FROM " + es.eventsSource() + " WHERE position = ANY($1::bigint[]) ORDER BY position ASC"
+
+	var events []Event
+	err := es.executeReadInTx(ctx, func(tx pgx.Tx) error {
+		events = nil
+		rows, err := tx.Query(ctx, sqlQuery, positions)
+		if err != nil {
+			return &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "read_by_positions",
+					Err: fmt.Errorf("failed to execute query: %w", err),
+				},
+				Resource: "database",
+			}
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var row rowEvent
+			if err := rows.Scan(row.scanTargets()...); err != nil {
+				return &ResourceError{
+					EventStoreError: EventStoreError{
+						Op:  "read_by_positions",
+						Err: fmt.Errorf("failed to scan event: %w", err),
+					},
+					Resource: "database",
+				}
+			}
+			events = append(events, convertRowToEvent(row))
+		}
+
+		if err := rows.Err(); err != nil {
+			return &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "read_by_positions",
+					Err: fmt.Errorf("error iterating over rows: %w", err),
+				},
+				Resource: "database",
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
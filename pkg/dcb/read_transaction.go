@@ -0,0 +1,34 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+)
+
+// =============================================================================
+// Read By Transaction
+// =============================================================================
+
+// WithTransactionID restricts the current QueryItem to events appended in the given transaction (AND)
+// The condition is served by the (transaction_id, position) index. Meant for reads and projections
+// (e.g. auditing what a command wrote, see CommandResult.TransactionID and the commands table); append conditions reject it
+func (qb *QueryBuilder) WithTransactionID(id uint64) *QueryBuilder {
+	qb.currentItem.transactionID = &id
+	return qb
+}
+
+// ReadByTransaction returns the events appended in transaction txID, in position order
+// Only committed transactions are visible; an unknown (or not yet committed) id returns no events
+func (es *eventStore) ReadByTransaction(ctx context.Context, txID uint64) ([]Event, error) {
+	if txID == 0 {
+		return nil, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "read_by_transaction",
+				Err: fmt.Errorf("transaction id must be positive"),
+			},
+			Field: "transactionID",
+			Value: "0",
+		}
+	}
+	return es.Query(ctx, NewQueryBuilder().WithTransactionID(txID).Build(), nil)
+}
@@ -0,0 +1,66 @@
+package dcb
+
+import (
+	"context"
+	"time"
+)
+
+// =============================================================================
+// Typed Reads
+// =============================================================================
+
+// TypedEvent is an event whose data ReadTyped decoded into E
+type TypedEvent[E any] struct {
+	Type          string    `json:"type"`
+	Tags          []Tag     `json:"tags"`
+	TransactionID uint64    `json:"transaction_id"`
+	Position      int64     `json:"position"`
+	OccurredAt    time.Time `json:"occurred_at"`
+	Metadata      []byte    `json:"metadata,omitempty"`
+	Data          E         `json:"data"`
+}
+
+// ReadTypedOptions tunes ReadTyped; a nil *ReadTypedOptions uses the defaults
+type ReadTypedOptions struct {
+	// After reads the events after this cursor, as Query does (nil = from the start)
+	After *Cursor
+	// SkipUndecodable leaves out events whose data doesn't decode into E instead of failing the
+	// read, e.g. when query matches several event types and only some have E's shape. Default false
+	SkipUndecodable bool
+}
+
+// ReadTyped reads the events matching query, as Query does, and decodes each event's data into E
+// with DecodeData, replacing the json.Unmarshal after every read. An event that fails to decode
+// fails the read with DecodeData's ValidationError, naming its type and position, unless
+// opts.SkipUndecodable is set. Events are returned in Query's order
+func ReadTyped[E any](ctx context.Context, store EventStore, query Query, opts *ReadTypedOptions) ([]TypedEvent[E], error) {
+	var options ReadTypedOptions
+	if opts != nil {
+		options = *opts
+	}
+
+	events, err := store.Query(ctx, query, options.After)
+	if err != nil {
+		return nil, err
+	}
+	typed := make([]TypedEvent[E], 0, len(events))
+	for _, event := range events {
+		var data E
+		if err := DecodeData(event, &data); err != nil {
+			if options.SkipUndecodable {
+				continue
+			}
+			return nil, err
+		}
+		typed = append(typed, TypedEvent[E]{
+			Type:          event.Type,
+			Tags:          event.Tags,
+			TransactionID: event.TransactionID,
+			Position:      event.Position,
+			OccurredAt:    event.OccurredAt,
+			Metadata:      event.Metadata,
+			Data:          data,
+		})
+	}
+	return typed, nil
+}
@@ -0,0 +1,37 @@
+package dcb
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReadTyped(t *testing.T) {
+	type moneyDeposited struct {
+		Amount int `json:"amount"`
+	}
+	ctx := context.Background()
+	query := NewQuery(NewTags("account_id", "a1"), "MoneyDeposited", "AccountNoted")
+	store := &stubStore{events: []Event{
+		{Type: "MoneyDeposited", Tags: NewTags("account_id", "a1"), Position: 1, Data: []byte(`{"amount": 10}`)},
+		{Type: "AccountNoted", Tags: NewTags("account_id", "a1"), Position: 2, Data: []byte(`{"amount": "ten"}`)},
+		{Type: "MoneyDeposited", Tags: NewTags("account_id", "a1"), Position: 3, Data: []byte(`{"amount": 5}`)},
+	}}
+
+	if _, err := ReadTyped[moneyDeposited](ctx, store, query, nil); !IsValidationError(err) {
+		t.Fatalf("expected the undecodable event to fail the read, got %v", err)
+	}
+
+	deposits, err := ReadTyped[moneyDeposited](ctx, store, query, &ReadTypedOptions{SkipUndecodable: true})
+	if err != nil {
+		t.Fatalf("ReadTyped: %v", err)
+	}
+	if len(deposits) != 2 {
+		t.Fatalf("expected the two decodable events, got %+v", deposits)
+	}
+	if deposits[0].Data.Amount != 10 || deposits[1].Data.Amount != 5 || deposits[1].Position != 3 {
+		t.Errorf("expected amounts 10 and 5 in order, got %+v", deposits)
+	}
+	if deposits[0].Type != "MoneyDeposited" || len(deposits[0].Tags) != 1 {
+		t.Errorf("expected the event's type and tags, got %+v", deposits[0])
+	}
+}
@@ -0,0 +1,124 @@
+package dcb
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// =============================================================================
+// Live Reconfiguration
+// =============================================================================
+
+// DynamicConfig lists the settings Reconfigure can change while the store is in use
+// nil fields are left unchanged; values use the same units as EventStoreConfig (milliseconds)
+type DynamicConfig struct {
+	// QueryTimeout and AppendTimeout update the defensive timeouts reported by GetConfig
+	QueryTimeout  *int `json:"query_timeout,omitempty"`
+	AppendTimeout *int `json:"append_timeout,omitempty"`
+
+	// LockTimeout applies to appends started after Reconfigure returns (0 = only the context deadline)
+	LockTimeout *int `json:"lock_timeout,omitempty"`
+
+	// MaxConcurrentStreams applies to streams opened after Reconfigure returns; lowering it
+	// doesn't close open streams, new ones are rejected until enough of them have ended
+	MaxConcurrentStreams *int `json:"max_concurrent_streams,omitempty"`
+
+	// MaxConns and MinConns can't change live: a pgxpool.Pool fixes its size when it is created,
+	// so Reconfigure rejects them. Resize by creating a new pool and a new EventStore
+	MaxConns *int32 `json:"max_conns,omitempty"`
+	MinConns *int32 `json:"min_conns,omitempty"`
+}
+
+// liveSettings holds the settings Reconfigure can change; it is shared by all copies of a store
+type liveSettings struct {
+	queryTimeout  atomic.Int64
+	appendTimeout atomic.Int64
+	lockTimeout   atomic.Int64
+
+	streamsMu   sync.Mutex
+	maxStreams  int
+	openStreams int
+
+	// evictedConns counts dead connections dropped by streams (ValidateStreamConnections)
+	evictedConns atomic.Int64
+}
+
+// newLiveSettings initializes live settings from cfg (with defaults applied)
+func newLiveSettings(cfg EventStoreConfig) *liveSettings {
+	live := &liveSettings{maxStreams: cfg.MaxConcurrentStreams}
+	live.queryTimeout.Store(int64(cfg.QueryTimeout))
+	live.appendTimeout.Store(int64(cfg.AppendTimeout))
+	live.lockTimeout.Store(int64(cfg.LockTimeout))
+	return live
+}
+
+// Reconfigure updates the settings in cfg without restarting the store
+// All values are validated first: on error nothing is changed, and the error names every
+// setting that was rejected (including settings that can't change live, like MaxConns)
+func (es *eventStore) Reconfigure(cfg DynamicConfig) error {
+	var rejected []string
+	if cfg.MaxConns != nil {
+		rejected = append(rejected, "MaxConns (fixed when the pool is created)")
+	}
+	if cfg.MinConns != nil {
+		rejected = append(rejected, "MinConns (fixed when the pool is created)")
+	}
+	if cfg.QueryTimeout != nil && *cfg.QueryTimeout <= 0 {
+		rejected = append(rejected, fmt.Sprintf("QueryTimeout (must be positive, got %d)", *cfg.QueryTimeout))
+	}
+	if cfg.AppendTimeout != nil && *cfg.AppendTimeout <= 0 {
+		rejected = append(rejected, fmt.Sprintf("AppendTimeout (must be positive, got %d)", *cfg.AppendTimeout))
+	}
+	if cfg.LockTimeout != nil && *cfg.LockTimeout < 0 {
+		rejected = append(rejected, fmt.Sprintf("LockTimeout (must not be negative, got %d)", *cfg.LockTimeout))
+	}
+	if cfg.MaxConcurrentStreams != nil && *cfg.MaxConcurrentStreams <= 0 {
+		rejected = append(rejected, fmt.Sprintf("MaxConcurrentStreams (must be positive, got %d)", *cfg.MaxConcurrentStreams))
+	}
+	if len(rejected) > 0 {
+		return &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "reconfigure",
+				Err: fmt.Errorf("cannot reconfigure: %s", strings.Join(rejected, "; ")),
+			},
+			Field: "dynamicConfig",
+			Value: strings.Join(rejected, "; "),
+		}
+	}
+
+	if cfg.QueryTimeout != nil {
+		es.live.queryTimeout.Store(int64(*cfg.QueryTimeout))
+	}
+	if cfg.AppendTimeout != nil {
+		es.live.appendTimeout.Store(int64(*cfg.AppendTimeout))
+	}
+	if cfg.LockTimeout != nil {
+		es.live.lockTimeout.Store(int64(*cfg.LockTimeout))
+	}
+	if cfg.MaxConcurrentStreams != nil {
+		es.live.streamsMu.Lock()
+		es.live.maxStreams = *cfg.MaxConcurrentStreams
+		es.live.streamsMu.Unlock()
+	}
+	return nil
+}
+
+// acquireStream reserves a stream slot, reporting false when maxStreams are already open
+func (l *liveSettings) acquireStream() (limit int, ok bool) {
+	l.streamsMu.Lock()
+	defer l.streamsMu.Unlock()
+	if l.openStreams >= l.maxStreams {
+		return l.maxStreams, false
+	}
+	l.openStreams++
+	return l.maxStreams, true
+}
+
+// releaseStream frees a slot reserved by acquireStream
+func (l *liveSettings) releaseStream() {
+	l.streamsMu.Lock()
+	l.openStreams--
+	l.streamsMu.Unlock()
+}
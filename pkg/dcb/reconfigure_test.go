@@ -0,0 +1,91 @@
+package dcb
+
+import (
+	"strings"
+	"testing"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestReconfigure(t *testing.T) {
+	t.Run("updates live settings", func(t *testing.T) {
+		es := newEventStore(nil, EventStoreConfig{LockTimeout: 5000, MaxConcurrentStreams: 2})
+		err := es.Reconfigure(DynamicConfig{
+			QueryTimeout:         intPtr(2000),
+			AppendTimeout:        intPtr(1000),
+			LockTimeout:          intPtr(250),
+			MaxConcurrentStreams: intPtr(5),
+		})
+		if err != nil {
+			t.Fatalf("Reconfigure: %v", err)
+		}
+
+		config := es.GetConfig()
+		if config.QueryTimeout != 2000 || config.AppendTimeout != 1000 || config.LockTimeout != 250 || config.MaxConcurrentStreams != 5 {
+			t.Errorf("GetConfig does not reflect the new settings: %+v", config)
+		}
+		if got := es.live.lockTimeout.Load(); got != 250 {
+			t.Errorf("expected lock timeout 250, got %d", got)
+		}
+	})
+
+	t.Run("leaves unset fields unchanged", func(t *testing.T) {
+		es := newEventStore(nil, EventStoreConfig{QueryTimeout: 3000, LockTimeout: 5000})
+		if err := es.Reconfigure(DynamicConfig{LockTimeout: intPtr(0)}); err != nil {
+			t.Fatalf("Reconfigure: %v", err)
+		}
+		if config := es.GetConfig(); config.QueryTimeout != 3000 || config.LockTimeout != 0 {
+			t.Errorf("unexpected config %+v", config)
+		}
+	})
+
+	t.Run("rejects settings that can't change live without applying any", func(t *testing.T) {
+		es := newEventStore(nil, EventStoreConfig{LockTimeout: 5000})
+		maxConns := int32(20)
+		err := es.Reconfigure(DynamicConfig{
+			LockTimeout:          intPtr(100),
+			MaxConns:             &maxConns,
+			MaxConcurrentStreams: intPtr(0),
+		})
+		if !IsValidationError(err) {
+			t.Fatalf("expected ValidationError, got %v", err)
+		}
+		for _, name := range []string{"MaxConns", "MaxConcurrentStreams"} {
+			if !strings.Contains(err.Error(), name) {
+				t.Errorf("expected error to name %s: %v", name, err)
+			}
+		}
+		if got := es.GetConfig().LockTimeout; got != 5000 {
+			t.Errorf("expected lock timeout unchanged, got %d", got)
+		}
+	})
+
+	t.Run("stream limit applies to new streams", func(t *testing.T) {
+		es := newEventStore(nil, EventStoreConfig{MaxConcurrentStreams: 2})
+		first, _ := es.acquireStreamSlot("query_stream")
+		second, _ := es.acquireStreamSlot("query_stream")
+
+		// Lowering the limit keeps open streams but rejects new ones until enough have ended
+		if err := es.Reconfigure(DynamicConfig{MaxConcurrentStreams: intPtr(1)}); err != nil {
+			t.Fatalf("Reconfigure: %v", err)
+		}
+		first()
+		if _, err := es.acquireStreamSlot("query_stream"); !IsResourceError(err) {
+			t.Fatalf("expected ResourceError while at the lowered limit, got %v", err)
+		}
+		second()
+		if _, err := es.acquireStreamSlot("query_stream"); err != nil {
+			t.Fatalf("expected a slot below the lowered limit: %v", err)
+		}
+
+		// Raising it admits more streams immediately
+		if err := es.Reconfigure(DynamicConfig{MaxConcurrentStreams: intPtr(3)}); err != nil {
+			t.Fatalf("Reconfigure: %v", err)
+		}
+		for i := 0; i < 2; i++ {
+			if _, err := es.acquireStreamSlot("query_stream"); err != nil {
+				t.Fatalf("slot %d after raising the limit: %v", i, err)
+			}
+		}
+	})
+}
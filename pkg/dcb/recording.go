@@ -0,0 +1,325 @@
+package dcb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Recording Decorator
+// =============================================================================
+
+// RecordedCall is one EventStore call captured by a RecordingStore
+// Only the fields that apply to Method are set. Arguments and results are kept by reference and
+// only formatted by String, so recording costs one append to the log per call
+type RecordedCall struct {
+	Method     string           // EventStore method, e.g. "AppendIf", or "ExecuteCommand" for a command's append
+	Events     []InputEvent     // Events passed to an append
+	Condition  AppendCondition  // Condition of a conditional append
+	Query      Query            // Query of a read
+	Projectors []StateProjector // Projectors of a projection
+	After      *Cursor          // Cursor a read or projection started after
+	Err        error            // Error returned by the call, nil on success
+	Result     any              // Main result: []Event for reads, the states map for projections
+}
+
+// String summarizes the call for test failure messages, e.g.
+// "AppendIf(2 events [CourseDefined StudentEnrolled], condition (type=CourseDefined)) -> ok"
+func (c RecordedCall) String() string {
+	var args []string
+	if c.Events != nil {
+		types := make([]string, len(c.Events))
+		for i, event := range c.Events {
+			types[i] = event.GetType()
+		}
+		args = append(args, fmt.Sprintf("%d events %v", len(c.Events), types))
+	}
+	if c.Condition != nil {
+		args = append(args, "condition "+describeCondition(c.Condition))
+	}
+	if c.Query != nil {
+		args = append(args, "query "+c.Query.String())
+	}
+	if c.Projectors != nil {
+		ids := make([]string, len(c.Projectors))
+		for i, projector := range c.Projectors {
+			ids[i] = projector.ID
+		}
+		args = append(args, fmt.Sprintf("projectors %v", ids))
+	}
+	if c.After != nil {
+		args = append(args, fmt.Sprintf("after position %d", c.After.Position))
+	}
+
+	result := "ok"
+	switch {
+	case c.Err != nil:
+		result = "error: " + c.Err.Error()
+	case c.Result != nil:
+		if events, ok := c.Result.([]Event); ok {
+			result = fmt.Sprintf("%d events", len(events))
+		}
+	}
+	return c.Method + "(" + strings.Join(args, ", ") + ") -> " + result
+}
+
+// callLog is the log shared by a RecordingStore and the stores it hands out (WithTransaction)
+type callLog struct {
+	mu    sync.Mutex
+	calls []RecordedCall
+}
+
+// RecordingStore is an EventStore decorator for tests that records the append, read and
+// projection calls made through it, e.g. to assert that a command handler appended exactly
+// these events with this condition without checking database state. Recorded calls are
+// delegated unchanged, and other methods are delegated without being recorded. The appends of
+// ExecuteCommand on a CommandExecutor built over a RecordingStore are recorded as
+// "ExecuteCommand", and the reads its handler makes through the store as usual.
+// It is safe for concurrent use
+type RecordingStore struct {
+	EventStore
+	log *callLog
+}
+
+// NewRecordingStore wraps inner in a RecordingStore with an empty log
+func NewRecordingStore(inner EventStore) *RecordingStore {
+	return &RecordingStore{EventStore: inner, log: &callLog{}}
+}
+
+// unwrapEventStore returns the decorated EventStore
+func (rs *RecordingStore) unwrapEventStore() EventStore {
+	return rs.EventStore
+}
+
+// Calls returns the recorded calls in the order they completed
+func (rs *RecordingStore) Calls() []RecordedCall {
+	rs.log.mu.Lock()
+	defer rs.log.mu.Unlock()
+	return append([]RecordedCall(nil), rs.log.calls...)
+}
+
+// CallsTo returns the recorded calls to method, in order
+func (rs *RecordingStore) CallsTo(method string) []RecordedCall {
+	var calls []RecordedCall
+	for _, call := range rs.Calls() {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset clears the log, e.g. after the setup of a test
+func (rs *RecordingStore) Reset() {
+	rs.log.mu.Lock()
+	defer rs.log.mu.Unlock()
+	rs.log.calls = nil
+}
+
+// record appends call to the log
+func (rs *RecordingStore) record(call RecordedCall) {
+	rs.log.mu.Lock()
+	defer rs.log.mu.Unlock()
+	rs.log.calls = append(rs.log.calls, call)
+}
+
+// recordCommand records the append of an ExecuteCommand; Result is the CommandResult
+func (rs *RecordingStore) recordCommand(result CommandResult, condition *AppendCondition, err error) {
+	call := RecordedCall{Method: "ExecuteCommand", Events: result.Events, Err: err, Result: result}
+	if condition != nil {
+		call.Condition = *condition
+	}
+	rs.record(call)
+}
+
+// Append records and delegates Append
+func (rs *RecordingStore) Append(ctx context.Context, events []InputEvent) error {
+	err := rs.EventStore.Append(ctx, events)
+	rs.record(RecordedCall{Method: "Append", Events: events, Err: err})
+	return err
+}
+
+// AppendIf records and delegates AppendIf
+func (rs *RecordingStore) AppendIf(ctx context.Context, events []InputEvent, condition AppendCondition) error {
+	err := rs.EventStore.AppendIf(ctx, events, condition)
+	rs.record(RecordedCall{Method: "AppendIf", Events: events, Condition: condition, Err: err})
+	return err
+}
+
+// AppendInto records and delegates AppendInto; Events are the entries' events
+func (rs *RecordingStore) AppendInto(ctx context.Context, entries []*AppendEntry, condition AppendCondition) error {
+	err := rs.EventStore.AppendInto(ctx, entries, condition)
+	events := make([]InputEvent, 0, len(entries))
+	for _, entry := range entries {
+		if entry != nil {
+			events = append(events, entry.Event)
+		}
+	}
+	rs.record(RecordedCall{Method: "AppendInto", Events: events, Condition: condition, Err: err})
+	return err
+}
+
+// AppendSkipDuplicates records and delegates AppendSkipDuplicates; Result is the SkipDuplicatesResult
+func (rs *RecordingStore) AppendSkipDuplicates(ctx context.Context, events []InputEvent) (SkipDuplicatesResult, error) {
+	result, err := rs.EventStore.AppendSkipDuplicates(ctx, events)
+	rs.record(RecordedCall{Method: "AppendSkipDuplicates", Events: events, Err: err, Result: result})
+	return result, err
+}
+
+// AppendIfElse records and delegates AppendIfElse; Result is the AppendIfElseResult
+func (rs *RecordingStore) AppendIfElse(ctx context.Context, events []InputEvent, condition AppendCondition, onFail []InputEvent) (AppendIfElseResult, error) {
+	result, err := rs.EventStore.AppendIfElse(ctx, events, condition, onFail)
+	rs.record(RecordedCall{Method: "AppendIfElse", Events: events, Condition: condition, Err: err, Result: result})
+	return result, err
+}
+
+// AppendIfNotExists records and delegates AppendIfNotExists; Result is the CreateResult
+func (rs *RecordingStore) AppendIfNotExists(ctx context.Context, events []InputEvent, condition AppendCondition, onConflict OnConflict) (CreateResult, error) {
+	result, err := rs.EventStore.AppendIfNotExists(ctx, events, condition, onConflict)
+	rs.record(RecordedCall{Method: "AppendIfNotExists", Events: events, Condition: condition, Err: err, Result: result})
+	return result, err
+}
+
+// AppendIfNoneExist records and delegates AppendIfNoneExist
+func (rs *RecordingStore) AppendIfNoneExist(ctx context.Context, events []InputEvent, tagKey string) error {
+	err := rs.EventStore.AppendIfNoneExist(ctx, events, tagKey)
+	rs.record(RecordedCall{Method: "AppendIfNoneExist", Events: events, Err: err})
+	return err
+}
+
+// AppendToAggregate records and delegates AppendToAggregate
+func (rs *RecordingStore) AppendToAggregate(ctx context.Context, tagKey, tagValue string, expectedVersion int, events []InputEvent) error {
+	err := rs.EventStore.AppendToAggregate(ctx, tagKey, tagValue, expectedVersion, events)
+	rs.record(RecordedCall{Method: "AppendToAggregate", Events: events, Err: err})
+	return err
+}
+
+// AppendAndProject records and delegates AppendAndProject; Result is the states map
+func (rs *RecordingStore) AppendAndProject(ctx context.Context, events []InputEvent, condition AppendCondition, projectors []StateProjector) (map[string]any, AppendCondition, []int64, error) {
+	states, next, positions, err := rs.EventStore.AppendAndProject(ctx, events, condition, projectors)
+	rs.record(RecordedCall{Method: "AppendAndProject", Events: events, Condition: condition, Projectors: projectors, Err: err, Result: states})
+	return states, next, positions, err
+}
+
+// AppendIfTx records and delegates AppendIfTx
+func (rs *RecordingStore) AppendIfTx(ctx context.Context, tx pgx.Tx, events []InputEvent, condition AppendCondition) error {
+	err := rs.EventStore.AppendIfTx(ctx, tx, events, condition)
+	rs.record(RecordedCall{Method: "AppendIfTx", Events: events, Condition: condition, Err: err})
+	return err
+}
+
+// Query records and delegates Query; Result is the []Event read
+func (rs *RecordingStore) Query(ctx context.Context, query Query, after *Cursor) ([]Event, error) {
+	events, err := rs.EventStore.Query(ctx, query, after)
+	rs.record(RecordedCall{Method: "Query", Query: query, After: after, Err: err, Result: events})
+	return events, err
+}
+
+// queryPage records a Pager page read as a Query and delegates it
+func (rs *RecordingStore) queryPage(ctx context.Context, query Query, after *Cursor, limit int) ([]Event, error) {
+	events, err := queryPage(ctx, rs.EventStore, query, after, limit)
+	rs.record(RecordedCall{Method: "Query", Query: query, After: after, Err: err, Result: events})
+	return events, err
+}
+
+// Latest records and delegates Latest; Result is the []Event read (empty when none matched)
+func (rs *RecordingStore) Latest(ctx context.Context, query Query) (Event, bool, error) {
+	event, ok, err := rs.EventStore.Latest(ctx, query)
+	events := []Event{}
+	if ok {
+		events = append(events, event)
+	}
+	rs.record(RecordedCall{Method: "Latest", Query: query, Err: err, Result: events})
+	return event, ok, err
+}
+
+// ReadIfChanged records and delegates ReadIfChanged; Result is the []Event read (nil when unchanged)
+func (rs *RecordingStore) ReadIfChanged(ctx context.Context, query Query, sinceHead int64) ([]Event, int64, bool, error) {
+	events, head, changed, err := rs.EventStore.ReadIfChanged(ctx, query, sinceHead)
+	rs.record(RecordedCall{Method: "ReadIfChanged", Query: query, Err: err, Result: events})
+	return events, head, changed, err
+}
+
+// QueryStream records the opening of a stream and delegates QueryStream
+func (rs *RecordingStore) QueryStream(ctx context.Context, query Query, after *Cursor) (<-chan Event, error) {
+	events, err := rs.EventStore.QueryStream(ctx, query, after)
+	rs.record(RecordedCall{Method: "QueryStream", Query: query, After: after, Err: err})
+	return events, err
+}
+
+// Project records and delegates Project; Result is the states map
+func (rs *RecordingStore) Project(ctx context.Context, projectors []StateProjector, after *Cursor) (map[string]any, AppendCondition, error) {
+	states, condition, err := rs.EventStore.Project(ctx, projectors, after)
+	rs.record(RecordedCall{Method: "Project", Projectors: projectors, After: after, Err: err, Result: states})
+	return states, condition, err
+}
+
+// ProjectWithStats records and delegates ProjectWithStats; Result is the states map
+func (rs *RecordingStore) ProjectWithStats(ctx context.Context, projectors []StateProjector, after *Cursor) (map[string]any, AppendCondition, ProjectionStats, error) {
+	states, condition, stats, err := rs.EventStore.ProjectWithStats(ctx, projectors, after)
+	rs.record(RecordedCall{Method: "ProjectWithStats", Projectors: projectors, After: after, Err: err, Result: states})
+	return states, condition, stats, err
+}
+
+// ProjectJSON records and delegates ProjectJSON; Result is the JSON states map
+func (rs *RecordingStore) ProjectJSON(ctx context.Context, projectors []StateProjector, after *Cursor) (map[string]json.RawMessage, AppendCondition, error) {
+	states, condition, err := rs.EventStore.ProjectJSON(ctx, projectors, after)
+	rs.record(RecordedCall{Method: "ProjectJSON", Projectors: projectors, After: after, Err: err, Result: states})
+	return states, condition, err
+}
+
+// ProjectMany records and delegates ProjectMany as one call whose Projectors are all the sets'
+// projectors in order; Result is the []ProjectManyResult
+func (rs *RecordingStore) ProjectMany(ctx context.Context, projectorSets [][]StateProjector) ([]ProjectManyResult, error) {
+	results, err := rs.EventStore.ProjectMany(ctx, projectorSets)
+	var projectors []StateProjector
+	for _, set := range projectorSets {
+		projectors = append(projectors, set...)
+	}
+	rs.record(RecordedCall{Method: "ProjectMany", Projectors: projectors, Err: err, Result: results})
+	return results, err
+}
+
+// ProjectTrace records and delegates ProjectTrace; Result is the []StateAtPosition
+func (rs *RecordingStore) ProjectTrace(ctx context.Context, projector StateProjector) ([]StateAtPosition, error) {
+	trace, err := rs.EventStore.ProjectTrace(ctx, projector)
+	rs.record(RecordedCall{Method: "ProjectTrace", Projectors: []StateProjector{projector}, Err: err, Result: trace})
+	return trace, err
+}
+
+// ProjectStream records the opening of a projection stream and delegates ProjectStream
+func (rs *RecordingStore) ProjectStream(ctx context.Context, projectors []StateProjector, after *Cursor) (<-chan map[string]any, <-chan AppendCondition, error) {
+	states, conditions, err := rs.EventStore.ProjectStream(ctx, projectors, after)
+	rs.record(RecordedCall{Method: "ProjectStream", Projectors: projectors, After: after, Err: err})
+	return states, conditions, err
+}
+
+// ProjectProgressive records the opening of a progressive projection and delegates ProjectProgressive
+func (rs *RecordingStore) ProjectProgressive(ctx context.Context, projectors []StateProjector, every int) (<-chan ProjectionSnapshot, error) {
+	snapshots, err := rs.EventStore.ProjectProgressive(ctx, projectors, every)
+	rs.record(RecordedCall{Method: "ProjectProgressive", Projectors: projectors, Err: err})
+	return snapshots, err
+}
+
+// ProjectTx records and delegates ProjectTx; Result is the states map
+func (rs *RecordingStore) ProjectTx(ctx context.Context, tx pgx.Tx, projectors []StateProjector, after *Cursor) (map[string]any, AppendCondition, error) {
+	states, condition, err := rs.EventStore.ProjectTx(ctx, tx, projectors, after)
+	rs.record(RecordedCall{Method: "ProjectTx", Projectors: projectors, After: after, Err: err, Result: states})
+	return states, condition, err
+}
+
+// WithTransaction delegates WithTransaction, recording the calls fn makes on its transaction-scoped
+// store into the same log (they are recorded even if the transaction later rolls back)
+func (rs *RecordingStore) WithTransaction(ctx context.Context, fn func(txStore EventStore) error) error {
+	if fn == nil {
+		return rs.EventStore.WithTransaction(ctx, nil)
+	}
+	return rs.EventStore.WithTransaction(ctx, func(txStore EventStore) error {
+		return fn(&RecordingStore{EventStore: txStore, log: rs.log})
+	})
+}
@@ -0,0 +1,91 @@
+package dcb
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// stubStore answers reads with fixed events and fails appends with appendErr
+type stubStore struct {
+	EventStore
+	events    []Event
+	appendErr error
+}
+
+func (s *stubStore) Query(ctx context.Context, query Query, after *Cursor) ([]Event, error) {
+	return s.events, nil
+}
+
+func (s *stubStore) Latest(ctx context.Context, query Query) (Event, bool, error) {
+	if len(s.events) == 0 {
+		return Event{}, false, nil
+	}
+	return s.events[len(s.events)-1], true, nil
+}
+
+func (s *stubStore) AppendIf(ctx context.Context, events []InputEvent, condition AppendCondition) error {
+	return s.appendErr
+}
+
+func (s *stubStore) WithTransaction(ctx context.Context, fn func(txStore EventStore) error) error {
+	return fn(s)
+}
+
+func TestRecordingStore(t *testing.T) {
+	inner := &stubStore{events: []Event{{Type: "CourseDefined", Position: 1}}}
+	store := NewRecordingStore(inner)
+	ctx := context.Background()
+
+	query := NewQuery(NewTags("course_id", "c1"), "CourseDefined")
+	if _, err := store.Query(ctx, query, nil); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	condition := NewAppendCondition(query)
+	enrolled := NewInputEvent("StudentEnrolled", NewTags("course_id", "c1"), []byte(`{}`))
+	if err := store.AppendIf(ctx, []InputEvent{enrolled}, condition); err != nil {
+		t.Fatalf("AppendIf: %v", err)
+	}
+
+	calls := store.Calls()
+	if len(calls) != 2 || calls[0].Method != "Query" || calls[1].Method != "AppendIf" {
+		t.Fatalf("expected Query then AppendIf, got %v", calls)
+	}
+	if calls[0].Query != query || len(calls[0].Result.([]Event)) != 1 {
+		t.Errorf("expected the query and its result recorded, got %+v", calls[0])
+	}
+	appended := store.CallsTo("AppendIf")
+	if len(appended) != 1 || appended[0].Events[0] != enrolled || appended[0].Condition != condition {
+		t.Errorf("expected the appended events and condition recorded, got %+v", appended)
+	}
+	want := "AppendIf(1 events [StudentEnrolled], condition (type=CourseDefined AND tags{course_id=c1})) -> ok"
+	if got := appended[0].String(); got != want {
+		t.Errorf("unexpected summary:\n got %s\nwant %s", got, want)
+	}
+	if got := calls[0].String(); !strings.HasSuffix(got, "-> 1 events") {
+		t.Errorf("expected the read result in the summary, got %s", got)
+	}
+
+	// Errors are recorded and returned unchanged
+	inner.appendErr = errors.New("conflict")
+	if err := store.AppendIf(ctx, []InputEvent{enrolled}, condition); err != inner.appendErr {
+		t.Fatalf("expected the inner error, got %v", err)
+	}
+	if failed := store.CallsTo("AppendIf"); len(failed) != 2 || failed[1].Err != inner.appendErr {
+		t.Errorf("expected the failed append recorded, got %v", failed)
+	}
+
+	// Calls on the transaction-scoped store share the log
+	store.Reset()
+	err := store.WithTransaction(ctx, func(txStore EventStore) error {
+		_, err := txStore.Query(ctx, query, nil)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithTransaction: %v", err)
+	}
+	if calls := store.Calls(); len(calls) != 1 || calls[0].Method != "Query" {
+		t.Errorf("expected the transaction's Query recorded after Reset, got %v", calls)
+	}
+}
@@ -0,0 +1,232 @@
+package dcb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Replay
+// =============================================================================
+
+// DefaultReplayBatchSize is the number of events Replay reads per batch when none is given
+const DefaultReplayBatchSize = 1000
+
+// ReplayOptions tunes Replay
+type ReplayOptions struct {
+	// Name identifies the replay's checkpoint in the dcb_replay_checkpoints table. After every
+	// batch the cursor of the last handled event is saved under it, and a replay with a saved
+	// checkpoint resumes after it (from is then ignored). Empty disables checkpointing; the table
+	// comes from docker-entrypoint-initdb.d/schema.sql, and without it a named replay is a ConfigurationError
+	Name string
+	// BatchSize is the number of events read per batch and so how often the checkpoint is saved
+	// (DefaultReplayBatchSize when zero)
+	BatchSize int
+	// SkipErrors logs handler errors and moves on to the next event instead of stopping
+	SkipErrors bool
+}
+
+// ReplayResult summarizes a Replay
+type ReplayResult struct {
+	// Handled is the number of events the handler accepted
+	Handled int
+	// Skipped is the number of events whose handler error was skipped (SkipErrors)
+	Skipped int
+	// Cursor is the last event handled or skipped, nil if none; it is what was checkpointed
+	Cursor *Cursor
+}
+
+// Replay calls handler for every committed event matching query, in (transaction_id, position)
+// order, e.g. to backfill or rebuild a read model. Events are read in batches; with
+// opts.Name the progress is checkpointed after every batch, and a later Replay with the same
+// name resumes after the checkpoint, so a restarted backfill doesn't start over.
+//
+// from is the position of the first event to replay (0 = the beginning of the stream); it must be
+// the position of an existing event, which need not match query. Replay covers the events that
+// had committed when each batch was read and returns once it has caught up.
+//
+// A handler error stops the replay: the checkpoint is saved up to the previous event and the error
+// is returned as a ResourceError (Resource "handler") wrapping it, so the next run retries the
+// failing event. With opts.SkipErrors the error is logged and the event counted as skipped.
+// Handlers may see an event again after a crash between handling and checkpointing, so they
+// should be idempotent. Replay is not bounded by WithDefaultTimeouts; use ctx to bound it.
+func (es *eventStore) Replay(ctx context.Context, query Query, from int64, handler func(Event) error, opts ReplayOptions) (ReplayResult, error) {
+	if err := validateReplay(query, from, handler, opts); err != nil {
+		return ReplayResult{}, err
+	}
+	batchSize := opts.BatchSize
+	if batchSize == 0 {
+		batchSize = DefaultReplayBatchSize
+	}
+
+	cursor, err := es.replayStart(ctx, from, opts.Name)
+	if err != nil {
+		return ReplayResult{}, err
+	}
+
+	var result ReplayResult
+	for {
+		events, err := es.readCommittedPage(ctx, "replay", query, cursor, batchSize)
+		if err != nil {
+			return result, err
+		}
+
+		var handlerErr error
+		for _, event := range events {
+			if err := handler(event); err != nil {
+				if !opts.SkipErrors {
+					handlerErr = &ResourceError{
+						EventStoreError: EventStoreError{
+							Op:  "replay",
+							Err: fmt.Errorf("handler failed at position %d: %w", event.Position, err),
+						},
+						Resource: "handler",
+					}
+					break
+				}
+				log.Printf("Replay %s: skipping event at position %d: %v", opts.Name, event.Position, err)
+				result.Skipped++
+			} else {
+				result.Handled++
+			}
+			cursor = &Cursor{TransactionID: event.TransactionID, Position: event.Position}
+			result.Cursor = cursor
+		}
+
+		if result.Cursor != nil {
+			if err := es.saveReplayCheckpoint(ctx, opts.Name, *result.Cursor); err != nil {
+				return result, err
+			}
+		}
+		if handlerErr != nil {
+			return result, handlerErr
+		}
+		if len(events) < batchSize {
+			return result, nil
+		}
+	}
+}
+
+// validateReplay checks Replay's arguments before any database work
+func validateReplay(query Query, from int64, handler func(Event) error, opts ReplayOptions) error {
+	if handler == nil {
+		return &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "replay",
+				Err: fmt.Errorf("handler cannot be nil"),
+			},
+			Field: "handler",
+			Value: "nil",
+		}
+	}
+	if from < 0 {
+		return &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "replay",
+				Err: fmt.Errorf("from position must not be negative: %d", from),
+			},
+			Field: "from",
+			Value: fmt.Sprintf("%d", from),
+		}
+	}
+	if opts.BatchSize < 0 {
+		return &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "replay",
+				Err: fmt.Errorf("batch size must not be negative: %d", opts.BatchSize),
+			},
+			Field: "batchSize",
+			Value: fmt.Sprintf("%d", opts.BatchSize),
+		}
+	}
+	return query.Validate()
+}
+
+// replayStart returns the cursor Replay reads after: the saved checkpoint of name if there is
+// one, otherwise just before the event at position from (nil for the beginning)
+func (es *eventStore) replayStart(ctx context.Context, from int64, name string) (*Cursor, error) {
+	db, err := es.db()
+	if err != nil {
+		return nil, err
+	}
+
+	if name != "" {
+		var checkpoint Cursor
+		err := db.QueryRow(ctx, `SELECT transaction_id, position FROM dcb_replay_checkpoints WHERE name = $1`, name).
+			Scan(&checkpoint.TransactionID, &checkpoint.Position)
+		if err == nil {
+			return &checkpoint, nil
+		}
+		if configErr := asMissingTableError("replay", "dcb_replay_checkpoints", err); configErr != nil {
+			return nil, configErr
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "replay",
+					Err: fmt.Errorf("failed to load checkpoint %q: %w", name, err),
+				},
+				Resource: "database",
+			}
+		}
+	}
+
+	if from == 0 {
+		return nil, nil
+	}
+
+	// A cursor just below from in its own transaction: the event at from and every later one
+	var transactionID uint64
+	err = db.QueryRow(ctx, `SELECT transaction_id FROM `+es.eventsSource()+` WHERE position = $1`, from).Scan(&transactionID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "replay",
+				Err: fmt.Errorf("no event at position %d", from),
+			},
+			Field: "from",
+			Value: fmt.Sprintf("%d", from),
+		}
+	}
+	if err != nil {
+		return nil, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "replay",
+				Err: fmt.Errorf("failed to resolve position %d: %w", from, err),
+			},
+			Resource: "database",
+		}
+	}
+	return &Cursor{TransactionID: transactionID, Position: from - 1}, nil
+}
+
+// saveReplayCheckpoint stores cursor as the checkpoint of name (a no-op without a name)
+func (es *eventStore) saveReplayCheckpoint(ctx context.Context, name string, cursor Cursor) error {
+	if name == "" {
+		return nil
+	}
+	db, err := es.db()
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(ctx, `
+		INSERT INTO dcb_replay_checkpoints (name, transaction_id, position, updated_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		ON CONFLICT (name) DO UPDATE
+		SET transaction_id = EXCLUDED.transaction_id, position = EXCLUDED.position, updated_at = EXCLUDED.updated_at
+	`, name, cursor.TransactionID, cursor.Position)
+	if err != nil {
+		return &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "replay",
+				Err: fmt.Errorf("failed to save checkpoint %q: %w", name, err),
+			},
+			Resource: "database",
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package dcb
+
+import "testing"
+
+func TestValidateReplay(t *testing.T) {
+	query := NewQuery(NewTags("course_id", "c1"), "CourseDefined")
+	handler := func(Event) error { return nil }
+
+	if err := validateReplay(query, 0, handler, ReplayOptions{Name: "backfill"}); err != nil {
+		t.Fatalf("expected valid replay, got %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		from    int64
+		handler func(Event) error
+		opts    ReplayOptions
+		field   string
+	}{
+		{name: "nil handler", handler: nil, field: "handler"},
+		{name: "negative from", from: -1, handler: handler, field: "from"},
+		{name: "negative batch size", handler: handler, opts: ReplayOptions{BatchSize: -5}, field: "batchSize"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateReplay(query, tt.from, tt.handler, tt.opts)
+			validationErr, ok := GetValidationError(err)
+			if !ok {
+				t.Fatalf("expected ValidationError, got %v", err)
+			}
+			if validationErr.Field != tt.field {
+				t.Fatalf("expected field %q, got %q", tt.field, validationErr.Field)
+			}
+		})
+	}
+}
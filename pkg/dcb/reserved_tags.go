@@ -0,0 +1,58 @@
+package dcb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// =============================================================================
+// Reserved Tag Keys
+// =============================================================================
+
+// validateReservedTagKeyPrefixes checks EventStoreConfig.ReservedTagKeyPrefixes
+func validateReservedTagKeyPrefixes(op string, prefixes []string) error {
+	for i, prefix := range prefixes {
+		if prefix == "" {
+			return &ValidationError{
+				EventStoreError: EventStoreError{Op: op, Err: fmt.Errorf("reserved tag key prefix %d is empty", i)},
+				Field:           "reservedTagKeyPrefixes",
+				Value:           fmt.Sprintf("index[%d]", i),
+			}
+		}
+	}
+	return nil
+}
+
+// reservedTagKeyPrefix returns the configured prefix key starts with, if any
+func (es *eventStore) reservedTagKeyPrefix(key string) (string, bool) {
+	for _, prefix := range es.config.ReservedTagKeyPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// validateReservedTags rejects events carrying a tag whose key starts with a reserved prefix.
+// Derived tags are checked when they are computed (deriveTags); the producer tag of ProducerAsTag
+// is the store's own and exempt
+func (es *eventStore) validateReservedTags(events []InputEvent, operation string) error {
+	if len(es.config.ReservedTagKeyPrefixes) == 0 {
+		return nil
+	}
+	for i, event := range events {
+		for j, t := range event.GetTags() {
+			if prefix, reserved := es.reservedTagKeyPrefix(t.GetKey()); reserved {
+				return &ValidationError{
+					EventStoreError: EventStoreError{
+						Op:  operation,
+						Err: fmt.Errorf("tag key %q in event %d uses the reserved prefix %q (ReservedTagKeyPrefixes)", t.GetKey(), i, prefix),
+					},
+					Field: fmt.Sprintf("event[%d].tag[%d].key", i, j),
+					Value: t.GetKey(),
+				}
+			}
+		}
+	}
+	return nil
+}
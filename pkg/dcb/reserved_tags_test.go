@@ -0,0 +1,32 @@
+package dcb
+
+import "testing"
+
+func TestValidateReservedTags(t *testing.T) {
+	es := &eventStore{config: EventStoreConfig{MaxAppendBatchSize: 10, ReservedTagKeyPrefixes: []string{"tenant_id", "_"}}}
+
+	allowed := []InputEvent{NewInputEvent("OrderPlaced", NewTags("order_id", "o1", "tenant", "t1"), []byte(`{}`))}
+	if err := es.validateAppendEvents(allowed, "append"); err != nil {
+		t.Errorf("expected unreserved tags to pass, got %v", err)
+	}
+
+	for _, key := range []string{"tenant_id", "tenant_id_override", "_schema_version"} {
+		events := append(allowed, NewInputEvent("OrderPlaced", []Tag{NewTag("order_id", "o2"), NewTag(key, "x")}, []byte(`{}`)))
+		err := es.validateAppendEvents(events, "append")
+		validationErr, ok := GetValidationError(err)
+		if !ok {
+			t.Fatalf("expected validation error for reserved key %q, got %v", key, err)
+		}
+		if validationErr.Field != "event[1].tag[1].key" || validationErr.Value != key {
+			t.Errorf("expected the reserved tag to be named, got field %q value %q", validationErr.Field, validationErr.Value)
+		}
+	}
+
+	if err := (&eventStore{config: EventStoreConfig{MaxAppendBatchSize: 10}}).validateAppendEvents(
+		[]InputEvent{NewInputEvent("OrderPlaced", NewTags("tenant_id", "t1"), []byte(`{}`))}, "append"); err != nil {
+		t.Errorf("expected no reserved keys by default, got %v", err)
+	}
+	if err := validateReservedTagKeyPrefixes("new_event_store", []string{"lock", ""}); !IsValidationError(err) {
+		t.Errorf("expected validation error for an empty prefix, got %v", err)
+	}
+}
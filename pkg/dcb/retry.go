@@ -0,0 +1,101 @@
+package dcb
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// =============================================================================
+// Transient Error Classification and Read Retries
+// =============================================================================
+
+// transientSQLStates lists PostgreSQL error codes caused by the connection or server state
+// rather than by the statement itself
+var transientSQLStates = map[string]bool{
+	"57P01": true, // admin_shutdown
+	"57P02": true, // crash_shutdown
+	"57P03": true, // cannot_connect_now
+	"53300": true, // too_many_connections
+}
+
+// IsTransient reports whether err is a transient connection or pool error
+// (connection reset, server shutdown, failure to connect or acquire a connection)
+// after which the same operation may succeed when retried.
+// Context cancellation and deadline errors are never transient.
+// Errors wrapped by EventStoreError and its subtypes are classified by their cause.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// Class 08: connection exception
+		return strings.HasPrefix(pgErr.Code, "08") || transientSQLStates[pgErr.Code]
+	}
+
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+
+	if pgconn.SafeToRetry(err) {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// withReadRetry runs a read-only operation, retrying it on transient errors up to
+// EventStoreConfig.ReadRetries times with exponential backoff starting at ReadRetryBackoff.
+// Only idempotent reads may use this; writes are never retried automatically.
+// Transaction-scoped stores don't retry: a failed statement aborts the surrounding transaction.
+func (es *eventStore) withReadRetry(ctx context.Context, operation func() error) error {
+	if es.scope != nil {
+		return operation()
+	}
+	backoff := time.Duration(es.config.ReadRetryBackoff) * time.Millisecond
+
+	err := operation()
+	for attempt := 0; attempt < es.config.ReadRetries && IsTransient(err); attempt++ {
+		if backoff > 0 {
+			select {
+			case <-ctx.Done():
+				return err
+			case <-es.clock().After(backoff):
+			}
+			backoff *= 2
+		}
+		err = operation()
+	}
+	return err
+}
+
+// queryWithRetry starts a read query on the pool, retrying transient failures that occur
+// before any row has been returned
+func (es *eventStore) queryWithRetry(ctx context.Context, sqlQuery string, args ...any) (pgx.Rows, error) {
+	db, err := es.db()
+	if err != nil {
+		return nil, err
+	}
+	var rows pgx.Rows
+	err = es.withReadRetry(ctx, func() error {
+		var err error
+		rows, err = db.Query(ctx, sqlQuery, args...)
+		return err
+	})
+	return rows, err
+}
@@ -0,0 +1,96 @@
+package dcb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"admin shutdown", &pgconn.PgError{Code: "57P01"}, true},
+		{"connection failure", &pgconn.PgError{Code: "08006"}, true},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, false},
+		{"connection reset", fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		{"context canceled", context.Canceled, false},
+		{"deadline exceeded", fmt.Errorf("query: %w", context.DeadlineExceeded), false},
+		{"plain error", errors.New("boom"), false},
+		{
+			"wrapped in ResourceError",
+			&ResourceError{EventStoreError: EventStoreError{Op: "query", Err: fmt.Errorf("failed: %w", &pgconn.PgError{Code: "57P01"})}},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransient(tt.err); got != tt.want {
+				t.Errorf("IsTransient(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithReadRetry(t *testing.T) {
+	transient := &pgconn.PgError{Code: "57P01"}
+
+	t.Run("retries transient errors until success", func(t *testing.T) {
+		es := &eventStore{config: EventStoreConfig{ReadRetries: 2}}
+		calls := 0
+		err := es.withReadRetry(context.Background(), func() error {
+			calls++
+			if calls < 3 {
+				return transient
+			}
+			return nil
+		})
+		if err != nil || calls != 3 {
+			t.Fatalf("expected success after 3 calls, got err=%v calls=%d", err, calls)
+		}
+	})
+
+	t.Run("stops after configured retries", func(t *testing.T) {
+		es := &eventStore{config: EventStoreConfig{ReadRetries: 1}}
+		calls := 0
+		err := es.withReadRetry(context.Background(), func() error {
+			calls++
+			return transient
+		})
+		if !errors.Is(err, transient) || calls != 2 {
+			t.Fatalf("expected transient error after 2 calls, got err=%v calls=%d", err, calls)
+		}
+	})
+
+	t.Run("doubles the backoff between retries", func(t *testing.T) {
+		clock := NewFakeClock(t)
+		es := &eventStore{config: EventStoreConfig{ReadRetries: 3, ReadRetryBackoff: 10, Clock: clock}}
+		_ = es.withReadRetry(context.Background(), func() error { return transient })
+		want := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond}
+		if !slices.Equal(clock.Waits(), want) {
+			t.Fatalf("expected backoff %v, got %v", want, clock.Waits())
+		}
+	})
+
+	t.Run("does not retry permanent errors", func(t *testing.T) {
+		es := &eventStore{config: EventStoreConfig{ReadRetries: 3}}
+		calls := 0
+		_ = es.withReadRetry(context.Background(), func() error {
+			calls++
+			return errors.New("syntax error")
+		})
+		if calls != 1 {
+			t.Fatalf("expected a single call, got %d", calls)
+		}
+	})
+}
@@ -0,0 +1,210 @@
+package dcb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// =============================================================================
+// database/sql Connections
+// =============================================================================
+
+// NewEventStoreFromSQLDB creates an EventStore over an existing *sql.DB opened with the pgx stdlib
+// driver (driver name "pgx", or stdlib.OpenDB), for applications whose database layer already
+// owns a database/sql pool. Each statement or transaction checks out one of db's connections and
+// runs on the pgx connection underneath it, so queries, appends and projections behave as over a
+// pgxpool.Pool and db's pool settings (SetMaxOpenConns and so on) apply.
+//
+// A few features need a pgxpool.Pool and are not available: GetPool returns nil (use
+// WithTransaction instead of AppendIfTx and ProjectTx with a pool transaction),
+// SubscribeCommands fails with a ValidationError, ValidateStreamConnections is ignored and a
+// non-zero WarmupConnections is rejected. Other drivers fail with a ConfigurationError.
+func NewEventStoreFromSQLDB(ctx context.Context, db *sql.DB, config EventStoreConfig) (EventStore, error) {
+	if db == nil {
+		return nil, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "new_event_store",
+				Err: fmt.Errorf("database cannot be nil"),
+			},
+			Field: "db",
+			Value: "nil",
+		}
+	}
+	if config.WarmupConnections != 0 {
+		return nil, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "new_event_store",
+				Err: fmt.Errorf("WarmupConnections requires a pgxpool.Pool; use db.SetMaxIdleConns to keep connections open"),
+			},
+			Field: "WarmupConnections",
+			Value: fmt.Sprintf("%d", config.WarmupConnections),
+		}
+	}
+	if err := validateEventStoreConfig(config); err != nil {
+		return nil, err
+	}
+
+	// Test the connection, and that it is a pgx one
+	conns := &sqlDBConns{db: db}
+	conn, _, err := conns.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conn.Close()
+
+	lowerTagValues, numericTagValue, err := inspectSchema(ctx, conns, config)
+	if err != nil {
+		return nil, err
+	}
+
+	es := newEventStore(nil, config)
+	es.sqlDB = conns
+	es.lowerTagValues = lowerTagValues
+	es.numericTagValue = numericTagValue
+	if config.SharedTagBlocks {
+		if err := es.loadTagBlocks(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return es, nil
+}
+
+// sqlDBConns runs statements and transactions on the pgx connections of a database/sql pool
+type sqlDBConns struct {
+	db *sql.DB
+}
+
+// conn checks out a connection of the pool and returns it with its pgx connection, which is the
+// caller's until it closes the *sql.Conn
+func (s *sqlDBConns) conn(ctx context.Context) (*sql.Conn, *pgx.Conn, error) {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, nil, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "sql_db",
+				Err: fmt.Errorf("failed to acquire connection: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	var pgxConn *pgx.Conn
+	err = conn.Raw(func(driverConn any) error {
+		stdlibConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return &ConfigurationError{
+				EventStoreError: EventStoreError{
+					Op:  "sql_db",
+					Err: fmt.Errorf("database/sql connection %T is not from the pgx stdlib driver", driverConn),
+				},
+				Component: "driver",
+				Remedy:    `open the *sql.DB with the pgx stdlib driver: sql.Open("pgx", dsn) or stdlib.OpenDB`,
+			}
+		}
+		pgxConn = stdlibConn.Conn()
+		return nil
+	})
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return conn, pgxConn, nil
+}
+
+// Query runs a query on a checked-out connection, released when the rows are closed
+func (s *sqlDBConns) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	conn, pgxConn, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := pgxConn.Query(ctx, sql, args...)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &releasingRows{Rows: rows, release: func() { conn.Close() }}, nil
+}
+
+// QueryRow runs a query on a checked-out connection, released once the row is scanned
+func (s *sqlDBConns) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	rows, err := s.Query(ctx, sql, args...)
+	return &sqlDBRow{rows: rows, err: err}
+}
+
+// Exec runs a statement on a checked-out connection
+func (s *sqlDBConns) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	conn, pgxConn, err := s.conn(ctx)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	defer conn.Close()
+	return pgxConn.Exec(ctx, sql, arguments...)
+}
+
+// BeginTx begins a transaction on a checked-out connection, released when it ends
+func (s *sqlDBConns) BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
+	conn, pgxConn, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := pgxConn.BeginTx(ctx, opts)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &sqlDBTx{Tx: tx, conn: conn}, nil
+}
+
+// sqlDBRow is the pgx.Row of sqlDBConns.QueryRow
+type sqlDBRow struct {
+	rows pgx.Rows
+	err  error
+}
+
+// Scan scans the first row like pgx's QueryRow, returning pgx.ErrNoRows when there is none
+func (r *sqlDBRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	defer r.rows.Close()
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+		return pgx.ErrNoRows
+	}
+	if err := r.rows.Scan(dest...); err != nil {
+		return err
+	}
+	r.rows.Close()
+	return r.rows.Err()
+}
+
+// sqlDBTx returns its connection to the database/sql pool when the transaction ends
+type sqlDBTx struct {
+	pgx.Tx
+	conn *sql.Conn
+}
+
+// Commit commits the transaction and releases the connection
+func (tx *sqlDBTx) Commit(ctx context.Context) error {
+	defer tx.release()
+	return tx.Tx.Commit(ctx)
+}
+
+// Rollback rolls the transaction back and releases the connection; it is safe to call after Commit
+func (tx *sqlDBTx) Rollback(ctx context.Context) error {
+	defer tx.release()
+	return tx.Tx.Rollback(ctx)
+}
+
+func (tx *sqlDBTx) release() {
+	if tx.conn != nil {
+		tx.conn.Close()
+		tx.conn = nil
+	}
+}
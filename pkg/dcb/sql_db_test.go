@@ -0,0 +1,36 @@
+package dcb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+func TestNewEventStoreFromSQLDBValidation(t *testing.T) {
+	if _, err := NewEventStoreFromSQLDB(context.Background(), nil, EventStoreConfig{}); !IsValidationError(err) {
+		t.Errorf("expected validation error for a nil database, got %v", err)
+	}
+
+	// Opening doesn't connect, and the warm-up is rejected before any connection is made
+	db, err := sql.Open("pgx", "postgres://localhost:1/none")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+	_, err = NewEventStoreFromSQLDB(context.Background(), db, EventStoreConfig{WarmupConnections: 2})
+	validationErr, ok := GetValidationError(err)
+	if !ok || validationErr.Field != "WarmupConnections" {
+		t.Errorf("expected WarmupConnections validation error, got %v", err)
+	}
+}
+
+func TestSQLDBRowScan(t *testing.T) {
+	failure := errors.New("connection refused")
+	row := &sqlDBRow{err: failure}
+	if err := row.Scan(); !errors.Is(err, failure) {
+		t.Errorf("expected the query error, got %v", err)
+	}
+}
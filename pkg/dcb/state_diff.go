@@ -0,0 +1,162 @@
+package dcb
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// =============================================================================
+// State Diff
+// =============================================================================
+
+// FieldChange is a field whose value differs between two projected states
+type FieldChange struct {
+	// Path is the dot-separated path of the field, using JSON field names for struct fields
+	// (as TagsFromStruct does) and formatted keys for map entries, e.g. "balance" or
+	// "items.sku-1.quantity". It is empty when the states themselves are scalars.
+	Path string
+	// Before is the old value, nil when the field or map entry didn't exist
+	Before any
+	// After is the new value, nil when the field or map entry no longer exists
+	After any
+}
+
+// DiffStates lists the fields that changed between two projected states, typically the state
+// from Project before a decision and the one returned by AppendAndProject after it:
+//
+//	before, _, _ := store.Project(ctx, projectors, nil)
+//	after, _, _, _ := store.AppendAndProject(ctx, events, condition, projectors)
+//	changes, _ := dcb.DiffStates(before["account"], after["account"])
+//
+// Structs (and pointers to them) are compared field by field and maps entry by entry,
+// recursing into nested structs and maps. Slices, arrays and structs without exported fields
+// (such as time.Time) are compared as a whole with reflect.DeepEqual. Changes are returned in
+// struct field order, map entries sorted by key. Both states must have the same type (a nil
+// state is allowed on either side); otherwise DiffStates returns a ValidationError.
+func DiffStates(before, after any) ([]FieldChange, error) {
+	beforeValue, afterValue := indirectState(reflect.ValueOf(before)), indirectState(reflect.ValueOf(after))
+	if beforeValue.IsValid() && afterValue.IsValid() && beforeValue.Type() != afterValue.Type() {
+		return nil, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "diff_states",
+				Err: fmt.Errorf("states have different types %s and %s", beforeValue.Type(), afterValue.Type()),
+			},
+			Field: "after",
+			Value: afterValue.Type().String(),
+		}
+	}
+
+	var changes []FieldChange
+	diffValues("", beforeValue, afterValue, &changes)
+	return changes, nil
+}
+
+// diffValues appends the changes between before and after (either may be invalid: absent or nil)
+func diffValues(path string, before, after reflect.Value, changes *[]FieldChange) {
+	before, after = indirectState(before), indirectState(after)
+	if !before.IsValid() || !after.IsValid() || before.Type() != after.Type() {
+		if before.IsValid() || after.IsValid() {
+			*changes = append(*changes, FieldChange{Path: path, Before: stateValue(before), After: stateValue(after)})
+		}
+		return
+	}
+
+	switch before.Kind() {
+	case reflect.Struct:
+		fields := exportedStateFields(before.Type())
+		if len(fields) == 0 {
+			break
+		}
+		for _, field := range fields {
+			diffValues(joinStatePath(path, field.name), stateFieldValue(before, field.index), stateFieldValue(after, field.index), changes)
+		}
+		return
+	case reflect.Map:
+		for _, key := range unionMapKeys(before, after) {
+			diffValues(joinStatePath(path, fmt.Sprint(key.Interface())), before.MapIndex(key), after.MapIndex(key), changes)
+		}
+		return
+	}
+
+	beforeAny, afterAny := stateValue(before), stateValue(after)
+	if !reflect.DeepEqual(beforeAny, afterAny) {
+		*changes = append(*changes, FieldChange{Path: path, Before: beforeAny, After: afterAny})
+	}
+}
+
+// indirectState follows pointers and interfaces, returning an invalid Value for nil
+func indirectState(value reflect.Value) reflect.Value {
+	for value.IsValid() && (value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface) {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+// stateValue returns the value as any, nil for invalid values
+func stateValue(value reflect.Value) any {
+	if !value.IsValid() || !value.CanInterface() {
+		return nil
+	}
+	return value.Interface()
+}
+
+type stateField struct {
+	name  string
+	index []int
+}
+
+// exportedStateFields lists the JSON-named exported fields of a struct type, shallowest first
+func exportedStateFields(structType reflect.Type) []stateField {
+	var fields []stateField
+	seen := make(map[string]bool)
+	for _, field := range reflect.VisibleFields(structType) {
+		name, ok := jsonFieldName(field)
+		if !ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		fields = append(fields, stateField{name: name, index: field.Index})
+	}
+	return fields
+}
+
+// stateFieldValue returns the field at index, invalid when promoted through a nil embedded pointer
+func stateFieldValue(value reflect.Value, index []int) reflect.Value {
+	field, err := value.FieldByIndexErr(index)
+	if err != nil {
+		return reflect.Value{}
+	}
+	return field
+}
+
+// unionMapKeys returns the keys of both maps, sorted by their formatted value
+func unionMapKeys(before, after reflect.Value) []reflect.Value {
+	keys := make(map[string]reflect.Value)
+	for _, m := range []reflect.Value{before, after} {
+		for _, key := range m.MapKeys() {
+			keys[fmt.Sprint(key.Interface())] = key
+		}
+	}
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sorted := make([]reflect.Value, len(names))
+	for i, name := range names {
+		sorted[i] = keys[name]
+	}
+	return sorted
+}
+
+func joinStatePath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
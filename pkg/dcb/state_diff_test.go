@@ -0,0 +1,126 @@
+package dcb
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type diffAddress struct {
+	City string `json:"city"`
+}
+
+type diffAudit struct {
+	UpdatedBy string `json:"updated_by"`
+}
+
+type diffAccount struct {
+	*diffAudit
+	ID       string         `json:"id"`
+	Balance  int            `json:"balance"`
+	Address  diffAddress    `json:"address"`
+	Limits   map[string]int `json:"limits"`
+	Tags     []string       `json:"tags"`
+	OpenedAt time.Time      `json:"opened_at"`
+	Internal string         `json:"-"`
+	note     string
+}
+
+func TestDiffStatesStructs(t *testing.T) {
+	opened := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := diffAccount{
+		ID:       "acc1",
+		Balance:  100,
+		Address:  diffAddress{City: "Lisbon"},
+		Limits:   map[string]int{"daily": 500, "monthly": 2000},
+		Tags:     []string{"gold"},
+		OpenedAt: opened,
+		Internal: "a",
+		note:     "a",
+	}
+	after := before
+	after.Balance = 150
+	after.Address = diffAddress{City: "Porto"}
+	after.Limits = map[string]int{"daily": 700, "weekly": 1000}
+	after.Tags = []string{"gold", "vip"}
+	after.Internal = "b"
+	after.note = "b"
+	after.diffAudit = &diffAudit{UpdatedBy: "alice"}
+
+	changes, err := DiffStates(before, &after)
+	if err != nil {
+		t.Fatalf("DiffStates: %v", err)
+	}
+	want := []FieldChange{
+		{Path: "updated_by", Before: nil, After: "alice"},
+		{Path: "balance", Before: 100, After: 150},
+		{Path: "address.city", Before: "Lisbon", After: "Porto"},
+		{Path: "limits.daily", Before: 500, After: 700},
+		{Path: "limits.monthly", Before: 2000, After: nil},
+		{Path: "limits.weekly", Before: nil, After: 1000},
+		{Path: "tags", Before: []string{"gold"}, After: []string{"gold", "vip"}},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("changes = %+v, want %+v", changes, want)
+	}
+}
+
+func TestDiffStatesUnchanged(t *testing.T) {
+	state := diffAccount{ID: "acc1", Limits: map[string]int{"daily": 1}, OpenedAt: time.Unix(0, 0)}
+	changes, err := DiffStates(state, state)
+	if err != nil {
+		t.Fatalf("DiffStates: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("expected no changes, got %+v", changes)
+	}
+}
+
+func TestDiffStatesMapsAndScalars(t *testing.T) {
+	before := map[string]any{"count": 1, "nested": map[string]any{"a": "x"}}
+	after := map[string]any{"count": 2, "nested": map[string]any{"a": "y"}}
+	changes, err := DiffStates(before, after)
+	if err != nil {
+		t.Fatalf("DiffStates: %v", err)
+	}
+	want := []FieldChange{
+		{Path: "count", Before: 1, After: 2},
+		{Path: "nested.a", Before: "x", After: "y"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("changes = %+v, want %+v", changes, want)
+	}
+
+	changes, err = DiffStates(3, 4)
+	if err != nil {
+		t.Fatalf("DiffStates: %v", err)
+	}
+	if !reflect.DeepEqual(changes, []FieldChange{{Path: "", Before: 3, After: 4}}) {
+		t.Fatalf("scalar changes = %+v", changes)
+	}
+}
+
+func TestDiffStatesNilState(t *testing.T) {
+	changes, err := DiffStates(nil, diffAddress{City: "Lisbon"})
+	if err != nil {
+		t.Fatalf("DiffStates: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Path != "" || changes[0].Before != nil {
+		t.Fatalf("changes = %+v", changes)
+	}
+
+	changes, err = DiffStates(nil, nil)
+	if err != nil || len(changes) != 0 {
+		t.Fatalf("DiffStates(nil, nil) = %+v, %v", changes, err)
+	}
+}
+
+func TestDiffStatesDifferentTypes(t *testing.T) {
+	_, err := DiffStates(diffAddress{}, diffAudit{})
+	if !IsValidationError(err) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	if ve, _ := GetValidationError(err); ve.Field != "after" {
+		t.Fatalf("Field = %q, want after", ve.Field)
+	}
+}
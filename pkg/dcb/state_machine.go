@@ -0,0 +1,82 @@
+package dcb
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// =============================================================================
+// State Machine Projectors
+// =============================================================================
+
+// StateMachineState is the state of a ProjectStrictStateMachine projector
+type StateMachineState struct {
+	// State is the current state of the machine
+	State string `json:"state"`
+	// Err records the first event with no transition from the state it arrived in; the machine
+	// stays in State and the projection stops reading its events
+	Err error `json:"-"`
+}
+
+// ProjectStateMachine creates a projector whose state is a string moved by a transition table
+// rather than a type switch: transitions[current][eventType] is the state an event of eventType
+// leads to from current, e.g. {"available": {"SeatBooked": "booked"}, "booked": {"BookingCancelled":
+// "cancelled"}}. The machine starts in initial. A matching event with no transition from the
+// current state leaves it unchanged; use ProjectStrictStateMachine to catch such events instead.
+// The table can be rendered with StateMachineDOT
+func ProjectStateMachine(id string, query Query, transitions map[string]map[string]string, initial string) StateProjector {
+	return StateProjector{
+		ID:           id,
+		Query:        query,
+		InitialState: initial,
+		TransitionFn: func(state any, event Event) any {
+			current := state.(string)
+			if next, ok := transitions[current][event.Type]; ok {
+				return next
+			}
+			return current
+		},
+	}
+}
+
+// ProjectStrictStateMachine is ProjectStateMachine that treats a matching event with no transition
+// from the current state as an error: the state is a StateMachineState whose Err names the event,
+// and the projector stops there (StopFn), so Project returns the state the machine was in
+func ProjectStrictStateMachine(id string, query Query, transitions map[string]map[string]string, initial string) StateProjector {
+	return StateProjector{
+		ID:           id,
+		Query:        query,
+		InitialState: StateMachineState{State: initial},
+		TransitionFn: func(state any, event Event) any {
+			current := state.(StateMachineState)
+			next, ok := transitions[current.State][event.Type]
+			if !ok {
+				current.Err = fmt.Errorf("invalid transition: %s event at position %d in state %q", event.Type, event.Position, current.State)
+				return current
+			}
+			current.State = next
+			return current
+		},
+		StopFn: func(state any) bool {
+			return state.(StateMachineState).Err != nil
+		},
+	}
+}
+
+// StateMachineDOT renders a transition table (see ProjectStateMachine) as a Graphviz DOT digraph,
+// one edge per transition labelled with its event type and the initial state drawn bold.
+// States and transitions are sorted, so the same table always renders the same output
+func StateMachineDOT(transitions map[string]map[string]string, initial string) string {
+	var b strings.Builder
+	b.WriteString("digraph {\n")
+	fmt.Fprintf(&b, "  %q [style=bold];\n", initial)
+	for _, from := range slices.Sorted(maps.Keys(transitions)) {
+		for _, eventType := range slices.Sorted(maps.Keys(transitions[from])) {
+			fmt.Fprintf(&b, "  %q -> %q [label=%q];\n", from, transitions[from][eventType], eventType)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
@@ -0,0 +1,70 @@
+package dcb
+
+import "testing"
+
+var seatTransitions = map[string]map[string]string{
+	"available": {"SeatBooked": "booked"},
+	"booked":    {"BookingCancelled": "cancelled", "SeatReleased": "available"},
+}
+
+// foldStateMachine applies events of the given types to projector's initial state
+func foldStateMachine(projector StateProjector, eventTypes ...string) any {
+	state := projector.InitialState
+	for i, eventType := range eventTypes {
+		if projector.StopFn != nil && projector.StopFn(state) {
+			break
+		}
+		state = projector.TransitionFn(state, Event{Type: eventType, Position: int64(i + 1)})
+	}
+	return state
+}
+
+func TestProjectStateMachine(t *testing.T) {
+	projector := ProjectStateMachine("seat", NewQuery(NewTags("seat_id", "s1")), seatTransitions, "available")
+
+	tests := []struct {
+		events []string
+		want   string
+	}{
+		{nil, "available"},
+		{[]string{"SeatBooked"}, "booked"},
+		{[]string{"SeatBooked", "SeatReleased", "SeatBooked", "BookingCancelled"}, "cancelled"},
+		{[]string{"BookingCancelled", "SeatBooked"}, "booked"}, // no transition: unchanged
+		{[]string{"SeatBooked", "BookingCancelled", "SeatBooked"}, "cancelled"},
+	}
+	for _, tt := range tests {
+		if got := foldStateMachine(projector, tt.events...); got != tt.want {
+			t.Errorf("%v: expected %q, got %q", tt.events, tt.want, got)
+		}
+	}
+}
+
+func TestProjectStrictStateMachine(t *testing.T) {
+	projector := ProjectStrictStateMachine("seat", NewQuery(NewTags("seat_id", "s1")), seatTransitions, "available")
+
+	state := foldStateMachine(projector, "SeatBooked", "BookingCancelled").(StateMachineState)
+	if state.State != "cancelled" || state.Err != nil {
+		t.Errorf("expected cancelled without error, got %+v", state)
+	}
+
+	state = foldStateMachine(projector, "SeatBooked", "SeatBooked", "SeatReleased").(StateMachineState)
+	if state.State != "booked" || state.Err == nil {
+		t.Fatalf("expected the invalid transition recorded in state booked, got %+v", state)
+	}
+	if want := `invalid transition: SeatBooked event at position 2 in state "booked"`; state.Err.Error() != want {
+		t.Errorf("expected %q, got %q", want, state.Err)
+	}
+}
+
+func TestStateMachineDOT(t *testing.T) {
+	want := `digraph {
+  "available" [style=bold];
+  "available" -> "booked" [label="SeatBooked"];
+  "booked" -> "cancelled" [label="BookingCancelled"];
+  "booked" -> "available" [label="SeatReleased"];
+}
+`
+	if got := StateMachineDOT(seatTransitions, "available"); got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+}
@@ -0,0 +1,110 @@
+package dcb
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// =============================================================================
+// Stream Connection Validation
+// =============================================================================
+
+// StreamStats reports the state of the store's streams (QueryStream, QueryGrouped, ProjectStream
+// and Subscribe). It is shared by all copies of a store, e.g. WithDefaultTimeouts wrappers
+type StreamStats struct {
+	Open  int // Streams currently open
+	Limit int // MaxConcurrentStreams, including changes made by Reconfigure
+	// EvictedConnections counts pool connections found dead when a stream checked them out and
+	// closed (EventStoreConfig.ValidateStreamConnections)
+	EvictedConnections int64
+}
+
+// StreamStats returns the current stream counters
+func (es *eventStore) StreamStats() StreamStats {
+	es.live.streamsMu.Lock()
+	defer es.live.streamsMu.Unlock()
+	return StreamStats{
+		Open:               es.live.openStreams,
+		Limit:              es.live.maxStreams,
+		EvictedConnections: es.live.evictedConns.Load(),
+	}
+}
+
+// streamQuery starts the query a stream reads its rows from. With ValidateStreamConnections the
+// connection is checked out and validated first (see acquireValidatedConn) and stays checked out
+// until the rows are closed; otherwise it is queryWithRetry. Read retries apply either way
+func (es *eventStore) streamQuery(ctx context.Context, sqlQuery string, args ...any) (pgx.Rows, error) {
+	if !es.config.ValidateStreamConnections || es.scope != nil || es.pool == nil {
+		return es.queryWithRetry(ctx, sqlQuery, args...)
+	}
+	var rows pgx.Rows
+	err := es.withReadRetry(ctx, func() error {
+		conn, err := es.acquireValidatedConn(ctx)
+		if err != nil {
+			return err
+		}
+		connRows, err := conn.Query(ctx, sqlQuery, args...)
+		if err != nil {
+			conn.Release()
+			return err
+		}
+		rows = &releasingRows{Rows: connRows, release: conn.Release}
+		return nil
+	})
+	return rows, err
+}
+
+// acquireValidatedConn checks out a pool connection and pings it, so a connection the server or a
+// proxy terminated while idle fails here rather than mid-stream. A dead connection is removed from
+// the pool and closed at once, instead of being handed to the next caller, and the checkout is
+// retried once with another connection
+func (es *eventStore) acquireValidatedConn(ctx context.Context) (*pgxpool.Conn, error) {
+	for attempt := 0; ; attempt++ {
+		conn, err := es.pool.Acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		err = conn.Ping(ctx)
+		if err == nil {
+			return conn, nil
+		}
+		if ctx.Err() != nil {
+			// The ping was cut short by the caller, which says nothing about the connection
+			conn.Release()
+			return nil, err
+		}
+
+		conn.Hijack().Close(ctx)
+		es.live.evictedConns.Add(1)
+		if attempt > 0 {
+			return nil, err
+		}
+	}
+}
+
+// releasingRows returns its connection to the pool when the rows are closed
+type releasingRows struct {
+	pgx.Rows
+	release func()
+}
+
+// Next advances to the next row, closing the rows (and so releasing the connection) after the
+// last one, as pgxpool does for its own rows
+func (r *releasingRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.Close()
+	return false
+}
+
+// Close closes the rows and releases the connection; it is safe to call more than once
+func (r *releasingRows) Close() {
+	r.Rows.Close()
+	if r.release != nil {
+		r.release()
+		r.release = nil
+	}
+}
@@ -0,0 +1,142 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Live Subscription
+// =============================================================================
+
+// DefaultSubscribePollInterval is how often Subscribe checks for new events when none is given
+const DefaultSubscribePollInterval = 500 * time.Millisecond
+
+// subscribePageSize bounds the events read per Subscribe poll; a full page is followed by an
+// immediate next poll, so a long replay is read in pages without waiting between them
+const subscribePageSize = 1000
+
+// Subscribe streams the events matching query after the cursor (all events when after is nil)
+// and then stays open, delivering newly committed matching events until ctx is cancelled.
+// It is the building block for live consumers such as dashboards: replay from a saved cursor,
+// then follow the store.
+//
+// New events are found by polling every pollInterval (DefaultSubscribePollInterval when zero or
+// negative). Only events of transactions older than every running one are delivered, the same
+// visibility rule append conditions use, so an event that commits late with a lower transaction
+// id than one already delivered is never skipped; the price is that a new event is delivered once
+// the transactions started before it have finished. Events arrive in (transaction_id, position)
+// order, each exactly once per subscription.
+//
+// The subscription holds one of the MaxConcurrentStreams slots until it ends. Cancel ctx to end
+// it: the channel is closed and the slot released. The channel is also closed if a read fails
+// after retries; resubscribe from the cursor of the last event received to continue without gaps.
+// Subscribe is not bounded by WithDefaultTimeouts, as it is meant to stay open.
+func (es *eventStore) Subscribe(ctx context.Context, query Query, after *Cursor, pollInterval time.Duration) (<-chan Event, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	if pollInterval <= 0 {
+		pollInterval = DefaultSubscribePollInterval
+	}
+
+	release, err := es.acquireStreamSlot("subscribe")
+	if err != nil {
+		return nil, err
+	}
+
+	eventChan := make(chan Event, es.config.StreamBuffer)
+	go func() {
+		defer release()
+		defer close(eventChan)
+
+		cursor := after
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+		for {
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				return
+			}
+
+			events, err := es.readCommittedPage(ctx, "subscribe", query, cursor, subscribePageSize)
+			if err != nil {
+				return
+			}
+			for _, event := range events {
+				select {
+				case eventChan <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if len(events) > 0 {
+				last := events[len(events)-1]
+				cursor = &Cursor{TransactionID: last.TransactionID, Position: last.Position}
+			}
+
+			if len(events) == subscribePageSize {
+				timer.Reset(0)
+			} else {
+				timer.Reset(pollInterval)
+			}
+		}
+	}()
+
+	return eventChan, nil
+}
+
+// readCommittedPage reads up to limit events matching query after cursor, limited to transactions
+// older than every running one so no event can later commit before the page's end
+func (es *eventStore) readCommittedPage(ctx context.Context, op string, query Query, after *Cursor, limit int) ([]Event, error) {
+	innerSQL, args, err := es.buildReadQuerySQL(query, after, nil)
+	if err != nil {
+		return nil, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  op,
+				Err: fmt.Errorf("failed to build SQL query: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	innerSQL = strings.TrimSuffix(innerSQL, readOrderBy)
+	sqlQuery := "SELECT " + eventColumns + " FROM (" + innerSQL + ") AS e" +
+		" WHERE e.transaction_id < pg_snapshot_xmin(pg_current_snapshot())" +
+		readOrderBy + fmt.Sprintf(" LIMIT %d", limit)
+
+	rows, err := es.queryWithRetry(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  op,
+				Err: fmt.Errorf("failed to read events: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	rowEvents, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (rowEvent, error) {
+		var r rowEvent
+		err := row.Scan(r.scanTargets()...)
+		return r, err
+	})
+	if err != nil {
+		return nil, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  op,
+				Err: fmt.Errorf("failed to scan events: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+
+	events := make([]Event, len(rowEvents))
+	for i, row := range rowEvents {
+		events[i] = convertRowToEvent(row)
+	}
+	return events, nil
+}
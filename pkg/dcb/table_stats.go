@@ -0,0 +1,81 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Approximate Table Statistics
+// =============================================================================
+
+// TableSize is the estimated size of one table
+type TableSize struct {
+	// Table is the table's name
+	Table string `json:"table"`
+	// Rows is the planner's row estimate (pg_class.reltuples), or -1 when the table has not been
+	// vacuumed or analyzed yet
+	Rows int64 `json:"rows"`
+	// Bytes is the table's size on disk, indexes and TOAST included (pg_total_relation_size)
+	Bytes int64 `json:"bytes"`
+}
+
+// TableStats holds the estimated sizes of the store's tables (ApproxStats)
+type TableStats struct {
+	Events   TableSize `json:"events"`
+	Commands TableSize `json:"commands"`
+	// Archive is set when EventStoreConfig.ArchiveTable is; a table not created yet has no rows
+	Archive *TableSize `json:"archive,omitempty"`
+}
+
+// ApproxStats returns the approximate row counts and on-disk sizes of the events and commands
+// tables (and of the archive table when configured) from the catalog, in one query that doesn't
+// scan them, for dashboards and capacity monitoring. The row counts are the planner's estimates,
+// refreshed by autovacuum and ANALYZE, so they lag recent appends and can be off by a few
+// percent; use a COUNT(*) when an exact number matters
+func (es *eventStore) ApproxStats(ctx context.Context) (TableStats, error) {
+	tables := []string{"events", "commands"}
+	if es.config.ArchiveTable != "" {
+		tables = append(tables, es.config.ArchiveTable)
+	}
+
+	sizes := make([]TableSize, 0, len(tables))
+	err := es.executeReadInTx(ctx, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+			SELECT t.name,
+				CASE WHEN c.oid IS NULL THEN 0 WHEN c.reltuples < 0 THEN -1 ELSE c.reltuples::bigint END,
+				COALESCE(pg_total_relation_size(c.oid), 0)
+			FROM unnest($1::text[]) WITH ORDINALITY AS t(name, ord)
+			LEFT JOIN pg_class c ON c.oid = to_regclass(quote_ident(t.name))
+			ORDER BY t.ord
+		`, tables)
+		if err == nil {
+			sizes, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (TableSize, error) {
+				var size TableSize
+				err := row.Scan(&size.Table, &size.Rows, &size.Bytes)
+				return size, err
+			})
+		}
+		if err != nil {
+			return &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "approx_stats",
+					Err: fmt.Errorf("failed to read table statistics: %w", err),
+				},
+				Resource: "database",
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return TableStats{}, err
+	}
+
+	stats := TableStats{Events: sizes[0], Commands: sizes[1]}
+	if len(sizes) > 2 {
+		stats.Archive = &sizes[2]
+	}
+	return stats, nil
+}
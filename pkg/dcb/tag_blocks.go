@@ -0,0 +1,208 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Blocked Tags
+// =============================================================================
+
+// TagBlockRefreshInterval is how often a store with EventStoreConfig.SharedTagBlocks reloads the
+// blocked tags from the dcb_blocked_tags table
+const TagBlockRefreshInterval = time.Second
+
+// tagBlocks is a store's list of blocked tags, shared with its transaction-scoped stores
+type tagBlocks struct {
+	mu         sync.RWMutex
+	blocked    map[string]struct{} // encoded "key:value" tags
+	generation uint64              // bumped by every BlockTag and UnblockTag
+	loadedAt   time.Time           // last reload from dcb_blocked_tags (SharedTagBlocks)
+	reloading  bool
+}
+
+func newTagBlocks() *tagBlocks {
+	return &tagBlocks{blocked: make(map[string]struct{})}
+}
+
+// BlockTag makes every append of an event carrying the tag key:value fail with a ResourceError
+// (Resource "blocked_tag") until UnblockTag, e.g. to stop a buggy producer flooding one tenant
+// without taking the store down. It is an operational safety valve, not an access control:
+// appends already past validation complete, and the block only applies to the tags callers give,
+// not to derived or producer tags. Without EventStoreConfig.SharedTagBlocks the block only
+// applies to this store (and its transaction-scoped stores); with it, the block is also stored
+// in dcb_blocked_tags and reaches every store with the setting within TagBlockRefreshInterval
+func (es *eventStore) BlockTag(ctx context.Context, key, value string) error {
+	tag, err := blockedTag("block_tag", key, value)
+	if err != nil {
+		return err
+	}
+	if es.config.SharedTagBlocks {
+		_, err := es.poolDB().Exec(ctx, `INSERT INTO dcb_blocked_tags (tag) VALUES ($1) ON CONFLICT (tag) DO NOTHING`, tag)
+		if err != nil {
+			return wrapDatabaseError("block_tag", "failed to store blocked tag", err)
+		}
+	}
+	es.tagBlocks.mu.Lock()
+	es.tagBlocks.blocked[tag] = struct{}{}
+	es.tagBlocks.generation++
+	es.tagBlocks.mu.Unlock()
+	return nil
+}
+
+// UnblockTag lifts a BlockTag of key:value; unblocking a tag that isn't blocked does nothing
+func (es *eventStore) UnblockTag(ctx context.Context, key, value string) error {
+	tag, err := blockedTag("unblock_tag", key, value)
+	if err != nil {
+		return err
+	}
+	if es.config.SharedTagBlocks {
+		if _, err := es.poolDB().Exec(ctx, `DELETE FROM dcb_blocked_tags WHERE tag = $1`, tag); err != nil {
+			return wrapDatabaseError("unblock_tag", "failed to remove blocked tag", err)
+		}
+	}
+	es.tagBlocks.mu.Lock()
+	delete(es.tagBlocks.blocked, tag)
+	es.tagBlocks.generation++
+	es.tagBlocks.mu.Unlock()
+	return nil
+}
+
+// blockedTag validates a BlockTag or UnblockTag argument and returns the encoded tag
+func blockedTag(op, key, value string) (string, error) {
+	if key == "" || value == "" {
+		return "", &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  op,
+				Err: fmt.Errorf("tag key and value cannot be empty"),
+			},
+			Field: "tag",
+			Value: key + ":" + value,
+		}
+	}
+	return key + ":" + value, nil
+}
+
+// validateBlockedTags rejects events carrying a blocked tag. With SharedTagBlocks a stale list
+// is reloaded in the background, so appends never wait for it
+func (es *eventStore) validateBlockedTags(events []InputEvent, op string) error {
+	if es.tagBlocks == nil {
+		return nil
+	}
+	if es.config.SharedTagBlocks {
+		es.reloadStaleTagBlocks()
+	}
+
+	es.tagBlocks.mu.RLock()
+	defer es.tagBlocks.mu.RUnlock()
+	if len(es.tagBlocks.blocked) == 0 {
+		return nil
+	}
+	for i, event := range events {
+		for _, tag := range event.GetTags() {
+			encoded := tag.GetKey() + ":" + tag.GetValue()
+			if _, ok := es.tagBlocks.blocked[encoded]; ok {
+				return blockedTagError(op, i, encoded)
+			}
+		}
+	}
+	return nil
+}
+
+// validateStoredTagBlocks rejects event index when its encoded tags as stored, including the
+// derived and producer tags the store attached after validateBlockedTags, carry a blocked tag
+func (es *eventStore) validateStoredTagBlocks(tagStrings []string, index int, op string) error {
+	if es.tagBlocks == nil {
+		return nil
+	}
+	es.tagBlocks.mu.RLock()
+	defer es.tagBlocks.mu.RUnlock()
+	for _, encoded := range tagStrings {
+		if _, ok := es.tagBlocks.blocked[encoded]; ok {
+			return blockedTagError(op, index, encoded)
+		}
+	}
+	return nil
+}
+
+// blockedTagError reports that event index carries the blocked tag encoded
+func blockedTagError(op string, index int, encoded string) error {
+	return &ResourceError{
+		EventStoreError: EventStoreError{
+			Op:  op,
+			Err: fmt.Errorf("event %d carries blocked tag %s", index, encoded),
+		},
+		Resource: "blocked_tag",
+	}
+}
+
+// reloadStaleTagBlocks starts a reload of the blocked tags when the last one is older than
+// TagBlockRefreshInterval and none is running. A failed reload is logged and the list kept until
+// the next interval
+func (es *eventStore) reloadStaleTagBlocks() {
+	blocks := es.tagBlocks
+	now := es.clock().Now()
+	blocks.mu.Lock()
+	if blocks.reloading || now.Sub(blocks.loadedAt) < TagBlockRefreshInterval {
+		blocks.mu.Unlock()
+		return
+	}
+	blocks.reloading = true
+	blocks.mu.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := es.loadTagBlocks(ctx); err != nil {
+			log.Printf("BlockTag: keeping the previous blocked tags: %v", err)
+			blocks.mu.Lock()
+			blocks.loadedAt = now
+			blocks.mu.Unlock()
+		}
+		blocks.mu.Lock()
+		blocks.reloading = false
+		blocks.mu.Unlock()
+	}()
+}
+
+// loadTagBlocks replaces the blocked tags with the contents of dcb_blocked_tags
+func (es *eventStore) loadTagBlocks(ctx context.Context) error {
+	es.tagBlocks.mu.RLock()
+	generation := es.tagBlocks.generation
+	es.tagBlocks.mu.RUnlock()
+
+	rows, err := es.poolDB().Query(ctx, `SELECT tag FROM dcb_blocked_tags`)
+	var tags []string
+	if err == nil {
+		tags, err = pgx.CollectRows(rows, pgx.RowTo[string])
+	}
+	if err != nil {
+		return wrapDatabaseError("load_blocked_tags", "failed to load blocked tags", err)
+	}
+	es.tagBlocks.replace(tags, generation, es.clock().Now())
+	return nil
+}
+
+// replace swaps in tags read from dcb_blocked_tags when no BlockTag or UnblockTag ran since
+// generation; otherwise the read may predate that change and is dropped, leaving loadedAt as is
+// so the next append reloads again
+func (b *tagBlocks) replace(tags []string, generation uint64, now time.Time) bool {
+	blocked := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		blocked[tag] = struct{}{}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.generation != generation {
+		return false
+	}
+	b.blocked = blocked
+	b.loadedAt = now
+	return true
+}
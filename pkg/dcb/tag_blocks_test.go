@@ -0,0 +1,107 @@
+package dcb
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBlockTagCoversDerivedTags(t *testing.T) {
+	ctx := context.Background()
+	tenant := func(event InputEvent, at time.Time) []Tag { return []Tag{NewTag("tenant_id", "t42")} }
+	es := newEventStore(nil, EventStoreConfig{TagDerivers: []TagDeriver{tenant}})
+	events := []InputEvent{NewInputEvent("OrderPlaced", NewTags("order_id", "o1"), []byte(`{}`))}
+
+	if err := es.BlockTag(ctx, "tenant_id", "t42"); err != nil {
+		t.Fatalf("BlockTag: %v", err)
+	}
+	if err := es.validateAppendEvents(events, "append"); err != nil {
+		t.Fatalf("expected the caller's tags to pass, got %v", err)
+	}
+	_, _, _, _, err := es.appendColumns(ctx, events)
+	if resourceErr, ok := GetResourceError(err); !ok || resourceErr.Resource != "blocked_tag" {
+		t.Errorf("expected the derived blocked tag to be rejected, got %v", err)
+	}
+}
+
+func TestBlockTag(t *testing.T) {
+	ctx := context.Background()
+	es := newEventStore(nil, EventStoreConfig{})
+	events := []InputEvent{
+		NewInputEvent("OrderPlaced", NewTags("tenant_id", "t1"), []byte(`{}`)),
+		NewInputEvent("OrderPlaced", NewTags("tenant_id", "t42", "order_id", "o1"), []byte(`{}`)),
+	}
+
+	if err := es.validateAppendEvents(events, "append"); err != nil {
+		t.Fatalf("expected no blocked tags by default, got %v", err)
+	}
+
+	if err := es.BlockTag(ctx, "tenant_id", "t42"); err != nil {
+		t.Fatalf("BlockTag: %v", err)
+	}
+	err := es.validateAppendEvents(events, "append")
+	resourceErr, ok := GetResourceError(err)
+	if !ok || resourceErr.Resource != "blocked_tag" {
+		t.Fatalf("expected blocked_tag resource error, got %v", err)
+	}
+	if err := es.validateAppendEvents(events[:1], "append"); err != nil {
+		t.Errorf("expected events without the blocked tag to pass, got %v", err)
+	}
+
+	// Transaction-scoped copies share the block list
+	scoped := *es
+	if err := scoped.validateAppendEvents(events, "append"); !IsResourceError(err) {
+		t.Errorf("expected the scoped store to reject the blocked tag, got %v", err)
+	}
+
+	if err := es.UnblockTag(ctx, "tenant_id", "t42"); err != nil {
+		t.Fatalf("UnblockTag: %v", err)
+	}
+	if err := es.validateAppendEvents(events, "append"); err != nil {
+		t.Errorf("expected unblocked tag to pass, got %v", err)
+	}
+	if err := es.UnblockTag(ctx, "tenant_id", "t42"); err != nil {
+		t.Errorf("expected unblocking twice to succeed, got %v", err)
+	}
+
+	if err := es.BlockTag(ctx, "tenant_id", ""); !IsValidationError(err) {
+		t.Errorf("expected validation error for an empty value, got %v", err)
+	}
+}
+
+func TestTagBlocksReplaceKeepsConcurrentChanges(t *testing.T) {
+	ctx := context.Background()
+	es := newEventStore(nil, EventStoreConfig{})
+	now := es.clock().Now()
+
+	// A reload that read dcb_blocked_tags before a BlockTag must not drop it
+	generation := es.tagBlocks.generation
+	if err := es.BlockTag(ctx, "tenant_id", "t42"); err != nil {
+		t.Fatalf("BlockTag: %v", err)
+	}
+	if es.tagBlocks.replace(nil, generation, now) {
+		t.Error("expected a reload started before BlockTag to be dropped")
+	}
+	if _, ok := es.tagBlocks.blocked["tenant_id:t42"]; !ok {
+		t.Error("expected the block to survive the stale reload")
+	}
+
+	// Nor one that read it before an UnblockTag bring the tag back
+	generation = es.tagBlocks.generation
+	if err := es.UnblockTag(ctx, "tenant_id", "t42"); err != nil {
+		t.Fatalf("UnblockTag: %v", err)
+	}
+	if es.tagBlocks.replace([]string{"tenant_id:t42"}, generation, now) {
+		t.Error("expected a reload started before UnblockTag to be dropped")
+	}
+	if _, ok := es.tagBlocks.blocked["tenant_id:t42"]; ok {
+		t.Error("expected the unblock to survive the stale reload")
+	}
+
+	if !es.tagBlocks.replace([]string{"tenant_id:t7"}, es.tagBlocks.generation, now) {
+		t.Fatal("expected a current reload to replace the blocked tags")
+	}
+	if _, ok := es.tagBlocks.blocked["tenant_id:t7"]; !ok || !es.tagBlocks.loadedAt.Equal(now) {
+		t.Errorf("expected the reloaded tags and time, got %v at %v", es.tagBlocks.blocked, es.tagBlocks.loadedAt)
+	}
+}
@@ -0,0 +1,77 @@
+package dcb
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// Derived Tags
+// =============================================================================
+
+// TagDeriver computes tags derived from an event being appended, e.g. a "year" or "month" time
+// bucket from at, or a partition key from another tag (see EventStoreConfig.TagDerivers).
+// at is the append time from the store's Clock, the same for every event of a batch; with
+// EventStoreConfig.Clock set it is also their occurred_at, otherwise occurred_at is the database's
+// transaction timestamp, which can differ from it by clock skew. It runs inside the append
+// transaction, so it must not block. Return nil to derive nothing for an event
+type TagDeriver func(event InputEvent, at time.Time) []Tag
+
+// deriveTags adds the tags of the configured TagDerivers to the encoded tags of event index.
+// A derived tag whose key the event already has is dropped, unless EventStoreConfig.
+// DerivedTagsOverride is set, in which case it replaces the event's tags with that key.
+// A derived tag key with a reserved prefix (ReservedTagKeyPrefixes) fails the append
+func (es *eventStore) deriveTags(event InputEvent, index int, at time.Time, tagStrings []string) ([]string, error) {
+	userKeys := make([]string, 0, len(tagStrings))
+	for _, tag := range tagStrings {
+		key, _, _ := strings.Cut(tag, ":")
+		userKeys = append(userKeys, key)
+	}
+
+	var derived []string
+	for _, deriver := range es.config.TagDerivers {
+		for _, tag := range deriver(event, at) {
+			if tag == nil || tag.GetKey() == "" || tag.GetValue() == "" {
+				return nil, &ValidationError{
+					EventStoreError: EventStoreError{
+						Op:  "appendInTx",
+						Err: fmt.Errorf("tag deriver returned an empty tag key or value for event %d", index),
+					},
+					Field: fmt.Sprintf("event[%d].derivedTags", index),
+					Value: event.GetType(),
+				}
+			}
+			if prefix, reserved := es.reservedTagKeyPrefix(tag.GetKey()); reserved {
+				return nil, &ValidationError{
+					EventStoreError: EventStoreError{
+						Op:  "appendInTx",
+						Err: fmt.Errorf("derived tag key %q for event %d uses the reserved prefix %q (ReservedTagKeyPrefixes)", tag.GetKey(), index, prefix),
+					},
+					Field: fmt.Sprintf("event[%d].derivedTags", index),
+					Value: tag.GetKey(),
+				}
+			}
+			encoded := tag.GetKey() + ":" + tag.GetValue()
+			if slices.Contains(derived, encoded) {
+				continue
+			}
+			if slices.Contains(userKeys, tag.GetKey()) && !es.config.DerivedTagsOverride {
+				continue
+			}
+			derived = append(derived, encoded)
+		}
+	}
+	if len(derived) == 0 {
+		return tagStrings, nil
+	}
+
+	if es.config.DerivedTagsOverride {
+		tagStrings = slices.DeleteFunc(slices.Clone(tagStrings), func(tag string) bool {
+			key, _, _ := strings.Cut(tag, ":")
+			return slices.ContainsFunc(derived, func(d string) bool { return strings.HasPrefix(d, key+":") })
+		})
+	}
+	return append(tagStrings, derived...), nil
+}
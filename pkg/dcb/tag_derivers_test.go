@@ -0,0 +1,53 @@
+package dcb
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDeriveTags(t *testing.T) {
+	at := time.Date(2024, time.March, 5, 10, 0, 0, 0, time.UTC)
+	year := func(event InputEvent, at time.Time) []Tag {
+		return []Tag{NewTag("year", at.Format("2006"))}
+	}
+	region := func(event InputEvent, at time.Time) []Tag {
+		if event.GetType() != "OrderPlaced" {
+			return nil
+		}
+		return []Tag{NewTag("region", "eu"), NewTag("year", at.Format("2006"))}
+	}
+	es := &eventStore{config: EventStoreConfig{TagDerivers: []TagDeriver{year, region}}}
+	order := NewInputEvent("OrderPlaced", NewTags("order_id", "o1"), []byte(`{}`))
+
+	tags, err := es.deriveTags(order, 0, at, []string{"order_id:o1"})
+	if err != nil {
+		t.Fatalf("deriveTags: %v", err)
+	}
+	if !slices.Equal(tags, []string{"order_id:o1", "year:2024", "region:eu"}) {
+		t.Errorf("expected the derived tags once each after the event's own, got %v", tags)
+	}
+
+	// The event's own tag wins by default, and is replaced with DerivedTagsOverride
+	tags, _ = es.deriveTags(order, 0, at, []string{"order_id:o1", "year:1999"})
+	if !slices.Equal(tags, []string{"order_id:o1", "year:1999", "region:eu"}) {
+		t.Errorf("expected the event's year to be kept, got %v", tags)
+	}
+	es.config.DerivedTagsOverride = true
+	tags, _ = es.deriveTags(order, 0, at, []string{"order_id:o1", "year:1999"})
+	if !slices.Equal(tags, []string{"order_id:o1", "year:2024", "region:eu"}) {
+		t.Errorf("expected the derived year to replace the event's, got %v", tags)
+	}
+
+	empty := func(event InputEvent, at time.Time) []Tag { return []Tag{NewTag("bucket", "")} }
+	es.config.TagDerivers = []TagDeriver{empty}
+	if _, err := es.deriveTags(order, 3, at, []string{"order_id:o1"}); !IsValidationError(err) {
+		t.Errorf("expected validation error for an empty derived value, got %v", err)
+	}
+
+	es.config.TagDerivers = []TagDeriver{year}
+	es.config.ReservedTagKeyPrefixes = []string{"ye"}
+	if _, err := es.deriveTags(order, 0, at, []string{"order_id:o1"}); !IsValidationError(err) {
+		t.Errorf("expected validation error for a derived tag with a reserved key, got %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// =============================================================================
+// Case-Insensitive Tag Matching
+// =============================================================================
+
+// caseInsensitiveTagIndex is the GIN expression index serving WithTagCI conditions
+const caseInsensitiveTagIndex = `CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_events_tags_lower ON events USING GIN (lower_tag_values(tags))`
+
+// WithTagCI adds a tag condition to the current QueryItem whose value matches case-insensitively (AND)
+// The key must match exactly. The default tags index can't serve it: without the expression index
+// from CreateCaseInsensitiveTagIndex every candidate row is checked, so combine it with an event type
+// or exact tag where possible. Meant for reads and projections; append conditions reject it
+func (qb *QueryBuilder) WithTagCI(key, value string) *QueryBuilder {
+	qb.currentItem.ciTags = append(qb.currentItem.ciTags, NewTag(key, value))
+	return qb
+}
+
+// CreateCaseInsensitiveTagIndex creates the GIN expression index idx_events_tags_lower over the
+// lower_tag_values SQL function (from docker-entrypoint-initdb.d/schema.sql) that WithTagCI
+// conditions use. It is safe to re-run, and the index is built CONCURRENTLY so appends are not
+// blocked (which means it can't run inside a transaction). A missing function is a
+// ConfigurationError. Stores detect the function when constructed: create the index before
+// constructing them, otherwise WithTagCI keeps using the unindexed form until the store is recreated.
+func CreateCaseInsensitiveTagIndex(ctx context.Context, pool *pgxpool.Pool) error {
+	if _, err := pool.Exec(ctx, caseInsensitiveTagIndex); err != nil {
+		if configErr := asMissingFunctionError("create_case_insensitive_tag_index", err); configErr != nil {
+			return configErr
+		}
+		return wrapDatabaseError("create_case_insensitive_tag_index", "failed to create case-insensitive tag index", err)
+	}
+	return nil
+}
+
+// caseInsensitiveTagCondition returns the SQL condition for a WithTagCI tag with its arguments
+// starting at $argIndex. With lower_tag_values installed the condition matches the expression index
+func (es *eventStore) caseInsensitiveTagCondition(tag Tag, argIndex int) (string, []any) {
+	if es.lowerTagValues {
+		return fmt.Sprintf("lower_tag_values(tags) @> ARRAY[$%d::text || ':' || lower($%d::text)]", argIndex, argIndex+1),
+			[]any{tag.GetKey(), tag.GetValue()}
+	}
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM unnest(tags) AS t WHERE split_part(t, ':', 1) = $%d::text AND lower(substr(t, strpos(t, ':') + 1)) = lower($%d::text))", argIndex, argIndex+1),
+		[]any{tag.GetKey(), tag.GetValue()}
+}
+
+// detectLowerTagValues reports whether lower_tag_values is installed
+func detectLowerTagValues(ctx context.Context, pool dbQuerier) (bool, error) {
+	var exists bool
+	err := pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM pg_proc WHERE proname = 'lower_tag_values' AND pronargs = 1 AND pg_function_is_visible(oid))`).Scan(&exists)
+	if err != nil {
+		return false, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "detect_lower_tag_values",
+				Err: fmt.Errorf("failed to check function lower_tag_values: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	return exists, nil
+}
+
+// matchesTagCI reports whether the event carries key with value, ignoring the value's case
+func matchesTagCI(event Event, tag Tag) bool {
+	for _, eventTag := range event.Tags {
+		if eventTag.GetKey() == tag.GetKey() && strings.ToLower(eventTag.GetValue()) == strings.ToLower(tag.GetValue()) {
+			return true
+		}
+	}
+	return false
+}
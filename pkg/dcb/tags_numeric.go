@@ -0,0 +1,187 @@
+package dcb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// =============================================================================
+// Numeric Tag Comparison
+// =============================================================================
+
+// Op is a comparison operator for WithTagNumeric
+type Op string
+
+const (
+	OpEq  Op = "="
+	OpNe  Op = "<>"
+	OpLt  Op = "<"
+	OpLte Op = "<="
+	OpGt  Op = ">"
+	OpGte Op = ">="
+)
+
+// valid reports whether op is one of the Op constants
+func (op Op) valid() bool {
+	switch op {
+	case OpEq, OpNe, OpLt, OpLte, OpGt, OpGte:
+		return true
+	}
+	return false
+}
+
+// NumericTagCondition compares the numeric value of an event's tag with Value (WithTagNumeric)
+type NumericTagCondition struct {
+	Key   string  `json:"key"`
+	Op    Op      `json:"op"`
+	Value float64 `json:"value"`
+}
+
+// numericTagPattern is the form of a numeric tag value: a decimal number, optionally signed and
+// with an exponent. Other values, such as "high" or "NaN", never compare
+const numericTagPattern = `^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+)?$`
+
+var numericTagRegexp = regexp.MustCompile(numericTagPattern)
+
+// WithTagNumeric adds a condition to the current QueryItem comparing the numeric value of the
+// event's tag key with value (AND), e.g. WithTagNumeric("priority", OpGte, 3) matches priority:5.
+// The first tag with key whose value is a decimal number is compared; events without one don't
+// match. The tags index can't serve it, so every candidate row is checked: combine it with an
+// event type or exact tag, and for frequent filters on a key create its expression index with
+// CreateNumericTagIndex. Meant for reads and projections; append conditions reject it
+func (qb *QueryBuilder) WithTagNumeric(key string, op Op, value float64) *QueryBuilder {
+	qb.currentItem.numericTags = append(qb.currentItem.numericTags, NumericTagCondition{Key: key, Op: op, Value: value})
+	return qb
+}
+
+// CreateNumericTagIndex creates the B-tree expression index idx_events_numeric_<key> over the
+// numeric_tag_value SQL function (from docker-entrypoint-initdb.d/schema.sql) that WithTagNumeric
+// conditions on key use. It is safe to re-run, and the index is built CONCURRENTLY so appends are
+// not blocked (which means it can't run inside a transaction). A missing function is a
+// ConfigurationError. Stores detect the function when constructed: create the index before constructing
+// them, otherwise WithTagNumeric keeps using the unindexed form until the store is recreated.
+func CreateNumericTagIndex(ctx context.Context, pool *pgxpool.Pool, key string) error {
+	if err := validateNumericTagKey("create_numeric_tag_index", key, "key"); err != nil {
+		return err
+	}
+	index := fmt.Sprintf("CREATE INDEX CONCURRENTLY IF NOT EXISTS %s ON events (numeric_tag_value(tags, %s))",
+		pgx.Identifier{"idx_events_numeric_" + key}.Sanitize(), quoteLiteral(key))
+	if _, err := pool.Exec(ctx, index); err != nil {
+		if configErr := asMissingFunctionError("create_numeric_tag_index", err); configErr != nil {
+			return configErr
+		}
+		return wrapDatabaseError("create_numeric_tag_index", "failed to create numeric tag index", err)
+	}
+	return nil
+}
+
+// validateNumericTagKey checks a WithTagNumeric key: non-empty and without ':'
+func validateNumericTagKey(op, key, field string) error {
+	if key == "" || strings.Contains(key, ":") {
+		return &ValidationError{
+			EventStoreError: EventStoreError{Op: op, Err: fmt.Errorf("invalid numeric tag key %q", key)},
+			Field:           field,
+			Value:           key,
+		}
+	}
+	return nil
+}
+
+// validateNumericTag checks a WithTagNumeric condition of item itemIndex
+func validateNumericTag(c NumericTagCondition, itemIndex, i int) error {
+	field := fmt.Sprintf("item[%d].numericTags[%d]", itemIndex, i)
+	if err := validateNumericTagKey("validate_query", c.Key, field); err != nil {
+		return err
+	}
+	if !c.Op.valid() {
+		return &ValidationError{
+			EventStoreError: EventStoreError{Op: "validate_query", Err: fmt.Errorf("invalid comparison operator %q in numeric tag condition %d of item %d", c.Op, i, itemIndex)},
+			Field:           field,
+			Value:           string(c.Op),
+		}
+	}
+	if math.IsNaN(c.Value) || math.IsInf(c.Value, 0) {
+		return &ValidationError{
+			EventStoreError: EventStoreError{Op: "validate_query", Err: fmt.Errorf("numeric tag condition %d of item %d compares with %v", i, itemIndex, c.Value)},
+			Field:           field,
+			Value:           fmt.Sprint(c.Value),
+		}
+	}
+	return nil
+}
+
+// numericTagSubquery is the body of numeric_tag_value with the key at $%[1]d, compared by %[3]s
+// with the value at $%[4]d
+const numericTagSubquery = `(SELECT substr(t, length($%[1]d::text) + 2)::numeric FROM unnest(tags) WITH ORDINALITY AS u(t, ord) ` +
+	`WHERE starts_with(t, $%[1]d::text || ':') AND substr(t, length($%[1]d::text) + 2) ~ '%[2]s' ` +
+	`ORDER BY ord LIMIT 1) %[3]s $%[4]d::numeric`
+
+// numericTagCondition returns the SQL condition for a WithTagNumeric condition with its arguments
+// starting at $argIndex. With numeric_tag_value installed the key is inlined, so the condition
+// matches the expression index of CreateNumericTagIndex; otherwise the function's body is inlined.
+// The operator is inlined too, so it is checked here even though Validate has checked it already
+func (es *eventStore) numericTagCondition(c NumericTagCondition, argIndex int) (string, []any, error) {
+	if !c.Op.valid() {
+		return "", nil, fmt.Errorf("invalid comparison operator %q", c.Op)
+	}
+	if es.numericTagValue {
+		return fmt.Sprintf("numeric_tag_value(tags, %s) %s $%d::numeric", quoteLiteral(c.Key), c.Op, argIndex),
+			[]any{c.Value}, nil
+	}
+	return fmt.Sprintf(numericTagSubquery, argIndex, numericTagPattern, c.Op, argIndex+1),
+		[]any{c.Key, c.Value}, nil
+}
+
+// detectNumericTagValue reports whether numeric_tag_value is installed
+func detectNumericTagValue(ctx context.Context, pool dbQuerier) (bool, error) {
+	var exists bool
+	err := pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM pg_proc WHERE proname = 'numeric_tag_value' AND pronargs = 2 AND pg_function_is_visible(oid))`).Scan(&exists)
+	if err != nil {
+		return false, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "detect_numeric_tag_value",
+				Err: fmt.Errorf("failed to check function numeric_tag_value: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	return exists, nil
+}
+
+// matchesNumericTag reports whether the event's first numeric tag with c.Key compares with c.Value
+func matchesNumericTag(event Event, c NumericTagCondition) bool {
+	for _, tag := range event.Tags {
+		if tag.GetKey() != c.Key || !numericTagRegexp.MatchString(tag.GetValue()) {
+			continue
+		}
+		// Out of float64 range parses as ±Inf, which still compares like the NUMERIC in SQL
+		value, err := strconv.ParseFloat(tag.GetValue(), 64)
+		if err != nil && !errors.Is(err, strconv.ErrRange) {
+			return false
+		}
+		switch c.Op {
+		case OpEq:
+			return value == c.Value
+		case OpNe:
+			return value != c.Value
+		case OpLt:
+			return value < c.Value
+		case OpLte:
+			return value <= c.Value
+		case OpGt:
+			return value > c.Value
+		case OpGte:
+			return value >= c.Value
+		}
+		return false
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package dcb
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// Tags From Struct
+// =============================================================================
+
+// TagsFromStruct builds tags from the named fields of a struct (or pointer to struct),
+// so a value carried in both the event data and its tags has a single source of truth.
+// Fields are matched by their JSON name (the `json` struct tag, or the Go field name when
+// there is none), including fields promoted from embedded structs. Tags are returned in
+// the order of fields and use the JSON name as key.
+//
+// Supported field types: strings, integers, unsigned integers, floats, bools, pointers to
+// these and types implementing encoding.TextMarshaler or fmt.Stringer.
+// Like NewTags it doesn't fail: an unknown field, a nil pointer or an unsupported type yields
+// a tag with an empty value, which EventStore operations reject with a ValidationError
+// naming the key.
+func TagsFromStruct(v any, fields ...string) []Tag {
+	values := jsonFieldValues(v)
+
+	tags := make([]Tag, 0, len(fields))
+	for _, field := range fields {
+		value, ok := values[field]
+		if !ok {
+			tags = append(tags, NewTag(field, ""))
+			continue
+		}
+		tags = append(tags, NewTag(field, tagValueString(value)))
+	}
+	return tags
+}
+
+// WithTagsFromStruct adds tags for the named fields of v (see TagsFromStruct)
+// Example: NewEvent("AccountOpened").WithTagsFromStruct(opened, "account_id").WithData(opened)
+func (eb *EventBuilder) WithTagsFromStruct(v any, fields ...string) *EventBuilder {
+	for _, tag := range TagsFromStruct(v, fields...) {
+		eb.tags[tag.GetKey()] = tag.GetValue()
+	}
+	return eb
+}
+
+// jsonFieldValues maps the JSON names of a struct's exported fields to their values
+// Returns an empty map if v is not a struct or a non-nil pointer to one
+func jsonFieldValues(v any) map[string]reflect.Value {
+	values := make(map[string]reflect.Value)
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return values
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return values
+	}
+
+	for _, field := range reflect.VisibleFields(rv.Type()) {
+		name, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+		// Promoted fields through nil embedded pointers are not reachable
+		fieldValue, err := rv.FieldByIndexErr(field.Index)
+		if err != nil {
+			continue
+		}
+		// The shallowest field wins, as in encoding/json
+		if _, exists := values[name]; !exists {
+			values[name] = fieldValue
+		}
+	}
+	return values
+}
+
+// jsonFieldName returns the JSON name of an exported, non-embedded struct field
+// (the `json` struct tag, or the Go field name when there is none); ok is false for
+// fields encoding/json skips
+func jsonFieldName(field reflect.StructField) (name string, ok bool) {
+	if field.Anonymous || !field.IsExported() {
+		return "", false
+	}
+	name = field.Name
+	if jsonTag, ok := field.Tag.Lookup("json"); ok {
+		jsonName, _, _ := strings.Cut(jsonTag, ",")
+		if jsonName == "-" {
+			return "", false
+		}
+		if jsonName != "" {
+			name = jsonName
+		}
+	}
+	return name, true
+}
+
+// tagValueString formats a field value as a tag value, returning "" for unsupported values
+func tagValueString(value reflect.Value) string {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+
+	if value.CanInterface() {
+		switch v := value.Interface().(type) {
+		case encoding.TextMarshaler:
+			text, err := v.MarshalText()
+			if err != nil {
+				return ""
+			}
+			return string(text)
+		case fmt.Stringer:
+			return v.String()
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return value.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, value.Type().Bits())
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool())
+	default:
+		return ""
+	}
+}
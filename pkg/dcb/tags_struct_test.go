@@ -0,0 +1,73 @@
+package dcb
+
+import (
+	"testing"
+	"time"
+)
+
+type tagsFromStructBase struct {
+	TenantID string `json:"tenant_id"`
+}
+
+type tagsFromStructSample struct {
+	tagsFromStructBase
+	AccountID string     `json:"account_id"`
+	Amount    int        `json:"amount,omitempty"`
+	Active    bool       // no json tag: matched by field name
+	Ignored   string     `json:"-"`
+	Parent    *string    `json:"parent_id"`
+	OpenedAt  time.Time  `json:"opened_at"`
+	ClosedAt  *time.Time `json:"closed_at"`
+}
+
+func TestTagsFromStruct(t *testing.T) {
+	opened := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	sample := tagsFromStructSample{
+		tagsFromStructBase: tagsFromStructBase{TenantID: "t1"},
+		AccountID:          "acc1",
+		Amount:             42,
+		Active:             true,
+		Ignored:            "x",
+		OpenedAt:           opened,
+	}
+
+	tags := TagsFromStruct(&sample, "account_id", "amount", "Active", "tenant_id", "opened_at")
+	want := []string{"account_id:acc1", "amount:42", "Active:true", "tenant_id:t1", "opened_at:2024-01-02T03:04:05Z"}
+	if len(tags) != len(want) {
+		t.Fatalf("got %v, want %v", TagsToArray(tags), want)
+	}
+	for i := range want {
+		if got := tags[i].GetKey() + ":" + tags[i].GetValue(); got != want[i] {
+			t.Errorf("tag %d: got %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+func TestTagsFromStructUnresolvableFieldsHaveEmptyValues(t *testing.T) {
+	sample := tagsFromStructSample{Ignored: "x"}
+
+	for _, field := range []string{"missing", "-", "Ignored", "parent_id", "closed_at"} {
+		tags := TagsFromStruct(sample, field)
+		if len(tags) != 1 || tags[0].GetKey() != field || tags[0].GetValue() != "" {
+			t.Errorf("field %q: expected one tag with empty value, got %v", field, TagsToArray(tags))
+		}
+	}
+
+	if tags := TagsFromStruct("not a struct", "account_id"); len(tags) != 1 || tags[0].GetValue() != "" {
+		t.Errorf("non-struct: expected one tag with empty value, got %v", TagsToArray(tags))
+	}
+}
+
+func TestEventBuilderWithTagsFromStruct(t *testing.T) {
+	sample := tagsFromStructSample{AccountID: "acc1"}
+
+	event := NewEvent("AccountOpened").WithTagsFromStruct(sample, "account_id").WithData(sample).Build()
+
+	tags := event.GetTags()
+	if len(tags) != 1 || tags[0].GetKey() != "account_id" || tags[0].GetValue() != "acc1" {
+		t.Fatalf("unexpected tags %v", TagsToArray(tags))
+	}
+	if err := validateEvent(event, 0); err != nil {
+		t.Fatalf("event should be valid: %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AppendToAggregate", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	accountEvent := func(eventType string) dcb.InputEvent {
+		return dcb.NewInputEvent(eventType, dcb.NewTags("account_id", "acc1"), []byte(`{}`))
+	}
+
+	It("should append when the expected version matches", func() {
+		err := store.AppendToAggregate(ctx, "account_id", "acc1", 0, []dcb.InputEvent{accountEvent("AccountOpened")})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = store.AppendToAggregate(ctx, "account_id", "acc1", 1, []dcb.InputEvent{
+			accountEvent("MoneyDeposited"),
+			accountEvent("MoneyWithdrawn"),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		events, err := store.Query(ctx, dcb.NewQuery(dcb.NewTags("account_id", "acc1")), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(3))
+	})
+
+	It("should return ConcurrencyError with expected and actual versions on mismatch", func() {
+		err := store.AppendToAggregate(ctx, "account_id", "acc1", 0, []dcb.InputEvent{accountEvent("AccountOpened")})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = store.AppendToAggregate(ctx, "account_id", "acc1", 0, []dcb.InputEvent{accountEvent("AccountOpened")})
+		Expect(err).To(HaveOccurred())
+
+		concurrencyErr, ok := dcb.AsConcurrencyError(err)
+		Expect(ok).To(BeTrue())
+		Expect(concurrencyErr.ExpectedVersion).To(Equal(0))
+		Expect(concurrencyErr.ActualVersion).To(Equal(1))
+	})
+
+	It("should not count events of other aggregates", func() {
+		err := store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("AccountOpened", dcb.NewTags("account_id", "acc2"), []byte(`{}`)),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = store.AppendToAggregate(ctx, "account_id", "acc1", 0, []dcb.InputEvent{accountEvent("AccountOpened")})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject events without the aggregate tag", func() {
+		err := store.AppendToAggregate(ctx, "account_id", "acc1", 0, []dcb.InputEvent{
+			dcb.NewInputEvent("AccountOpened", dcb.NewTags("account_id", "other"), []byte(`{}`)),
+		})
+		Expect(dcb.IsValidationError(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,78 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Per-aggregate versions", func() {
+	var versioned dcb.EventStore
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+
+		config := store.GetConfig()
+		config.AggregateVersionTagKeys = []string{"account_id"}
+		versioned, err = dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = pool.Exec(ctx, "TRUNCATE TABLE dcb_aggregate_versions")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	deposited := func(accountID string) dcb.InputEvent {
+		return dcb.NewInputEvent("Deposited", dcb.NewTags("account_id", accountID), []byte(`{}`))
+	}
+
+	versionsOf := func(accountID string) []int64 {
+		events, err := versioned.Query(ctx, dcb.NewQuery(dcb.NewTags("account_id", accountID)), nil)
+		Expect(err).NotTo(HaveOccurred())
+		versions := make([]int64, len(events))
+		for i, event := range events {
+			versions[i], _ = event.AggregateVersion("account_id", accountID)
+		}
+		return versions
+	}
+
+	It("should number each aggregate's events from 1", func() {
+		Expect(versioned.Append(ctx, []dcb.InputEvent{deposited("a1"), deposited("a2"), deposited("a1")})).To(Succeed())
+		Expect(versioned.AppendIf(ctx, []dcb.InputEvent{deposited("a1")}, dcb.FailIfExists("account_id", "a3"))).To(Succeed())
+
+		Expect(versionsOf("a1")).To(Equal([]int64{1, 2, 3}))
+		Expect(versionsOf("a2")).To(Equal([]int64{1}))
+	})
+
+	It("should continue the count of events appended before versioning was enabled", func() {
+		Expect(store.Append(ctx, []dcb.InputEvent{deposited("a1"), deposited("a1")})).To(Succeed())
+		Expect(versioned.Append(ctx, []dcb.InputEvent{deposited("a1")})).To(Succeed())
+
+		Expect(versionsOf("a1")).To(Equal([]int64{0, 0, 3}))
+	})
+
+	It("should not bump versions of a failed conditional append", func() {
+		Expect(versioned.Append(ctx, []dcb.InputEvent{deposited("a1")})).To(Succeed())
+		err := versioned.AppendIf(ctx, []dcb.InputEvent{deposited("a1")}, dcb.FailIfExists("account_id", "a1"))
+		Expect(dcb.IsConcurrencyError(err)).To(BeTrue())
+
+		Expect(versioned.Append(ctx, []dcb.InputEvent{deposited("a1")})).To(Succeed())
+		Expect(versionsOf("a1")).To(Equal([]int64{1, 2}))
+	})
+
+	It("should use the stored version as AppendToAggregate's expected version", func() {
+		Expect(versioned.AppendToAggregate(ctx, "account_id", "a1", 0, []dcb.InputEvent{deposited("a1"), deposited("a1")})).To(Succeed())
+		Expect(versioned.AppendToAggregate(ctx, "account_id", "a1", 2, []dcb.InputEvent{deposited("a1")})).To(Succeed())
+
+		err := versioned.AppendToAggregate(ctx, "account_id", "a1", 2, []dcb.InputEvent{deposited("a1")})
+		Expect(dcb.IsConcurrencyError(err)).To(BeTrue())
+		Expect(versionsOf("a1")).To(Equal([]int64{1, 2, 3}))
+	})
+
+	It("should reject invalid tag keys", func() {
+		config := store.GetConfig()
+		config.AggregateVersionTagKeys = []string{"account:id"}
+		_, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(dcb.IsValidationError(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,65 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Aggregate", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("MoneyDeposited", dcb.NewTags("account_id", "a1"), []byte(`{"amount": 100.25}`)),
+			dcb.NewInputEvent("MoneyDeposited", dcb.NewTags("account_id", "a1"), []byte(`{"amount": "50"}`)),
+			dcb.NewInputEvent("MoneyDeposited", dcb.NewTags("account_id", "a1"), []byte(`{"amount": null}`)),
+			dcb.NewInputEvent("MoneyDeposited", dcb.NewTags("account_id", "a1"), []byte(`{"note": "no amount"}`)),
+			dcb.NewInputEvent("MoneyDeposited", dcb.NewTags("account_id", "a2"), []byte(`{"amount": 1000}`)),
+			dcb.NewInputEvent("FeeCharged", dcb.NewTags("account_id", "a1"), []byte(`{"fee": {"amount": 2.5}}`)),
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	deposits := dcb.NewQuery(dcb.NewTags("account_id", "a1"), "MoneyDeposited")
+
+	It("should compute sum, min, max, avg and count in SQL, ignoring missing and null values", func() {
+		expected := map[dcb.AggregationFunc]float64{
+			dcb.AggregateSum:   150.25,
+			dcb.AggregateMin:   50,
+			dcb.AggregateMax:   100.25,
+			dcb.AggregateAvg:   75.125,
+			dcb.AggregateCount: 2,
+		}
+		for fn, want := range expected {
+			got, err := store.Aggregate(ctx, deposits, dcb.Aggregation{Func: fn, DataPath: "amount"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got).To(BeNumerically("~", want, 1e-9), string(fn))
+		}
+
+		count, err := store.Aggregate(ctx, deposits, dcb.Aggregation{Func: dcb.AggregateCount})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(4.0))
+	})
+
+	It("should support nested data paths", func() {
+		fees := dcb.NewQuery(dcb.NewTags("account_id", "a1"), "FeeCharged")
+		total, err := store.Aggregate(ctx, fees, dcb.Aggregation{Func: dcb.AggregateSum, DataPath: "fee.amount"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(total).To(Equal(2.5))
+	})
+
+	It("should return 0 when nothing matches", func() {
+		none := dcb.NewQuery(dcb.NewTags("account_id", "missing"), "MoneyDeposited")
+		total, err := store.Aggregate(ctx, none, dcb.Aggregation{Func: dcb.AggregateSum, DataPath: "amount"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(total).To(Equal(0.0))
+	})
+
+	It("should reject non-numeric values with a validation error", func() {
+		_, err := store.Aggregate(ctx, deposits, dcb.Aggregation{Func: dcb.AggregateSum, DataPath: "note"})
+		Expect(err).To(MatchError(dcb.ErrValidation))
+	})
+})
@@ -0,0 +1,50 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WithAnyTagValue", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should match events carrying any of the listed values for a key", func() {
+		err := store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("PriceChanged", dcb.NewTags("product_id", "p1", "product_id", "p2", "product_id", "p3"), []byte(`{"pct":5}`)),
+			dcb.NewInputEvent("PriceChanged", dcb.NewTags("product_id", "p4"), []byte(`{"pct":3}`)),
+			dcb.NewInputEvent("StockChanged", dcb.NewTags("product_id", "p2"), []byte(`{}`)),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		query := dcb.NewQueryBuilder().
+			WithAnyTagValue("product_id", []string{"p2", "p4"}).
+			WithType("PriceChanged").
+			Build()
+		events, err := store.Query(ctx, query, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+
+		counter := dcb.StateProjector{
+			ID:           "p3_price_changes",
+			Query:        dcb.NewQueryBuilder().WithAnyTagValue("product_id", []string{"p3"}).Build(),
+			InitialState: 0,
+			TransitionFn: func(state any, event dcb.Event) any { return state.(int) + 1 },
+		}
+		states, _, err := store.Project(ctx, []dcb.StateProjector{counter}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(states["p3_price_changes"]).To(Equal(1))
+	})
+
+	It("should be rejected in append conditions", func() {
+		condition := dcb.NewAppendCondition(dcb.NewQueryBuilder().WithAnyTagValue("product_id", []string{"p1"}).Build())
+		err := store.AppendIf(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("PriceChanged", dcb.NewTags("product_id", "p1"), []byte(`{}`)),
+		}, condition)
+		Expect(err).To(MatchError(dcb.ErrValidation))
+	})
+})
@@ -0,0 +1,55 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AppendIfElse", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	booked := []dcb.InputEvent{dcb.NewInputEvent("SeatBooked", dcb.NewTags("seat_id", "A1"), []byte(`{"customer":"c1"}`))}
+	rejected := []dcb.InputEvent{dcb.NewInputEvent("BookingRejected", dcb.NewTags("seat_id", "A1", "customer_id", "c1"), []byte(`{"reason":"seat taken"}`))}
+	seatFree := dcb.NewAppendCondition(dcb.NewQuery(dcb.NewTags("seat_id", "A1"), "SeatBooked"))
+
+	typesInStore := func() []string {
+		events, err := store.Query(ctx, dcb.NewQueryAll(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		types := make([]string, len(events))
+		for i, event := range events {
+			types[i] = event.Type
+		}
+		return types
+	}
+
+	It("should append the events when the condition holds", func() {
+		result, err := store.AppendIfElse(ctx, booked, seatFree, rejected)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Branch).To(Equal(dcb.AppendBranchEvents))
+		Expect(result.Violation).To(BeNil())
+		Expect(typesInStore()).To(Equal([]string{"SeatBooked"}))
+	})
+
+	It("should append the onFail events when the condition fails", func() {
+		Expect(store.Append(ctx, booked)).To(Succeed())
+
+		result, err := store.AppendIfElse(ctx, booked, seatFree, rejected)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Branch).To(Equal(dcb.AppendBranchOnFail))
+		Expect(result.Violation).NotTo(BeNil())
+		Expect(typesInStore()).To(Equal([]string{"SeatBooked", "BookingRejected"}))
+	})
+
+	It("should validate the onFail events before appending anything", func() {
+		invalid := []dcb.InputEvent{dcb.NewInputEvent("BookingRejected", nil, []byte(`{}`))}
+
+		_, err := store.AppendIfElse(ctx, booked, seatFree, invalid)
+		Expect(err).To(MatchError(dcb.ErrValidation))
+		Expect(typesInStore()).To(BeEmpty())
+	})
+})
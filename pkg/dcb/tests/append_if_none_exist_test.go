@@ -0,0 +1,87 @@
+package dcb
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AppendIfNoneExist", func() {
+	opened := func(accountIDs ...string) []dcb.InputEvent {
+		events := make([]dcb.InputEvent, len(accountIDs))
+		for i, id := range accountIDs {
+			events[i] = dcb.NewInputEvent("AccountOpened", dcb.NewTags("account_id", id), []byte(`{}`))
+		}
+		return events
+	}
+
+	countAccounts := func() int {
+		events, err := store.Query(ctx, dcb.NewQueryBuilder().WithType("AccountOpened").Build(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		return len(events)
+	}
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should append the whole batch when no value exists", func() {
+		Expect(store.AppendIfNoneExist(ctx, opened("a1", "a2", "a3"), "account_id")).To(Succeed())
+		Expect(countAccounts()).To(Equal(3))
+	})
+
+	It("should append nothing and list the existing values", func() {
+		Expect(store.AppendIfNoneExist(ctx, opened("a2", "a4"), "account_id")).To(Succeed())
+
+		err := store.AppendIfNoneExist(ctx, opened("a1", "a2", "a3", "a4"), "account_id")
+		Expect(dcb.IsConcurrencyError(err)).To(BeTrue())
+		concurrencyErr, ok := dcb.GetConcurrencyError(err)
+		Expect(ok).To(BeTrue())
+		Expect(concurrencyErr.ConflictingValues).To(Equal([]string{"a2", "a4"}))
+		Expect(countAccounts()).To(Equal(2))
+	})
+
+	It("should only consider the given tag key", func() {
+		other := dcb.NewInputEvent("UserRegistered", dcb.NewTags("user_id", "a1"), []byte(`{}`))
+		Expect(store.Append(ctx, []dcb.InputEvent{other})).To(Succeed())
+
+		Expect(store.AppendIfNoneExist(ctx, opened("a1"), "account_id")).To(Succeed())
+	})
+
+	It("should conflict with entities created by AppendIfNotExists", func() {
+		_, err := store.AppendIfNotExists(ctx, opened("a1"), dcb.FailIfExists("account_id", "a1"), dcb.OnConflictError)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = store.AppendIfNoneExist(ctx, opened("a1", "a2"), "account_id")
+		Expect(dcb.IsConcurrencyError(err)).To(BeTrue())
+	})
+
+	It("should let only one of concurrent overlapping batches succeed", func() {
+		const creators = 8
+		var wg sync.WaitGroup
+		errs := make([]error, creators)
+		for i := range creators {
+			wg.Go(func() {
+				// Every batch shares "shared" and has one value of its own
+				errs[i] = store.AppendIfNoneExist(ctx, opened("shared", fmt.Sprintf("own-%d", i)), "account_id")
+			})
+		}
+		wg.Wait()
+
+		succeeded := 0
+		for _, err := range errs {
+			if err == nil {
+				succeeded++
+				continue
+			}
+			Expect(dcb.IsConcurrencyError(err)).To(BeTrue(), "unexpected error: %v", err)
+		}
+		Expect(succeeded).To(Equal(1))
+		Expect(countAccounts()).To(Equal(2))
+	})
+})
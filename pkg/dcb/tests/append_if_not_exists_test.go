@@ -0,0 +1,131 @@
+package dcb
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AppendIfNotExists", func() {
+	accountOpened := func(accountID, owner string) []dcb.InputEvent {
+		return []dcb.InputEvent{
+			dcb.NewInputEvent("AccountOpened", dcb.NewTags("account_id", accountID), dcb.ToJSON(map[string]string{"owner": owner})),
+		}
+	}
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should create the entity when it does not exist", func() {
+		result, err := store.AppendIfNotExists(ctx, accountOpened("a1", "ann"), dcb.FailIfExists("account_id", "a1"), dcb.OnConflictIgnore)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Created).To(BeTrue())
+		Expect(result.Existing).To(BeNil())
+	})
+
+	It("should return the ConcurrencyError by default when the entity exists", func() {
+		_, err := store.AppendIfNotExists(ctx, accountOpened("a1", "ann"), dcb.FailIfExists("account_id", "a1"), "")
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := store.AppendIfNotExists(ctx, accountOpened("a1", "bob"), dcb.FailIfExists("account_id", "a1"), "")
+		Expect(dcb.IsConcurrencyError(err)).To(BeTrue())
+		Expect(result.Created).To(BeFalse())
+	})
+
+	It("should treat an existing entity as an idempotent create with OnConflictIgnore", func() {
+		_, err := store.AppendIfNotExists(ctx, accountOpened("a1", "ann"), dcb.FailIfExists("account_id", "a1"), dcb.OnConflictIgnore)
+		Expect(err).NotTo(HaveOccurred())
+		events, err := store.Query(ctx, dcb.NewQuery(dcb.NewTags("account_id", "a1")), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+
+		result, err := store.AppendIfNotExists(ctx, accountOpened("a1", "bob"), dcb.FailIfExists("account_id", "a1"), dcb.OnConflictIgnore)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Created).To(BeFalse())
+		Expect(result.Existing).NotTo(BeNil())
+		position, ok := result.Existing.AfterPosition()
+		Expect(ok).To(BeTrue())
+		Expect(position).To(Equal(events[0].Position))
+
+		// The existing condition guards follow-up appends on the entity
+		err = store.AppendIf(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("AccountCredited", dcb.NewTags("account_id", "a1"), []byte(`{"amount":10}`)),
+		}, result.Existing)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should hold the advisory lock on the connection that appends, until its transaction ends", func() {
+		// Counts the advisory locks held by the backend that is appending to events, and by any other backend
+		locksSQL := `
+			SELECT
+				COUNT(*) FILTER (WHERE a.pid IN (SELECT pid FROM pg_locks WHERE relation = 'events'::regclass AND mode = 'RowExclusiveLock')),
+				COUNT(*) FILTER (WHERE a.pid NOT IN (SELECT pid FROM pg_locks WHERE relation = 'events'::regclass AND mode = 'RowExclusiveLock'))
+			FROM pg_locks a
+			WHERE a.locktype = 'advisory' AND a.database = (SELECT oid FROM pg_database WHERE datname = current_database())`
+
+		err := store.WithTransaction(ctx, func(txStore dcb.EventStore) error {
+			result, err := txStore.AppendIfNotExists(ctx, accountOpened("pinned", "alice"), dcb.FailIfExists("account_id", "pinned"), dcb.OnConflictError)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Created).To(BeTrue())
+
+			var appender, others int
+			Expect(pool.QueryRow(ctx, locksSQL).Scan(&appender, &others)).To(Succeed())
+			Expect(appender).To(Equal(1))
+			Expect(others).To(Equal(0))
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		var held int
+		Expect(pool.QueryRow(ctx, `SELECT COUNT(*) FROM pg_locks WHERE locktype = 'advisory'`).Scan(&held)).To(Succeed())
+		Expect(held).To(Equal(0))
+	})
+
+	It("should let exactly one of many concurrent creators append", func() {
+		const creators = 10
+		for _, onConflict := range []dcb.OnConflict{dcb.OnConflictIgnore, dcb.OnConflictError} {
+			accountID := fmt.Sprintf("account-%s", onConflict)
+
+			var wg sync.WaitGroup
+			start := make(chan struct{})
+			results := make([]dcb.CreateResult, creators)
+			errs := make([]error, creators)
+			for i := range creators {
+				wg.Go(func() {
+					<-start
+					results[i], errs[i] = store.AppendIfNotExists(ctx, accountOpened(accountID, fmt.Sprintf("owner-%d", i)),
+						dcb.FailIfExists("account_id", accountID), onConflict)
+				})
+			}
+			close(start)
+			wg.Wait()
+
+			created, conflicts := 0, 0
+			for i := range creators {
+				switch {
+				case errs[i] == nil && results[i].Created:
+					created++
+				case errs[i] == nil && onConflict == dcb.OnConflictIgnore:
+					Expect(results[i].Existing).NotTo(BeNil())
+					conflicts++
+				case dcb.IsConcurrencyError(errs[i]) && onConflict == dcb.OnConflictError:
+					conflicts++
+				default:
+					Fail(fmt.Sprintf("unexpected outcome for %s: %+v, %v", onConflict, results[i], errs[i]))
+				}
+			}
+			Expect(created).To(Equal(1))
+			Expect(conflicts).To(Equal(creators - 1))
+
+			events, err := store.Query(ctx, dcb.NewQuery(dcb.NewTags("account_id", accountID), "AccountOpened"), nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(1))
+		}
+	})
+})
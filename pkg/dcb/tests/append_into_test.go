@@ -0,0 +1,59 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AppendInto", func() {
+	opened := func(accountID string) dcb.InputEvent {
+		return dcb.NewInputEvent("AccountOpened", dcb.NewTags("account_id", accountID), []byte(`{}`))
+	}
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should write the assigned positions back into the entries", func() {
+		Expect(store.Append(ctx, []dcb.InputEvent{opened("a0")})).To(Succeed())
+
+		entries := dcb.NewAppendEntries(opened("a1"), opened("a2"))
+		first := entries[0]
+		Expect(store.AppendInto(ctx, entries, dcb.FailIfExists("account_id", "a1"))).To(Succeed())
+
+		// The caller's references see the positions
+		Expect(first.Position).To(Equal(int64(2)))
+		Expect(entries[1].Position).To(Equal(int64(3)))
+		Expect(entries[1].TransactionID).To(Equal(first.TransactionID))
+
+		events, err := store.ReadByPositions(ctx, []int64{first.Position, entries[1].Position})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].TransactionID).To(Equal(first.TransactionID))
+
+		// A follow-up event can reference a just-written one without a re-read
+		cause := dcb.Event{Position: first.Position, TransactionID: first.TransactionID}
+		caused := dcb.NewEvent("WelcomeSent").WithTag("account_id", "a1").CausedBy(cause).Build()
+		Expect(store.Append(ctx, []dcb.InputEvent{caused})).To(Succeed())
+
+		welcome, err := store.Query(ctx, dcb.NewQuery(nil, "WelcomeSent"), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(welcome).To(HaveLen(1))
+		position, ok := welcome[0].CausationPosition()
+		Expect(ok).To(BeTrue())
+		Expect(position).To(Equal(first.Position))
+	})
+
+	It("should leave the entries unchanged when the condition fails", func() {
+		Expect(store.Append(ctx, []dcb.InputEvent{opened("a1")})).To(Succeed())
+
+		entries := dcb.NewAppendEntries(opened("a1"))
+		err := store.AppendInto(ctx, entries, dcb.FailIfExists("account_id", "a1"))
+		Expect(dcb.IsConcurrencyError(err)).To(BeTrue())
+		Expect(entries[0].Position).To(BeZero())
+		Expect(entries[0].TransactionID).To(BeZero())
+	})
+})
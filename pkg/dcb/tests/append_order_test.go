@@ -0,0 +1,132 @@
+package dcb
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// orderedEvent is the payload of the append order stress events
+type orderedEvent struct {
+	Aggregate string `json:"aggregate"`
+	Batch     int    `json:"batch"`
+	Seq       int    `json:"seq"`
+}
+
+// interleavedBatch builds events for aggregates in round-robin order, seq counting per aggregate
+func interleavedBatch(batch int, aggregates []string, perAggregate int) []dcb.InputEvent {
+	events := make([]dcb.InputEvent, 0, len(aggregates)*perAggregate)
+	for seq := range perAggregate {
+		for _, aggregate := range aggregates {
+			events = append(events, dcb.NewEvent("OrderedEvent").
+				WithTag("aggregate", aggregate).
+				WithTag("order_test", "true").
+				WithData(orderedEvent{Aggregate: aggregate, Batch: batch, Seq: seq}).
+				Build())
+		}
+	}
+	return events
+}
+
+func decodeOrdered(event dcb.Event) orderedEvent {
+	var payload orderedEvent
+	Expect(json.Unmarshal(event.Data, &payload)).To(Succeed())
+	return payload
+}
+
+var _ = Describe("Append ordering within a batch", func() {
+	aggregates := []string{"agg-a", "agg-b", "agg-c", "agg-d"}
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should assign strictly increasing positions in input order", func() {
+		events := interleavedBatch(0, aggregates, 50)
+		Expect(store.Append(ctx, events)).To(Succeed())
+
+		read, err := store.Query(ctx, dcb.NewQuery(dcb.NewTags("order_test", "true")), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(read).To(HaveLen(len(events)))
+
+		for i, event := range read {
+			Expect(event.Data).To(MatchJSON(events[i].GetData()), "event %d read out of input order", i)
+			if i > 0 {
+				Expect(event.Position).To(BeNumerically(">", read[i-1].Position))
+				Expect(event.TransactionID).To(Equal(read[0].TransactionID))
+			}
+		}
+	})
+
+	It("should preserve per-aggregate order under concurrent interleaved appends", func() {
+		const writers = 8
+		const batchesPerWriter = 10
+		const perAggregate = 5
+
+		var wg sync.WaitGroup
+		errs := make(chan error, writers*batchesPerWriter)
+		for w := range writers {
+			wg.Go(func() {
+				for b := range batchesPerWriter {
+					batch := w*batchesPerWriter + b
+					// Mix unconditional and conditional appends: both share the same insert path
+					events := interleavedBatch(batch, aggregates, perAggregate)
+					if b%2 == 0 {
+						errs <- store.Append(ctx, events)
+					} else {
+						condition := dcb.NewAppendCondition(dcb.NewQuery(dcb.NewTags("order_test", fmt.Sprintf("none-%d", batch))))
+						errs <- store.AppendIf(ctx, events, condition)
+					}
+				}
+			})
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		for _, aggregate := range aggregates {
+			read, err := store.Query(ctx, dcb.NewQuery(dcb.NewTags("aggregate", aggregate)), nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(read).To(HaveLen(writers * batchesPerWriter * perAggregate))
+
+			// Batches may commit in any order, but each batch's events for an aggregate are
+			// contiguous in transaction order and keep their input order
+			nextSeq := make(map[int]int)
+			for i, event := range read {
+				payload := decodeOrdered(event)
+				Expect(payload.Aggregate).To(Equal(aggregate))
+				Expect(payload.Seq).To(Equal(nextSeq[payload.Batch]), "aggregate %s batch %d out of order at %d", aggregate, payload.Batch, i)
+				nextSeq[payload.Batch]++
+				if i > 0 && event.TransactionID == read[i-1].TransactionID {
+					Expect(event.Position).To(BeNumerically(">", read[i-1].Position))
+				}
+			}
+		}
+	})
+
+	It("should keep input order with a custom position allocator", func() {
+		config := store.GetConfig()
+		config.PositionAllocator = &fixedPositionAllocator{next: 2_000_000}
+		allocStore, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+
+		events := interleavedBatch(0, aggregates, 10)
+		Expect(allocStore.Append(ctx, events)).To(Succeed())
+
+		read, err := allocStore.Query(ctx, dcb.NewQuery(dcb.NewTags("order_test", "true")), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(read).To(HaveLen(len(events)))
+		for i, event := range read {
+			Expect(event.Position).To(Equal(int64(2_000_001 + i)))
+			Expect(event.Data).To(MatchJSON(events[i].GetData()))
+		}
+	})
+})
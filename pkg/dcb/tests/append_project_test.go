@@ -0,0 +1,55 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AppendAndProject", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	balance := dcb.StateProjector{
+		ID:           "balance",
+		Query:        dcb.NewQuery(dcb.NewTags("account_id", "a1"), "MoneyDeposited"),
+		InitialState: 0,
+		TransitionFn: func(state any, event dcb.Event) any { return state.(int) + 10 },
+	}
+	deposit := func() dcb.InputEvent {
+		return dcb.NewInputEvent("MoneyDeposited", dcb.NewTags("account_id", "a1"), []byte(`{"amount":10}`))
+	}
+
+	It("should return the state including the appended events and their positions", func() {
+		states, condition, positions, err := store.AppendAndProject(ctx, []dcb.InputEvent{deposit(), deposit()}, nil, []dcb.StateProjector{balance})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(states["balance"]).To(Equal(20))
+		Expect(positions).To(HaveLen(2))
+		Expect(positions[0]).To(BeNumerically("<", positions[1]))
+
+		after, ok := condition.AfterPosition()
+		Expect(ok).To(BeTrue())
+		Expect(after).To(Equal(positions[1]))
+
+		// The returned condition guards the next decision
+		states, _, _, err = store.AppendAndProject(ctx, []dcb.InputEvent{deposit()}, condition, []dcb.StateProjector{balance})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(states["balance"]).To(Equal(30))
+	})
+
+	It("should not append when the condition is violated", func() {
+		_, condition, err := store.Project(ctx, []dcb.StateProjector{balance}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.Append(ctx, []dcb.InputEvent{deposit()})).To(Succeed())
+
+		_, _, _, err = store.AppendAndProject(ctx, []dcb.InputEvent{deposit()}, condition, []dcb.StateProjector{balance})
+		Expect(err).To(MatchError(dcb.ErrConcurrency))
+
+		events, err := store.Query(ctx, dcb.NewQuery(dcb.NewTags("account_id", "a1")), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+	})
+})
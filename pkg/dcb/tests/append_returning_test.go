@@ -0,0 +1,84 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Positions returned by the insert", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	batch := func(n int, account string) []dcb.InputEvent {
+		events := make([]dcb.InputEvent, n)
+		for i := range events {
+			events[i] = dcb.NewInputEvent("Deposited", dcb.NewTags("account", account), []byte(fmt.Sprintf(`{"seq":%d}`, i)))
+		}
+		return events
+	}
+	stored := func(account string) []dcb.Event {
+		events, err := store.Query(ctx, dcb.NewQuery(dcb.NewTags("account", account), "Deposited"), nil)
+		Expect(err).NotTo(HaveOccurred())
+		return events
+	}
+	balance := dcb.StateProjector{
+		ID:           "count",
+		Query:        dcb.NewQuery(dcb.NewTags("account", "a1"), "Deposited"),
+		InitialState: 0,
+		TransitionFn: func(state any, event dcb.Event) any { return state.(int) + 1 },
+	}
+
+	It("should return the positions of a MaxAppendBatchSize batch in append order", func() {
+		size := store.GetConfig().MaxAppendBatchSize
+		_, _, positions, err := store.AppendAndProject(ctx, batch(size, "a1"), nil, []dcb.StateProjector{balance})
+		Expect(err).NotTo(HaveOccurred())
+
+		events := stored("a1")
+		Expect(events).To(HaveLen(size))
+		Expect(positions).To(HaveLen(size))
+		for i, event := range events {
+			Expect(positions[i]).To(Equal(event.Position))
+			Expect(string(event.Data)).To(Equal(fmt.Sprintf(`{"seq":%d}`, i)))
+		}
+	})
+
+	It("should return positions and the transaction from a conditional append", func() {
+		condition := dcb.NewAppendCondition(dcb.NewQuery(dcb.NewTags("account", "a1"), "Deposited"))
+		result, err := dcb.NewCommandExecutor(store).ExecuteCommand(ctx, dcb.NewCommand("Deposit", []byte(`{}`), nil),
+			dcb.CommandHandlerFunc(func(ctx context.Context, store dcb.EventStore, command dcb.Command) ([]dcb.InputEvent, any, error) {
+				return batch(3, "a1"), nil, nil
+			}), &condition)
+		Expect(err).NotTo(HaveOccurred())
+
+		events := stored("a1")
+		Expect(events).To(HaveLen(3))
+		Expect(result.Positions).To(Equal([]int64{events[0].Position, events[1].Position, events[2].Position}))
+		Expect(result.TransactionID).To(Equal(events[0].TransactionID))
+	})
+
+	It("should report only the command's own events inside a shared transaction", func() {
+		err := store.WithTransaction(ctx, func(txStore dcb.EventStore) error {
+			if err := txStore.Append(ctx, batch(2, "a0")); err != nil {
+				return err
+			}
+			result, err := dcb.NewCommandExecutor(txStore).ExecuteCommand(ctx, dcb.NewCommand("Deposit", []byte(`{}`), nil),
+				dcb.CommandHandlerFunc(func(ctx context.Context, store dcb.EventStore, command dcb.Command) ([]dcb.InputEvent, any, error) {
+					return batch(1, "a1"), nil, nil
+				}), nil)
+			if err != nil {
+				return err
+			}
+			Expect(result.Positions).To(HaveLen(1))
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored("a1")[0].Position).To(BeNumerically(">", stored("a0")[1].Position))
+	})
+})
@@ -0,0 +1,39 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StateProjector.AppliesTo", func() {
+	userEvent := func(eventType string) dcb.InputEvent {
+		return dcb.NewInputEvent(eventType, dcb.NewTags("user_id", "u1"), []byte(`{}`))
+	}
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should fold only the listed types while the condition covers the whole query", func() {
+		Expect(store.Append(ctx, []dcb.InputEvent{userEvent("UserCreated"), userEvent("UserRenamed")})).To(Succeed())
+
+		projector := dcb.StateProjector{
+			ID:           "renames",
+			Query:        dcb.NewQuery(dcb.NewTags("user_id", "u1"), "UserCreated", "UserRenamed"),
+			InitialState: 0,
+			TransitionFn: func(state any, event dcb.Event) any { return state.(int) + 1 },
+			AppliesTo:    []string{"UserRenamed"},
+		}
+		states, condition, err := store.Project(ctx, []dcb.StateProjector{projector}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(states["renames"]).To(Equal(1))
+
+		// An event of a type the fold ignores still invalidates the decision
+		Expect(store.Append(ctx, []dcb.InputEvent{userEvent("UserCreated")})).To(Succeed())
+		err = store.AppendIf(ctx, []dcb.InputEvent{userEvent("UserRenamed")}, condition)
+		Expect(dcb.IsConcurrencyError(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,82 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Archive", func() {
+	const archiveTable = "events_archive_test"
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = pool.Exec(ctx, "DROP TABLE IF EXISTS "+archiveTable)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		_, err := pool.Exec(ctx, "DROP TABLE IF EXISTS "+archiveTable)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should move events before the position and keep them readable through the archive", func() {
+		err := store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("AccountOpened", dcb.NewTags("account_id", "a1"), []byte(`{"n":1}`)),
+			dcb.NewInputEvent("AccountOpened", dcb.NewTags("account_id", "a2"), []byte(`{"n":2}`)),
+			dcb.NewInputEvent("AccountOpened", dcb.NewTags("account_id", "a3"), []byte(`{"n":3}`)),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		all, err := store.Query(ctx, dcb.NewQueryAll(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(all).To(HaveLen(3))
+
+		moved, err := store.Archive(ctx, all[2].Position, archiveTable)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(moved).To(Equal(int64(2)))
+
+		live, err := store.Query(ctx, dcb.NewQueryAll(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(live).To(HaveLen(1))
+		Expect(live[0].Position).To(Equal(all[2].Position))
+
+		config := store.GetConfig()
+		config.ArchiveTable = archiveTable
+		archiveStore, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+
+		combined, err := archiveStore.Query(ctx, dcb.NewQueryAll(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(combined).To(HaveLen(3))
+		for i := range combined {
+			Expect(combined[i].Position).To(Equal(all[i].Position))
+			Expect(combined[i].TransactionID).To(Equal(all[i].TransactionID))
+		}
+
+		// Cursors taken before archiving stay valid
+		after := &dcb.Cursor{TransactionID: all[0].TransactionID, Position: all[0].Position}
+		rest, err := archiveStore.Query(ctx, dcb.NewQueryAll(), after)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rest).To(HaveLen(2))
+	})
+
+	It("should reject invalid table names and positions", func() {
+		_, err := store.Archive(ctx, 10, "events")
+		Expect(err).To(MatchError(dcb.ErrValidation))
+
+		_, err = store.Archive(ctx, 10, "archive; DROP TABLE events")
+		Expect(err).To(MatchError(dcb.ErrValidation))
+
+		// Only events_archive tables can be targeted, never the store's own
+		for _, table := range []string{"commands", "dcb_failed_commands", "dcb_aggregate_versions", "events_archived"} {
+			_, err = store.Archive(ctx, 10, table)
+			Expect(err).To(MatchError(dcb.ErrValidation))
+		}
+
+		_, err = store.Archive(ctx, 0, archiveTable)
+		Expect(err).To(MatchError(dcb.ErrValidation))
+	})
+})
@@ -0,0 +1,63 @@
+package dcb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Append batch limits", func() {
+	var limited dcb.EventStore
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+
+		config := store.GetConfig()
+		config.MaxAppendBatchSize = 5
+		config.MaxEventDataSize = 64
+		limited, err = dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	newEvents := func(n int) []dcb.InputEvent {
+		events := make([]dcb.InputEvent, n)
+		for i := range events {
+			events[i] = dcb.NewInputEvent("TestEvent", dcb.NewTags("test", fmt.Sprintf("value%d", i)), []byte(`{}`))
+		}
+		return events
+	}
+
+	It("should append a batch of exactly MaxAppendBatchSize events", func() {
+		Expect(limited.Append(ctx, newEvents(5))).To(Succeed())
+		Expect(limited.AppendIf(ctx, newEvents(5), nil)).To(Succeed())
+
+		events, err := limited.Query(ctx, dcb.NewQueryAll(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(10))
+	})
+
+	It("should reject a batch one over MaxAppendBatchSize without appending anything", func() {
+		err := limited.Append(ctx, newEvents(6))
+		Expect(err).To(MatchError(dcb.ErrValidation))
+		Expect(err.Error()).To(ContainSubstring("batch size 6 exceeds maximum 5"))
+
+		err = limited.AppendIf(ctx, newEvents(6), nil)
+		Expect(err).To(MatchError(dcb.ErrValidation))
+
+		events, err := limited.Query(ctx, dcb.NewQueryAll(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(BeEmpty())
+	})
+
+	It("should reject events whose data exceeds MaxEventDataSize", func() {
+		large := []byte(`{"payload":"` + strings.Repeat("x", 64) + `"}`)
+		err := limited.Append(ctx, []dcb.InputEvent{dcb.NewInputEvent("TestEvent", dcb.NewTags("test", "large"), large)})
+		Expect(err).To(MatchError(dcb.ErrValidation))
+		Expect(err.Error()).To(ContainSubstring("MaxEventDataSize"))
+	})
+})
@@ -507,6 +507,27 @@ var _ = Describe("Batch Projection", func() {
 		Expect(err.Error()).To(ContainSubstring("empty"))
 	})
 
+	It("should reject projectors sharing an ID", func() {
+		enrollment := func(studentID string) dcb.StateProjector {
+			return dcb.StateProjector{
+				ID:           "enrollment",
+				Query:        dcb.NewQuery(dcb.NewTags("student_id", studentID)),
+				InitialState: "not_enrolled",
+				TransitionFn: func(state any, event dcb.Event) any {
+					return "enrolled"
+				},
+			}
+		}
+		projectors := []dcb.StateProjector{enrollment("123"), enrollment("456")}
+
+		_, _, err := store.Project(ctx, projectors, nil)
+		Expect(dcb.IsValidationError(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(`duplicate projector ID "enrollment"`))
+
+		_, _, err = store.ProjectStream(ctx, projectors, nil)
+		Expect(dcb.IsValidationError(err)).To(BeTrue())
+	})
+
 	It("should handle nil transition function", func() {
 		// Create projector with nil transition function
 		projector := dcb.StateProjector{
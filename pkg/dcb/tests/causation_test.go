@@ -0,0 +1,54 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Causation", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should record causation in metadata and find downstream events", func() {
+		err := store.Append(ctx, []dcb.InputEvent{
+			dcb.NewEvent("OrderPlaced").WithTag("order_id", "o1").WithData(map[string]string{"status": "placed"}).Build(),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		sources, err := store.Query(ctx, dcb.NewQuery(dcb.NewTags("order_id", "o1"), "OrderPlaced"), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sources).To(HaveLen(1))
+		source := sources[0]
+
+		err = store.Append(ctx, []dcb.InputEvent{
+			dcb.NewEvent("InvoiceIssued").WithTag("order_id", "o1").WithData(map[string]string{"status": "issued"}).CausedBy(source).Build(),
+			dcb.NewEvent("InvoiceIssued").WithTag("order_id", "o2").WithData(map[string]string{"status": "issued"}).Build(),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		downstream, err := store.Query(ctx, dcb.NewQueryBuilder().WithCausedBy(source.Position).Build(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(downstream).To(HaveLen(1))
+		Expect(downstream[0].Type).To(Equal("InvoiceIssued"))
+
+		position, ok := downstream[0].CausationPosition()
+		Expect(ok).To(BeTrue())
+		Expect(position).To(Equal(source.Position))
+
+		// Causation is metadata, not a tag
+		Expect(dcb.TagsToString(downstream[0].Tags)).To(ConsistOf("order_id:o1"))
+	})
+
+	It("should reject causation predicates in append conditions", func() {
+		condition := dcb.NewAppendCondition(dcb.NewQueryBuilder().WithCausedBy(1).Build())
+		err := store.AppendIf(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("TestEvent", dcb.NewTags("key", "value"), []byte(`{}`)),
+		}, condition)
+		Expect(err).To(HaveOccurred())
+		Expect(dcb.IsValidationError(err)).To(BeTrue())
+	})
+})
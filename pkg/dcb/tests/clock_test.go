@@ -0,0 +1,56 @@
+package dcb
+
+import (
+	"time"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Clock", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should stamp appended events with the configured clock", func() {
+		clock := dcb.NewFakeClock(GinkgoT())
+		config := store.GetConfig()
+		config.Clock = clock
+		clockStore, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+
+		first := clock.Now()
+		Expect(clockStore.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("Ticked", dcb.NewTags("clock", "fake"), []byte(`{}`)),
+			dcb.NewInputEvent("Ticked", dcb.NewTags("clock", "fake"), []byte(`{}`)),
+		})).To(Succeed())
+
+		clock.Advance(90 * time.Minute)
+		condition := dcb.NewAppendCondition(dcb.NewQuery(dcb.NewTags("clock", "none")))
+		Expect(clockStore.AppendIf(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("Ticked", dcb.NewTags("clock", "fake"), []byte(`{}`)),
+		}, condition)).To(Succeed())
+
+		events, err := clockStore.Query(ctx, dcb.NewQuery(dcb.NewTags("clock", "fake")), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(3))
+		Expect(events[0].OccurredAt.Equal(first)).To(BeTrue())
+		Expect(events[1].OccurredAt.Equal(first)).To(BeTrue())
+		Expect(events[2].OccurredAt.Equal(first.Add(90 * time.Minute))).To(BeTrue())
+	})
+
+	It("should keep the database timestamp without a clock", func() {
+		before := time.Now().Add(-time.Minute)
+		Expect(store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("Ticked", dcb.NewTags("clock", "db"), []byte(`{}`)),
+		})).To(Succeed())
+
+		events, err := store.Query(ctx, dcb.NewQuery(dcb.NewTags("clock", "db")), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].OccurredAt).To(BeTemporally(">", before))
+	})
+})
@@ -0,0 +1,143 @@
+package dcb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Command dead-lettering", func() {
+	var executor dcb.CommandExecutor
+
+	openAccount := dcb.NewCommand("OpenAccount", []byte(`{"account_id":"a1"}`), map[string]interface{}{"source": "test"})
+
+	succeeding := dcb.CommandHandlerFunc(func(ctx context.Context, store dcb.EventStore, command dcb.Command) ([]dcb.InputEvent, any, error) {
+		return []dcb.InputEvent{dcb.NewInputEvent("AccountOpened", dcb.NewTags("account_id", "a1"), command.GetData())}, nil, nil
+	})
+	panicking := dcb.CommandHandlerFunc(func(ctx context.Context, store dcb.EventStore, command dcb.Command) ([]dcb.InputEvent, any, error) {
+		panic("nil pointer in handler")
+	})
+
+	failedCommand := func(id int64) (attempts int, panicked, resolved bool) {
+		err := pool.QueryRow(ctx, `SELECT attempts, panicked, resolved_at IS NOT NULL FROM dcb_failed_commands WHERE id = $1`, id).
+			Scan(&attempts, &panicked, &resolved)
+		Expect(err).NotTo(HaveOccurred())
+		return attempts, panicked, resolved
+	}
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+		executor = dcb.NewCommandExecutorWithConfig(store, dcb.CommandExecutorConfig{DeadLetter: true})
+	})
+
+	It("should recover a panicking handler and record the command", func() {
+		result, err := executor.ExecuteCommand(ctx, openAccount, panicking, nil)
+		Expect(dcb.IsResourceError(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("nil pointer in handler"))
+		Expect(result.FailedCommandID).To(BeNumerically(">", 0))
+
+		attempts, panicked, resolved := failedCommand(result.FailedCommandID)
+		Expect(attempts).To(Equal(1))
+		Expect(panicked).To(BeTrue())
+		Expect(resolved).To(BeFalse())
+	})
+
+	It("should recover panics without dead-lettering when it is disabled", func() {
+		result, err := dcb.NewCommandExecutor(store).ExecuteCommand(ctx, openAccount, panicking, nil)
+		Expect(dcb.IsResourceError(err)).To(BeTrue())
+		Expect(result.FailedCommandID).To(BeZero())
+	})
+
+	It("should record handler errors but not concurrency errors", func() {
+		result, err := executor.ExecuteCommand(ctx, openAccount, dcb.CommandHandlerFunc(
+			func(ctx context.Context, store dcb.EventStore, command dcb.Command) ([]dcb.InputEvent, any, error) {
+				return nil, nil, errors.New("account service unavailable")
+			}), nil)
+		Expect(err).To(HaveOccurred())
+		_, panicked, _ := failedCommand(result.FailedCommandID)
+		Expect(panicked).To(BeFalse())
+
+		_, err = executor.ExecuteCommand(ctx, openAccount, succeeding, nil)
+		Expect(err).NotTo(HaveOccurred())
+		condition := dcb.FailIfExists("account_id", "a1")
+		result, err = executor.ExecuteCommand(ctx, openAccount, succeeding, &condition)
+		Expect(dcb.IsConcurrencyError(err)).To(BeTrue())
+		Expect(result.FailedCommandID).To(BeZero())
+	})
+
+	It("should record commands that failed because their context was cancelled", func() {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		result, err := executor.ExecuteCommand(cancelled, openAccount, succeeding, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(result.FailedCommandID).To(BeNumerically(">", 0))
+
+		attempts, _, resolved := failedCommand(result.FailedCommandID)
+		Expect(attempts).To(Equal(1))
+		Expect(resolved).To(BeFalse())
+	})
+
+	It("should retry a failed command and mark it resolved", func() {
+		result, err := executor.ExecuteCommand(ctx, openAccount, panicking, nil)
+		Expect(err).To(HaveOccurred())
+		id := result.FailedCommandID
+
+		// A failing retry updates the same record
+		_, err = executor.RetryFailedCommand(ctx, id, panicking, nil)
+		Expect(err).To(HaveOccurred())
+		attempts, _, resolved := failedCommand(id)
+		Expect(attempts).To(Equal(2))
+		Expect(resolved).To(BeFalse())
+
+		retried, err := executor.RetryFailedCommand(ctx, id, succeeding, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(retried.Events).To(HaveLen(1))
+		_, _, resolved = failedCommand(id)
+		Expect(resolved).To(BeTrue())
+
+		events, err := store.ReadByTransaction(ctx, retried.TransactionID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Data).To(MatchJSON(`{"account_id":"a1"}`))
+
+		_, err = executor.RetryFailedCommand(ctx, id, succeeding, nil)
+		Expect(dcb.IsValidationError(err)).To(BeTrue())
+	})
+
+	It("should run a failed command once when it is retried concurrently", func() {
+		result, err := executor.ExecuteCommand(ctx, openAccount, panicking, nil)
+		Expect(err).To(HaveOccurred())
+		id := result.FailedCommandID
+
+		slow := dcb.CommandHandlerFunc(func(ctx context.Context, store dcb.EventStore, command dcb.Command) ([]dcb.InputEvent, any, error) {
+			time.Sleep(100 * time.Millisecond)
+			return succeeding(ctx, store, command)
+		})
+		errs := make(chan error, 2)
+		for range 2 {
+			go func() {
+				defer GinkgoRecover()
+				_, err := executor.RetryFailedCommand(ctx, id, slow, nil)
+				errs <- err
+			}()
+		}
+		first, second := <-errs, <-errs
+		Expect([]bool{first == nil, second == nil}).To(ConsistOf(true, false))
+		Expect(dcb.IsValidationError(first) || dcb.IsValidationError(second)).To(BeTrue())
+
+		events, err := store.Query(ctx, dcb.NewQuery(dcb.NewTags("account_id", "a1"), "AccountOpened"), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+	})
+
+	It("should reject unknown failed commands", func() {
+		_, err := executor.RetryFailedCommand(ctx, -1, succeeding, nil)
+		Expect(dcb.IsValidationError(err)).To(BeTrue())
+	})
+})
@@ -38,8 +38,10 @@ var _ = Describe("CommandExecutor", func() {
 				})
 
 				// Execute command using function-based handler
-				_, err = commandExecutor.ExecuteCommand(ctx, command, dcb.CommandHandlerFunc(handleTestCommand), nil)
+				result, err := commandExecutor.ExecuteCommand(ctx, command, dcb.CommandHandlerFunc(handleTestCommand), nil)
 				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Events).To(HaveLen(1))
+				Expect(result.Output).To(Equal("Hello, World!"))
 
 				// Verify events were created
 				events, err := store.Query(ctx, dcb.NewQueryAll(), nil)
@@ -48,6 +50,8 @@ var _ = Describe("CommandExecutor", func() {
 
 				event := events[0]
 				Expect(event.Type).To(Equal("test_event"))
+				Expect(result.Positions).To(Equal([]int64{event.Position}))
+				Expect(result.TransactionID).To(Equal(event.TransactionID))
 				Expect(event.Tags).To(HaveLen(1))
 				Expect(event.Tags[0].GetKey()).To(Equal("test_tag"))
 				Expect(event.Tags[0].GetValue()).To(Equal("test_value"))
@@ -61,6 +65,18 @@ var _ = Describe("CommandExecutor", func() {
 			})
 		})
 
+		Context("when the result is not needed", func() {
+			It("should execute the command through RunCommand", func() {
+				command := dcb.NewCommand("test_command", []byte(`{"message":"ignored"}`), nil)
+				err := dcb.RunCommand(ctx, commandExecutor, command, dcb.CommandHandlerFunc(handleTestCommand), nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				events, err := store.Query(ctx, dcb.NewQueryAll(), nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(events).To(HaveLen(1))
+			})
+		})
+
 		Context("with nil command", func() {
 			It("should return validation error", func() {
 				_, err := commandExecutor.ExecuteCommand(ctx, nil, dcb.CommandHandlerFunc(handleTestCommand), nil)
@@ -91,10 +107,10 @@ var _ = Describe("CommandExecutor", func() {
 })
 
 // Test command handler functions
-func handleTestCommand(ctx context.Context, eventStore dcb.EventStore, command dcb.Command) ([]dcb.InputEvent, error) {
+func handleTestCommand(ctx context.Context, eventStore dcb.EventStore, command dcb.Command) ([]dcb.InputEvent, any, error) {
 	var cmdData map[string]interface{}
 	if err := json.Unmarshal(command.GetData(), &cmdData); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	eventData := map[string]interface{}{
@@ -104,16 +120,16 @@ func handleTestCommand(ctx context.Context, eventStore dcb.EventStore, command d
 
 	eventBytes, err := json.Marshal(eventData)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	return []dcb.InputEvent{
 		dcb.NewInputEvent("test_event", []dcb.Tag{
 			dcb.NewTag("test_tag", "test_value"),
 		}, eventBytes),
-	}, nil
+	}, cmdData["message"], nil
 }
 
-func handleEmptyCommand(ctx context.Context, eventStore dcb.EventStore, command dcb.Command) ([]dcb.InputEvent, error) {
-	return nil, nil // Return empty events to test validation
+func handleEmptyCommand(ctx context.Context, eventStore dcb.EventStore, command dcb.Command) ([]dcb.InputEvent, any, error) {
+	return nil, nil, nil // Return empty events to test validation
 }
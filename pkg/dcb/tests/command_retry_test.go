@@ -0,0 +1,62 @@
+package dcb
+
+import (
+	"context"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExecuteCommandWithRetry", func() {
+	var executor dcb.CommandExecutor
+
+	query := dcb.NewQuery(dcb.NewTags("account_id", "a1"), "Deposited")
+	deposit := dcb.NewInputEvent("Deposited", dcb.NewTags("account_id", "a1"), []byte(`{}`))
+
+	// countingHandler projects the number of deposits, then appends another one guarded by the
+	// projection's condition; before returning, the first race runs append a competing deposit
+	countingHandler := func(races int) (dcb.ConditionalCommandHandler, *[]int) {
+		var seen []int
+		return dcb.ConditionalCommandHandlerFunc(func(ctx context.Context, s dcb.EventStore, command dcb.Command) ([]dcb.InputEvent, *dcb.AppendCondition, any, error) {
+			states, condition, err := s.Project(ctx, []dcb.StateProjector{dcb.ProjectCounter("deposits", "Deposited", "account_id", "a1")}, nil)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			seen = append(seen, states["deposits"].(int))
+			if len(seen) <= races {
+				if err := store.Append(context.Background(), []dcb.InputEvent{deposit}); err != nil {
+					return nil, nil, nil, err
+				}
+			}
+			return []dcb.InputEvent{deposit}, &condition, nil, nil
+		}), &seen
+	}
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+		executor = dcb.NewCommandExecutor(store)
+	})
+
+	It("should decide again on fresh state after losing a race", func() {
+		handler, seen := countingHandler(1)
+		result, err := executor.ExecuteCommandWithRetry(ctx, dcb.NewCommand("deposit", []byte(`{}`), nil), handler, dcb.RetryPolicy{MaxAttempts: 3})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Attempts).To(Equal(2))
+		Expect(*seen).To(Equal([]int{0, 1}))
+
+		events, err := store.Query(ctx, query, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+	})
+
+	It("should return the concurrency error once the attempts are used up", func() {
+		handler, seen := countingHandler(2)
+		result, err := executor.ExecuteCommandWithRetry(ctx, dcb.NewCommand("deposit", []byte(`{}`), nil), handler, dcb.RetryPolicy{MaxAttempts: 2, Backoff: 1})
+		Expect(dcb.IsConcurrencyError(err)).To(BeTrue())
+		Expect(result.Attempts).To(Equal(2))
+		Expect(*seen).To(HaveLen(2))
+	})
+})
@@ -0,0 +1,171 @@
+package dcb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scheduled commands", func() {
+	var (
+		clock    *dcb.FakeClock
+		executor dcb.CommandExecutor
+	)
+
+	expireBooking := func(bookingID string) dcb.Command {
+		return dcb.NewCommand("ExpireBooking", []byte(fmt.Sprintf(`{"booking_id":%q}`, bookingID)), nil)
+	}
+	expiring := dcb.CommandHandlerFunc(func(ctx context.Context, store dcb.EventStore, command dcb.Command) ([]dcb.InputEvent, any, error) {
+		return []dcb.InputEvent{dcb.NewInputEvent("BookingExpired", dcb.NewTags("booking", "expiry"), command.GetData())}, nil, nil
+	})
+	countExpired := func() int {
+		events, err := store.Query(ctx, dcb.NewQueryBuilder().WithType("BookingExpired").Build(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		return len(events)
+	}
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = pool.Exec(ctx, "TRUNCATE TABLE dcb_scheduled_commands RESTART IDENTITY")
+		Expect(err).NotTo(HaveOccurred())
+
+		clock = dcb.NewFakeClock(GinkgoT())
+		config := store.GetConfig()
+		config.Clock = clock
+		clockStore, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+		executor = dcb.NewCommandExecutor(clockStore)
+	})
+
+	It("should execute a command once its time has arrived, and only once", func() {
+		id, err := executor.ScheduleCommand(ctx, expireBooking("b1"), clock.Now().Add(15*time.Minute))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(id).To(BeNumerically(">", 0))
+
+		executed, err := executor.RunDue(ctx, expiring)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(executed).To(Equal(0))
+		Expect(countExpired()).To(Equal(0))
+
+		clock.Advance(15 * time.Minute)
+		executed, err = executor.RunDue(ctx, expiring)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(executed).To(Equal(1))
+		Expect(countExpired()).To(Equal(1))
+
+		var done bool
+		err = pool.QueryRow(ctx, `SELECT done_at = $2 AND done_transaction_id IS NOT NULL FROM dcb_scheduled_commands WHERE id = $1`,
+			id, clock.Now()).Scan(&done)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(done).To(BeTrue())
+
+		executed, err = executor.RunDue(ctx, expiring)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(executed).To(Equal(0))
+		Expect(countExpired()).To(Equal(1))
+	})
+
+	It("should keep a failed command pending and retry it on a later run", func() {
+		id, err := executor.ScheduleCommand(ctx, expireBooking("b1"), clock.Now())
+		Expect(err).NotTo(HaveOccurred())
+
+		failing := dcb.CommandHandlerFunc(func(ctx context.Context, store dcb.EventStore, command dcb.Command) ([]dcb.InputEvent, any, error) {
+			return nil, nil, errors.New("booking service unavailable")
+		})
+		executed, err := executor.RunDue(ctx, failing)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(executed).To(Equal(0))
+
+		var (
+			attempts  int
+			lastError string
+		)
+		err = pool.QueryRow(ctx, `SELECT attempts, last_error FROM dcb_scheduled_commands WHERE id = $1 AND done_at IS NULL`, id).
+			Scan(&attempts, &lastError)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(attempts).To(Equal(1))
+		Expect(lastError).To(ContainSubstring("booking service unavailable"))
+
+		executed, err = executor.RunDue(ctx, expiring)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(executed).To(Equal(1))
+		Expect(countExpired()).To(Equal(1))
+	})
+
+	It("should move a command to dcb_failed_commands after MaxScheduledAttempts failures", func() {
+		_, err := pool.Exec(ctx, "TRUNCATE TABLE dcb_failed_commands RESTART IDENTITY")
+		Expect(err).NotTo(HaveOccurred())
+		config := store.GetConfig()
+		config.Clock = clock
+		clockStore, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+		capped := dcb.NewCommandExecutorWithConfig(clockStore, dcb.CommandExecutorConfig{MaxScheduledAttempts: 2})
+
+		id, err := capped.ScheduleCommand(ctx, expireBooking("b1"), clock.Now())
+		Expect(err).NotTo(HaveOccurred())
+
+		calls := 0
+		failing := dcb.CommandHandlerFunc(func(ctx context.Context, store dcb.EventStore, command dcb.Command) ([]dcb.InputEvent, any, error) {
+			calls++
+			return nil, nil, errors.New("booking service unavailable")
+		})
+		for range 3 {
+			executed, err := capped.RunDue(ctx, failing)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(executed).To(Equal(0))
+		}
+		Expect(calls).To(Equal(2))
+
+		var failedID int64
+		err = pool.QueryRow(ctx, `SELECT failed_command_id FROM dcb_scheduled_commands WHERE id = $1 AND done_at IS NOT NULL`, id).
+			Scan(&failedID)
+		Expect(err).NotTo(HaveOccurred())
+		var (
+			attempts  int
+			lastError string
+		)
+		err = pool.QueryRow(ctx, `SELECT attempts, error FROM dcb_failed_commands WHERE id = $1`, failedID).Scan(&attempts, &lastError)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(attempts).To(Equal(2))
+		Expect(lastError).To(ContainSubstring("booking service unavailable"))
+
+		result, err := capped.RetryFailedCommand(ctx, failedID, expiring, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.TransactionID).NotTo(BeZero())
+		Expect(countExpired()).To(Equal(1))
+	})
+
+	It("should execute each command once across concurrent workers", func() {
+		const commands, workers = 20, 4
+		for i := range commands {
+			_, err := executor.ScheduleCommand(ctx, expireBooking(fmt.Sprintf("b%d", i)), clock.Now())
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		var wg sync.WaitGroup
+		executed := make([]int, workers)
+		errs := make([]error, workers)
+		for w := range workers {
+			wg.Go(func() {
+				executed[w], errs[w] = executor.RunDue(ctx, expiring)
+			})
+		}
+		wg.Wait()
+
+		total := 0
+		for w := range workers {
+			Expect(errs[w]).NotTo(HaveOccurred())
+			total += executed[w]
+		}
+		Expect(total).To(Equal(commands))
+		Expect(countExpired()).To(Equal(commands))
+	})
+})
@@ -0,0 +1,76 @@
+package dcb
+
+import (
+	"context"
+	"time"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SubscribeCommands", func() {
+	var executor dcb.CommandExecutor
+
+	handler := dcb.CommandHandlerFunc(func(ctx context.Context, store dcb.EventStore, command dcb.Command) ([]dcb.InputEvent, any, error) {
+		return []dcb.InputEvent{dcb.NewInputEvent("AccountOpened", dcb.NewTags("account", "a1"), command.GetData())}, nil, nil
+	})
+	execute := func(commandType string) dcb.CommandResult {
+		result, err := executor.ExecuteCommand(ctx, dcb.NewCommand(commandType, []byte(`{}`), nil), handler, nil)
+		Expect(err).NotTo(HaveOccurred())
+		return result
+	}
+	receive := func(commands <-chan dcb.StoredCommand) dcb.StoredCommand {
+		var command dcb.StoredCommand
+		Eventually(commands, 5*time.Second).Should(Receive(&command))
+		return command
+	}
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+		executor = dcb.NewCommandExecutor(store)
+	})
+
+	It("should replay the commands after the given transaction and then deliver new ones", func() {
+		first := execute("OpenAccount")
+		second := execute("DepositMoney")
+
+		subCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		commands, err := executor.SubscribeCommands(subCtx, first.TransactionID)
+		Expect(err).NotTo(HaveOccurred())
+
+		replayed := receive(commands)
+		Expect(replayed.Type).To(Equal("DepositMoney"))
+		Expect(replayed.TransactionID).To(Equal(second.TransactionID))
+		Expect(replayed.Data).To(MatchJSON(`{}`))
+		Consistently(commands, 100*time.Millisecond).ShouldNot(Receive())
+
+		third := execute("WithdrawMoney")
+		live := receive(commands)
+		Expect(live.Type).To(Equal("WithdrawMoney"))
+		Expect(live.TransactionID).To(Equal(third.TransactionID))
+	})
+
+	It("should deliver the commands of a notifying executor", func() {
+		executor = dcb.NewCommandExecutorWithConfig(store, dcb.CommandExecutorConfig{NotifyCommands: true})
+		subCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		commands, err := executor.SubscribeCommands(subCtx, execute("OpenAccount").TransactionID)
+		Expect(err).NotTo(HaveOccurred())
+
+		live := execute("DepositMoney")
+		Expect(receive(commands).TransactionID).To(Equal(live.TransactionID))
+	})
+
+	It("should close the channel when the context is cancelled", func() {
+		subCtx, cancel := context.WithCancel(ctx)
+		commands, err := executor.SubscribeCommands(subCtx, execute("OpenAccount").TransactionID)
+		Expect(err).NotTo(HaveOccurred())
+
+		cancel()
+		Eventually(commands, 5*time.Second).Should(BeClosed())
+	})
+})
@@ -0,0 +1,68 @@
+package dcb
+
+import (
+	"context"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Command traceability", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	deposited := func(accountID string) dcb.InputEvent {
+		return dcb.NewInputEvent("Deposited", dcb.NewTags("account_id", accountID), []byte(`{}`))
+	}
+
+	It("should stamp the events a command produced", func() {
+		Expect(store.Append(ctx, []dcb.InputEvent{deposited("a0")})).To(Succeed())
+
+		result, err := dcb.NewCommandExecutor(store).ExecuteCommand(ctx, dcb.NewCommand("Deposit", []byte(`{}`), nil),
+			dcb.CommandHandlerFunc(func(ctx context.Context, store dcb.EventStore, command dcb.Command) ([]dcb.InputEvent, any, error) {
+				return []dcb.InputEvent{deposited("a1"), deposited("a2")}, nil, nil
+			}), nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		// ReadByTransaction doubles as "events produced by this command"
+		events, err := store.ReadByTransaction(ctx, result.TransactionID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+		for _, event := range events {
+			id, ok := event.CommandTransactionID()
+			Expect(ok).To(BeTrue())
+			Expect(id).To(Equal(result.TransactionID))
+			commandType, _ := event.CommandType()
+			Expect(commandType).To(Equal("Deposit"))
+		}
+
+		var commandType string
+		err = pool.QueryRow(ctx, "SELECT type FROM commands WHERE transaction_id = $1::xid8", result.TransactionID).Scan(&commandType)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(commandType).To(Equal("Deposit"))
+
+		// Events appended without a command are not stamped
+		plain, err := store.ReadByPositions(ctx, []int64{1})
+		Expect(err).NotTo(HaveOccurred())
+		_, ok := plain[0].CommandTransactionID()
+		Expect(ok).To(BeFalse())
+
+		var linked int
+		err = pool.QueryRow(ctx, "SELECT count(*) FROM events WHERE command_transaction_id = $1::xid8", result.TransactionID).Scan(&linked)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(linked).To(Equal(2))
+	})
+
+	It("should reject plain appends that claim to come from a command", func() {
+		forged := dcb.NewEvent("Deposited").
+			WithTag("account_id", "a1").
+			WithData(map[string]any{}).
+			WithMetadata(dcb.MetadataCommandType, "Deposit").
+			Build()
+		Expect(dcb.IsValidationError(store.Append(ctx, []dcb.InputEvent{forged}))).To(BeTrue())
+	})
+})
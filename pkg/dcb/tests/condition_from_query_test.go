@@ -0,0 +1,67 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ConditionFromQuery", func() {
+	query := dcb.NewQuery(dcb.NewTags("course_id", "c1"), "SeatReserved")
+	reserve := func(courseID string) []dcb.InputEvent {
+		return []dcb.InputEvent{dcb.NewInputEvent("SeatReserved", dcb.NewTags("course_id", courseID), []byte(`{}`))}
+	}
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should fail on any matching event when none existed", func() {
+		condition, err := store.ConditionFromQuery(ctx, query)
+		Expect(err).NotTo(HaveOccurred())
+		_, hasCursor := condition.AfterPosition()
+		Expect(hasCursor).To(BeFalse())
+
+		Expect(store.AppendIf(ctx, reserve("c1"), condition)).To(Succeed())
+		Expect(dcb.IsConcurrencyError(store.AppendIf(ctx, reserve("c1"), condition))).To(BeTrue())
+	})
+
+	It("should capture the latest matching event", func() {
+		Expect(store.Append(ctx, reserve("c1"))).To(Succeed())
+		Expect(store.Append(ctx, reserve("c2"))).To(Succeed())
+
+		condition, err := store.ConditionFromQuery(ctx, query)
+		Expect(err).NotTo(HaveOccurred())
+		after, ok := condition.AfterPosition()
+		Expect(ok).To(BeTrue())
+		Expect(after).To(Equal(int64(1)))
+
+		// Unrelated events don't conflict; a matching one appended since the snapshot does
+		Expect(store.Append(ctx, reserve("c2"))).To(Succeed())
+		Expect(store.AppendIf(ctx, reserve("c1"), condition)).To(Succeed())
+		Expect(dcb.IsConcurrencyError(store.AppendIf(ctx, reserve("c1"), condition))).To(BeTrue())
+	})
+
+	It("should match the condition Project returns", func() {
+		Expect(store.Append(ctx, reserve("c1"))).To(Succeed())
+		Expect(store.Append(ctx, reserve("c1"))).To(Succeed())
+
+		projector := dcb.StateProjector{
+			ID:           "seats",
+			Query:        query,
+			InitialState: 0,
+			TransitionFn: func(state any, _ dcb.Event) any { return state.(int) + 1 },
+		}
+		_, projected, err := store.Project(ctx, []dcb.StateProjector{projector}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		condition, err := store.ConditionFromQuery(ctx, query)
+		Expect(err).NotTo(HaveOccurred())
+		after, _ := condition.AfterPosition()
+		projectedAfter, _ := projected.AfterPosition()
+		Expect(after).To(Equal(projectedAfter))
+		Expect(after).To(Equal(int64(2)))
+	})
+})
@@ -0,0 +1,85 @@
+package dcb
+
+import (
+	"context"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Empty appends", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	noEvents := dcb.CommandHandlerFunc(func(ctx context.Context, store dcb.EventStore, command dcb.Command) ([]dcb.InputEvent, any, error) {
+		return nil, "nothing to do", nil
+	})
+
+	Context("by default", func() {
+		It("should reject an empty slice on every append path", func() {
+			Expect(store.Append(ctx, []dcb.InputEvent{})).To(MatchError(dcb.ErrValidation))
+			Expect(store.AppendIf(ctx, nil, nil)).To(MatchError(dcb.ErrValidation))
+
+			_, _, _, err := store.AppendAndProject(ctx, nil, nil, []dcb.StateProjector{
+				dcb.NewExistsProjector("any", dcb.NewQueryAll()),
+			})
+			Expect(err).To(MatchError(dcb.ErrValidation))
+
+			_, err = dcb.NewCommandExecutor(store).ExecuteCommand(ctx, dcb.NewCommand("noop", []byte(`{}`), nil), noEvents, nil)
+			Expect(err).To(MatchError(dcb.ErrValidation))
+		})
+	})
+
+	Context("with AllowEmptyAppend", func() {
+		var lenient dcb.EventStore
+
+		BeforeEach(func() {
+			config := store.GetConfig()
+			config.AllowEmptyAppend = true
+			var err error
+			lenient, err = dcb.NewEventStoreWithConfig(ctx, pool, config)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = lenient.Append(ctx, []dcb.InputEvent{
+				dcb.NewInputEvent("CourseDefined", dcb.NewTags("course_id", "c1"), []byte(`{}`)),
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should succeed without writing or checking the condition", func() {
+			Expect(lenient.Append(ctx, []dcb.InputEvent{})).To(Succeed())
+
+			// The condition would fail if it were evaluated
+			condition := dcb.NewAppendCondition(dcb.NewQuery(dcb.NewTags("course_id", "c1"), "CourseDefined"))
+			Expect(lenient.AppendIf(ctx, nil, condition)).To(Succeed())
+
+			events, err := lenient.Query(ctx, dcb.NewQueryAll(), nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(1))
+		})
+
+		It("should still project in AppendAndProject", func() {
+			states, _, positions, err := lenient.AppendAndProject(ctx, nil, nil, []dcb.StateProjector{
+				dcb.NewExistsProjector("course", dcb.NewQuery(dcb.NewTags("course_id", "c1"), "CourseDefined")),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(positions).To(BeEmpty())
+			Expect(states["course"]).To(BeTrue())
+		})
+
+		It("should return the handler output without storing the command", func() {
+			result, err := dcb.NewCommandExecutor(lenient).ExecuteCommand(ctx, dcb.NewCommand("noop", []byte(`{}`), nil), noEvents, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Events).To(BeEmpty())
+			Expect(result.Output).To(Equal("nothing to do"))
+
+			var commands int
+			Expect(pool.QueryRow(ctx, `SELECT COUNT(*) FROM commands WHERE type = 'noop'`).Scan(&commands)).To(Succeed())
+			Expect(commands).To(Equal(0))
+		})
+	})
+})
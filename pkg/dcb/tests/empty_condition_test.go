@@ -0,0 +1,53 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AppendIf with an empty condition", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+
+		// An existing event that any non-empty condition on course c1 would match
+		err = store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("CourseDefined", dcb.NewTags("course_id", "c1"), []byte(`{}`)),
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	newEvent := func() []dcb.InputEvent {
+		return []dcb.InputEvent{dcb.NewInputEvent("CourseDefined", dcb.NewTags("course_id", "c1"), []byte(`{}`))}
+	}
+
+	It("should append unconditionally with a nil condition", func() {
+		Expect(store.AppendIf(ctx, newEvent(), nil)).To(Succeed())
+
+		events, err := store.Query(ctx, dcb.NewQueryAll(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+	})
+
+	It("should append unconditionally with an empty-query condition", func() {
+		for _, condition := range []dcb.AppendCondition{
+			dcb.NewAppendCondition(nil),
+			dcb.NewAppendCondition(dcb.NewQueryEmpty()),
+		} {
+			Expect(condition.IsEmpty()).To(BeTrue())
+			Expect(store.AppendIf(ctx, newEvent(), condition)).To(Succeed())
+		}
+
+		events, err := store.Query(ctx, dcb.NewQueryAll(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(3))
+	})
+
+	It("should still enforce non-empty conditions", func() {
+		condition := dcb.NewAppendCondition(dcb.NewQuery(dcb.NewTags("course_id", "c1"), "CourseDefined"))
+		Expect(condition.IsEmpty()).To(BeFalse())
+		Expect(store.AppendIf(ctx, newEvent(), condition)).To(MatchError(dcb.ErrConcurrency))
+	})
+})
@@ -0,0 +1,46 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EstimateConditionCost", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("SeatReserved", dcb.NewTags("concert_id", "c1", "seat", "A1"), []byte(`{}`)),
+			dcb.NewInputEvent("SeatReserved", dcb.NewTags("concert_id", "c1", "seat", "A2"), []byte(`{}`)),
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should estimate the check of a tag condition", func() {
+		condition := dcb.NewAppendCondition(dcb.NewQuery(dcb.NewTags("concert_id", "c1", "seat", "A1"), "SeatReserved"))
+		estimate, err := store.EstimateConditionCost(ctx, condition)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(estimate.TotalCost).To(BeNumerically(">", 0))
+		Expect(estimate.UsesIndex || estimate.SequentialScan).To(BeTrue())
+	})
+
+	It("should estimate conditions with an after cursor", func() {
+		_, condition, err := store.Project(ctx, []dcb.StateProjector{
+			dcb.NewExistsProjector("reserved", dcb.NewQuery(dcb.NewTags("seat", "A1"), "SeatReserved")),
+		}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		estimate, err := store.EstimateConditionCost(ctx, condition)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(estimate.TotalCost).To(BeNumerically(">", 0))
+	})
+
+	It("should return a zero estimate for an empty condition", func() {
+		estimate, err := store.EstimateConditionCost(ctx, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(estimate).To(Equal(dcb.CostEstimate{}))
+	})
+})
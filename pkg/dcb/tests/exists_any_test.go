@@ -0,0 +1,43 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExistsAny", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should report which values already have an event of the given type", func() {
+		err := store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("UserCreated", dcb.NewTags("user_id", "u1", "email", "a@x.io"), []byte(`{}`)),
+			dcb.NewInputEvent("UserCreated", dcb.NewTags("user_id", "u2"), []byte(`{}`)),
+			dcb.NewInputEvent("UserDeleted", dcb.NewTags("user_id", "u3"), []byte(`{}`)),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		exists, err := store.ExistsAny(ctx, "UserCreated", "user_id", []string{"u1", "u2", "u3", "u4"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exists).To(Equal(map[string]bool{"u1": true, "u2": true, "u3": false, "u4": false}))
+
+		exists, err = store.ExistsAny(ctx, "", "user_id", []string{"u3"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exists["u3"]).To(BeTrue())
+	})
+
+	It("should return an empty map for no values", func() {
+		exists, err := store.ExistsAny(ctx, "UserCreated", "user_id", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exists).To(BeEmpty())
+	})
+
+	It("should reject an empty tag key", func() {
+		_, err := store.ExistsAny(ctx, "UserCreated", "", []string{"u1"})
+		Expect(dcb.IsValidationError(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,86 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Early-stopping projectors", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should answer existence checks with NewExistsProjector", func() {
+		err := store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("UserCreated", dcb.NewTags("user_id", "u1"), []byte(`{}`)),
+			dcb.NewInputEvent("UserCreated", dcb.NewTags("user_id", "u1"), []byte(`{}`)),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		states, condition, err := store.Project(ctx, []dcb.StateProjector{
+			dcb.NewExistsProjector("userExists", dcb.NewQuery(dcb.NewTags("user_id", "u1"), "UserCreated")),
+		}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(states["userExists"]).To(BeTrue())
+		_, hasCursor := condition.AfterPosition()
+		Expect(hasCursor).To(BeTrue())
+
+		states, condition, err = store.Project(ctx, []dcb.StateProjector{
+			dcb.NewExistsProjector("userExists", dcb.NewQuery(dcb.NewTags("user_id", "u2"), "UserCreated")),
+		}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(states["userExists"]).To(BeFalse())
+
+		// Nothing matched, so the condition guards the decision like a full projection
+		err = store.AppendIf(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("UserCreated", dcb.NewTags("user_id", "u2"), []byte(`{}`)),
+		}, condition)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should stop folding a projector once StopFn holds", func() {
+		events := make([]dcb.InputEvent, 0, 5)
+		for i := 0; i < 5; i++ {
+			events = append(events, dcb.NewInputEvent("SeatReserved", dcb.NewTags("concert_id", "c1"), []byte(`{}`)))
+		}
+		Expect(store.Append(ctx, events)).To(Succeed())
+
+		atLeastTwo := dcb.StateProjector{
+			ID:           "reservedAtLeastTwo",
+			Query:        dcb.NewQuery(dcb.NewTags("concert_id", "c1"), "SeatReserved"),
+			InitialState: 0,
+			TransitionFn: func(state any, event dcb.Event) any { return state.(int) + 1 },
+			StopFn:       func(state any) bool { return state.(int) >= 2 },
+		}
+		states, condition, err := store.Project(ctx, []dcb.StateProjector{atLeastTwo}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(states["reservedAtLeastTwo"]).To(Equal(2))
+
+		// The rows left unread don't fail the condition, events appended after the projection do
+		reserved := []dcb.InputEvent{dcb.NewInputEvent("SeatReserved", dcb.NewTags("concert_id", "c1"), []byte(`{}`))}
+		Expect(store.AppendIf(ctx, reserved, condition)).To(Succeed())
+		err = store.AppendIf(ctx, reserved, condition)
+		Expect(dcb.IsConcurrencyError(err)).To(BeTrue())
+	})
+
+	It("should cover the whole query in the condition of an existence check", func() {
+		err := store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("UserCreated", dcb.NewTags("user_id", "u1"), []byte(`{}`)),
+			dcb.NewInputEvent("UserCreated", dcb.NewTags("user_id", "u1"), []byte(`{}`)),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		exists := dcb.NewExistsProjector("userExists", dcb.NewQuery(dcb.NewTags("user_id", "u1"), "UserCreated"))
+		_, condition, err := store.Project(ctx, []dcb.StateProjector{exists}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		events, err := store.Query(ctx, dcb.NewQuery(dcb.NewTags("user_id", "u1"), "UserCreated"), nil)
+		Expect(err).NotTo(HaveOccurred())
+		position, hasCursor := condition.AfterPosition()
+		Expect(hasCursor).To(BeTrue())
+		Expect(position).To(Equal(events[len(events)-1].Position))
+	})
+})
@@ -0,0 +1,106 @@
+package dcb
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FailIfAnyExists", func() {
+	register := func(studentID, email string) []dcb.InputEvent {
+		return []dcb.InputEvent{
+			dcb.NewInputEvent("StudentRegistered", dcb.NewTags("student_id", studentID, "email", email), []byte(`{}`)),
+		}
+	}
+	unique := func(studentID, email string) dcb.AppendCondition {
+		return dcb.FailIfAnyExists(
+			dcb.TagMatch{Type: "StudentRegistered", Key: "student_id", Value: studentID},
+			dcb.TagMatch{Type: "StudentRegistered", Key: "email", Value: email},
+		)
+	}
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should conflict when any unique field is taken", func() {
+		_, err := store.AppendIfNotExists(ctx, register("s1", "ann@example.com"), unique("s1", "ann@example.com"), dcb.OnConflictError)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Same email, new student ID
+		_, err = store.AppendIfNotExists(ctx, register("s2", "ann@example.com"), unique("s2", "ann@example.com"), dcb.OnConflictError)
+		Expect(dcb.IsConcurrencyError(err)).To(BeTrue())
+
+		// Same student ID, new email
+		_, err = store.AppendIfNotExists(ctx, register("s1", "bob@example.com"), unique("s1", "bob@example.com"), dcb.OnConflictError)
+		Expect(dcb.IsConcurrencyError(err)).To(BeTrue())
+
+		// Neither taken
+		result, err := store.AppendIfNotExists(ctx, register("s3", "cat@example.com"), unique("s3", "cat@example.com"), dcb.OnConflictError)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Created).To(BeTrue())
+	})
+
+	It("should be rejected by AppendIf, which would only fail on an event matching every field", func() {
+		err := store.AppendIf(ctx, register("s1", "ann@example.com"), unique("s1", "ann@example.com"))
+		Expect(dcb.IsValidationError(err)).To(BeTrue())
+
+		events, err := store.Query(ctx, dcb.NewQueryBuilder().WithType("StudentRegistered").Build(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(BeEmpty())
+	})
+
+	It("should return the existing condition on an ignored conflict", func() {
+		_, err := store.AppendIfNotExists(ctx, register("s1", "ann@example.com"), unique("s1", "ann@example.com"), dcb.OnConflictError)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := store.AppendIfNotExists(ctx, register("s2", "ann@example.com"), unique("s2", "ann@example.com"), dcb.OnConflictIgnore)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Created).To(BeFalse())
+		after, ok := result.Existing.AfterPosition()
+		Expect(ok).To(BeTrue())
+		Expect(after).To(Equal(int64(1)))
+	})
+
+	It("should let exactly one of concurrent conflicting registrations append", func() {
+		// Every registration uses a different student ID; pairs share an email
+		const registrations = 10
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		errs := make([]error, registrations)
+		for i := range registrations {
+			wg.Go(func() {
+				<-start
+				studentID := fmt.Sprintf("s%d", i)
+				email := fmt.Sprintf("student%d@example.com", i/2)
+				_, errs[i] = store.AppendIfNotExists(ctx, register(studentID, email), unique(studentID, email), dcb.OnConflictError)
+			})
+		}
+		close(start)
+		wg.Wait()
+
+		created := 0
+		for i, err := range errs {
+			switch {
+			case err == nil:
+				created++
+			case dcb.IsConcurrencyError(err):
+			default:
+				Fail(fmt.Sprintf("unexpected error for registration %d: %v", i, err))
+			}
+		}
+		Expect(created).To(Equal(registrations / 2))
+
+		for pair := range registrations / 2 {
+			email := fmt.Sprintf("student%d@example.com", pair)
+			events, err := store.Query(ctx, dcb.NewQuery(dcb.NewTags("email", email), "StudentRegistered"), nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(1))
+		}
+	})
+})
@@ -0,0 +1,48 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("QueryGrouped", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should deliver events grouped by tag value in position order", func() {
+		err := store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("OrderPlaced", dcb.NewTags("customer_id", "c2", "order_id", "o1"), []byte(`{}`)),
+			dcb.NewInputEvent("OrderPlaced", dcb.NewTags("customer_id", "c1", "order_id", "o2"), []byte(`{}`)),
+			dcb.NewInputEvent("OrderShipped", dcb.NewTags("customer_id", "c2", "order_id", "o1"), []byte(`{}`)),
+			dcb.NewInputEvent("OrderPlaced", dcb.NewTags("order_id", "o3"), []byte(`{}`)),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		query := dcb.NewQueryFromItems(dcb.NewQueryItem([]string{"OrderPlaced", "OrderShipped"}, nil))
+		groups, err := store.QueryGrouped(ctx, query, "customer_id")
+		Expect(err).NotTo(HaveOccurred())
+
+		var received []dcb.EventGroup
+		for group := range groups {
+			received = append(received, group)
+		}
+
+		Expect(received).To(HaveLen(2))
+		Expect(received[0].TagValue).To(Equal("c1"))
+		Expect(received[0].Events).To(HaveLen(1))
+		Expect(received[1].TagValue).To(Equal("c2"))
+		Expect(received[1].Events).To(HaveLen(2))
+		Expect(received[1].Events[0].Type).To(Equal("OrderPlaced"))
+		Expect(received[1].Events[1].Type).To(Equal("OrderShipped"))
+		Expect(received[1].Events[0].Position).To(BeNumerically("<", received[1].Events[1].Position))
+	})
+
+	It("should reject an empty group tag key", func() {
+		_, err := store.QueryGrouped(ctx, dcb.NewQueryAll(), "")
+		Expect(err).To(MatchError(dcb.ErrValidation))
+	})
+})
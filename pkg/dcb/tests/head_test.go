@@ -0,0 +1,61 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Head", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should return 0 for an empty store", func() {
+		head, err := store.Head(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(head).To(Equal(int64(0)))
+	})
+
+	It("should return the position of the last committed event", func() {
+		err := store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("CourseDefined", dcb.NewTags("course_id", "c1"), []byte(`{}`)),
+			dcb.NewInputEvent("CourseDefined", dcb.NewTags("course_id", "c2"), []byte(`{}`)),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		events, err := store.Query(ctx, dcb.NewQueryAll(), nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		head, err := store.Head(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(head).To(Equal(events[len(events)-1].Position))
+	})
+
+	It("should not report positions of uncommitted appends", func() {
+		Expect(store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("CourseDefined", dcb.NewTags("course_id", "c1"), []byte(`{}`)),
+		})).To(Succeed())
+		before, err := store.Head(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = store.WithTransaction(ctx, func(txStore dcb.EventStore) error {
+			if err := txStore.Append(ctx, []dcb.InputEvent{
+				dcb.NewInputEvent("CourseDefined", dcb.NewTags("course_id", "c2"), []byte(`{}`)),
+			}); err != nil {
+				return err
+			}
+			head, err := store.Head(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(head).To(Equal(before))
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		after, err := store.Head(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(after).To(BeNumerically(">", before))
+	})
+})
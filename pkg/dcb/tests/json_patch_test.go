@@ -0,0 +1,50 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JSON Patch events", func() {
+	tags := dcb.NewTags("profile_id", "p1")
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should rebuild a document from its creation event and patches", func() {
+		Expect(store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("ProfileCreated", tags, []byte(`{"name":"Ada","address":{"city":"London"}}`)),
+			dcb.NewJSONPatchEvent("ProfileUpdated", tags, []byte(`[{"op":"replace","path":"/address/city","value":"Paris"}]`)),
+		})).To(Succeed())
+		Expect(store.Append(ctx, []dcb.InputEvent{
+			dcb.NewJSONPatchEvent("ProfileUpdated", tags, []byte(`[{"op":"add","path":"/title","value":"Countess"},{"op":"remove","path":"/address"}]`)),
+		})).To(Succeed())
+
+		projector := dcb.ProjectJSONPatch("profile", dcb.NewQuery(tags), nil)
+		states, _, err := store.Project(ctx, []dcb.StateProjector{projector}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		document := states["profile"].(dcb.JSONDocument)
+		Expect(document.Err).NotTo(HaveOccurred())
+		Expect(document.Document).To(MatchJSON(`{"name":"Ada","title":"Countess"}`))
+
+		events, err := store.Query(ctx, dcb.NewQueryBuilder().WithType(dcb.JSONPatchType("ProfileUpdated")).Build(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+	})
+
+	It("should reject a malformed patch on append", func() {
+		err := store.Append(ctx, []dcb.InputEvent{
+			dcb.NewJSONPatchEvent("ProfileUpdated", tags, []byte(`[{"op":"rename","path":"/name"}]`)),
+		})
+		Expect(dcb.IsValidationError(err)).To(BeTrue())
+
+		events, err := store.Query(ctx, dcb.NewQuery(tags), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(BeEmpty())
+	})
+})
@@ -0,0 +1,56 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Latest", func() {
+	type addressChanged struct {
+		City string `json:"city"`
+	}
+	query := dcb.NewQuery(dcb.NewTags("customer_id", "c1"), "AddressChanged")
+	moveTo := func(customerID, city string) []dcb.InputEvent {
+		return []dcb.InputEvent{dcb.NewInputEvent("AddressChanged", dcb.NewTags("customer_id", customerID), []byte(`{"city": "`+city+`"}`))}
+	}
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should report no event when none matches", func() {
+		Expect(store.Append(ctx, moveTo("c2", "Lisbon"))).To(Succeed())
+
+		_, ok, err := store.Latest(ctx, query)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should return the last matching event", func() {
+		Expect(store.Append(ctx, moveTo("c1", "Lisbon"))).To(Succeed())
+		Expect(store.Append(ctx, moveTo("c1", "Porto"))).To(Succeed())
+		Expect(store.Append(ctx, moveTo("c2", "Faro"))).To(Succeed())
+
+		event, ok, err := store.Latest(ctx, query)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(event.Position).To(Equal(int64(2)))
+
+		events, err := store.Query(ctx, query, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event.Position).To(Equal(events[len(events)-1].Position))
+	})
+
+	It("should decode the latest event with LatestTyped", func() {
+		Expect(store.Append(ctx, moveTo("c1", "Lisbon"))).To(Succeed())
+		Expect(store.Append(ctx, moveTo("c1", "Porto"))).To(Succeed())
+
+		address, ok, err := dcb.LatestTyped[addressChanged](ctx, store, query)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(address.City).To(Equal("Porto"))
+	})
+})
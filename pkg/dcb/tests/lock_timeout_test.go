@@ -0,0 +1,67 @@
+package dcb
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Lock timeout", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	// holdEventsLock holds a lock that conflicts with inserts into events until the test ends
+	holdEventsLock := func() {
+		lockTx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() { _ = lockTx.Rollback(ctx) })
+		_, err = lockTx.Exec(ctx, "LOCK TABLE events IN EXCLUSIVE MODE")
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	// AppendIfNotExists takes an advisory lock, so its lock waits are bounded
+	locked := []dcb.InputEvent{dcb.NewInputEvent("Locked", dcb.NewTags("test", "lock"), []byte(`{}`))}
+
+	expectLockTimeout := func(err error) {
+		resourceErr, ok := dcb.GetResourceError(err)
+		Expect(ok).To(BeTrue(), "expected a ResourceError, got %v", err)
+		Expect(resourceErr.Resource).To(Equal("lock"))
+	}
+
+	It("should fail fast when the caller's deadline is shorter than the configured lock timeout", func() {
+		config := store.GetConfig()
+		config.LockTimeout = 5000
+		lockStore, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+		holdEventsLock()
+
+		shortCtx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err = lockStore.AppendIfNotExists(shortCtx, locked, dcb.FailIfExists("test", "lock"), dcb.OnConflictError)
+		expectLockTimeout(err)
+		Expect(time.Since(start)).To(BeNumerically("<", 2*time.Second))
+	})
+
+	It("should give up after the configured lock timeout when the context has no deadline", func() {
+		config := store.GetConfig()
+		config.LockTimeout = 200
+		lockStore, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+		holdEventsLock()
+
+		start := time.Now()
+		_, err = lockStore.AppendIfNotExists(context.Background(), locked, dcb.FailIfExists("test", "lock"), dcb.OnConflictError)
+		expectLockTimeout(err)
+		Expect(time.Since(start)).To(BeNumerically(">=", 200*time.Millisecond))
+		Expect(time.Since(start)).To(BeNumerically("<", 2*time.Second))
+	})
+})
@@ -0,0 +1,29 @@
+package dcb
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MissingIndexes", func() {
+	AfterEach(func() {
+		_, err := pool.Exec(ctx, "DROP INDEX IF EXISTS idx_events_type_transaction_position")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should report the per-type index the default schema lacks and create it on request", func() {
+		statements, err := store.MissingIndexes(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statements).To(Equal([]string{
+			"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_events_type_transaction_position ON events (type, transaction_id, position)",
+		}))
+
+		created, err := store.CreateMissingIndexes(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(Equal(statements))
+
+		statements, err = store.MissingIndexes(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statements).To(BeEmpty())
+	})
+})
@@ -0,0 +1,51 @@
+package dcb
+
+import (
+	"fmt"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Pager", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should page through matching events and resume from a saved cursor", func() {
+		for i := 0; i < 7; i++ {
+			err := store.Append(ctx, []dcb.InputEvent{
+				dcb.NewInputEvent("StudentRegistered", dcb.NewTags("student_id", fmt.Sprint(i)), []byte(`{}`)),
+				dcb.NewInputEvent("Unrelated", dcb.NewTags("student_id", fmt.Sprint(i)), []byte(`{}`)),
+			})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		query := dcb.NewQueryFromItems(dcb.NewQueryItem([]string{"StudentRegistered"}, nil))
+
+		pager := dcb.NewPager(store, query, 3)
+		first, more, err := pager.Next(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(first).To(HaveLen(3))
+		Expect(more).To(BeTrue())
+
+		// Simulate a restart: continue from the saved cursor with a new pager
+		resumed := dcb.NewPagerFrom(store, query, 3, pager.Cursor())
+		var rest []dcb.Event
+		for {
+			events, more, err := resumed.Next(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			rest = append(rest, events...)
+			if !more {
+				break
+			}
+		}
+		Expect(rest).To(HaveLen(4))
+		Expect(rest[0].Position).To(BeNumerically(">", first[2].Position))
+		for _, event := range rest {
+			Expect(event.Type).To(Equal("StudentRegistered"))
+		}
+	})
+})
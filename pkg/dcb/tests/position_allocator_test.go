@@ -0,0 +1,77 @@
+package dcb
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fixedPositionAllocator hands out predictable positions starting after next
+type fixedPositionAllocator struct {
+	next int64
+}
+
+func (a *fixedPositionAllocator) Allocate(ctx context.Context, tx pgx.Tx, n int) ([]int64, error) {
+	positions := make([]int64, n)
+	for i := range positions {
+		a.next++
+		positions[i] = a.next
+	}
+	return positions, nil
+}
+
+var _ = Describe("PositionAllocator", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should store the positions returned by a custom allocator", func() {
+		config := store.GetConfig()
+		config.PositionAllocator = &fixedPositionAllocator{next: 1_000_000}
+		allocStore, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = allocStore.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("First", dcb.NewTags("test", "alloc"), []byte(`{}`)),
+			dcb.NewInputEvent("Second", dcb.NewTags("test", "alloc"), []byte(`{}`)),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		condition := dcb.NewAppendCondition(dcb.NewQuery(dcb.NewTags("test", "other")))
+		err = allocStore.AppendIf(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("Third", dcb.NewTags("test", "alloc"), []byte(`{}`)),
+		}, condition)
+		Expect(err).NotTo(HaveOccurred())
+
+		events, err := allocStore.Query(ctx, dcb.NewQuery(dcb.NewTags("test", "alloc")), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(3))
+		Expect(events[0].Position).To(Equal(int64(1_000_001)))
+		Expect(events[1].Position).To(Equal(int64(1_000_002)))
+		Expect(events[2].Position).To(Equal(int64(1_000_003)))
+	})
+
+	It("should keep sequence positions in event order with the sequence allocator", func() {
+		config := store.GetConfig()
+		config.PositionAllocator = dcb.NewSequencePositionAllocator()
+		allocStore, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = allocStore.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("First", dcb.NewTags("test", "seq"), []byte(`{}`)),
+			dcb.NewInputEvent("Second", dcb.NewTags("test", "seq"), []byte(`{}`)),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		events, err := allocStore.Query(ctx, dcb.NewQuery(dcb.NewTags("test", "seq")), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].Type).To(Equal("First"))
+		Expect(events[0].Position).To(BeNumerically("<", events[1].Position))
+	})
+})
@@ -0,0 +1,62 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BetweenPositions", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should combine a position window with type and tag predicates", func() {
+		for i := 0; i < 5; i++ {
+			err := store.Append(ctx, []dcb.InputEvent{
+				dcb.NewInputEvent("CourseDefined", dcb.NewTags("course_id", "c1"), []byte(`{}`)),
+				dcb.NewInputEvent("StudentRegistered", dcb.NewTags("course_id", "c1"), []byte(`{}`)),
+			})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		all, err := store.Query(ctx, dcb.NewQueryAll(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(all).To(HaveLen(10))
+
+		from, to := all[2].Position, all[7].Position
+		query := dcb.NewQueryBuilder().
+			WithTag("course_id", "c1").
+			WithType("CourseDefined").
+			BetweenPositions(from, to).
+			Build()
+		events, err := store.Query(ctx, query, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(3))
+		for _, event := range events {
+			Expect(event.Type).To(Equal("CourseDefined"))
+			Expect(event.Position).To(BeNumerically(">=", from))
+			Expect(event.Position).To(BeNumerically("<=", to))
+		}
+
+		// Point-in-time projection: everything up to a position
+		counter := dcb.StateProjector{
+			ID:           "events_so_far",
+			Query:        dcb.NewQueryBuilder().BetweenPositions(all[0].Position, all[3].Position).Build(),
+			InitialState: 0,
+			TransitionFn: func(state any, event dcb.Event) any { return state.(int) + 1 },
+		}
+		states, _, err := store.Project(ctx, []dcb.StateProjector{counter}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(states["events_so_far"]).To(Equal(4))
+	})
+
+	It("should be rejected in append conditions", func() {
+		condition := dcb.NewAppendCondition(dcb.NewQueryBuilder().WithType("CourseDefined").BetweenPositions(1, 10).Build())
+		err := store.AppendIf(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("CourseDefined", dcb.NewTags("course_id", "c1"), []byte(`{}`)),
+		}, condition)
+		Expect(err).To(MatchError(dcb.ErrValidation))
+	})
+})
@@ -0,0 +1,100 @@
+package dcb
+
+import (
+	"encoding/json"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Producer tag", func() {
+	newProducerStore := func(asTag bool) dcb.EventStore {
+		config := store.GetConfig()
+		config.ProducerTag = "source:orders-service"
+		config.ProducerAsTag = asTag
+		producerStore, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+		return producerStore
+	}
+	metadataOf := func(event dcb.Event) map[string]any {
+		var metadata map[string]any
+		Expect(json.Unmarshal(event.Metadata, &metadata)).To(Succeed())
+		return metadata
+	}
+	orderPlaced := func() *dcb.EventBuilder {
+		return dcb.NewEvent("OrderPlaced").WithTag("order_id", "o1").WithData(map[string]string{"total": "10"})
+	}
+	query := dcb.NewQueryBuilder().WithType("OrderPlaced").Build()
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject a malformed producer tag", func() {
+		config := store.GetConfig()
+		config.ProducerTag = "orders-service"
+		_, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(dcb.IsValidationError(err)).To(BeTrue())
+	})
+
+	It("should store the producer in the metadata of every appended event", func() {
+		producerStore := newProducerStore(false)
+		source := dcb.NewEvent("PaymentReceived").WithTag("order_id", "o1").WithData(map[string]string{}).Build()
+		Expect(producerStore.Append(ctx, []dcb.InputEvent{source})).To(Succeed())
+		cause, err := producerStore.Query(ctx, dcb.NewQueryBuilder().WithType("PaymentReceived").Build(), nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(producerStore.AppendIf(ctx, []dcb.InputEvent{orderPlaced().CausedBy(cause[0]).Build()},
+			dcb.FailIfExists("order_id", "o2"))).To(Succeed())
+
+		events, err := producerStore.Query(ctx, query, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		metadata := metadataOf(events[0])
+		Expect(metadata).To(HaveKeyWithValue("source", "orders-service"))
+		Expect(metadata).To(HaveKey(dcb.MetadataCausationPosition))
+		Expect(events[0].Tags).To(HaveLen(1))
+	})
+
+	It("should keep metadata the caller set under the producer key", func() {
+		producerStore := newProducerStore(false)
+		event := orderPlaced().WithMetadata("source", "legacy-import").Build()
+		Expect(producerStore.Append(ctx, []dcb.InputEvent{event})).To(Succeed())
+
+		events, err := producerStore.Query(ctx, query, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(metadataOf(events[0])).To(HaveKeyWithValue("source", "legacy-import"))
+	})
+
+	It("should let one append override or drop the producer", func() {
+		producerStore := newProducerStore(false)
+		Expect(producerStore.Append(dcb.WithProducer(ctx, "source:billing-service"), []dcb.InputEvent{orderPlaced().Build()})).To(Succeed())
+		Expect(producerStore.Append(dcb.WithProducer(ctx, ""), []dcb.InputEvent{orderPlaced().Build()})).To(Succeed())
+
+		events, err := producerStore.Query(ctx, query, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+		Expect(metadataOf(events[0])).To(HaveKeyWithValue("source", "billing-service"))
+		Expect(events[1].Metadata).To(BeEmpty())
+	})
+
+	It("should add the producer as a queryable tag with ProducerAsTag", func() {
+		producerStore := newProducerStore(true)
+		Expect(producerStore.Append(ctx, []dcb.InputEvent{orderPlaced().Build()})).To(Succeed())
+		Expect(producerStore.Append(ctx, []dcb.InputEvent{orderPlaced().WithTag("source", "legacy-import").Build()})).To(Succeed())
+
+		events, err := producerStore.Query(ctx, dcb.NewQueryBuilder().WithTag("source", "orders-service").Build(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Position).To(Equal(int64(1)))
+		Expect(events[0].Metadata).To(BeEmpty())
+
+		legacy, err := producerStore.Query(ctx, dcb.NewQueryBuilder().WithTag("source", "legacy-import").Build(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(legacy).To(HaveLen(1))
+		Expect(legacy[0].Tags).To(HaveLen(2))
+	})
+})
@@ -0,0 +1,39 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ProjectCountByTag", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	enrolled := func(courseID, studentID string) dcb.InputEvent {
+		return dcb.NewInputEvent("StudentEnrolled", dcb.NewTags("course_id", courseID, "student_id", studentID), []byte(`{}`))
+	}
+
+	It("should count events per tag value in one projection", func() {
+		Expect(store.Append(ctx, []dcb.InputEvent{
+			enrolled("c1", "s1"),
+			enrolled("c1", "s2"),
+			enrolled("c2", "s1"),
+			dcb.NewInputEvent("StudentDropped", dcb.NewTags("course_id", "c1", "student_id", "s2"), []byte(`{}`)),
+		})).To(Succeed())
+
+		states, _, err := store.Project(ctx, []dcb.StateProjector{
+			dcb.ProjectCountByTag("enrollments", "StudentEnrolled", "course_id"),
+			dcb.ProjectCountByTagWithTypes("activity", []string{"StudentEnrolled", "StudentDropped"}, "course_id"),
+			dcb.ProjectCountByTag("none", "CourseCancelled", "course_id"),
+		}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(states["enrollments"]).To(Equal(map[string]int{"c1": 2, "c2": 1}))
+		Expect(states["activity"]).To(Equal(map[string]int{"c1": 3, "c2": 1}))
+		Expect(states["none"]).To(BeNil())
+	})
+})
@@ -0,0 +1,102 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ProjectMany", func() {
+	seats := func(courseID string) dcb.StateProjector {
+		return dcb.StateProjector{
+			ID:           "seats",
+			Query:        dcb.NewQuery(dcb.NewTags("course_id", courseID), "SeatReserved"),
+			InitialState: 0,
+			TransitionFn: func(state any, _ dcb.Event) any { return state.(int) + 1 },
+		}
+	}
+	reserve := func(courseID string) []dcb.InputEvent {
+		return []dcb.InputEvent{dcb.NewInputEvent("SeatReserved", dcb.NewTags("course_id", courseID), []byte(`{}`))}
+	}
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should return the result of each set in order", func() {
+		var sets [][]dcb.StateProjector
+		for i := range 10 {
+			courseID := fmt.Sprintf("c%d", i)
+			for range i {
+				Expect(store.Append(ctx, reserve(courseID))).To(Succeed())
+			}
+			sets = append(sets, []dcb.StateProjector{seats(courseID)})
+		}
+
+		results, err := store.ProjectMany(ctx, sets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(10))
+		for i, result := range results {
+			Expect(result.States["seats"]).To(Equal(i))
+
+			// Each condition guards its own set like the one Project returns
+			_, condition, err := store.Project(ctx, sets[i], nil)
+			Expect(err).NotTo(HaveOccurred())
+			got, gotOK := result.Condition.AfterPosition()
+			want, wantOK := condition.AfterPosition()
+			Expect(gotOK).To(Equal(wantOK))
+			Expect(got).To(Equal(want))
+		}
+	})
+
+	It("should project with a single worker", func() {
+		single, err := dcb.NewEventStoreWithConfig(ctx, pool, dcb.EventStoreConfig{ProjectionWorkers: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.Append(ctx, reserve("c1"))).To(Succeed())
+
+		results, err := single.ProjectMany(ctx, [][]dcb.StateProjector{{seats("c1")}, {seats("c2")}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results[0].States["seats"]).To(Equal(1))
+		Expect(results[1].States["seats"]).To(Equal(0))
+	})
+
+	It("should fail with the first error", func() {
+		bounded, err := dcb.NewEventStoreWithConfig(ctx, pool, dcb.EventStoreConfig{MaxProjectionStateBytes: 1024})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.Append(ctx, reserve("c1"))).To(Succeed())
+		failing := seats("c1")
+		failing.InitialState = ""
+		failing.TransitionFn = func(state any, _ dcb.Event) any { return state.(string) + strings.Repeat("x", 4096) }
+
+		results, err := bounded.ProjectMany(ctx, [][]dcb.StateProjector{{seats("c1")}, {failing}, {seats("c2")}})
+		Expect(dcb.IsResourceError(err)).To(BeTrue())
+		Expect(results).To(BeNil())
+	})
+
+	It("should fail when the context is cancelled", func() {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := store.ProjectMany(cancelled, [][]dcb.StateProjector{{seats("c1")}, {seats("c2")}})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should project the sets one at a time inside a transaction", func() {
+		Expect(store.Append(ctx, reserve("c1"))).To(Succeed())
+		err := store.WithTransaction(ctx, func(txStore dcb.EventStore) error {
+			results, err := txStore.ProjectMany(ctx, [][]dcb.StateProjector{{seats("c1")}, {seats("c2")}})
+			if err != nil {
+				return err
+			}
+			Expect(results[0].States["seats"]).To(Equal(1))
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
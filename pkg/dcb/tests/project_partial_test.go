@@ -0,0 +1,91 @@
+package dcb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Project with ReturnPartialOnCancel", func() {
+	const (
+		batches   = 5
+		batchSize = 1000
+		cancelAt  = 100
+	)
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Enough data that the rows cannot all be buffered before the cancellation is noticed
+		data := []byte(fmt.Sprintf(`{"padding":%q}`, strings.Repeat("x", 1024)))
+		for b := range batches {
+			events := make([]dcb.InputEvent, batchSize)
+			for i := range events {
+				events[i] = dcb.NewInputEvent("Viewed", dcb.NewTags("page", "home", "batch", fmt.Sprint(b)), data)
+			}
+			Expect(store.Append(ctx, events)).To(Succeed())
+		}
+	})
+
+	// counter cancels the projection's context once it has folded cancelAt events
+	counter := func(cancel context.CancelFunc) dcb.StateProjector {
+		return dcb.StateProjector{
+			ID:           "views",
+			Query:        dcb.NewQuery(dcb.NewTags("page", "home"), "Viewed"),
+			InitialState: 0,
+			TransitionFn: func(state any, event dcb.Event) any {
+				count := state.(int) + 1
+				if count == cancelAt {
+					cancel()
+				}
+				return count
+			},
+		}
+	}
+
+	It("should return the states folded so far with ErrPartial", func() {
+		config := store.GetConfig()
+		config.ReturnPartialOnCancel = true
+		partialStore, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+
+		projectCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		states, condition, err := partialStore.Project(projectCtx, []dcb.StateProjector{counter(cancel)}, nil)
+		Expect(errors.Is(err, dcb.ErrPartial)).To(BeTrue(), "unexpected error: %v", err)
+		Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+
+		count := states["views"].(int)
+		Expect(count).To(BeNumerically(">=", cancelAt))
+		Expect(count).To(BeNumerically("<", batches*batchSize))
+
+		partial, ok := dcb.GetPartialResultError(err)
+		Expect(ok).To(BeTrue())
+		Expect(partial.Cursor).NotTo(BeNil())
+		position, ok := condition.AfterPosition()
+		Expect(ok).To(BeTrue())
+		Expect(position).To(Equal(partial.Cursor.Position))
+
+		// Resuming from the cursor folds the remaining events
+		rest, _, err := partialStore.Project(ctx, []dcb.StateProjector{counter(func() {})}, partial.Cursor)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count + rest["views"].(int)).To(Equal(batches * batchSize))
+	})
+
+	It("should return only the error by default", func() {
+		projectCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		states, condition, err := store.Project(projectCtx, []dcb.StateProjector{counter(cancel)}, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(errors.Is(err, dcb.ErrPartial)).To(BeFalse())
+		Expect(states).To(BeNil())
+		Expect(condition).To(BeNil())
+	})
+})
@@ -0,0 +1,96 @@
+package dcb
+
+import (
+	"context"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ProjectProgressive", func() {
+	enrolled := dcb.StateProjector{
+		ID:           "enrolled",
+		Query:        dcb.NewQuery(dcb.NewTags("course_id", "c1"), "StudentEnrolled"),
+		InitialState: []int64{},
+		TransitionFn: func(state any, event dcb.Event) any { return append(state.([]int64), event.Position) },
+	}
+	enroll := func(n int) {
+		events := make([]dcb.InputEvent, n)
+		for i := range events {
+			events[i] = dcb.NewInputEvent("StudentEnrolled", dcb.NewTags("course_id", "c1"), []byte(`{}`))
+		}
+		Expect(store.Append(ctx, events)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should emit a snapshot every interval and a final one", func() {
+		enroll(7)
+
+		snapshots, err := store.ProjectProgressive(ctx, []dcb.StateProjector{enrolled}, 3)
+		Expect(err).NotTo(HaveOccurred())
+
+		var received []dcb.ProjectionSnapshot
+		for snapshot := range snapshots {
+			received = append(received, snapshot)
+		}
+		Expect(received).To(HaveLen(3))
+		Expect(received[0].Events).To(Equal(3))
+		Expect(received[0].States["enrolled"]).To(HaveLen(3))
+		Expect(received[1].Events).To(Equal(6))
+		Expect(received[1].States["enrolled"]).To(HaveLen(6))
+		Expect(received[0].Final || received[1].Final).To(BeFalse())
+
+		final := received[2]
+		Expect(final.Final).To(BeTrue())
+		Expect(final.Err).NotTo(HaveOccurred())
+		Expect(final.Events).To(Equal(7))
+		Expect(final.States["enrolled"]).To(HaveLen(7))
+		Expect(final.Cursor.Position).To(Equal(int64(7)))
+
+		// The final snapshot matches Project
+		states, condition, err := store.Project(ctx, []dcb.StateProjector{enrolled}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(final.States).To(Equal(states))
+		got, _ := final.Condition.AfterPosition()
+		want, _ := condition.AfterPosition()
+		Expect(got).To(Equal(want))
+	})
+
+	It("should emit only the final snapshot without events", func() {
+		snapshots, err := store.ProjectProgressive(ctx, []dcb.StateProjector{enrolled}, 10)
+		Expect(err).NotTo(HaveOccurred())
+
+		var received []dcb.ProjectionSnapshot
+		for snapshot := range snapshots {
+			received = append(received, snapshot)
+		}
+		Expect(received).To(HaveLen(1))
+		Expect(received[0].Final).To(BeTrue())
+		Expect(received[0].Cursor).To(BeNil())
+		Expect(received[0].States["enrolled"]).To(BeEmpty())
+	})
+
+	It("should close the channel without a final snapshot when cancelled", func() {
+		enroll(20)
+		// A one-snapshot buffer keeps the projection from running ahead of the reader
+		unbuffered, err := dcb.NewEventStoreWithConfig(ctx, pool, dcb.EventStoreConfig{StreamBuffer: 1})
+		Expect(err).NotTo(HaveOccurred())
+		progressCtx, cancel := context.WithCancel(ctx)
+
+		snapshots, err := unbuffered.ProjectProgressive(progressCtx, []dcb.StateProjector{enrolled}, 1)
+		Expect(err).NotTo(HaveOccurred())
+		first := <-snapshots
+		Expect(first.Events).To(Equal(1))
+		cancel()
+
+		for snapshot := range snapshots {
+			Expect(snapshot.Final).To(BeFalse())
+		}
+	})
+})
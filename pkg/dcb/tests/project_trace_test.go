@@ -0,0 +1,84 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ProjectTrace", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("StudentEnrolled", dcb.NewTags("course_id", "c1", "student_id", "s1"), []byte(`{}`)),
+			dcb.NewInputEvent("StudentEnrolled", dcb.NewTags("course_id", "c2", "student_id", "s2"), []byte(`{}`)),
+			dcb.NewInputEvent("StudentEnrolled", dcb.NewTags("course_id", "c1", "student_id", "s3"), []byte(`{}`)),
+			dcb.NewInputEvent("StudentDropped", dcb.NewTags("course_id", "c1", "student_id", "s1"), []byte(`{}`)),
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	// Students enrolled in c1, as a set mutated in place by the fold
+	enrolled := dcb.StateProjector{
+		ID:           "enrolled",
+		Query:        dcb.NewQuery(dcb.NewTags("course_id", "c1"), "StudentEnrolled", "StudentDropped"),
+		InitialState: map[string]bool{},
+		TransitionFn: func(state any, event dcb.Event) any {
+			students := state.(map[string]bool)
+			for _, tag := range event.Tags {
+				if tag.GetKey() != "student_id" {
+					continue
+				}
+				if event.Type == "StudentEnrolled" {
+					students[tag.GetValue()] = true
+				} else {
+					delete(students, tag.GetValue())
+				}
+			}
+			return students
+		},
+	}
+
+	It("should return the state after every consumed event", func() {
+		projector := enrolled
+		projector.InitialState = map[string]bool{}
+		trace, err := store.ProjectTrace(ctx, projector)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(trace).To(HaveLen(3))
+
+		Expect(trace[0].Position).To(Equal(int64(1)))
+		Expect(trace[0].Event.Type).To(Equal("StudentEnrolled"))
+		Expect(trace[0].StateAfter).To(Equal(map[string]bool{"s1": true}))
+		// Each step holds its own copy, although the fold mutated one map
+		Expect(trace[1].Position).To(Equal(int64(3)))
+		Expect(trace[1].StateAfter).To(Equal(map[string]bool{"s1": true, "s3": true}))
+		Expect(trace[2].Event.Type).To(Equal("StudentDropped"))
+		Expect(trace[2].StateAfter).To(Equal(map[string]bool{"s3": true}))
+
+		// The last step is the state Project returns
+		projector.InitialState = map[string]bool{}
+		states, _, err := store.Project(ctx, []dcb.StateProjector{projector}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(trace[2].StateAfter).To(Equal(states["enrolled"]))
+	})
+
+	It("should stop the trace when the projector stops", func() {
+		projector := enrolled
+		projector.InitialState = map[string]bool{}
+		projector.StopFn = func(state any) bool { return len(state.(map[string]bool)) == 2 }
+		trace, err := store.ProjectTrace(ctx, projector)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(trace).To(HaveLen(2))
+	})
+
+	It("should return an empty trace without matching events", func() {
+		projector := enrolled
+		projector.Query = dcb.NewQuery(dcb.NewTags("course_id", "c9"), "StudentEnrolled")
+		trace, err := store.ProjectTrace(ctx, projector)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(trace).To(BeEmpty())
+	})
+})
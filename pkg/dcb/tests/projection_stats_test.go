@@ -0,0 +1,84 @@
+package dcb
+
+import (
+	"sync"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Projection stats", func() {
+	var (
+		statsStore dcb.EventStore
+		mu         sync.Mutex
+		reported   []dcb.ProjectionStats
+		projectors []dcb.StateProjector
+	)
+
+	counter := func(id string, query dcb.Query) dcb.StateProjector {
+		return dcb.StateProjector{
+			ID:           id,
+			Query:        query,
+			InitialState: 0,
+			TransitionFn: func(state any, event dcb.Event) any { return state.(int) + 1 },
+		}
+	}
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+
+		mu.Lock()
+		reported = nil
+		mu.Unlock()
+		config := store.GetConfig()
+		config.OnProjectionStats = func(stats dcb.ProjectionStats) {
+			mu.Lock()
+			defer mu.Unlock()
+			reported = append(reported, stats)
+		}
+		statsStore, err = dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("CourseDefined", dcb.NewTags("course_id", "c1"), []byte(`{"capacity":10}`)),
+			dcb.NewInputEvent("StudentEnrolled", dcb.NewTags("course_id", "c1", "student_id", "s1"), []byte(`{}`)),
+			dcb.NewInputEvent("StudentEnrolled", dcb.NewTags("course_id", "c1", "student_id", "s2"), []byte(`{}`)),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		projectors = []dcb.StateProjector{
+			counter("course", dcb.NewQuery(dcb.NewTags("course_id", "c1"), "CourseDefined")),
+			counter("enrollments", dcb.NewQuery(dcb.NewTags("course_id", "c1"), "StudentEnrolled")),
+		}
+	})
+
+	It("should return and report the events scanned per projector", func() {
+		_, _, stats, err := statsStore.ProjectWithStats(ctx, projectors, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats.Op).To(Equal("Project"))
+		Expect(stats.EventsScanned).To(Equal(3))
+		Expect(stats.BytesScanned).To(BeNumerically(">", 0))
+		Expect(stats.EventsByProjector).To(Equal(map[string]int{"course": 1, "enrollments": 2}))
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(reported).To(HaveLen(1))
+		Expect(reported[0].EventsScanned).To(Equal(3))
+	})
+
+	It("should report ProjectStream stats once the stream completes", func() {
+		statesChan, conditionChan, err := statsStore.ProjectStream(ctx, projectors, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(statesChan).Should(Receive())
+		Eventually(conditionChan).Should(Receive())
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(reported).To(HaveLen(1))
+		Expect(reported[0].Op).To(Equal("ProjectStream"))
+		Expect(reported[0].EventsByProjector["enrollments"]).To(Equal(2))
+	})
+})
@@ -0,0 +1,46 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("QueryETag", func() {
+	query := dcb.NewQuery(dcb.NewTags("course_id", "c1"), "StudentEnrolled")
+	enroll := func(courseID string) []dcb.InputEvent {
+		return []dcb.InputEvent{dcb.NewInputEvent("StudentEnrolled", dcb.NewTags("course_id", courseID), []byte(`{}`))}
+	}
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should stay the same until a matching event is appended", func() {
+		empty, err := store.QueryETag(ctx, query)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(empty).To(HavePrefix(`W/"`))
+
+		Expect(store.Append(ctx, enroll("c1"))).To(Succeed())
+		first, err := store.QueryETag(ctx, query)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(first).NotTo(Equal(empty))
+
+		again, err := store.QueryETag(ctx, query)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again).To(Equal(first))
+
+		// Unrelated events leave the tag alone
+		Expect(store.Append(ctx, enroll("c2"))).To(Succeed())
+		unrelated, err := store.QueryETag(ctx, query)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unrelated).To(Equal(first))
+
+		Expect(store.Append(ctx, enroll("c1"))).To(Succeed())
+		second, err := store.QueryETag(ctx, query)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second).NotTo(Equal(first))
+	})
+})
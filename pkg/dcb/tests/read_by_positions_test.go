@@ -0,0 +1,51 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReadByPositions", func() {
+	var all []dcb.Event
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("CourseDefined", dcb.NewTags("course_id", "c1"), []byte(`{"n":1}`)),
+			dcb.NewInputEvent("CourseDefined", dcb.NewTags("course_id", "c2"), []byte(`{"n":2}`)),
+			dcb.NewInputEvent("CourseDefined", dcb.NewTags("course_id", "c3"), []byte(`{"n":3}`)),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		all, err = store.Query(ctx, dcb.NewQueryAll(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(all).To(HaveLen(3))
+	})
+
+	It("should return the requested events in position order", func() {
+		events, err := store.ReadByPositions(ctx, []int64{all[2].Position, all[0].Position})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].Position).To(Equal(all[0].Position))
+		Expect(events[1].Position).To(Equal(all[2].Position))
+		Expect(events[1].Data).To(MatchJSON(`{"n":3}`))
+		Expect(events[1].TransactionID).To(Equal(all[2].TransactionID))
+	})
+
+	It("should omit missing positions and return duplicates once", func() {
+		events, err := store.ReadByPositions(ctx, []int64{all[1].Position, all[1].Position, all[2].Position + 1000})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Position).To(Equal(all[1].Position))
+	})
+
+	It("should return no events for an empty list", func() {
+		events, err := store.ReadByPositions(ctx, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(BeEmpty())
+	})
+})
@@ -0,0 +1,73 @@
+package dcb
+
+import (
+	"context"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReadByTransaction", func() {
+	var first, second []dcb.Event
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("OrderPlaced", dcb.NewTags("order_id", "o1"), []byte(`{"n":1}`)),
+			dcb.NewInputEvent("OrderItemAdded", dcb.NewTags("order_id", "o1"), []byte(`{"n":2}`)),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		err = store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("OrderPlaced", dcb.NewTags("order_id", "o2"), []byte(`{"n":3}`)),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		all, err := store.Query(ctx, dcb.NewQueryAll(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(all).To(HaveLen(3))
+		first, second = all[:2], all[2:]
+		Expect(first[0].TransactionID).NotTo(Equal(second[0].TransactionID))
+	})
+
+	It("should return exactly the events of the transaction in position order", func() {
+		events, err := store.ReadByTransaction(ctx, first[0].TransactionID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(Equal(first))
+
+		events, err = store.ReadByTransaction(ctx, second[0].TransactionID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(Equal(second))
+	})
+
+	It("should return no events for an unknown transaction", func() {
+		events, err := store.ReadByTransaction(ctx, second[0].TransactionID+1000)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(BeEmpty())
+	})
+
+	It("should combine WithTransactionID with other conditions", func() {
+		query := dcb.NewQueryBuilder().WithType("OrderItemAdded").WithTransactionID(first[0].TransactionID).Build()
+		events, err := store.Query(ctx, query, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Data).To(MatchJSON(`{"n":2}`))
+	})
+
+	It("should return the events a command produced", func() {
+		handler := dcb.CommandHandlerFunc(func(ctx context.Context, store dcb.EventStore, command dcb.Command) ([]dcb.InputEvent, any, error) {
+			return []dcb.InputEvent{dcb.NewInputEvent("OrderShipped", dcb.NewTags("order_id", "o1"), []byte(`{}`))}, nil, nil
+		})
+		result, err := dcb.NewCommandExecutor(store).ExecuteCommand(ctx, dcb.NewCommand("ShipOrder", []byte(`{}`), nil), handler, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		events, err := store.ReadByTransaction(ctx, result.TransactionID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Type).To(Equal("OrderShipped"))
+		Expect(events[0].Position).To(Equal(result.Positions[0]))
+	})
+})
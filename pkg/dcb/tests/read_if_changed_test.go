@@ -0,0 +1,54 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReadIfChanged", func() {
+	query := dcb.NewQuery(dcb.NewTags("course_id", "c1"), "StudentEnrolled")
+	enroll := func(courseID string) []dcb.InputEvent {
+		return []dcb.InputEvent{dcb.NewInputEvent("StudentEnrolled", dcb.NewTags("course_id", courseID), []byte(`{}`))}
+	}
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should report no change for an empty result", func() {
+		events, head, changed, err := store.ReadIfChanged(ctx, query, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeFalse())
+		Expect(head).To(Equal(int64(0)))
+		Expect(events).To(BeEmpty())
+	})
+
+	It("should return the events only when a matching event was appended", func() {
+		Expect(store.Append(ctx, enroll("c1"))).To(Succeed())
+		Expect(store.Append(ctx, enroll("c1"))).To(Succeed())
+
+		events, head, changed, err := store.ReadIfChanged(ctx, query, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(events).To(HaveLen(2))
+		Expect(head).To(Equal(int64(2)))
+
+		// Unchanged, even after an unrelated append
+		Expect(store.Append(ctx, enroll("c2"))).To(Succeed())
+		events, sameHead, changed, err := store.ReadIfChanged(ctx, query, head)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeFalse())
+		Expect(events).To(BeNil())
+		Expect(sameHead).To(Equal(head))
+
+		Expect(store.Append(ctx, enroll("c1"))).To(Succeed())
+		events, newHead, changed, err := store.ReadIfChanged(ctx, query, head)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(events).To(HaveLen(3))
+		Expect(newHead).To(Equal(int64(4)))
+	})
+})
@@ -0,0 +1,79 @@
+package dcb
+
+import (
+	"encoding/json"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReadMulti", func() {
+	const tenantTable = "events_tenant_b"
+	var multiStore dcb.EventStore
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = pool.Exec(ctx, "DROP TABLE IF EXISTS "+tenantTable)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = pool.Exec(ctx, "CREATE TABLE "+tenantTable+" (LIKE events INCLUDING ALL)")
+		Expect(err).NotTo(HaveOccurred())
+
+		config := store.GetConfig()
+		config.ReadableTables = []string{tenantTable}
+		multiStore, err = dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Tenant a lives in events, tenant b in its own table, written at interleaved times
+		_, err = pool.Exec(ctx, `
+			INSERT INTO events (type, tags, data, transaction_id, position, occurred_at) VALUES
+				('OrderPlaced', '{tenant:a}', '{"n":1}', pg_current_xact_id(), 1, '2025-01-01T10:00:00Z'),
+				('OrderPlaced', '{tenant:a}', '{"n":3}', pg_current_xact_id(), 2, '2025-01-01T12:00:00Z')`)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = pool.Exec(ctx, `
+			INSERT INTO `+tenantTable+` (type, tags, data, transaction_id, position, occurred_at) VALUES
+				('OrderPlaced', '{tenant:b}', '{"n":2}', pg_current_xact_id(), 1, '2025-01-01T11:00:00Z'),
+				('OrderCancelled', '{tenant:b}', '{"n":4}', pg_current_xact_id(), 2, '2025-01-01T13:00:00Z')`)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		_, err := pool.Exec(ctx, "DROP TABLE IF EXISTS "+tenantTable)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	numbers := func(events []dcb.Event) []int {
+		ns := make([]int, len(events))
+		for i, event := range events {
+			var data struct{ N int }
+			Expect(json.Unmarshal(event.Data, &data)).To(Succeed())
+			ns[i] = data.N
+		}
+		return ns
+	}
+
+	It("should merge tables by occurred_at by default", func() {
+		events, err := multiStore.ReadMulti(ctx, []string{"events", tenantTable}, dcb.NewQueryBuilder().WithTypes("OrderPlaced", "OrderCancelled").Build(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(numbers(events)).To(Equal([]int{1, 2, 3, 4}))
+	})
+
+	It("should keep each table's order with ReadOrderByTable and apply the query and limit", func() {
+		events, err := multiStore.ReadMulti(ctx, []string{tenantTable, "events"}, dcb.NewQueryBuilder().WithType("OrderPlaced").Build(),
+			&dcb.ReadOptions{Order: dcb.ReadOrderByTable, Limit: 2})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(numbers(events)).To(Equal([]int{2, 1}))
+	})
+
+	It("should reject tables outside the allow-list", func() {
+		_, err := store.ReadMulti(ctx, []string{"events", tenantTable}, dcb.NewQuery(dcb.NewTags("tenant", "a")), nil)
+		Expect(dcb.IsValidationError(err)).To(BeTrue())
+
+		config := store.GetConfig()
+		config.ReadableTables = []string{"bad name"}
+		_, err = dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(dcb.IsValidationError(err)).To(BeTrue())
+	})
+})
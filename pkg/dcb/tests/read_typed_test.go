@@ -0,0 +1,57 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReadTyped", func() {
+	type addressChanged struct {
+		City string `json:"city"`
+	}
+	query := dcb.NewQuery(dcb.NewTags("customer_id", "c1"), "AddressChanged")
+	moveTo := func(city string) []dcb.InputEvent {
+		return []dcb.InputEvent{dcb.NewInputEvent("AddressChanged", dcb.NewTags("customer_id", "c1"), []byte(`{"city": "`+city+`"}`))}
+	}
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should decode every matching event in order", func() {
+		Expect(store.Append(ctx, moveTo("Lisbon"))).To(Succeed())
+		Expect(store.Append(ctx, moveTo("Porto"))).To(Succeed())
+
+		addresses, err := dcb.ReadTyped[addressChanged](ctx, store, query, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(addresses).To(HaveLen(2))
+		Expect(addresses[0].Data.City).To(Equal("Lisbon"))
+		Expect(addresses[1].Data.City).To(Equal("Porto"))
+		Expect(addresses[1].Type).To(Equal("AddressChanged"))
+		Expect(addresses[1].Position).To(BeNumerically(">", addresses[0].Position))
+
+		after := &dcb.Cursor{TransactionID: addresses[0].TransactionID, Position: addresses[0].Position}
+		later, err := dcb.ReadTyped[addressChanged](ctx, store, query, &dcb.ReadTypedOptions{After: after})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(later).To(HaveLen(1))
+		Expect(later[0].Data.City).To(Equal("Porto"))
+	})
+
+	It("should fail on undecodable data unless told to skip it", func() {
+		Expect(store.Append(ctx, moveTo("Lisbon"))).To(Succeed())
+		Expect(store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("AddressChanged", dcb.NewTags("customer_id", "c1"), []byte(`{"city": 7}`)),
+		})).To(Succeed())
+
+		_, err := dcb.ReadTyped[addressChanged](ctx, store, query, nil)
+		Expect(dcb.IsValidationError(err)).To(BeTrue())
+
+		addresses, err := dcb.ReadTyped[addressChanged](ctx, store, query, &dcb.ReadTypedOptions{SkipUndecodable: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(addresses).To(HaveLen(1))
+		Expect(addresses[0].Data.City).To(Equal("Lisbon"))
+	})
+})
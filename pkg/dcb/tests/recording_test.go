@@ -0,0 +1,65 @@
+package dcb
+
+import (
+	"context"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RecordingStore", func() {
+	var recorder *dcb.RecordingStore
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+		recorder = dcb.NewRecordingStore(store)
+	})
+
+	It("should record the reads of a command handler and the events its command appended", func() {
+		Expect(recorder.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("CourseDefined", dcb.NewTags("course_id", "c1"), []byte(`{"capacity":1}`)),
+		})).To(Succeed())
+		recorder.Reset()
+
+		courseExists := dcb.NewExistsProjector("courseExists", dcb.NewQuery(dcb.NewTags("course_id", "c1"), "CourseDefined"))
+		handler := dcb.CommandHandlerFunc(func(ctx context.Context, store dcb.EventStore, command dcb.Command) ([]dcb.InputEvent, any, error) {
+			if _, _, err := store.Project(ctx, []dcb.StateProjector{courseExists}, nil); err != nil {
+				return nil, nil, err
+			}
+			return []dcb.InputEvent{
+				dcb.NewInputEvent("StudentEnrolled", dcb.NewTags("course_id", "c1", "student_id", "s1"), []byte(`{}`)),
+			}, nil, nil
+		})
+		condition := dcb.FailIfEventType("StudentEnrolled", "student_id", "s1")
+
+		executor := dcb.NewCommandExecutor(recorder)
+		_, err := executor.ExecuteCommand(ctx, dcb.NewCommand("Enroll", []byte(`{}`), nil), handler, &condition)
+		Expect(err).NotTo(HaveOccurred())
+
+		calls := recorder.Calls()
+		Expect(calls).To(HaveLen(2))
+		Expect(calls[0].Method).To(Equal("Project"))
+		Expect(calls[0].Projectors[0].ID).To(Equal("courseExists"))
+		Expect(calls[1].Method).To(Equal("ExecuteCommand"))
+		Expect(calls[1].Events).To(HaveLen(1))
+		Expect(calls[1].Events[0].GetType()).To(Equal("StudentEnrolled"))
+		Expect(calls[1].Condition).To(Equal(condition))
+		Expect(calls[1].Err).NotTo(HaveOccurred())
+	})
+
+	It("should record failed appends", func() {
+		event := dcb.NewInputEvent("AccountOpened", dcb.NewTags("account_id", "a1"), []byte(`{}`))
+		Expect(recorder.AppendIf(ctx, []dcb.InputEvent{event}, dcb.FailIfExists("account_id", "a1"))).To(Succeed())
+		err := recorder.AppendIf(ctx, []dcb.InputEvent{event}, dcb.FailIfExists("account_id", "a1"))
+		Expect(dcb.IsConcurrencyError(err)).To(BeTrue())
+
+		appends := recorder.CallsTo("AppendIf")
+		Expect(appends).To(HaveLen(2))
+		Expect(appends[0].Err).NotTo(HaveOccurred())
+		Expect(appends[1].Err).To(Equal(err))
+		Expect(appends[1].String()).To(ContainSubstring("AppendIf(1 events [AccountOpened]"))
+	})
+})
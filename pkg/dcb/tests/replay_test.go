@@ -0,0 +1,120 @@
+package dcb
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Replay", func() {
+	query := dcb.NewQueryBuilder().WithType("StudentEnrolled").Build()
+
+	appendEnrollments := func(n int) {
+		events := make([]dcb.InputEvent, n)
+		for i := range n {
+			events[i] = dcb.NewInputEvent("StudentEnrolled",
+				dcb.NewTags("student_id", fmt.Sprintf("s%d", i+1)),
+				[]byte(fmt.Sprintf(`{"n":%d}`, i+1)))
+		}
+		Expect(store.Append(ctx, events)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = pool.Exec(ctx, "TRUNCATE TABLE dcb_replay_checkpoints")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should replay every matching event in order across batches", func() {
+		appendEnrollments(7)
+		Expect(store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("CourseDefined", dcb.NewTags("course_id", "c1"), []byte(`{}`)),
+		})).To(Succeed())
+
+		var positions []int64
+		result, err := store.Replay(ctx, query, 0, func(event dcb.Event) error {
+			positions = append(positions, event.Position)
+			return nil
+		}, dcb.ReplayOptions{BatchSize: 3})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(positions).To(Equal([]int64{1, 2, 3, 4, 5, 6, 7}))
+		Expect(result.Handled).To(Equal(7))
+		Expect(result.Skipped).To(Equal(0))
+		Expect(result.Cursor.Position).To(Equal(int64(7)))
+	})
+
+	It("should start at the given position", func() {
+		appendEnrollments(5)
+
+		var positions []int64
+		_, err := store.Replay(ctx, query, 3, func(event dcb.Event) error {
+			positions = append(positions, event.Position)
+			return nil
+		}, dcb.ReplayOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(positions).To(Equal([]int64{3, 4, 5}))
+	})
+
+	It("should reject a position without an event", func() {
+		appendEnrollments(2)
+
+		_, err := store.Replay(ctx, query, 99, func(dcb.Event) error { return nil }, dcb.ReplayOptions{})
+		Expect(dcb.IsValidationError(err)).To(BeTrue())
+	})
+
+	It("should stop on a handler error and resume from the checkpoint", func() {
+		appendEnrollments(6)
+		failure := errors.New("read model unavailable")
+
+		var firstRun []int64
+		result, err := store.Replay(ctx, query, 0, func(event dcb.Event) error {
+			if event.Position == 5 {
+				return failure
+			}
+			firstRun = append(firstRun, event.Position)
+			return nil
+		}, dcb.ReplayOptions{Name: "enrollments-backfill", BatchSize: 2})
+		Expect(err).To(MatchError(failure))
+		Expect(dcb.IsResourceError(err)).To(BeTrue())
+		Expect(firstRun).To(Equal([]int64{1, 2, 3, 4}))
+		Expect(result.Handled).To(Equal(4))
+		Expect(result.Cursor.Position).To(Equal(int64(4)))
+
+		var secondRun []int64
+		result, err = store.Replay(ctx, query, 0, func(event dcb.Event) error {
+			secondRun = append(secondRun, event.Position)
+			return nil
+		}, dcb.ReplayOptions{Name: "enrollments-backfill", BatchSize: 2})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(secondRun).To(Equal([]int64{5, 6}))
+		Expect(result.Handled).To(Equal(2))
+
+		// Caught up: a third run has nothing left
+		result, err = store.Replay(ctx, query, 0, func(dcb.Event) error {
+			Fail("no event expected after the checkpoint")
+			return nil
+		}, dcb.ReplayOptions{Name: "enrollments-backfill"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Handled).To(Equal(0))
+	})
+
+	It("should skip failing events with SkipErrors", func() {
+		appendEnrollments(4)
+
+		result, err := store.Replay(ctx, query, 0, func(event dcb.Event) error {
+			if event.Position%2 == 0 {
+				return errors.New("malformed event")
+			}
+			return nil
+		}, dcb.ReplayOptions{SkipErrors: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Handled).To(Equal(2))
+		Expect(result.Skipped).To(Equal(2))
+		Expect(result.Cursor.Position).To(Equal(int64(4)))
+	})
+})
@@ -0,0 +1,59 @@
+package dcb
+
+import (
+	"context"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reserved tag keys", func() {
+	var reservedStore dcb.EventStore
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+
+		config := store.GetConfig()
+		config.ReservedTagKeyPrefixes = []string{"source", "_"}
+		config.ProducerTag = "source:orders-service"
+		config.ProducerAsTag = true
+		reservedStore, err = dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject appends of events with reserved tag keys and write nothing", func() {
+		forged := dcb.NewInputEvent("OrderPlaced", dcb.NewTags("order_id", "o1", "source", "billing-service"), []byte(`{}`))
+		err := reservedStore.Append(ctx, []dcb.InputEvent{forged})
+		Expect(dcb.IsValidationError(err)).To(BeTrue())
+
+		_, err = dcb.NewCommandExecutor(reservedStore).ExecuteCommand(ctx, dcb.NewCommand("PlaceOrder", []byte(`{}`), nil),
+			dcb.CommandHandlerFunc(func(ctx context.Context, store dcb.EventStore, command dcb.Command) ([]dcb.InputEvent, any, error) {
+				return []dcb.InputEvent{dcb.NewInputEvent("OrderPlaced", dcb.NewTags("order_id", "o1", "_schema_version", "2"), []byte(`{}`))}, nil, nil
+			}), nil)
+		Expect(dcb.IsValidationError(err)).To(BeTrue())
+
+		events, err := store.Query(ctx, dcb.NewQueryBuilder().WithType("OrderPlaced").Build(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(BeEmpty())
+	})
+
+	It("should still attach the store's own tags with a reserved key", func() {
+		Expect(reservedStore.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("OrderPlaced", dcb.NewTags("order_id", "o1"), []byte(`{}`)),
+		})).To(Succeed())
+
+		events, err := store.Query(ctx, dcb.NewQuery(dcb.NewTags("source", "orders-service"), "OrderPlaced"), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+	})
+
+	It("should reject an empty prefix", func() {
+		config := store.GetConfig()
+		config.ReservedTagKeyPrefixes = []string{""}
+		_, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(dcb.IsValidationError(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,33 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Schema function validation", func() {
+	It("should fail construction with a ConfigurationError when an append function is missing", func() {
+		_, err := pool.Exec(ctx, `ALTER FUNCTION append_events_batch(TEXT[], TEXT[], JSONB[], JSONB[], BIGINT[], TIMESTAMPTZ, BOOLEAN) RENAME TO append_events_batch_hidden`)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() {
+			_, err := pool.Exec(ctx, `ALTER FUNCTION append_events_batch_hidden(TEXT[], TEXT[], JSONB[], JSONB[], BIGINT[], TIMESTAMPTZ, BOOLEAN) RENAME TO append_events_batch`)
+			Expect(err).NotTo(HaveOccurred())
+		}()
+
+		_, err = dcb.NewEventStore(ctx, pool)
+		Expect(err).To(MatchError(dcb.ErrConfiguration))
+
+		configErr, ok := dcb.AsConfigurationError(err)
+		Expect(ok).To(BeTrue())
+		Expect(configErr.Component).To(Equal("function append_events_batch"))
+		Expect(configErr.Remedy).To(ContainSubstring("schema.sql"))
+
+		// A store built before the function went missing reports it on first use
+		err = store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("CourseDefined", dcb.NewTags("course_id", "c1"), []byte(`{}`)),
+		})
+		Expect(err).To(MatchError(dcb.ErrConfiguration))
+	})
+})
@@ -0,0 +1,81 @@
+package dcb
+
+import (
+	"database/sql"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewEventStoreFromSQLDB", func() {
+	var (
+		db       *sql.DB
+		sqlStore dcb.EventStore
+	)
+
+	query := dcb.NewQuery(dcb.NewTags("course_id", "c1"), "CourseDefined", "StudentEnrolled")
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+
+		db = stdlib.OpenDBFromPool(pool)
+		sqlStore, err = dcb.NewEventStoreFromSQLDB(ctx, db, store.GetConfig())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).To(Succeed())
+	})
+
+	It("should append, read and project like a pgxpool store", func() {
+		Expect(sqlStore.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("CourseDefined", dcb.NewTags("course_id", "c1"), []byte(`{}`)),
+		})).To(Succeed())
+
+		events, err := sqlStore.Query(ctx, query, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+
+		states, condition, err := sqlStore.Project(ctx, []dcb.StateProjector{
+			dcb.ProjectCounter("events", "CourseDefined", "course_id", "c1"),
+		}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(states["events"]).To(Equal(1))
+
+		enroll := []dcb.InputEvent{dcb.NewInputEvent("StudentEnrolled", dcb.NewTags("course_id", "c1"), []byte(`{}`))}
+		Expect(sqlStore.AppendIf(ctx, enroll, condition)).To(Succeed())
+		Expect(dcb.IsConcurrencyError(sqlStore.AppendIf(ctx, enroll, condition))).To(BeTrue())
+
+		events, err = store.Query(ctx, query, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+	})
+
+	It("should run transactions and return their connections to the pool", func() {
+		for range 3 {
+			err := sqlStore.WithTransaction(ctx, func(txStore dcb.EventStore) error {
+				return txStore.Append(ctx, []dcb.InputEvent{
+					dcb.NewInputEvent("StudentEnrolled", dcb.NewTags("course_id", "c1"), []byte(`{}`)),
+				})
+			})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(db.Stats().InUse).To(Equal(0))
+
+		events, err := sqlStore.Query(ctx, query, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(3))
+		Expect(db.Stats().InUse).To(Equal(0))
+	})
+
+	It("should report the features that need a pgxpool.Pool", func() {
+		Expect(sqlStore.GetPool()).To(BeNil())
+
+		_, err := dcb.NewCommandExecutor(sqlStore).SubscribeCommands(ctx, 0)
+		Expect(dcb.IsValidationError(err)).To(BeTrue())
+	})
+})
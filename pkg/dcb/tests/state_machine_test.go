@@ -0,0 +1,49 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("State machine projectors", func() {
+	tags := dcb.NewTags("seat_id", "s1")
+	transitions := map[string]map[string]string{
+		"available": {"SeatBooked": "booked"},
+		"booked":    {"BookingCancelled": "cancelled"},
+	}
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should project the state reached by the stored events", func() {
+		Expect(store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("SeatBooked", tags, []byte(`{}`)),
+			dcb.NewInputEvent("BookingCancelled", tags, []byte(`{}`)),
+		})).To(Succeed())
+
+		projector := dcb.ProjectStateMachine("seat", dcb.NewQuery(tags), transitions, "available")
+		states, _, err := store.Project(ctx, []dcb.StateProjector{projector}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(states["seat"]).To(Equal("cancelled"))
+	})
+
+	It("should stop at the first invalid transition in strict mode", func() {
+		Expect(store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("SeatBooked", tags, []byte(`{}`)),
+			dcb.NewInputEvent("SeatBooked", tags, []byte(`{}`)),
+			dcb.NewInputEvent("BookingCancelled", tags, []byte(`{}`)),
+		})).To(Succeed())
+
+		projector := dcb.ProjectStrictStateMachine("seat", dcb.NewQuery(tags), transitions, "available")
+		states, _, err := store.Project(ctx, []dcb.StateProjector{projector}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		state := states["seat"].(dcb.StateMachineState)
+		Expect(state.State).To(Equal("booked"))
+		Expect(state.Err).To(MatchError(ContainSubstring("SeatBooked event at position 2")))
+	})
+})
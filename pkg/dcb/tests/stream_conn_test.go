@@ -0,0 +1,56 @@
+package dcb
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Stream connection validation", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should evict a connection terminated while idle and stream on a new one", func() {
+		Expect(store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("Streamed", dcb.NewTags("n", "1"), []byte(`{}`)),
+			dcb.NewInputEvent("Streamed", dcb.NewTags("n", "2"), []byte(`{}`)),
+		})).To(Succeed())
+
+		// A single-connection pool, so the stream checks out the connection terminated below
+		poolConfig := pool.Config().Copy()
+		poolConfig.MaxConns = 1
+		poolConfig.MinConns = 0
+		single, err := pgxpool.NewWithConfig(ctx, poolConfig)
+		Expect(err).NotTo(HaveOccurred())
+		defer single.Close()
+
+		config := store.GetConfig()
+		config.ValidateStreamConnections = true
+		validating, err := dcb.NewEventStoreWithConfig(ctx, single, config)
+		Expect(err).NotTo(HaveOccurred())
+
+		var pid int32
+		Expect(single.QueryRow(ctx, "SELECT pg_backend_pid()").Scan(&pid)).To(Succeed())
+		_, err = pool.Exec(ctx, "SELECT pg_terminate_backend($1)", pid)
+		Expect(err).NotTo(HaveOccurred())
+
+		stream, err := validating.QueryStream(ctx, dcb.NewQuery(nil, "Streamed"), nil)
+		Expect(err).NotTo(HaveOccurred())
+		var received []dcb.Event
+		for event := range stream {
+			received = append(received, event)
+		}
+		Expect(received).To(HaveLen(2))
+		Expect(validating.StreamStats().EvictedConnections).To(Equal(int64(1)))
+	})
+
+	It("should report open streams", func() {
+		stats := store.StreamStats()
+		Expect(stats.Open).To(Equal(0))
+		Expect(stats.Limit).To(Equal(store.GetConfig().MaxConcurrentStreams))
+	})
+})
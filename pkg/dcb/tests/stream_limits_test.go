@@ -0,0 +1,56 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Stream limits", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject streams beyond MaxConcurrentStreams until one ends", func() {
+		events := make([]dcb.InputEvent, 0, 20)
+		for i := 0; i < 20; i++ {
+			events = append(events, dcb.NewInputEvent("Streamed", dcb.NewTags("n", fmt.Sprint(i)), []byte(`{}`)))
+		}
+		Expect(store.Append(ctx, events)).To(Succeed())
+
+		config := store.GetConfig()
+		config.MaxConcurrentStreams = 2
+		config.StreamBuffer = 1 // keep producers blocked on slow consumers
+		limitedStore, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+
+		streamCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		query := dcb.NewQueryFromItems(dcb.NewQueryItem([]string{"Streamed"}, nil))
+		first, err := limitedStore.QueryStream(streamCtx, query, nil)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = limitedStore.QueryStream(streamCtx, query, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = limitedStore.QueryStream(streamCtx, query, nil)
+		Expect(err).To(MatchError(dcb.ErrResource))
+
+		// Draining a stream frees its slot
+		for range first {
+		}
+		Eventually(func() error {
+			stream, err := limitedStore.QueryStream(streamCtx, query, nil)
+			if err == nil {
+				for range stream {
+				}
+			}
+			return err
+		}).Should(Succeed())
+	})
+})
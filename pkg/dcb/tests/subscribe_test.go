@@ -0,0 +1,84 @@
+package dcb
+
+import (
+	"context"
+	"time"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Subscribe", func() {
+	query := dcb.NewQuery(dcb.NewTags("stream", "live"))
+	pollInterval := 20 * time.Millisecond
+
+	newEvent := func(eventType string) dcb.InputEvent {
+		return dcb.NewInputEvent(eventType, dcb.NewTags("stream", "live"), []byte(`{}`))
+	}
+
+	receive := func(events <-chan dcb.Event) dcb.Event {
+		var event dcb.Event
+		Eventually(events, 5*time.Second).Should(Receive(&event))
+		return event
+	}
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should replay existing events and then deliver new ones", func() {
+		Expect(store.Append(ctx, []dcb.InputEvent{newEvent("First"), newEvent("Second")})).To(Succeed())
+		Expect(store.Append(ctx, []dcb.InputEvent{dcb.NewInputEvent("Other", dcb.NewTags("stream", "other"), []byte(`{}`))})).To(Succeed())
+
+		subCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		events, err := store.Subscribe(subCtx, query, nil, pollInterval)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(receive(events).Type).To(Equal("First"))
+		Expect(receive(events).Type).To(Equal("Second"))
+		Consistently(events, 100*time.Millisecond).ShouldNot(Receive())
+
+		Expect(store.Append(ctx, []dcb.InputEvent{newEvent("Third")})).To(Succeed())
+		Expect(receive(events).Type).To(Equal("Third"))
+	})
+
+	It("should start after the given cursor", func() {
+		Expect(store.Append(ctx, []dcb.InputEvent{newEvent("First"), newEvent("Second")})).To(Succeed())
+		existing, err := store.Query(ctx, query, nil)
+		Expect(err).NotTo(HaveOccurred())
+		after := &dcb.Cursor{TransactionID: existing[0].TransactionID, Position: existing[0].Position}
+
+		subCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		events, err := store.Subscribe(subCtx, query, after, pollInterval)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(receive(events).Type).To(Equal("Second"))
+	})
+
+	It("should close the channel and free the stream slot when cancelled", func() {
+		config := store.GetConfig()
+		config.MaxConcurrentStreams = 1
+		limitedStore, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+
+		subCtx, cancel := context.WithCancel(ctx)
+		events, err := limitedStore.Subscribe(subCtx, query, nil, pollInterval)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = limitedStore.Subscribe(ctx, query, nil, pollInterval)
+		Expect(dcb.IsResourceError(err)).To(BeTrue())
+
+		cancel()
+		Eventually(events, 5*time.Second).Should(BeClosed())
+		Eventually(func() error {
+			retryCtx, retryCancel := context.WithCancel(ctx)
+			defer retryCancel()
+			_, err := limitedStore.Subscribe(retryCtx, query, nil, pollInterval)
+			return err
+		}, 5*time.Second).Should(Succeed())
+	})
+})
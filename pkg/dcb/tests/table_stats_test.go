@@ -0,0 +1,47 @@
+package dcb
+
+import (
+	"fmt"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ApproxStats", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should report the analyzed row count and size of the tables", func() {
+		events := make([]dcb.InputEvent, 25)
+		for i := range events {
+			events[i] = dcb.NewInputEvent("StudentEnrolled", dcb.NewTags("student_id", fmt.Sprintf("s%d", i)), []byte(`{}`))
+		}
+		Expect(store.Append(ctx, events)).To(Succeed())
+		_, err := pool.Exec(ctx, "ANALYZE events")
+		Expect(err).NotTo(HaveOccurred())
+
+		stats, err := store.ApproxStats(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats.Events.Table).To(Equal("events"))
+		Expect(stats.Events.Rows).To(Equal(int64(25)))
+		Expect(stats.Events.Bytes).To(BeNumerically(">", 0))
+		Expect(stats.Commands.Table).To(Equal("commands"))
+		Expect(stats.Commands.Bytes).To(BeNumerically(">", 0))
+		Expect(stats.Archive).To(BeNil())
+	})
+
+	It("should report an archive table that doesn't exist yet as empty", func() {
+		config := store.GetConfig()
+		config.ArchiveTable = "events_archive_not_created"
+		archiving, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+
+		stats, err := archiving.ApproxStats(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats.Archive).To(Equal(&dcb.TableSize{Table: "events_archive_not_created"}))
+	})
+})
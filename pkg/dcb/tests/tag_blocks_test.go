@@ -0,0 +1,78 @@
+package dcb
+
+import (
+	"context"
+	"time"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Blocked tags", func() {
+	blocked := func() dcb.InputEvent {
+		return dcb.NewInputEvent("OrderPlaced", dcb.NewTags("tenant_id", "t42", "order_id", "o1"), []byte(`{}`))
+	}
+	allowed := func() dcb.InputEvent {
+		return dcb.NewInputEvent("OrderPlaced", dcb.NewTags("tenant_id", "t1", "order_id", "o2"), []byte(`{}`))
+	}
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject appends of a blocked tag until it is unblocked", func() {
+		blockingStore, err := dcb.NewEventStoreWithConfig(ctx, pool, store.GetConfig())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(blockingStore.BlockTag(ctx, "tenant_id", "t42")).To(Succeed())
+
+		err = blockingStore.Append(ctx, []dcb.InputEvent{allowed(), blocked()})
+		Expect(dcb.IsResourceError(err)).To(BeTrue())
+		_, err = dcb.NewCommandExecutor(blockingStore).ExecuteCommand(ctx, dcb.NewCommand("PlaceOrder", []byte(`{}`), nil),
+			dcb.CommandHandlerFunc(func(ctx context.Context, store dcb.EventStore, command dcb.Command) ([]dcb.InputEvent, any, error) {
+				return []dcb.InputEvent{blocked()}, nil, nil
+			}), nil)
+		Expect(dcb.IsResourceError(err)).To(BeTrue())
+		Expect(blockingStore.Append(ctx, []dcb.InputEvent{allowed()})).To(Succeed())
+
+		// Other stores don't see an in-memory block
+		Expect(store.Append(ctx, []dcb.InputEvent{blocked()})).To(Succeed())
+
+		Expect(blockingStore.UnblockTag(ctx, "tenant_id", "t42")).To(Succeed())
+		Expect(blockingStore.Append(ctx, []dcb.InputEvent{blocked()})).To(Succeed())
+
+		events, err := store.Query(ctx, dcb.NewQueryBuilder().WithType("OrderPlaced").Build(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(3))
+	})
+
+	It("should share blocks between stores with SharedTagBlocks", func() {
+		config := store.GetConfig()
+		config.SharedTagBlocks = true
+		first, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+		second, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() {
+			_, err := pool.Exec(ctx, `DELETE FROM dcb_blocked_tags`)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Expect(first.BlockTag(ctx, "tenant_id", "t42")).To(Succeed())
+		Eventually(func() error {
+			return second.Append(ctx, []dcb.InputEvent{blocked()})
+		}, 5*time.Second, 200*time.Millisecond).Should(Satisfy(dcb.IsResourceError))
+
+		// A store created after the block loads it at once
+		third, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dcb.IsResourceError(third.Append(ctx, []dcb.InputEvent{blocked()}))).To(BeTrue())
+
+		Expect(first.UnblockTag(ctx, "tenant_id", "t42")).To(Succeed())
+		Eventually(func() error {
+			return second.Append(ctx, []dcb.InputEvent{blocked()})
+		}, 5*time.Second, 200*time.Millisecond).Should(Succeed())
+	})
+})
@@ -0,0 +1,68 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Case-insensitive tag matching", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("OrderPlaced", dcb.NewTags("customer_id", "ABC-1"), []byte(`{}`)),
+			dcb.NewInputEvent("OrderPlaced", dcb.NewTags("customer_id", "abc-1"), []byte(`{}`)),
+			dcb.NewInputEvent("OrderPlaced", dcb.NewTags("customer_id", "xyz-2"), []byte(`{}`)),
+			dcb.NewInputEvent("OrderPlaced", dcb.NewTags("Customer_ID", "abc-1"), []byte(`{}`)),
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	readCI := func(es dcb.EventStore) []dcb.Event {
+		query := dcb.NewQueryBuilder().WithType("OrderPlaced").WithTagCI("customer_id", "Abc-1").Build()
+		events, err := es.Query(ctx, query, nil)
+		Expect(err).NotTo(HaveOccurred())
+		return events
+	}
+
+	It("should match values regardless of case and keys exactly", func() {
+		events := readCI(store)
+		Expect(events).To(HaveLen(2))
+		for _, event := range events {
+			Expect(event.Tags[0].GetKey()).To(Equal("customer_id"))
+		}
+	})
+
+	It("should project with the same semantics", func() {
+		projector := dcb.StateProjector{
+			ID:           "orders",
+			Query:        dcb.NewQueryBuilder().WithTagCI("customer_id", "ABC-1").Build(),
+			InitialState: 0,
+			TransitionFn: func(state any, event dcb.Event) any { return state.(int) + 1 },
+		}
+		states, _, err := store.Project(ctx, []dcb.StateProjector{projector}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(states["orders"]).To(Equal(2))
+	})
+
+	It("should return the same events after creating the expression index", func() {
+		Expect(dcb.CreateCaseInsensitiveTagIndex(ctx, pool)).To(Succeed())
+		// Safe to re-run
+		Expect(dcb.CreateCaseInsensitiveTagIndex(ctx, pool)).To(Succeed())
+
+		indexed, err := dcb.NewEventStoreWithConfig(ctx, pool, store.GetConfig())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(readCI(indexed)).To(HaveLen(2))
+	})
+
+	It("should be rejected in append conditions", func() {
+		condition := dcb.NewAppendCondition(dcb.NewQueryBuilder().WithTagCI("customer_id", "abc-1").Build())
+		err := store.AppendIf(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("OrderPlaced", dcb.NewTags("customer_id", "abc-1"), []byte(`{}`)),
+		}, condition)
+		Expect(err).To(MatchError(dcb.ErrValidation))
+	})
+})
@@ -0,0 +1,54 @@
+package dcb
+
+import (
+	"time"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tag derivers", func() {
+	var derivingStore dcb.EventStore
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+
+		config := store.GetConfig()
+		config.Clock = dcb.NewFakeClock(GinkgoT())
+		config.TagDerivers = []dcb.TagDeriver{func(event dcb.InputEvent, at time.Time) []dcb.Tag {
+			return []dcb.Tag{dcb.NewTag("year", at.Format("2006"))}
+		}}
+		derivingStore, err = dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should store derived tags so they can be queried", func() {
+		Expect(derivingStore.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("OrderPlaced", dcb.NewTags("order_id", "o1"), []byte(`{}`)),
+		})).To(Succeed())
+
+		events, err := store.Query(ctx, dcb.NewQuery(dcb.NewTags("order_id", "o1"), "OrderPlaced"), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		year := events[0].OccurredAt.UTC().Format("2006")
+
+		byYear, err := store.Query(ctx, dcb.NewQuery(dcb.NewTags("year", year), "OrderPlaced"), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(byYear).To(HaveLen(1))
+	})
+
+	It("should keep the event's own tag with the derived key", func() {
+		Expect(derivingStore.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("OrderPlaced", dcb.NewTags("order_id", "o1", "year", "1999"), []byte(`{}`)),
+		})).To(Succeed())
+
+		events, err := store.Query(ctx, dcb.NewQuery(dcb.NewTags("order_id", "o1"), "OrderPlaced"), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Tags).To(HaveLen(2))
+		Expect(events[0].Tags).To(ContainElement(dcb.NewTag("year", "1999")))
+	})
+})
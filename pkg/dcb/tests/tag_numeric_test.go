@@ -0,0 +1,72 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Numeric tag comparison", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("TicketOpened", dcb.NewTags("ticket_id", "t1", "priority", "1"), []byte(`{}`)),
+			dcb.NewInputEvent("TicketOpened", dcb.NewTags("ticket_id", "t2", "priority", "3"), []byte(`{}`)),
+			dcb.NewInputEvent("TicketOpened", dcb.NewTags("ticket_id", "t3", "priority", "10.5"), []byte(`{}`)),
+			dcb.NewInputEvent("TicketOpened", dcb.NewTags("ticket_id", "t4", "priority", "high"), []byte(`{}`)),
+			dcb.NewInputEvent("TicketOpened", dcb.NewTags("ticket_id", "t5"), []byte(`{}`)),
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	query := dcb.NewQueryBuilder().WithType("TicketOpened").WithTagNumeric("priority", dcb.OpGte, 3).Build()
+
+	ticketIDs := func(events []dcb.Event) []string {
+		ids := make([]string, len(events))
+		for i, event := range events {
+			ids[i] = tagValue(event, "ticket_id")
+		}
+		return ids
+	}
+
+	It("should compare numerically and skip non-numeric values", func() {
+		events, err := store.Query(ctx, query, nil)
+		Expect(err).NotTo(HaveOccurred())
+		// "10.5" >= 3 numerically, though not as text; "high" doesn't fail the query
+		Expect(ticketIDs(events)).To(Equal([]string{"t2", "t3"}))
+	})
+
+	It("should project with the same semantics", func() {
+		projector := dcb.StateProjector{
+			ID:           "urgent",
+			Query:        query,
+			InitialState: 0,
+			TransitionFn: func(state any, event dcb.Event) any { return state.(int) + 1 },
+		}
+		states, _, err := store.Project(ctx, []dcb.StateProjector{projector}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(states["urgent"]).To(Equal(2))
+	})
+
+	It("should return the same events after creating the expression index", func() {
+		Expect(dcb.CreateNumericTagIndex(ctx, pool, "priority")).To(Succeed())
+		// Safe to re-run
+		Expect(dcb.CreateNumericTagIndex(ctx, pool, "priority")).To(Succeed())
+
+		indexed, err := dcb.NewEventStoreWithConfig(ctx, pool, store.GetConfig())
+		Expect(err).NotTo(HaveOccurred())
+		events, err := indexed.Query(ctx, query, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ticketIDs(events)).To(Equal([]string{"t2", "t3"}))
+	})
+
+	It("should be rejected by append conditions", func() {
+		err := store.AppendIf(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("TicketOpened", dcb.NewTags("ticket_id", "t6"), []byte(`{}`)),
+		}, dcb.NewAppendCondition(query))
+		Expect(dcb.IsValidationError(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,55 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tag order", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should store the same tags identically whatever order they are given in", func() {
+		Expect(store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("StudentEnrolled", dcb.NewTags("student_id", "s1", "course_id", "c1"), []byte(`{}`)),
+			dcb.NewInputEvent("StudentEnrolled", dcb.NewTags("course_id", "c1", "student_id", "s1"), []byte(`{}`)),
+		})).To(Succeed())
+
+		var distinct int
+		err := pool.QueryRow(ctx, `SELECT COUNT(DISTINCT tags) FROM events`).Scan(&distinct)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(distinct).To(Equal(1))
+
+		for _, query := range []dcb.Query{
+			dcb.NewQuery(dcb.NewTags("student_id", "s1", "course_id", "c1"), "StudentEnrolled"),
+			dcb.NewQuery(dcb.NewTags("course_id", "c1", "student_id", "s1"), "StudentEnrolled"),
+		} {
+			events, err := store.Query(ctx, query, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(2))
+			for _, event := range events {
+				Expect(event.Tags).To(Equal(dcb.NewTags("course_id", "c1", "student_id", "s1")))
+			}
+		}
+	})
+
+	It("should keep the given order with PreserveTagOrder", func() {
+		config := store.GetConfig()
+		config.PreserveTagOrder = true
+		preserving, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(preserving.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("StudentEnrolled", dcb.NewTags("student_id", "s1", "course_id", "c1"), []byte(`{}`)),
+		})).To(Succeed())
+
+		events, err := store.Query(ctx, dcb.NewQuery(dcb.NewTags("course_id", "c1"), "StudentEnrolled"), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Tags).To(Equal(dcb.NewTags("student_id", "s1", "course_id", "c1")))
+	})
+})
@@ -0,0 +1,86 @@
+package dcb
+
+import (
+	"github.com/jackc/pgx/v5"
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Caller-managed transactions", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	countProjector := dcb.StateProjector{
+		ID:           "count",
+		Query:        dcb.NewQuery(dcb.NewTags("course_id", "c1"), "StudentEnrolled"),
+		InitialState: 0,
+		TransitionFn: func(state any, event dcb.Event) any { return state.(int) + 1 },
+	}
+	enrolled := func(student string) dcb.InputEvent {
+		return dcb.NewInputEvent("StudentEnrolled", dcb.NewTags("course_id", "c1", "student_id", student), []byte(`{}`))
+	}
+
+	It("should commit projection-based appends together with the caller's work", func() {
+		tx, err := pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		Expect(err).NotTo(HaveOccurred())
+		defer tx.Rollback(ctx)
+
+		states, condition, err := store.ProjectTx(ctx, tx, []dcb.StateProjector{countProjector}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(states["count"]).To(Equal(0))
+
+		err = store.AppendIfTx(ctx, tx, []dcb.InputEvent{enrolled("s1")}, condition)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Not visible outside the transaction before commit
+		events, err := store.Query(ctx, dcb.NewQueryAll(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(BeEmpty())
+
+		Expect(tx.Commit(ctx)).To(Succeed())
+
+		events, err = store.Query(ctx, dcb.NewQueryAll(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+	})
+
+	It("should discard appended events when the caller rolls back", func() {
+		tx, err := pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, condition, err := store.ProjectTx(ctx, tx, []dcb.StateProjector{countProjector}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.AppendIfTx(ctx, tx, []dcb.InputEvent{enrolled("s1")}, condition)).To(Succeed())
+		Expect(tx.Rollback(ctx)).To(Succeed())
+
+		events, err := store.Query(ctx, dcb.NewQueryAll(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(BeEmpty())
+	})
+
+	It("should detect events committed concurrently after the projection", func() {
+		tx, err := pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+		Expect(err).NotTo(HaveOccurred())
+		defer tx.Rollback(ctx)
+
+		_, condition, err := store.ProjectTx(ctx, tx, []dcb.StateProjector{countProjector}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(store.Append(ctx, []dcb.InputEvent{enrolled("s2")})).To(Succeed())
+
+		err = store.AppendIfTx(ctx, tx, []dcb.InputEvent{enrolled("s1")}, condition)
+		Expect(err).To(MatchError(dcb.ErrConcurrency))
+	})
+
+	It("should reject a nil transaction", func() {
+		_, _, err := store.ProjectTx(ctx, nil, []dcb.StateProjector{countProjector}, nil)
+		Expect(err).To(MatchError(dcb.ErrValidation))
+
+		err = store.AppendIfTx(ctx, nil, []dcb.InputEvent{enrolled("s1")}, nil)
+		Expect(err).To(MatchError(dcb.ErrValidation))
+	})
+})
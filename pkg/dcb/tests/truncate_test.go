@@ -0,0 +1,42 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Truncate", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should be disabled by default", func() {
+		err := store.Truncate(ctx)
+		Expect(err).To(MatchError(dcb.ErrValidation))
+	})
+
+	It("should delete all events and restart positions when allowed", func() {
+		config := store.GetConfig()
+		config.AllowTruncate = true
+		truncateStore, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+
+		event := dcb.NewInputEvent("Reset", dcb.NewTags("test", "truncate"), []byte(`{}`))
+		Expect(truncateStore.Append(ctx, []dcb.InputEvent{event, event})).To(Succeed())
+
+		Expect(truncateStore.Truncate(ctx)).To(Succeed())
+
+		events, err := truncateStore.Query(ctx, dcb.NewQueryAll(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(BeEmpty())
+
+		Expect(truncateStore.Append(ctx, []dcb.InputEvent{event})).To(Succeed())
+		events, err = truncateStore.Query(ctx, dcb.NewQueryAll(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(1))
+		Expect(events[0].Position).To(Equal(int64(1)))
+	})
+})
@@ -0,0 +1,108 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AppendSkipDuplicates", func() {
+	// A type no other test appends, so the index doesn't affect them while it exists
+	constraint := dcb.UniqueTagConstraint{Name: "test_seat", EventType: "UniqueSeatReserved", TagKeys: []string{"concert_id", "customer_id"}}
+	var unique dcb.EventStore
+
+	reserved := func(concertID, customerID string) dcb.InputEvent {
+		return dcb.NewInputEvent("UniqueSeatReserved", dcb.NewTags("concert_id", concertID, "customer_id", customerID), []byte(`{}`))
+	}
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(dcb.CreateUniqueTagIndexes(ctx, pool, []dcb.UniqueTagConstraint{constraint})).To(Succeed())
+		config := store.GetConfig()
+		config.UniqueTags = []dcb.UniqueTagConstraint{constraint}
+		unique, err = dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		_, err := pool.Exec(ctx, "DROP INDEX IF EXISTS idx_events_unique_test_seat")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should skip events already stored and report them", func() {
+		first, err := unique.AppendSkipDuplicates(ctx, []dcb.InputEvent{reserved("c1", "u1")})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(first.Skipped).To(BeEmpty())
+		Expect(first.Positions[0]).To(BeNumerically(">", 0))
+
+		result, err := unique.AppendSkipDuplicates(ctx, []dcb.InputEvent{
+			reserved("c1", "u2"),
+			reserved("c1", "u1"), // Stored above
+			reserved("c1", "u2"), // Earlier in the same batch
+			reserved("c2", "u1"),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Skipped).To(Equal([]int{1, 2}))
+		Expect(result.Positions[1]).To(BeZero())
+		Expect(result.Positions[2]).To(BeZero())
+		Expect(result.Positions[3]).To(BeNumerically(">", result.Positions[0]))
+		Expect(result.TransactionID).NotTo(BeZero())
+
+		events, err := store.Query(ctx, dcb.NewQuery(nil, "UniqueSeatReserved"), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(3))
+		Expect(events[1].Position).To(Equal(result.Positions[0]))
+	})
+
+	It("should not count skipped duplicates in aggregate versions", func() {
+		config := unique.GetConfig()
+		config.AggregateVersionTagKeys = []string{"concert_id"}
+		versioned, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = pool.Exec(ctx, "TRUNCATE TABLE dcb_aggregate_versions")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = versioned.AppendSkipDuplicates(ctx, []dcb.InputEvent{reserved("c1", "u1")})
+		Expect(err).NotTo(HaveOccurred())
+		result, err := versioned.AppendSkipDuplicates(ctx, []dcb.InputEvent{reserved("c1", "u1"), reserved("c1", "u2")})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Skipped).To(Equal([]int{0}))
+
+		events, err := versioned.Query(ctx, dcb.NewQuery(dcb.NewTags("concert_id", "c1"), "UniqueSeatReserved"), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+		for i, event := range events {
+			version, ok := event.AggregateVersion("concert_id", "c1")
+			Expect(ok).To(BeTrue())
+			Expect(version).To(Equal(int64(i + 1)))
+		}
+
+		var stored int64
+		err = pool.QueryRow(ctx, "SELECT version FROM dcb_aggregate_versions WHERE tag = 'concert_id:c1'").Scan(&stored)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored).To(Equal(int64(2)))
+	})
+
+	It("should not constrain events missing one of the tag keys", func() {
+		partial := dcb.NewInputEvent("UniqueSeatReserved", dcb.NewTags("concert_id", "c1"), []byte(`{}`))
+		result, err := unique.AppendSkipDuplicates(ctx, []dcb.InputEvent{partial, partial})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Skipped).To(BeEmpty())
+	})
+
+	It("should fail other appends on a duplicate", func() {
+		Expect(unique.Append(ctx, []dcb.InputEvent{reserved("c1", "u1")})).To(Succeed())
+		err := unique.Append(ctx, []dcb.InputEvent{reserved("c1", "u1")})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject a store configured with an invalid constraint", func() {
+		config := store.GetConfig()
+		config.UniqueTags = []dcb.UniqueTagConstraint{{Name: "bad name", EventType: "T", TagKeys: []string{"k"}}}
+		_, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(dcb.IsValidationError(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,133 @@
+package dcb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// tagValue returns the value of the event's tag key, "" if it has none
+func tagValue(event dcb.Event, key string) string {
+	for _, tag := range event.Tags {
+		if tag.GetKey() == key {
+			return tag.GetValue()
+		}
+	}
+	return ""
+}
+
+var _ = Describe("MaterializeView", func() {
+	// enrollments counts the enrollments of each course; a CourseCancelled event deletes its row
+	enrollments := dcb.ViewDefinition{
+		Name:  "enrollments",
+		Query: dcb.NewQueryBuilder().WithTypes("StudentEnrolled", "CourseCancelled").Build(),
+		Key: func(event dcb.Event) string {
+			return tagValue(event, "course_id")
+		},
+		Reduce: func(state json.RawMessage, event dcb.Event) (json.RawMessage, error) {
+			if event.Type == "CourseCancelled" {
+				return nil, nil
+			}
+			count := 0
+			if state != nil {
+				if err := json.Unmarshal(state, &count); err != nil {
+					return nil, err
+				}
+			}
+			return json.RawMessage(strconv.Itoa(count + 1)), nil
+		},
+		BatchSize: 2,
+	}
+
+	enroll := func(courseID string, n int) {
+		events := make([]dcb.InputEvent, n)
+		for i := range n {
+			events[i] = dcb.NewInputEvent("StudentEnrolled",
+				dcb.NewTags("course_id", courseID, "student_id", fmt.Sprintf("s%d", i+1)), []byte(`{}`))
+		}
+		Expect(store.Append(ctx, events)).To(Succeed())
+	}
+
+	viewRow := func(name, key string) (json.RawMessage, bool) {
+		state, ok, err := store.QueryView(ctx, name, key)
+		Expect(err).NotTo(HaveOccurred())
+		return state, ok
+	}
+
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = pool.Exec(ctx, "TRUNCATE TABLE dcb_view_rows, dcb_view_checkpoints")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should fold the events into one row per key across batches", func() {
+		enroll("c1", 3)
+		enroll("c2", 2)
+
+		result, err := store.MaterializeView(ctx, enrollments)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Applied).To(Equal(5))
+		Expect(result.Cursor).NotTo(BeNil())
+
+		state, ok := viewRow("enrollments", "c1")
+		Expect(ok).To(BeTrue())
+		Expect(string(state)).To(Equal("3"))
+		state, _ = viewRow("enrollments", "c2")
+		Expect(string(state)).To(Equal("2"))
+		_, ok = viewRow("enrollments", "c3")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should resume after its checkpoint and delete rows reduced to nil", func() {
+		enroll("c1", 2)
+		_, err := store.MaterializeView(ctx, enrollments)
+		Expect(err).NotTo(HaveOccurred())
+
+		enroll("c1", 1)
+		enroll("c2", 1)
+		result, err := store.MaterializeView(ctx, enrollments)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Applied).To(Equal(2))
+		state, _ := viewRow("enrollments", "c1")
+		Expect(string(state)).To(Equal("3"))
+
+		Expect(store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("CourseCancelled", dcb.NewTags("course_id", "c2"), []byte(`{}`)),
+		})).To(Succeed())
+		_, err = store.MaterializeView(ctx, enrollments)
+		Expect(err).NotTo(HaveOccurred())
+		_, ok := viewRow("enrollments", "c2")
+		Expect(ok).To(BeFalse())
+
+		result, err = store.MaterializeView(ctx, enrollments)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Applied).To(Equal(0))
+	})
+
+	It("should roll back the failing batch and keep the earlier ones", func() {
+		enroll("c1", 3)
+		failing := enrollments
+		failing.Reduce = func(state json.RawMessage, event dcb.Event) (json.RawMessage, error) {
+			if tagValue(event, "student_id") == "s3" {
+				return nil, errors.New("boom")
+			}
+			return enrollments.Reduce(state, event)
+		}
+
+		result, err := store.MaterializeView(ctx, failing)
+		resourceErr, ok := dcb.GetResourceError(err)
+		Expect(ok).To(BeTrue())
+		Expect(resourceErr.Resource).To(Equal("handler"))
+		Expect(result.Applied).To(Equal(2))
+
+		state, _ := viewRow("enrollments", "c1")
+		Expect(string(state)).To(Equal("2"))
+	})
+})
@@ -0,0 +1,26 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Connection warm-up", func() {
+	It("should open the configured connections before returning the store", func() {
+		config := store.GetConfig()
+		config.WarmupConnections = 3
+		warm, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warm).NotTo(BeNil())
+		Expect(pool.Stat().TotalConns()).To(BeNumerically(">=", 3))
+	})
+
+	It("should fail construction when the pool can't hold the connections", func() {
+		config := store.GetConfig()
+		config.WarmupConnections = int(pool.Config().MaxConns) + 1
+		_, err := dcb.NewEventStoreWithConfig(ctx, pool, config)
+		Expect(dcb.IsValidationError(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,95 @@
+package dcb
+
+import (
+	"errors"
+
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WithTransaction", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	courseEvent := func(courseID string) []dcb.InputEvent {
+		return []dcb.InputEvent{dcb.NewInputEvent("CourseDefined", dcb.NewTags("course_id", courseID), []byte(`{}`))}
+	}
+
+	It("should commit all operations when the function succeeds", func() {
+		err := store.WithTransaction(ctx, func(txStore dcb.EventStore) error {
+			if err := txStore.Append(ctx, courseEvent("c1")); err != nil {
+				return err
+			}
+			// Reads through the scoped store see the uncommitted append
+			events, err := txStore.Query(ctx, dcb.NewQueryAll(), nil)
+			if err != nil {
+				return err
+			}
+			Expect(events).To(HaveLen(1))
+
+			// Reads outside the transaction don't
+			outside, err := store.Query(ctx, dcb.NewQueryAll(), nil)
+			if err != nil {
+				return err
+			}
+			Expect(outside).To(BeEmpty())
+
+			return txStore.Append(ctx, courseEvent("c2"))
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		events, err := store.Query(ctx, dcb.NewQueryAll(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+	})
+
+	It("should roll back and return the function's error", func() {
+		failure := errors.New("business rule violated")
+		err := store.WithTransaction(ctx, func(txStore dcb.EventStore) error {
+			if err := txStore.Append(ctx, courseEvent("c1")); err != nil {
+				return err
+			}
+			return failure
+		})
+		Expect(err).To(Equal(failure))
+
+		events, err := store.Query(ctx, dcb.NewQueryAll(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(BeEmpty())
+	})
+
+	It("should keep the transaction usable after a failed conditional append", func() {
+		condition := dcb.NewAppendCondition(dcb.NewQuery(dcb.NewTags("course_id", "c1"), "CourseDefined"))
+		err := store.WithTransaction(ctx, func(txStore dcb.EventStore) error {
+			if err := txStore.Append(ctx, courseEvent("c1")); err != nil {
+				return err
+			}
+			Expect(txStore.AppendIf(ctx, courseEvent("c1"), condition)).To(MatchError(dcb.ErrConcurrency))
+			return txStore.Append(ctx, courseEvent("c2"))
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		events, err := store.Query(ctx, dcb.NewQueryAll(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+	})
+
+	It("should reject operations on the scoped store after the function returns", func() {
+		var leaked dcb.EventStore
+		err := store.WithTransaction(ctx, func(txStore dcb.EventStore) error {
+			leaked = txStore
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = leaked.Append(ctx, courseEvent("c1"))
+		Expect(err).To(MatchError(dcb.ErrResource))
+
+		_, err = leaked.Query(ctx, dcb.NewQueryAll(), nil)
+		Expect(err).To(MatchError(dcb.ErrResource))
+	})
+})
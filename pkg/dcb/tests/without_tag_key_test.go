@@ -0,0 +1,54 @@
+package dcb
+
+import (
+	"github.com/rodolfodpk/go-crablet/pkg/dcb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WithoutTagKey", func() {
+	BeforeEach(func() {
+		err := truncateEventsTable(ctx, pool)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(store.Append(ctx, []dcb.InputEvent{
+			dcb.NewInputEvent("OrderPlaced", dcb.NewTags("order_id", "o1", "region", "eu"), []byte(`{}`)),
+			dcb.NewInputEvent("OrderPlaced", dcb.NewTags("order_id", "o2"), []byte(`{}`)),
+			dcb.NewInputEvent("OrderPlaced", dcb.NewTags("order_id", "o3", "regional_office", "lisbon"), []byte(`{}`)),
+			dcb.NewInputEvent("OrderShipped", dcb.NewTags("order_id", "o2"), []byte(`{}`)),
+		})).To(Succeed())
+	})
+
+	orderIDs := func(events []dcb.Event) []string {
+		ids := make([]string, 0, len(events))
+		for _, event := range events {
+			for _, tag := range event.Tags {
+				if tag.GetKey() == "order_id" {
+					ids = append(ids, tag.GetValue())
+				}
+			}
+		}
+		return ids
+	}
+
+	It("should match only events without the key, whatever its value", func() {
+		events, err := store.Query(ctx, dcb.NewQueryBuilder().WithoutTagKey("region").Build(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		// A key sharing the prefix ("regional_office") is a different key
+		Expect(orderIDs(events)).To(Equal([]string{"o2", "o3", "o2"}))
+	})
+
+	It("should AND with the item's other predicates", func() {
+		query := dcb.NewQueryBuilder().WithType("OrderPlaced").WithoutTagKey("region").WithoutTagKey("regional_office").Build()
+		events, err := store.Query(ctx, query, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(orderIDs(events)).To(Equal([]string{"o2"}))
+	})
+
+	It("should match events carrying the key through a tag condition instead", func() {
+		events, err := store.Query(ctx, dcb.NewQueryBuilder().WithTag("region", "eu").Build(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(orderIDs(events)).To(Equal([]string{"o1"}))
+	})
+})
@@ -0,0 +1,394 @@
+package dcb
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Timeout Decorator
+// =============================================================================
+
+// timeoutEventStore wraps an EventStore and applies default timeouts to operations
+// whose context has no deadline. Methods that are not overridden are delegated as-is.
+type timeoutEventStore struct {
+	EventStore
+	readTimeout   time.Duration
+	appendTimeout time.Duration
+}
+
+// WithDefaultTimeouts wraps store so that every operation gets a timeout when the
+// caller's context has no deadline: read for queries and projections, append for appends.
+// A deadline already present on the caller's context is always kept, so a shorter
+// caller-provided deadline is never extended and a longer one is never shortened.
+// A zero or negative duration disables the default for that kind of operation.
+func WithDefaultTimeouts(store EventStore, read, append time.Duration) EventStore {
+	return &timeoutEventStore{
+		EventStore:    store,
+		readTimeout:   read,
+		appendTimeout: append,
+	}
+}
+
+// unwrapEventStore returns the decorated EventStore
+func (ts *timeoutEventStore) unwrapEventStore() EventStore {
+	return ts.EventStore
+}
+
+// withDefaultTimeout returns ctx with a timeout of d if ctx has no deadline yet
+func withDefaultTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// Query reads events with the default read timeout applied
+func (ts *timeoutEventStore) Query(ctx context.Context, query Query, after *Cursor) ([]Event, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.readTimeout)
+	defer cancel()
+	return ts.EventStore.Query(ctx, query, after)
+}
+
+// queryPage reads a Pager page with the default read timeout applied
+func (ts *timeoutEventStore) queryPage(ctx context.Context, query Query, after *Cursor, limit int) ([]Event, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.readTimeout)
+	defer cancel()
+	return queryPage(ctx, ts.EventStore, query, after, limit)
+}
+
+// QueryStream streams events with the default read timeout applied for the lifetime of the stream
+func (ts *timeoutEventStore) QueryStream(ctx context.Context, query Query, after *Cursor) (<-chan Event, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.readTimeout)
+	events, err := ts.EventStore.QueryStream(ctx, query, after)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// Forward events so the timeout context is released once the stream ends
+	out := make(chan Event, cap(events))
+	go func() {
+		defer cancel()
+		defer close(out)
+		forwardUntilDone(ctx, events, out)
+	}()
+	return out, nil
+}
+
+// QueryGrouped streams event groups with the default read timeout applied for the lifetime of the stream
+func (ts *timeoutEventStore) QueryGrouped(ctx context.Context, query Query, groupTagKey string) (<-chan EventGroup, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.readTimeout)
+	groups, err := ts.EventStore.QueryGrouped(ctx, query, groupTagKey)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// Forward groups so the timeout context is released once the stream ends
+	out := make(chan EventGroup, cap(groups))
+	go func() {
+		defer cancel()
+		defer close(out)
+		forwardUntilDone(ctx, groups, out)
+	}()
+	return out, nil
+}
+
+// ReadMulti reads several event tables with the default read timeout applied
+func (ts *timeoutEventStore) ReadMulti(ctx context.Context, tables []string, query Query, opts *ReadOptions) ([]Event, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.readTimeout)
+	defer cancel()
+	return ts.EventStore.ReadMulti(ctx, tables, query, opts)
+}
+
+// ExistsAny checks tag values with the default read timeout applied
+func (ts *timeoutEventStore) ExistsAny(ctx context.Context, eventType string, tagKey string, values []string) (map[string]bool, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.readTimeout)
+	defer cancel()
+	return ts.EventStore.ExistsAny(ctx, eventType, tagKey, values)
+}
+
+// Aggregate computes an aggregate with the default read timeout applied
+func (ts *timeoutEventStore) Aggregate(ctx context.Context, query Query, agg Aggregation) (float64, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.readTimeout)
+	defer cancel()
+	return ts.EventStore.Aggregate(ctx, query, agg)
+}
+
+// ReadByPositions reads events by position with the default read timeout applied
+func (ts *timeoutEventStore) ReadByPositions(ctx context.Context, positions []int64) ([]Event, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.readTimeout)
+	defer cancel()
+	return ts.EventStore.ReadByPositions(ctx, positions)
+}
+
+// ReadByTransaction reads the events of a transaction with the default read timeout applied
+func (ts *timeoutEventStore) ReadByTransaction(ctx context.Context, txID uint64) ([]Event, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.readTimeout)
+	defer cancel()
+	return ts.EventStore.ReadByTransaction(ctx, txID)
+}
+
+// QueryView reads a view row with the default read timeout applied
+func (ts *timeoutEventStore) QueryView(ctx context.Context, viewName, key string) (json.RawMessage, bool, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.readTimeout)
+	defer cancel()
+	return ts.EventStore.QueryView(ctx, viewName, key)
+}
+
+// AppendIfNotExists appends events unless they exist with the default append timeout applied
+func (ts *timeoutEventStore) AppendIfNotExists(ctx context.Context, events []InputEvent, condition AppendCondition, onConflict OnConflict) (CreateResult, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.appendTimeout)
+	defer cancel()
+	return ts.EventStore.AppendIfNotExists(ctx, events, condition, onConflict)
+}
+
+// AppendIfNoneExist appends a batch of creations with the default append timeout applied
+func (ts *timeoutEventStore) AppendIfNoneExist(ctx context.Context, events []InputEvent, tagKey string) error {
+	ctx, cancel := withDefaultTimeout(ctx, ts.appendTimeout)
+	defer cancel()
+	return ts.EventStore.AppendIfNoneExist(ctx, events, tagKey)
+}
+
+// EstimateConditionCost explains a condition check with the default read timeout applied
+func (ts *timeoutEventStore) EstimateConditionCost(ctx context.Context, condition AppendCondition) (CostEstimate, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.readTimeout)
+	defer cancel()
+	return ts.EventStore.EstimateConditionCost(ctx, condition)
+}
+
+// Latest reads the most recent matching event with the default read timeout applied
+func (ts *timeoutEventStore) Latest(ctx context.Context, query Query) (Event, bool, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.readTimeout)
+	defer cancel()
+	return ts.EventStore.Latest(ctx, query)
+}
+
+// QueryETag computes the query's ETag with the default read timeout applied
+func (ts *timeoutEventStore) QueryETag(ctx context.Context, query Query) (string, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.readTimeout)
+	defer cancel()
+	return ts.EventStore.QueryETag(ctx, query)
+}
+
+// ReadIfChanged reads the query's events if its head moved with the default read timeout applied
+func (ts *timeoutEventStore) ReadIfChanged(ctx context.Context, query Query, sinceHead int64) ([]Event, int64, bool, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.readTimeout)
+	defer cancel()
+	return ts.EventStore.ReadIfChanged(ctx, query, sinceHead)
+}
+
+// ConditionFromQuery snapshots a condition with the default read timeout applied
+func (ts *timeoutEventStore) ConditionFromQuery(ctx context.Context, query Query) (AppendCondition, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.readTimeout)
+	defer cancel()
+	return ts.EventStore.ConditionFromQuery(ctx, query)
+}
+
+// Head reads the head position with the default read timeout applied
+func (ts *timeoutEventStore) Head(ctx context.Context) (int64, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.readTimeout)
+	defer cancel()
+	return ts.EventStore.Head(ctx)
+}
+
+// ApproxStats reads the table statistics with the default read timeout applied
+func (ts *timeoutEventStore) ApproxStats(ctx context.Context) (TableStats, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.readTimeout)
+	defer cancel()
+	return ts.EventStore.ApproxStats(ctx)
+}
+
+// Append appends events with the default append timeout applied
+func (ts *timeoutEventStore) Append(ctx context.Context, events []InputEvent) error {
+	ctx, cancel := withDefaultTimeout(ctx, ts.appendTimeout)
+	defer cancel()
+	return ts.EventStore.Append(ctx, events)
+}
+
+// AppendIf appends events conditionally with the default append timeout applied
+func (ts *timeoutEventStore) AppendIf(ctx context.Context, events []InputEvent, condition AppendCondition) error {
+	ctx, cancel := withDefaultTimeout(ctx, ts.appendTimeout)
+	defer cancel()
+	return ts.EventStore.AppendIf(ctx, events, condition)
+}
+
+// AppendInto appends and records the assigned positions with the default append timeout applied
+func (ts *timeoutEventStore) AppendInto(ctx context.Context, entries []*AppendEntry, condition AppendCondition) error {
+	ctx, cancel := withDefaultTimeout(ctx, ts.appendTimeout)
+	defer cancel()
+	return ts.EventStore.AppendInto(ctx, entries, condition)
+}
+
+// AppendSkipDuplicates appends skipping duplicates with the default append timeout applied
+func (ts *timeoutEventStore) AppendSkipDuplicates(ctx context.Context, events []InputEvent) (SkipDuplicatesResult, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.appendTimeout)
+	defer cancel()
+	return ts.EventStore.AppendSkipDuplicates(ctx, events)
+}
+
+// AppendToAggregate appends to an aggregate stream with the default append timeout applied
+func (ts *timeoutEventStore) AppendToAggregate(ctx context.Context, tagKey, tagValue string, expectedVersion int, events []InputEvent) error {
+	ctx, cancel := withDefaultTimeout(ctx, ts.appendTimeout)
+	defer cancel()
+	return ts.EventStore.AppendToAggregate(ctx, tagKey, tagValue, expectedVersion, events)
+}
+
+// AppendIfElse appends events or the onFail events with the default append timeout applied to both
+func (ts *timeoutEventStore) AppendIfElse(ctx context.Context, events []InputEvent, condition AppendCondition, onFail []InputEvent) (AppendIfElseResult, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.appendTimeout)
+	defer cancel()
+	return ts.EventStore.AppendIfElse(ctx, events, condition, onFail)
+}
+
+// ProjectTx projects states in the caller's transaction with the default read timeout applied
+func (ts *timeoutEventStore) ProjectTx(ctx context.Context, tx pgx.Tx, projectors []StateProjector, after *Cursor) (map[string]any, AppendCondition, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.readTimeout)
+	defer cancel()
+	return ts.EventStore.ProjectTx(ctx, tx, projectors, after)
+}
+
+// AppendIfTx appends events in the caller's transaction with the default append timeout applied
+func (ts *timeoutEventStore) AppendIfTx(ctx context.Context, tx pgx.Tx, events []InputEvent, condition AppendCondition) error {
+	ctx, cancel := withDefaultTimeout(ctx, ts.appendTimeout)
+	defer cancel()
+	return ts.EventStore.AppendIfTx(ctx, tx, events, condition)
+}
+
+// AppendAndProject appends and projects with the default append timeout applied
+func (ts *timeoutEventStore) AppendAndProject(ctx context.Context, events []InputEvent, condition AppendCondition, projectors []StateProjector) (map[string]any, AppendCondition, []int64, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.appendTimeout)
+	defer cancel()
+	return ts.EventStore.AppendAndProject(ctx, events, condition, projectors)
+}
+
+// BlockTag blocks a tag with the default append timeout applied
+func (ts *timeoutEventStore) BlockTag(ctx context.Context, key, value string) error {
+	ctx, cancel := withDefaultTimeout(ctx, ts.appendTimeout)
+	defer cancel()
+	return ts.EventStore.BlockTag(ctx, key, value)
+}
+
+// UnblockTag unblocks a tag with the default append timeout applied
+func (ts *timeoutEventStore) UnblockTag(ctx context.Context, key, value string) error {
+	ctx, cancel := withDefaultTimeout(ctx, ts.appendTimeout)
+	defer cancel()
+	return ts.EventStore.UnblockTag(ctx, key, value)
+}
+
+// Archive moves events to an archive table with the default append timeout applied
+func (ts *timeoutEventStore) Archive(ctx context.Context, beforePosition int64, archiveTable string) (int64, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.appendTimeout)
+	defer cancel()
+	return ts.EventStore.Archive(ctx, beforePosition, archiveTable)
+}
+
+// MissingIndexes checks the events table's indexes with the default read timeout applied
+func (ts *timeoutEventStore) MissingIndexes(ctx context.Context) ([]string, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.readTimeout)
+	defer cancel()
+	return ts.EventStore.MissingIndexes(ctx)
+}
+
+// WithTransaction runs fn in a transaction; the transaction-scoped store passed to fn
+// applies the same default timeouts to each of its operations
+func (ts *timeoutEventStore) WithTransaction(ctx context.Context, fn func(txStore EventStore) error) error {
+	if fn == nil {
+		return ts.EventStore.WithTransaction(ctx, nil)
+	}
+	return ts.EventStore.WithTransaction(ctx, func(txStore EventStore) error {
+		return fn(WithDefaultTimeouts(txStore, ts.readTimeout, ts.appendTimeout))
+	})
+}
+
+// Truncate deletes all events with the default append timeout applied
+func (ts *timeoutEventStore) Truncate(ctx context.Context) error {
+	ctx, cancel := withDefaultTimeout(ctx, ts.appendTimeout)
+	defer cancel()
+	return ts.EventStore.Truncate(ctx)
+}
+
+// Project projects states with the default read timeout applied
+func (ts *timeoutEventStore) Project(ctx context.Context, projectors []StateProjector, after *Cursor) (map[string]any, AppendCondition, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.readTimeout)
+	defer cancel()
+	return ts.EventStore.Project(ctx, projectors, after)
+}
+
+// ProjectWithStats projects states and reports what was read with the default read timeout applied
+func (ts *timeoutEventStore) ProjectWithStats(ctx context.Context, projectors []StateProjector, after *Cursor) (map[string]any, AppendCondition, ProjectionStats, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.readTimeout)
+	defer cancel()
+	return ts.EventStore.ProjectWithStats(ctx, projectors, after)
+}
+
+// ProjectTrace traces a projection with the default read timeout applied
+func (ts *timeoutEventStore) ProjectTrace(ctx context.Context, projector StateProjector) ([]StateAtPosition, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.readTimeout)
+	defer cancel()
+	return ts.EventStore.ProjectTrace(ctx, projector)
+}
+
+// ProjectMany projects the projector sets with the default read timeout applied to the whole batch
+func (ts *timeoutEventStore) ProjectMany(ctx context.Context, projectorSets [][]StateProjector) ([]ProjectManyResult, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.readTimeout)
+	defer cancel()
+	return ts.EventStore.ProjectMany(ctx, projectorSets)
+}
+
+// ProjectJSON projects states as JSON with the default read timeout applied
+func (ts *timeoutEventStore) ProjectJSON(ctx context.Context, projectors []StateProjector, after *Cursor) (map[string]json.RawMessage, AppendCondition, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.readTimeout)
+	defer cancel()
+	return ts.EventStore.ProjectJSON(ctx, projectors, after)
+}
+
+// ProjectStream streams projected states with the default read timeout applied for the lifetime of the stream
+func (ts *timeoutEventStore) ProjectStream(ctx context.Context, projectors []StateProjector, after *Cursor) (<-chan map[string]any, <-chan AppendCondition, error) {
+	ctx, cancel := withDefaultTimeout(ctx, ts.readTimeout)
+	states, conditions, err := ts.EventStore.ProjectStream(ctx, projectors, after)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+
+	// Forward both channels so the timeout context is released once the stream ends.
+	// Output channels keep the same capacity as the inner ones so consumers may read them in any order.
+	outStates := make(chan map[string]any, cap(states))
+	outConditions := make(chan AppendCondition, cap(conditions))
+	go func() {
+		defer cancel()
+		defer close(outConditions)
+		defer close(outStates)
+		if !forwardUntilDone(ctx, states, outStates) {
+			for range conditions {
+			}
+			return
+		}
+		forwardUntilDone(ctx, conditions, outConditions)
+	}()
+	return outStates, outConditions, nil
+}
+
+// forwardUntilDone forwards in to out until in is closed or ctx is done, so a consumer that stops
+// reading doesn't block the forwarder forever. When ctx is done it drains in, letting the inner
+// stream finish, and returns false
+func forwardUntilDone[T any](ctx context.Context, in <-chan T, out chan<- T) bool {
+	for value := range in {
+		select {
+		case out <- value:
+		case <-ctx.Done():
+			for range in {
+			}
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,118 @@
+package dcb
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// deadlineRecordingStore records the deadline of the context it receives
+type deadlineRecordingStore struct {
+	EventStore
+	deadline    time.Time
+	hasDeadline bool
+}
+
+func (s *deadlineRecordingStore) Query(ctx context.Context, query Query, after *Cursor) ([]Event, error) {
+	s.deadline, s.hasDeadline = ctx.Deadline()
+	return nil, nil
+}
+
+func (s *deadlineRecordingStore) Append(ctx context.Context, events []InputEvent) error {
+	s.deadline, s.hasDeadline = ctx.Deadline()
+	return nil
+}
+
+func TestWithDefaultTimeouts(t *testing.T) {
+	t.Run("applies operation timeout when context has no deadline", func(t *testing.T) {
+		inner := &deadlineRecordingStore{}
+		store := WithDefaultTimeouts(inner, time.Second, time.Minute)
+
+		before := time.Now()
+		if _, err := store.Query(context.Background(), NewQueryAll(), nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !inner.hasDeadline {
+			t.Fatal("expected read deadline to be set")
+		}
+		if inner.deadline.Sub(before) > 2*time.Second {
+			t.Errorf("expected read timeout to be used, got deadline %v after call", inner.deadline.Sub(before))
+		}
+
+		if err := store.Append(context.Background(), nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !inner.hasDeadline {
+			t.Fatal("expected append deadline to be set")
+		}
+		if inner.deadline.Sub(before) < 30*time.Second {
+			t.Errorf("expected append timeout to be used, got deadline %v after call", inner.deadline.Sub(before))
+		}
+	})
+
+	t.Run("keeps caller deadline", func(t *testing.T) {
+		inner := &deadlineRecordingStore{}
+		store := WithDefaultTimeouts(inner, time.Millisecond, time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+		expected, _ := ctx.Deadline()
+
+		if err := store.Append(ctx, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !inner.deadline.Equal(expected) {
+			t.Errorf("expected caller deadline %v, got %v", expected, inner.deadline)
+		}
+	})
+
+	t.Run("zero duration disables default", func(t *testing.T) {
+		inner := &deadlineRecordingStore{}
+		store := WithDefaultTimeouts(inner, 0, 0)
+
+		if _, err := store.Query(context.Background(), NewQueryAll(), nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if inner.hasDeadline {
+			t.Error("expected no deadline when timeout is zero")
+		}
+	})
+
+	t.Run("unwraps to the inner store", func(t *testing.T) {
+		es := &eventStore{}
+		wrapped := WithDefaultTimeouts(WithDefaultTimeouts(es, time.Second, time.Second), time.Second, time.Second)
+
+		got, ok := asEventStore(wrapped)
+		if !ok || got != es {
+			t.Error("expected asEventStore to unwrap decorators")
+		}
+	})
+}
+
+func TestForwardUntilDone(t *testing.T) {
+	// The inner stream has events ready and closes once its context is done, like the store's streams
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan int, 3)
+	for i := 0; i < cap(in); i++ {
+		in <- i
+	}
+	go func() {
+		<-ctx.Done()
+		close(in)
+	}()
+
+	// Nobody reads out; cancelling must still release the forwarder
+	out := make(chan int)
+	done := make(chan bool)
+	go func() { done <- forwardUntilDone(ctx, in, out) }()
+	cancel()
+
+	select {
+	case forwarded := <-done:
+		if forwarded {
+			t.Error("expected the forward to report it was cut short")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("forwarder blocked after the context was cancelled")
+	}
+}
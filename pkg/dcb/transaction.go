@@ -0,0 +1,114 @@
+package dcb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Caller-Managed Transactions
+// =============================================================================
+
+// ProjectTx projects state like Project, but reads through the caller's transaction tx
+// so that projection, append and the caller's own writes can commit atomically:
+//
+//	tx, _ := store.GetPool().BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+//	defer tx.Rollback(ctx)
+//	states, condition, _ := store.ProjectTx(ctx, tx, projectors, nil)
+//	// decide...
+//	_ = store.AppendIfTx(ctx, tx, events, condition)
+//	_, _ = tx.Exec(ctx, "INSERT INTO my_table ...")
+//	_ = tx.Commit(ctx)
+//
+// The caller owns tx: it chooses the isolation level when beginning it (DefaultReadIsolation
+// is not applied), and it must commit or roll back. Nothing is committed by the store.
+// ProjectTx does not take a projection slot (MaxConcurrentProjections) and is never retried,
+// since the connection is already held by the caller.
+func (es *eventStore) ProjectTx(ctx context.Context, tx pgx.Tx, projectors []StateProjector, after *Cursor) (map[string]any, AppendCondition, error) {
+	if tx == nil {
+		return nil, nil, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "projectTx",
+				Err: fmt.Errorf("transaction cannot be nil"),
+			},
+			Field: "tx",
+			Value: "nil",
+		}
+	}
+
+	if err := validateProjectors("projectTx", projectors); err != nil {
+		return nil, nil, err
+	}
+
+	query := CombineProjectorQueries(projectors)
+	sqlQuery, args, err := es.buildReadQuerySQL(query, after, projectionLimit(projectors))
+	if err != nil {
+		return nil, nil, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "projectTx",
+				Err: fmt.Errorf("failed to build query: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+
+	states, latestCursor, _, err := es.projectRowsInTx(ctx, tx, "projectTx", query, sqlQuery, args, projectors)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Build append condition from projector queries for DCB concurrency control
+	appendCondition := BuildAppendConditionFromQuery(query)
+	if latestCursor != nil {
+		appendCondition.setAfterCursor(latestCursor)
+	}
+
+	return states, appendCondition, nil
+}
+
+// AppendIfTx appends events like AppendIf, but inside the caller's transaction tx.
+// A violated condition returns a ConcurrencyError and leaves tx usable; the caller decides
+// whether to roll back. The events become visible only when the caller commits tx.
+//
+// Isolation: the condition check only sees events committed by other transactions
+// (events appended earlier in tx itself are not checked). Use READ COMMITTED (each statement
+// sees the latest commits, so concurrent writers since ProjectTx are detected) or SERIALIZABLE
+// (conflicts surface as serialization failures on commit). Avoid REPEATABLE READ: its snapshot
+// is taken at the first statement, so events committed concurrently after ProjectTx are invisible
+// to the condition check.
+func (es *eventStore) AppendIfTx(ctx context.Context, tx pgx.Tx, events []InputEvent, condition AppendCondition) error {
+	if tx == nil {
+		return &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "appendIfTx",
+				Err: fmt.Errorf("transaction cannot be nil"),
+			},
+			Field: "tx",
+			Value: "nil",
+		}
+	}
+
+	conditionJSON, err := json.Marshal(condition)
+	if err != nil {
+		return &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "appendIfTx",
+				Err: fmt.Errorf("failed to marshal condition: %w", err),
+			},
+			Resource: "json",
+		}
+	}
+
+	if es.skipEmptyAppend(events) {
+		return nil
+	}
+	if err := es.validateAppendEvents(events, "appendIfTx"); err != nil {
+		return err
+	}
+
+	_, err = es.appendInTx(ctx, tx, events, condition, conditionJSON)
+	return err
+}
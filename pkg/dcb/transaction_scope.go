@@ -0,0 +1,136 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// =============================================================================
+// Transaction-Scoped EventStore
+// =============================================================================
+
+// txScope is the transaction shared by a transaction-scoped EventStore and its callback
+type txScope struct {
+	tx    pgx.Tx
+	ended atomic.Bool
+}
+
+// dbQuerier is the subset of pgxpool.Pool and pgx.Tx used for statements outside a transaction
+type dbQuerier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// WithTransaction runs fn with an EventStore whose operations all run in one transaction
+// (begun with the ContextWithIsolation level of ctx, else DefaultAppendIsolation). The
+// transaction commits when fn returns nil and rolls back otherwise; fn's error is returned
+// unchanged. Operations on txStore that would begin their own transaction use a savepoint
+// instead, so a failed Append only undoes itself.
+//
+// txStore is bound to a single connection: don't use it from several goroutines, drain or close
+// any stream before the next call, and don't keep it after fn returns (its operations then fail
+// with a ResourceError). GetPool on txStore still returns the pool, whose connections are outside
+// the transaction. Calling WithTransaction on txStore nests through a savepoint.
+func (es *eventStore) WithTransaction(ctx context.Context, fn func(txStore EventStore) error) error {
+	if fn == nil {
+		return &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "with_transaction",
+				Err: fmt.Errorf("transaction function must not be nil"),
+			},
+			Field: "fn",
+			Value: "nil",
+		}
+	}
+
+	tx, err := es.beginTx(ctx, pgx.TxOptions{
+		IsoLevel: appendIsolation(ctx, es.config),
+	})
+	if err != nil {
+		return &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "with_transaction",
+				Err: fmt.Errorf("failed to begin transaction: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+
+	scope := &txScope{tx: tx}
+	defer func() {
+		scope.ended.Store(true)
+		tx.Rollback(ctx)
+	}()
+
+	scoped := *es
+	scoped.scope = scope
+	if err := fn(&scoped); err != nil {
+		return err
+	}
+
+	scope.ended.Store(true)
+	if err := tx.Commit(ctx); err != nil {
+		return &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "with_transaction",
+				Err: fmt.Errorf("failed to commit transaction: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	return nil
+}
+
+// beginTx begins a transaction on the pool, or a savepoint in the scope's transaction
+// for a transaction-scoped store
+func (es *eventStore) beginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
+	if es.scope == nil {
+		return es.poolDB().BeginTx(ctx, opts)
+	}
+	if err := es.scopeOpen(); err != nil {
+		return nil, err
+	}
+	return es.scope.tx.Begin(ctx)
+}
+
+// poolDB returns the store's connection pool: the pgxpool.Pool, or the database/sql pool of a
+// store created by NewEventStoreFromSQLDB
+func (es *eventStore) poolDB() interface {
+	dbQuerier
+	BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error)
+} {
+	if es.sqlDB != nil {
+		return es.sqlDB
+	}
+	return es.pool
+}
+
+// db returns where single statements run: the pool, or the scope's transaction
+func (es *eventStore) db() (dbQuerier, error) {
+	if es.scope == nil {
+		return es.poolDB(), nil
+	}
+	if err := es.scopeOpen(); err != nil {
+		return nil, err
+	}
+	return es.scope.tx, nil
+}
+
+// scopeOpen fails once the WithTransaction callback owning the scope has returned
+func (es *eventStore) scopeOpen() error {
+	if es.scope.ended.Load() {
+		return &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "with_transaction",
+				Err: fmt.Errorf("transaction-scoped EventStore used after its WithTransaction callback returned"),
+			},
+			Resource: "transaction",
+		}
+	}
+	return nil
+}
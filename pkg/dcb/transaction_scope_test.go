@@ -0,0 +1,29 @@
+package dcb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestEndedScopeRejectsOperations(t *testing.T) {
+	es := &eventStore{scope: &txScope{}}
+	es.scope.ended.Store(true)
+
+	if _, err := es.beginTx(context.Background(), pgx.TxOptions{}); !IsResourceError(err) {
+		t.Fatalf("beginTx after scope ended: expected ResourceError, got %v", err)
+	}
+	_, err := es.db()
+	resErr, ok := GetResourceError(err)
+	if !ok || resErr.Resource != "transaction" {
+		t.Fatalf("db after scope ended: expected ResourceError on %q, got %v", "transaction", err)
+	}
+}
+
+func TestWithTransactionRequiresFunction(t *testing.T) {
+	es := &eventStore{}
+	if err := es.WithTransaction(context.Background(), nil); !IsValidationError(err) {
+		t.Fatalf("expected ValidationError for nil function, got %v", err)
+	}
+}
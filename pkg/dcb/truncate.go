@@ -0,0 +1,64 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Truncate (tests and benchmarks only)
+// =============================================================================
+
+// Truncate DESTRUCTIVELY deletes all events and restarts the position sequence.
+// It is meant for tests, benchmarks and examples so they don't hand-write TRUNCATE statements
+// against the wrong table. It is disabled unless EventStoreConfig.AllowTruncate is true
+// and returns a ValidationError otherwise; never enable it in production.
+// The configured ArchiveTable, if it exists, is truncated too so archived positions can't collide
+// with positions reused after the restart.
+func (es *eventStore) Truncate(ctx context.Context) error {
+	if !es.config.AllowTruncate {
+		return &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "truncate",
+				Err: fmt.Errorf("truncate is disabled; set EventStoreConfig.AllowTruncate to enable it"),
+			},
+			Field: "allowTruncate",
+			Value: "false",
+		}
+	}
+
+	db, err := es.db()
+	if err != nil {
+		return err
+	}
+
+	tables := "events"
+	if es.config.ArchiveTable != "" {
+		var exists bool
+		if err := db.QueryRow(ctx, `SELECT to_regclass($1) IS NOT NULL`, es.config.ArchiveTable).Scan(&exists); err != nil {
+			return &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "truncate",
+					Err: fmt.Errorf("failed to check archive table: %w", err),
+				},
+				Resource: "database",
+			}
+		}
+		if exists {
+			tables += ", " + pgx.Identifier{es.config.ArchiveTable}.Sanitize()
+		}
+	}
+
+	if _, err := db.Exec(ctx, "TRUNCATE TABLE "+tables+" RESTART IDENTITY CASCADE"); err != nil {
+		return &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "truncate",
+				Err: fmt.Errorf("failed to truncate events: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	return nil
+}
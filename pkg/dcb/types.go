@@ -31,6 +31,17 @@ type Event struct {
 	TransactionID uint64    `json:"transaction_id"`
 	Position      int64     `json:"position"`
 	OccurredAt    time.Time `json:"occurred_at"`
+	Metadata      []byte    `json:"metadata,omitempty"`
+	// CommandTxID is the transaction of the ExecuteCommand that appended the event (the
+	// events.command_transaction_id column), 0 for other appends; see CommandTransactionID
+	CommandTxID uint64 `json:"command_transaction_id,omitempty"`
+}
+
+// EventGroup holds the events that share one value of a grouping tag key (see QueryGrouped)
+// Events are ordered by (transaction_id, position)
+type EventGroup struct {
+	TagValue string  `json:"tag_value"`
+	Events   []Event `json:"events"`
 }
 
 // Cursor represents a position in the event stream
@@ -92,6 +103,64 @@ type EventStoreConfig struct {
 	// Larger batches improve performance but increase memory usage and transaction duration
 	MaxAppendBatchSize int `json:"max_append_batch_size"`
 
+	// MaxEventDataSize limits the size in bytes of each event's JSON data (0 = no limit)
+	// Oversized events are rejected with a ValidationError before the append reaches the database
+	MaxEventDataSize int `json:"max_event_data_size"`
+
+	// AllowEmptyAppend makes appending an empty event slice a successful no-op instead of a ValidationError
+	// Nothing is written and append conditions are not evaluated; AppendAndProject still projects and
+	// ExecuteCommand returns the handler output without storing the command. Default false
+	AllowEmptyAppend bool `json:"allow_empty_append"`
+
+	// ProducerTag identifies the service appending events, as "key:value" (e.g. "source:orders-service")
+	// It is stored in every appended event's metadata under key, unless the event's metadata already
+	// has that key; WithProducer overrides it for one append. Empty (default) attaches nothing
+	ProducerTag string `json:"producer_tag"`
+
+	// ProducerAsTag stores ProducerTag as a queryable tag instead of metadata; an event that already
+	// has a tag with the producer's key keeps its own. Default false
+	ProducerAsTag bool `json:"producer_as_tag"`
+
+	// UniqueTags declares event types that are unique per combination of tag values (e.g. one
+	// TicketsBooked per concert_id and customer_id). AppendSkipDuplicates skips events violating
+	// them; each needs the unique index created by CreateUniqueTagIndexes. Empty (default) declares none
+	UniqueTags []UniqueTagConstraint `json:"unique_tags"`
+
+	// TagDerivers compute tags added to every appended event, e.g. time buckets or partition keys,
+	// so handlers don't each compute them (see TagDeriver). Derived tags are stored with the event
+	// and queryable like its own tags. A derived tag whose key the event already has is dropped,
+	// so callers' tags win; a derived tag with a reserved key (ReservedTagKeyPrefixes) or a blocked
+	// tag (BlockTag) fails the append like a caller's tag. Empty (default) derives nothing
+	TagDerivers []TagDeriver `json:"-"`
+
+	// DerivedTagsOverride lets a derived tag replace the event's own tags with the same key
+	// instead of being dropped. Default false
+	DerivedTagsOverride bool `json:"derived_tags_override"`
+
+	// PreserveTagOrder stores each event's tags in the order they were given. By default they are
+	// sorted by key, then value, including derived and producer tags, so the same logical tags are
+	// always stored the same way and Event.Tags comes back in a stable order; tag order has no
+	// meaning for matching either way. Default false
+	PreserveTagOrder bool `json:"preserve_tag_order"`
+
+	// SharedTagBlocks stores BlockTag's blocks in the dcb_blocked_tags table (created by the
+	// constructor), so a block reaches every store with the setting on the same database within
+	// TagBlockRefreshInterval. Default false: blocks only apply to the store that made them
+	SharedTagBlocks bool `json:"shared_tag_blocks"`
+
+	// ReservedTagKeyPrefixes lists tag key prefixes that only the store's own mechanisms may write,
+	// e.g. "tenant_id" or "_": appending an event with a tag key starting with one of them fails with
+	// a ValidationError, derived tags (TagDerivers) included. The tag the store attaches itself
+	// (ProducerAsTag) is exempt, so reserving the producer's key stops callers from forging it.
+	// Empty (default) reserves nothing
+	ReservedTagKeyPrefixes []string `json:"reserved_tag_key_prefixes"`
+
+	// AggregateVersionTagKeys lists tag keys identifying aggregates (e.g. "account_id") whose events
+	// get a monotonic per-aggregate version at append time, stored in their metadata (see
+	// Event.AggregateVersion) and counted in the dcb_aggregate_versions table. Appends to the same
+	// aggregate then serialize on its version row, so it costs write throughput. Empty (default) versions nothing
+	AggregateVersionTagKeys []string `json:"aggregate_version_tag_keys"`
+
 	// DefaultAppendIsolation sets the PostgreSQL transaction isolation level for append operations
 	// Higher isolation levels provide stronger consistency guarantees but may impact performance
 	DefaultAppendIsolation IsolationLevel `json:"default_append_isolation"`
@@ -105,6 +174,13 @@ type EventStoreConfig struct {
 	// This is a defensive timeout to prevent hanging appends
 	AppendTimeout int `json:"append_timeout"`
 
+	// LockTimeout sets the maximum time (in milliseconds) the operations that take explicit locks wait
+	// for one: AppendIfNotExists, AppendIfNoneExist, Archive, and appends with AggregateVersionTagKeys.
+	// The effective PostgreSQL lock_timeout is min(LockTimeout, time left until the context deadline),
+	// so a short request budget is never exceeded by a lock wait. 0 means only the deadline applies.
+	// Other appends take no explicit locks and don't set it
+	LockTimeout int `json:"lock_timeout"`
+
 	// =============================================================================
 	// QUERY OPERATIONS CONFIGURATION
 	// =============================================================================
@@ -113,7 +189,60 @@ type EventStoreConfig struct {
 	// This is a defensive timeout to prevent hanging queries
 	QueryTimeout int `json:"query_timeout"`
 
-	// StreamBuffer sets the channel buffer size for streaming operations (QueryStream, ProjectStream)
+	// DisableQuerySQLCache turns off the per-store cache of read SQL by query shape. With the cache,
+	// reads whose queries have the same shape (the same predicates per item, only other values)
+	// reuse one SQL text instead of generating it, and pgx's statement cache then reuses the
+	// prepared statement; QuerySQLCacheStats reports the hit rate. Default false (cache enabled)
+	DisableQuerySQLCache bool `json:"disable_query_sql_cache"`
+
+	// ReadRetries sets how many times read-only operations (Query, QueryStream, QueryGrouped, Project,
+	// ProjectStream, ExistsAny) are retried after a transient connection error (see IsTransient)
+	// Appends are never retried automatically. 0 disables retries
+	ReadRetries int `json:"read_retries"`
+
+	// ReadRetryBackoff sets the delay (in milliseconds) before the first read retry;
+	// the delay doubles on each further retry
+	ReadRetryBackoff int `json:"read_retry_backoff"`
+
+	// Clock supplies time to the store (see Clock); nil (default) uses the real clock.
+	// When set, appended events and stored commands get their occurred_at from Clock.Now()
+	// instead of the database transaction timestamp, so tests can inject NewFakeClock for
+	// deterministic timestamps
+	Clock Clock `json:"-"`
+
+	// PositionAllocator assigns positions to appended events (see PositionAllocator for the contract)
+	// nil (default) uses the events position sequence inside the append functions
+	PositionAllocator PositionAllocator `json:"-"`
+
+	// ArchiveTable names the table populated by Archive; when set, reads transparently
+	// include archived events (events UNION ALL archive). Append conditions never do.
+	// Like Archive's table it must be "events_archive" or start with "events_archive_".
+	// Empty (default) means reads only see the events table
+	ArchiveTable string `json:"archive_table"`
+
+	// ReadableTables lists additional tables with the events table's structure that ReadMulti may
+	// read, e.g. tenant-partitioned event tables; "events" and ArchiveTable are always readable.
+	// Names must be plain identifiers. Empty (default) allows no other tables
+	ReadableTables []string `json:"readable_tables"`
+
+	// AllowTruncate enables the DESTRUCTIVE Truncate method (tests and benchmarks only)
+	// Default false: Truncate fails, so production stores can't be wiped by accident
+	AllowTruncate bool `json:"allow_truncate"`
+
+	// WarmupConnections makes NewEventStoreWithConfig open and ping that many pool connections at
+	// once before returning, so the first requests after a deploy don't pay for connection setup.
+	// It must not exceed the pool's MaxConns. The warm-up is bounded by QueryTimeout (10s if unset)
+	// and a failure fails construction. 0 (default) opens no connections up front
+	WarmupConnections int `json:"warmup_connections"`
+
+	// ValidateStreamConnections pings the connection a stream (QueryStream, QueryGrouped,
+	// ProjectStream) checks out before starting its query. A dead one, e.g. terminated while idle by
+	// the server or a connection reaper in between, is evicted from the pool and the checkout retried
+	// once, instead of failing the stream mid-read; StreamStats counts the evictions. It costs a
+	// round trip per stream. Default false
+	ValidateStreamConnections bool `json:"validate_stream_connections"`
+
+	// StreamBuffer sets the channel buffer size for streaming operations (QueryStream, QueryGrouped, ProjectStream)
 	// Larger buffers improve throughput but increase memory usage
 	StreamBuffer int `json:"stream_buffer"`
 
@@ -126,10 +255,39 @@ type EventStoreConfig struct {
 	// Default: 50 concurrent projections (supports 100 users with reasonable queuing)
 	MaxConcurrentProjections int `json:"max_concurrent_projections"`
 
+	// MaxConcurrentStreams limits the number of event streams (QueryStream, QueryGrouped) open at once
+	// Each open stream holds a pool connection until it is drained or its context ends, so slow
+	// consumers could otherwise exhaust the pool. Opening more fails fast with a ResourceError
+	// Default: 50 open streams (ProjectStream is limited by MaxConcurrentProjections instead)
+	MaxConcurrentStreams int `json:"max_concurrent_streams"`
+
+	// ProjectionWorkers limits how many projector sets ProjectMany projects at once, each with its
+	// own query. It is capped at MaxConcurrentProjections, whose slots the workers take.
+	// Default: DefaultProjectionWorkers (4)
+	ProjectionWorkers int `json:"projection_workers"`
+
 	// MaxProjectionGoroutines limits the number of internal goroutines used per projection operation
 	// This prevents excessive goroutine creation in ProjectStream operations
 	// Default: 100 goroutines per projection
 	MaxProjectionGoroutines int `json:"max_projection_goroutines"`
+
+	// MaxProjectionStateBytes bounds the approximate memory of each projector's state (0 = no limit)
+	// A projector whose state grows past it (e.g. a map accumulating every entity) fails the
+	// projection with a ResourceError (Resource "projection_memory") instead of exhausting memory.
+	// Sizes are estimated by walking the state as it grows, so it can be exceeded by up to a
+	// quarter before the check fires. For unbounded cardinality use Aggregate or narrower queries
+	MaxProjectionStateBytes int `json:"max_projection_state_bytes"`
+
+	// ReturnPartialOnCancel makes Project return the states folded from the events read so far,
+	// with a PartialResultError (errors.Is ErrPartial) holding the last cursor, when its context is
+	// cancelled mid-read, e.g. for progressive rendering. Deadlines still fail without states.
+	// Default false: a cancelled projection returns only the error
+	ReturnPartialOnCancel bool `json:"return_partial_on_cancel"`
+
+	// OnProjectionStats is called with the ProjectionStats of every successful Project and
+	// ProjectStream, e.g. to record metrics or log projections that replay too many events.
+	// It runs synchronously on the projecting goroutine, so keep it fast. nil (default) disables it
+	OnProjectionStats func(ProjectionStats) `json:"-"`
 }
 
 // =============================================================================
@@ -0,0 +1,290 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// =============================================================================
+// Unique Tags (Idempotent Appends)
+// =============================================================================
+
+// UniqueTagConstraint declares that events of EventType are unique per combination of values of
+// TagKeys, e.g. one TicketsBooked per concert_id and customer_id. It is enforced by the unique
+// index idx_events_unique_<Name> that CreateUniqueTagIndexes creates. Events of EventType missing
+// one of the keys (or with several values for one) are not constrained
+type UniqueTagConstraint struct {
+	Name      string   `json:"name"`       // Index name suffix; a plain identifier of at most 45 characters
+	EventType string   `json:"event_type"` // Constrained event type
+	TagKeys   []string `json:"tag_keys"`   // Tag keys whose values identify an event; order doesn't matter
+}
+
+// indexName returns the name of the index enforcing the constraint
+func (c UniqueTagConstraint) indexName() string {
+	return "idx_events_unique_" + c.Name
+}
+
+// keys returns TagKeys sorted, so the index expression doesn't depend on declaration order
+func (c UniqueTagConstraint) keys() []string {
+	return slices.Sorted(slices.Values(c.TagKeys))
+}
+
+// indexSQL returns the statement creating the constraint's partial unique expression index
+func (c UniqueTagConstraint) indexSQL() string {
+	keys := make([]string, len(c.TagKeys))
+	for i, key := range c.keys() {
+		keys[i] = quoteLiteral(key)
+	}
+	return fmt.Sprintf("CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS %s ON events (unique_tag_values(tags, ARRAY[%s]::TEXT[])) WHERE type = %s",
+		pgx.Identifier{c.indexName()}.Sanitize(), strings.Join(keys, ", "), quoteLiteral(c.EventType))
+}
+
+// matches reports whether event is constrained by c, i.e. a conflict on it can come from c's index
+func (c UniqueTagConstraint) matches(event InputEvent) bool {
+	if event.GetType() != c.EventType {
+		return false
+	}
+	for _, key := range c.TagKeys {
+		if !slices.ContainsFunc(event.GetTags(), func(tag Tag) bool { return tag.GetKey() == key }) {
+			return false
+		}
+	}
+	return true
+}
+
+// quoteLiteral quotes s as an SQL string literal
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// validateUniqueTagConstraint validates an EventStoreConfig.UniqueTags entry
+func validateUniqueTagConstraint(op string, c UniqueTagConstraint) error {
+	invalid := func(field, value string, err error) error {
+		return &ValidationError{
+			EventStoreError: EventStoreError{Op: op, Err: fmt.Errorf("unique tag constraint %q: %w", c.Name, err)},
+			Field:           field,
+			Value:           value,
+		}
+	}
+	if !tableNamePattern.MatchString(c.Name) || len(c.indexName()) > 63 {
+		return invalid("uniqueTags.name", c.Name, fmt.Errorf("name must be a plain identifier of at most 45 characters"))
+	}
+	if c.EventType == "" || len(c.EventType) > 64 {
+		return invalid("uniqueTags.eventType", c.EventType, fmt.Errorf("event type must have 1 to 64 characters"))
+	}
+	if len(c.TagKeys) == 0 {
+		return invalid("uniqueTags.tagKeys", "empty", fmt.Errorf("at least one tag key is required"))
+	}
+	keys := c.keys()
+	for i, key := range keys {
+		if key == "" || strings.Contains(key, ":") {
+			return invalid("uniqueTags.tagKeys", key, fmt.Errorf("tag key %q is invalid", key))
+		}
+		if i > 0 && keys[i-1] == key {
+			return invalid("uniqueTags.tagKeys", key, fmt.Errorf("tag key %q is repeated", key))
+		}
+	}
+	return nil
+}
+
+// validateUniqueTags validates EventStoreConfig.UniqueTags; names must be distinct
+func validateUniqueTags(op string, constraints []UniqueTagConstraint) error {
+	for i, c := range constraints {
+		if err := validateUniqueTagConstraint(op, c); err != nil {
+			return err
+		}
+		if slices.ContainsFunc(constraints[:i], func(other UniqueTagConstraint) bool { return other.Name == c.Name }) {
+			return &ValidationError{
+				EventStoreError: EventStoreError{Op: op, Err: fmt.Errorf("unique tag constraint name %q is repeated", c.Name)},
+				Field:           "uniqueTags.name",
+				Value:           c.Name,
+			}
+		}
+	}
+	return nil
+}
+
+// CreateUniqueTagIndexes creates the unique index of each constraint, as declared in
+// EventStoreConfig.UniqueTags, over the unique_tag_values SQL function from
+// docker-entrypoint-initdb.d/schema.sql (a missing function is a ConfigurationError). It is safe to
+// re-run, and the indexes are built CONCURRENTLY so appends are not blocked (which means it can't
+// run inside a transaction).
+//
+// Building an index fails if stored events already violate it; the failed build leaves an INVALID
+// index behind, which IF NOT EXISTS would then skip, so drop it (DROP INDEX CONCURRENTLY
+// idx_events_unique_<Name>) before retrying. To change a constraint's keys, declare it under a new
+// Name, create its index and drop the old one; an index is never altered in place.
+func CreateUniqueTagIndexes(ctx context.Context, pool *pgxpool.Pool, constraints []UniqueTagConstraint) error {
+	if err := validateUniqueTags("create_unique_tag_indexes", constraints); err != nil {
+		return err
+	}
+	for _, c := range constraints {
+		if _, err := pool.Exec(ctx, c.indexSQL()); err != nil {
+			if configErr := asMissingFunctionError("create_unique_tag_indexes", err); configErr != nil {
+				return configErr
+			}
+			return wrapDatabaseError("create_unique_tag_indexes", "failed to create unique tag index", err)
+		}
+	}
+	return nil
+}
+
+// SkipDuplicatesResult reports what AppendSkipDuplicates appended
+type SkipDuplicatesResult struct {
+	Positions     []int64 // Position of each event in input order; 0 for a skipped duplicate
+	Skipped       []int   // Indexes of the events skipped as duplicates, ascending
+	TransactionID uint64  // The appending transaction, set even if every event was skipped
+}
+
+// appendSkippingDuplicates inserts the events, skipping those that conflict with a unique index,
+// and returns the position of each one (NULL when skipped) in input order. Positions are drawn
+// before the insert, so skipped events leave gaps in the sequence
+const appendSkippingDuplicates = `
+	WITH input AS MATERIALIZED (
+		SELECT t.ord, t.type, t.tag_string::TEXT[] AS tags, t.data, t.metadata,
+			COALESCE(t.position, nextval(pg_get_serial_sequence('events', 'position'))) AS position
+		FROM UNNEST($1::TEXT[], $2::TEXT[], $3::JSONB[], $4::JSONB[], $5::BIGINT[]) WITH ORDINALITY AS t(type, tag_string, data, metadata, position, ord)
+		ORDER BY t.ord
+	), inserted AS (
+		INSERT INTO events (type, tags, data, transaction_id, metadata, position, occurred_at)
+		SELECT type, tags, data, pg_current_xact_id(), metadata, position, COALESCE($6::TIMESTAMPTZ, CURRENT_TIMESTAMP)
+		FROM input ORDER BY ord
+		ON CONFLICT DO NOTHING
+		RETURNING events.position
+	)
+	SELECT inserted.position, pg_current_xact_id()
+	FROM input LEFT JOIN inserted ON inserted.position = input.position
+	ORDER BY input.ord`
+
+// AppendSkipDuplicates appends events unconditionally like Append, except that events conflicting
+// with an EventStoreConfig.UniqueTags constraint are skipped instead of failing the append: they
+// were already stored (or occur earlier in the same batch). This makes per-entity writes
+// idempotent in the database, without a read and a conditional append:
+//
+//	result, err := store.AppendSkipDuplicates(ctx, []dcb.InputEvent{booked})
+//	if len(result.Skipped) > 0 { /* this customer already booked this concert */ }
+//
+// Skipped events get no aggregate version (EventStoreConfig.AggregateVersionTagKeys): the stored
+// ones are versioned after the insert and their metadata updated in the same transaction.
+// The indexes must exist (CreateUniqueTagIndexes); otherwise nothing is deduplicated. Other append
+// methods don't skip: a duplicate fails them with the database's unique violation.
+// A store without UniqueTags rejects the call with a ValidationError
+func (es *eventStore) AppendSkipDuplicates(ctx context.Context, events []InputEvent) (SkipDuplicatesResult, error) {
+	if len(es.config.UniqueTags) == 0 {
+		return SkipDuplicatesResult{}, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "appendSkipDuplicates",
+				Err: fmt.Errorf("no unique tag constraints configured (EventStoreConfig.UniqueTags)"),
+			},
+			Field: "uniqueTags",
+			Value: "empty",
+		}
+	}
+	if es.skipEmptyAppend(events) {
+		return SkipDuplicatesResult{}, nil
+	}
+	if err := es.validateAppendEvents(events, "appendSkipDuplicates"); err != nil {
+		return SkipDuplicatesResult{}, err
+	}
+
+	tx, err := es.beginTx(ctx, pgx.TxOptions{
+		IsoLevel: appendIsolation(ctx, es.config),
+	})
+	if err != nil {
+		return SkipDuplicatesResult{}, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "appendSkipDuplicates",
+				Err: fmt.Errorf("failed to begin transaction: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	defer tx.Rollback(ctx)
+
+	if err := es.applyVersionLockTimeout(ctx, tx, "appendSkipDuplicates"); err != nil {
+		return SkipDuplicatesResult{}, err
+	}
+
+	types, tags, data, metadata, err := es.appendColumns(ctx, events)
+	if err != nil {
+		return SkipDuplicatesResult{}, err
+	}
+	positions, err := es.allocatePositions(ctx, tx, len(events))
+	if err != nil {
+		return SkipDuplicatesResult{}, err
+	}
+
+	rows, err := tx.Query(ctx, appendSkippingDuplicates, types, tags, data, metadata, positions, es.occurredAt())
+	if err != nil {
+		return SkipDuplicatesResult{}, skipDuplicatesError(err)
+	}
+	result := SkipDuplicatesResult{Positions: make([]int64, 0, len(events))}
+	var position *int64
+	_, err = pgx.ForEachRow(rows, []any{&position, &result.TransactionID}, func() error {
+		if position == nil {
+			result.Skipped = append(result.Skipped, len(result.Positions))
+			result.Positions = append(result.Positions, 0)
+		} else {
+			result.Positions = append(result.Positions, *position)
+		}
+		return nil
+	})
+	if err != nil {
+		return SkipDuplicatesResult{}, skipDuplicatesError(err)
+	}
+
+	// ON CONFLICT DO NOTHING covers every unique index, including the position key: an event no
+	// constraint applies to can only have clashed on its position (e.g. a faulty PositionAllocator)
+	for _, i := range result.Skipped {
+		if !slices.ContainsFunc(es.config.UniqueTags, func(c UniqueTagConstraint) bool { return c.matches(events[i]) }) {
+			return SkipDuplicatesResult{}, &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "appendSkipDuplicates",
+					Err: fmt.Errorf("event %d (%s) conflicted with a stored row but matches no unique tag constraint", i, events[i].GetType()),
+				},
+				Resource: "database",
+			}
+		}
+	}
+
+	// Versions are assigned once the insert shows which events were stored
+	if err := es.versionStoredEvents(ctx, tx, events, metadata, result.Positions); err != nil {
+		return SkipDuplicatesResult{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return SkipDuplicatesResult{}, &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "appendSkipDuplicates",
+				Err: fmt.Errorf("failed to commit transaction: %w", err),
+			},
+			Resource: "database",
+		}
+	}
+	return result, nil
+}
+
+// skipDuplicatesError wraps an error of the AppendSkipDuplicates insert
+func skipDuplicatesError(err error) error {
+	if isLockTimeout(err) {
+		return &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "appendSkipDuplicates",
+				Err: fmt.Errorf("lock wait timed out: %w", err),
+			},
+			Resource: "lock",
+		}
+	}
+	return &ResourceError{
+		EventStoreError: EventStoreError{
+			Op:  "appendSkipDuplicates",
+			Err: fmt.Errorf("failed to append events: %w", err),
+		},
+		Resource: "database",
+	}
+}
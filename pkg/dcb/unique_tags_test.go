@@ -0,0 +1,55 @@
+package dcb
+
+import (
+	"context"
+	"testing"
+)
+
+func TestUniqueTagConstraintIndexSQL(t *testing.T) {
+	c := UniqueTagConstraint{Name: "booking", EventType: "Tickets'Booked", TagKeys: []string{"customer_id", "concert_id"}}
+	if err := validateUniqueTags("test", []UniqueTagConstraint{c}); err != nil {
+		t.Fatalf("expected a valid constraint, got %v", err)
+	}
+	// Keys are sorted so the index doesn't depend on declaration order; literals are quoted
+	want := `CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS "idx_events_unique_booking" ON events (unique_tag_values(tags, ARRAY['concert_id', 'customer_id']::TEXT[])) WHERE type = 'Tickets''Booked'`
+	if got := c.indexSQL(); got != want {
+		t.Errorf("unexpected index:\n got %s\nwant %s", got, want)
+	}
+
+	booked := NewInputEvent("Tickets'Booked", NewTags("concert_id", "c1", "customer_id", "u1"), []byte(`{}`))
+	partial := NewInputEvent("Tickets'Booked", NewTags("concert_id", "c1"), []byte(`{}`))
+	if !c.matches(booked) || c.matches(partial) {
+		t.Error("expected only events with every tag key to match")
+	}
+}
+
+func TestValidateUniqueTags(t *testing.T) {
+	valid := UniqueTagConstraint{Name: "booking", EventType: "TicketsBooked", TagKeys: []string{"concert_id"}}
+	for name, tc := range map[string]struct {
+		constraints []UniqueTagConstraint
+		field       string
+	}{
+		"bad name":      {[]UniqueTagConstraint{{Name: "drop table", EventType: "T", TagKeys: []string{"k"}}}, "uniqueTags.name"},
+		"long name":     {[]UniqueTagConstraint{{Name: "n123456789012345678901234567890123456789012345", EventType: "T", TagKeys: []string{"k"}}}, "uniqueTags.name"},
+		"no type":       {[]UniqueTagConstraint{{Name: "n", TagKeys: []string{"k"}}}, "uniqueTags.eventType"},
+		"no keys":       {[]UniqueTagConstraint{{Name: "n", EventType: "T"}}, "uniqueTags.tagKeys"},
+		"repeated key":  {[]UniqueTagConstraint{{Name: "n", EventType: "T", TagKeys: []string{"k", "k"}}}, "uniqueTags.tagKeys"},
+		"key with :":    {[]UniqueTagConstraint{{Name: "n", EventType: "T", TagKeys: []string{"a:b"}}}, "uniqueTags.tagKeys"},
+		"repeated name": {[]UniqueTagConstraint{valid, valid}, "uniqueTags.name"},
+	} {
+		err := validateUniqueTags("test", tc.constraints)
+		if validationErr, ok := GetValidationError(err); !ok || validationErr.Field != tc.field {
+			t.Errorf("%s: expected a ValidationError for %s, got %v", name, tc.field, err)
+		}
+	}
+}
+
+func TestAppendSkipDuplicatesRequiresUniqueTags(t *testing.T) {
+	// es has no pool: the missing configuration must be reported before the database
+	es := newEventStore(nil, EventStoreConfig{})
+	booked := NewInputEvent("TicketsBooked", NewTags("concert_id", "c1"), []byte(`{}`))
+	_, err := es.AppendSkipDuplicates(context.Background(), []InputEvent{booked})
+	if validationErr, ok := GetValidationError(err); !ok || validationErr.Field != "uniqueTags" {
+		t.Errorf("expected a ValidationError for uniqueTags, got %v", err)
+	}
+}
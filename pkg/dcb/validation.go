@@ -55,6 +55,56 @@ func validateQueryTags(query Query) error {
 	return nil
 }
 
+// validateCombinedCondition validates a condition that append_events_if checks as one combined
+// match (AppendIf and the other conditional appends): on top of validateConditionQuery it rejects
+// FailIfAnyExists conditions of several matches, which would only fail on an event carrying all
+// of them
+func validateCombinedCondition(condition AppendCondition) error {
+	if err := validateConditionQuery(condition); err != nil {
+		return err
+	}
+	ac, ok := condition.(*appendCondition)
+	if !ok || !ac.anyItem || ac.FailIfEventsMatch == nil || len(ac.FailIfEventsMatch.Items) < 2 {
+		return nil
+	}
+	return &ValidationError{
+		EventStoreError: EventStoreError{
+			Op:  "validateConditionQuery",
+			Err: fmt.Errorf("a FailIfAnyExists condition with %d matches must be checked with AppendIfNotExists; AppendIf would only fail on an event matching all of them", len(ac.FailIfEventsMatch.Items)),
+		},
+		Field: "condition",
+		Value: "FailIfAnyExists",
+	}
+}
+
+// validateConditionQuery validates that an append condition only uses predicates
+// that the append functions can evaluate (event types and tags)
+func validateConditionQuery(condition AppendCondition) error {
+	failQuery := condition.getFailIfEventsMatch()
+	if failQuery == nil {
+		return nil
+	}
+
+	if err := (*failQuery).Validate(); err != nil {
+		return err
+	}
+
+	for itemIndex, item := range (*failQuery).GetItems() {
+		if qi, ok := asQueryItem(item); ok && qi.hasExtendedPredicates() {
+			return &ValidationError{
+				EventStoreError: EventStoreError{
+					Op:  "validateConditionQuery",
+					Err: fmt.Errorf("item %d of append condition uses predicates not supported in conditions (only event types and tags are)", itemIndex),
+				},
+				Field: fmt.Sprintf("condition.item[%d]", itemIndex),
+				Value: "extended predicate",
+			}
+		}
+	}
+
+	return nil
+}
+
 // validateEvent validates a single event and returns a ValidationError if invalid
 func validateEvent(e InputEvent, index int) error {
 	// Validate JSON data FIRST (fail early)
@@ -81,6 +131,13 @@ func validateEvent(e InputEvent, index int) error {
 		}
 	}
 
+	// Events marked as JSON Patches must carry a well-formed patch
+	if IsJSONPatchType(e.GetType()) {
+		if err := validateJSONPatchEvent(e, index); err != nil {
+			return err
+		}
+	}
+
 	if len(e.GetTags()) == 0 {
 		return &ValidationError{
 			EventStoreError: EventStoreError{
@@ -115,7 +172,46 @@ func validateEvent(e InputEvent, index int) error {
 		}
 	}
 
-	return nil
+	return validateCommandMetadata(e, index)
+}
+
+// skipEmptyAppend reports whether events is empty and EventStoreConfig.AllowEmptyAppend turns
+// the append into a no-op; otherwise an empty batch is rejected by validateAppendEvents
+func (es *eventStore) skipEmptyAppend(events []InputEvent) bool {
+	return len(events) == 0 && es.config.AllowEmptyAppend
+}
+
+// validateAppendEvents validates a batch before any database work: it must be non-empty,
+// within MaxAppendBatchSize, and every event must be valid, within MaxEventDataSize, free of
+// reserved tag keys and of blocked tags (BlockTag)
+func (es *eventStore) validateAppendEvents(events []InputEvent, operation string) error {
+	if len(events) == 0 {
+		return &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  operation,
+				Err: fmt.Errorf("events slice cannot be empty (set EventStoreConfig.AllowEmptyAppend to make it a no-op)"),
+			},
+			Field: "events",
+			Value: "empty",
+		}
+	}
+
+	if err := es.validateBatchSize(events, operation); err != nil {
+		return err
+	}
+
+	for i, event := range events {
+		if err := es.validateEventDataSize(event, i, operation); err != nil {
+			return err
+		}
+		if err := validateEvent(event, i); err != nil {
+			return err
+		}
+	}
+	if err := es.validateReservedTags(events, operation); err != nil {
+		return err
+	}
+	return es.validateBlockedTags(events, operation)
 }
 
 // validateBatchSize validates that the batch size is within limits
@@ -124,7 +220,7 @@ func (es *eventStore) validateBatchSize(events []InputEvent, operation string) e
 		return &ValidationError{
 			EventStoreError: EventStoreError{
 				Op:  operation,
-				Err: fmt.Errorf("batch size %d exceeds maximum %d", len(events), es.config.MaxAppendBatchSize),
+				Err: fmt.Errorf("batch size %d exceeds maximum %d (MaxAppendBatchSize); split the events into smaller batches", len(events), es.config.MaxAppendBatchSize),
 			},
 			Field: "batchSize",
 			Value: fmt.Sprintf("%d", len(events)),
@@ -133,4 +229,17 @@ func (es *eventStore) validateBatchSize(events []InputEvent, operation string) e
 	return nil
 }
 
-
+// validateEventDataSize validates that the event data is within MaxEventDataSize (0 = no limit)
+func (es *eventStore) validateEventDataSize(e InputEvent, index int, operation string) error {
+	if es.config.MaxEventDataSize <= 0 || len(e.GetData()) <= es.config.MaxEventDataSize {
+		return nil
+	}
+	return &ValidationError{
+		EventStoreError: EventStoreError{
+			Op:  operation,
+			Err: fmt.Errorf("data of event %d is %d bytes, exceeds maximum %d (MaxEventDataSize)", index, len(e.GetData()), es.config.MaxEventDataSize),
+		},
+		Field: fmt.Sprintf("event[%d].data", index),
+		Value: fmt.Sprintf("%d", len(e.GetData())),
+	}
+}
@@ -0,0 +1,278 @@
+package dcb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Materialized Views
+// =============================================================================
+
+// ViewDefinition describes a read model kept by MaterializeView: one JSON row per key in the
+// dcb_view_rows table, folded from the events matching Query
+type ViewDefinition struct {
+	// Name identifies the view's rows and checkpoint
+	Name string
+	// Query selects the events the view is built from
+	Query Query
+	// Key returns the row an event updates, e.g. the value of its course_id tag; "" skips the event
+	Key func(event Event) string
+	// Reduce returns the new state of the row given its current state (nil for a new row) and
+	// the event. The state must be JSON; returning nil deletes the row
+	Reduce func(state json.RawMessage, event Event) (json.RawMessage, error)
+	// BatchSize is the number of events applied per transaction (DefaultReplayBatchSize when zero)
+	BatchSize int
+}
+
+// ViewResult summarizes a MaterializeView
+type ViewResult struct {
+	// Applied is the number of events reduced into a row
+	Applied int
+	// Cursor is the view's checkpoint after the run, nil if it has not processed any event
+	Cursor *Cursor
+}
+
+// MaterializeView brings view up to date: it applies the committed events matching view.Query
+// after the view's checkpoint to its rows and returns once it has caught up. Each batch is applied
+// in one transaction that also moves the checkpoint, so rows and checkpoint never disagree and
+// every event is applied exactly once, even across crashes. Concurrent calls for the same view
+// take turns on the checkpoint row.
+//
+// Views are updated on demand: call MaterializeView after appending, periodically, or on every
+// event received from Subscribe. Read the rows with QueryView. A Reduce error rolls back the
+// batch and is returned as a ResourceError (Resource "handler"); the view keeps the batches
+// applied before it. To rebuild a view after changing Reduce, use a new Name. The dcb_view_rows
+// and dcb_view_checkpoints tables come from docker-entrypoint-initdb.d/schema.sql; without them
+// MaterializeView and QueryView return a ConfigurationError.
+func (es *eventStore) MaterializeView(ctx context.Context, view ViewDefinition) (ViewResult, error) {
+	if err := validateViewDefinition(view); err != nil {
+		return ViewResult{}, err
+	}
+	batchSize := view.BatchSize
+	if batchSize == 0 {
+		batchSize = DefaultReplayBatchSize
+	}
+
+	var result ViewResult
+	for {
+		applied, cursor, read, err := es.materializeViewBatch(ctx, view, batchSize)
+		if err != nil {
+			return result, err
+		}
+		result.Applied += applied
+		result.Cursor = cursor
+		if read < batchSize {
+			return result, nil
+		}
+	}
+}
+
+// materializeViewBatch applies the next batch of events to the view's rows and checkpoint in one
+// transaction. It returns the events applied, the checkpoint and the number of events read
+func (es *eventStore) materializeViewBatch(ctx context.Context, view ViewDefinition, batchSize int) (int, *Cursor, int, error) {
+	tx, err := es.beginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, nil, 0, wrapDatabaseError("materialize_view", "failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the checkpoint, so a concurrent materializer of the view waits for this batch
+	if _, err := tx.Exec(ctx, `INSERT INTO dcb_view_checkpoints (view_name) VALUES ($1) ON CONFLICT (view_name) DO NOTHING`, view.Name); err != nil {
+		if configErr := asMissingTableError("materialize_view", "dcb_view_checkpoints", err); configErr != nil {
+			return 0, nil, 0, configErr
+		}
+		return 0, nil, 0, wrapDatabaseError("materialize_view", "failed to create checkpoint", err)
+	}
+	var checkpoint Cursor
+	err = tx.QueryRow(ctx, `SELECT transaction_id, position FROM dcb_view_checkpoints WHERE view_name = $1 FOR UPDATE`, view.Name).
+		Scan(&checkpoint.TransactionID, &checkpoint.Position)
+	if err != nil {
+		return 0, nil, 0, wrapDatabaseError("materialize_view", "failed to load checkpoint", err)
+	}
+	var after *Cursor
+	if checkpoint.TransactionID != 0 {
+		after = &checkpoint
+	}
+
+	events, err := es.readCommittedPage(ctx, "materialize_view", view.Query, after, batchSize)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	if len(events) == 0 {
+		return 0, after, 0, nil
+	}
+
+	states, err := loadViewRows(ctx, tx, view, events)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	applied := 0
+	for _, event := range events {
+		key := view.Key(event)
+		if key == "" {
+			continue
+		}
+		state, err := view.Reduce(states[key], event)
+		if err == nil && state != nil && !json.Valid(state) {
+			err = fmt.Errorf("state of key %q is not valid JSON", key)
+		}
+		if err != nil {
+			return 0, nil, 0, &ResourceError{
+				EventStoreError: EventStoreError{
+					Op:  "materialize_view",
+					Err: fmt.Errorf("view %s: reduce failed at position %d: %w", view.Name, event.Position, err),
+				},
+				Resource: "handler",
+			}
+		}
+		states[key] = state
+		applied++
+	}
+
+	if err := saveViewRows(ctx, tx, view.Name, states); err != nil {
+		return 0, nil, 0, err
+	}
+	last := events[len(events)-1]
+	checkpoint = Cursor{TransactionID: last.TransactionID, Position: last.Position}
+	_, err = tx.Exec(ctx, `
+		UPDATE dcb_view_checkpoints SET transaction_id = $2, position = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE view_name = $1
+	`, view.Name, checkpoint.TransactionID, checkpoint.Position)
+	if err != nil {
+		return 0, nil, 0, wrapDatabaseError("materialize_view", "failed to save checkpoint", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, nil, 0, wrapDatabaseError("materialize_view", "failed to commit transaction", err)
+	}
+	return applied, &checkpoint, len(events), nil
+}
+
+// loadViewRows returns the current state of every row the events update; keys without a row
+// are present with a nil state
+func loadViewRows(ctx context.Context, tx pgx.Tx, view ViewDefinition, events []Event) (map[string]json.RawMessage, error) {
+	states := make(map[string]json.RawMessage)
+	var keys []string
+	for _, event := range events {
+		key := view.Key(event)
+		if _, ok := states[key]; key == "" || ok {
+			continue
+		}
+		states[key] = nil
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return states, nil
+	}
+
+	rows, err := tx.Query(ctx, `SELECT key, state FROM dcb_view_rows WHERE view_name = $1 AND key = ANY($2)`, view.Name, keys)
+	if err != nil {
+		return nil, wrapDatabaseError("materialize_view", "failed to load view rows", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key string
+		var state json.RawMessage
+		if err := rows.Scan(&key, &state); err != nil {
+			return nil, wrapDatabaseError("materialize_view", "failed to scan view row", err)
+		}
+		states[key] = state
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrapDatabaseError("materialize_view", "failed to load view rows", err)
+	}
+	return states, nil
+}
+
+// saveViewRows writes the states back: rows with a state are upserted, nil states are deleted
+func saveViewRows(ctx context.Context, tx pgx.Tx, name string, states map[string]json.RawMessage) error {
+	var keys, values, deleted []string
+	for key, state := range states {
+		if state == nil {
+			deleted = append(deleted, key)
+			continue
+		}
+		keys = append(keys, key)
+		values = append(values, string(state))
+	}
+	if len(keys) > 0 {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO dcb_view_rows (view_name, key, state, updated_at)
+			SELECT $1, k, s::jsonb, CURRENT_TIMESTAMP FROM unnest($2::text[], $3::text[]) AS u(k, s)
+			ON CONFLICT (view_name, key) DO UPDATE SET state = EXCLUDED.state, updated_at = EXCLUDED.updated_at
+		`, name, keys, values)
+		if err != nil {
+			return wrapDatabaseError("materialize_view", "failed to save view rows", err)
+		}
+	}
+	if len(deleted) > 0 {
+		if _, err := tx.Exec(ctx, `DELETE FROM dcb_view_rows WHERE view_name = $1 AND key = ANY($2)`, name, deleted); err != nil {
+			return wrapDatabaseError("materialize_view", "failed to delete view rows", err)
+		}
+	}
+	return nil
+}
+
+// QueryView returns the state of key in the view maintained by MaterializeView; ok is false when
+// the view has no row for key. The row is as of the view's last MaterializeView
+func (es *eventStore) QueryView(ctx context.Context, viewName, key string) (json.RawMessage, bool, error) {
+	if viewName == "" {
+		return nil, false, &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "query_view",
+				Err: fmt.Errorf("view name cannot be empty"),
+			},
+			Field: "viewName",
+			Value: viewName,
+		}
+	}
+	db, err := es.db()
+	if err != nil {
+		return nil, false, err
+	}
+
+	var state json.RawMessage
+	err = db.QueryRow(ctx, `SELECT state FROM dcb_view_rows WHERE view_name = $1 AND key = $2`, viewName, key).Scan(&state)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, nil
+	}
+	if configErr := asMissingTableError("query_view", "dcb_view_rows", err); configErr != nil {
+		return nil, false, configErr
+	}
+	if err != nil {
+		return nil, false, wrapDatabaseError("query_view", "failed to read view row", err)
+	}
+	return state, true, nil
+}
+
+// validateViewDefinition checks a ViewDefinition before any database work
+func validateViewDefinition(view ViewDefinition) error {
+	field, reason := "", ""
+	switch {
+	case view.Name == "":
+		field, reason = "name", "view name cannot be empty"
+	case view.Query == nil:
+		field, reason = "query", "view query cannot be nil"
+	case view.Key == nil:
+		field, reason = "key", "view key function cannot be nil"
+	case view.Reduce == nil:
+		field, reason = "reduce", "view reduce function cannot be nil"
+	case view.BatchSize < 0:
+		field, reason = "batchSize", fmt.Sprintf("batch size must not be negative: %d", view.BatchSize)
+	}
+	if field != "" {
+		return &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "materialize_view",
+				Err: errors.New(reason),
+			},
+			Field: field,
+			Value: view.Name,
+		}
+	}
+	return view.Query.Validate()
+}
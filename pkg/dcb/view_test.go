@@ -0,0 +1,51 @@
+package dcb
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestValidateViewDefinition(t *testing.T) {
+	valid := ViewDefinition{
+		Name:   "enrollments",
+		Query:  NewQuery(nil, "StudentEnrolled"),
+		Key:    func(Event) string { return "c1" },
+		Reduce: func(state json.RawMessage, event Event) (json.RawMessage, error) { return state, nil },
+	}
+	if err := validateViewDefinition(valid); err != nil {
+		t.Fatalf("expected valid view, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*ViewDefinition)
+		field  string
+	}{
+		{name: "empty name", modify: func(v *ViewDefinition) { v.Name = "" }, field: "name"},
+		{name: "nil query", modify: func(v *ViewDefinition) { v.Query = nil }, field: "query"},
+		{name: "nil key", modify: func(v *ViewDefinition) { v.Key = nil }, field: "key"},
+		{name: "nil reduce", modify: func(v *ViewDefinition) { v.Reduce = nil }, field: "reduce"},
+		{name: "negative batch size", modify: func(v *ViewDefinition) { v.BatchSize = -1 }, field: "batchSize"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view := valid
+			tt.modify(&view)
+			validationErr, ok := GetValidationError(validateViewDefinition(view))
+			if !ok {
+				t.Fatalf("expected ValidationError")
+			}
+			if validationErr.Field != tt.field {
+				t.Fatalf("expected field %q, got %q", tt.field, validationErr.Field)
+			}
+		})
+	}
+}
+
+func TestQueryViewRequiresName(t *testing.T) {
+	es := &eventStore{}
+	if _, _, err := es.QueryView(context.Background(), "", "c1"); !IsValidationError(err) {
+		t.Errorf("expected validation error for an empty view name, got %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package dcb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// =============================================================================
+// Connection Warm-Up
+// =============================================================================
+
+// DefaultWarmupTimeout bounds the connection warm-up when EventStoreConfig.QueryTimeout is not set
+const DefaultWarmupTimeout = 10 * time.Second
+
+// warmupConnections opens n pool connections at once and pings each, so they are established
+// before the first request instead of during it. The connections are returned to the pool
+// afterwards, where they stay until pgxpool's MaxConnIdleTime closes them.
+// The warm-up is bounded by timeout (on top of ctx); any failure is returned as a ResourceError
+func warmupConnections(ctx context.Context, pool *pgxpool.Pool, n int, timeout time.Duration) error {
+	if n <= 0 {
+		return nil
+	}
+	if maxConns := int(pool.Config().MaxConns); n > maxConns {
+		return &ValidationError{
+			EventStoreError: EventStoreError{
+				Op:  "warmup_connections",
+				Err: fmt.Errorf("cannot warm up %d connections in a pool of at most %d", n, maxConns),
+			},
+			Field: "warmupConnections",
+			Value: fmt.Sprintf("%d", n),
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Hold every connection until all are open, otherwise the pool would hand out the same one again
+	var (
+		mu       sync.Mutex
+		conns    []*pgxpool.Conn
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	for range n {
+		wg.Go(func() {
+			conn, err := pool.Acquire(ctx)
+			if err == nil {
+				err = conn.Ping(ctx)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if conn != nil {
+				conns = append(conns, conn)
+			}
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		})
+	}
+	wg.Wait()
+	for _, conn := range conns {
+		conn.Release()
+	}
+
+	if firstErr != nil {
+		return &ResourceError{
+			EventStoreError: EventStoreError{
+				Op:  "warmup_connections",
+				Err: fmt.Errorf("failed to warm up %d connections within %s: %w", n, timeout, firstErr),
+			},
+			Resource: "database",
+		}
+	}
+	return nil
+}
+
+// warmupTimeout returns the warm-up bound for config: its QueryTimeout, or DefaultWarmupTimeout
+func warmupTimeout(config EventStoreConfig) time.Duration {
+	if config.QueryTimeout > 0 {
+		return time.Duration(config.QueryTimeout) * time.Millisecond
+	}
+	return DefaultWarmupTimeout
+}